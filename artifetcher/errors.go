@@ -1,6 +1,10 @@
 package artifetcher
 
-import "fmt"
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
 
 type CreateTempFileError struct {
 	Err error
@@ -10,6 +14,18 @@ func (e CreateTempFileError) Error() string {
 	return fmt.Sprintf("cannot create temp file: %s", e.Err)
 }
 
+func (e CreateTempFileError) Code() string {
+	return "CreateTempFileError"
+}
+
+func (e CreateTempFileError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e CreateTempFileError) Retryable() bool {
+	return true
+}
+
 type FetcherRequestError struct {
 	Err error
 }
@@ -18,6 +34,18 @@ func (e FetcherRequestError) Error() string {
 	return fmt.Sprintf("cannot create artifact fetch request: %s", e.Err)
 }
 
+func (e FetcherRequestError) Code() string {
+	return "FetcherRequestError"
+}
+
+func (e FetcherRequestError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e FetcherRequestError) Retryable() bool {
+	return false
+}
+
 type GetUrlError struct {
 	Url string
 	Err error
@@ -27,6 +55,18 @@ func (e GetUrlError) Error() string {
 	return fmt.Sprintf("cannot GET url: %s: %s", e.Url, e.Err)
 }
 
+func (e GetUrlError) Code() string {
+	return "GetUrlError"
+}
+
+func (e GetUrlError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e GetUrlError) Retryable() bool {
+	return true
+}
+
 type GetStatusError struct {
 	Url    string
 	Status string
@@ -36,6 +76,18 @@ func (e GetStatusError) Error() string {
 	return fmt.Sprintf("cannot GET url: %s: %s", e.Url, e.Status)
 }
 
+func (e GetStatusError) Code() string {
+	return "GetStatusError"
+}
+
+func (e GetStatusError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e GetStatusError) Retryable() bool {
+	return false
+}
+
 type WriteResponseError struct {
 	Err error
 }
@@ -44,6 +96,18 @@ func (e WriteResponseError) Error() string {
 	return fmt.Sprintf("cannot write response to file: %s", e.Err)
 }
 
+func (e WriteResponseError) Code() string {
+	return "WriteResponseError"
+}
+
+func (e WriteResponseError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e WriteResponseError) Retryable() bool {
+	return true
+}
+
 type CreateTempDirectoryError struct {
 	Err error
 }
@@ -52,6 +116,18 @@ func (e CreateTempDirectoryError) Error() string {
 	return fmt.Sprintf("cannot create temp directory: %s", e.Err)
 }
 
+func (e CreateTempDirectoryError) Code() string {
+	return "CreateTempDirectoryError"
+}
+
+func (e CreateTempDirectoryError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e CreateTempDirectoryError) Retryable() bool {
+	return true
+}
+
 type UnzipError struct {
 	Err error
 }
@@ -59,3 +135,15 @@ type UnzipError struct {
 func (e UnzipError) Error() string {
 	return fmt.Sprintf("cannot unzip artifact: %s", e.Err)
 }
+
+func (e UnzipError) Code() string {
+	return "UnzipError"
+}
+
+func (e UnzipError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e UnzipError) Retryable() bool {
+	return false
+}
@@ -1,6 +1,10 @@
 package extractor
 
-import "fmt"
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
 
 type CreateDirectoryError struct {
 	Err error
@@ -10,6 +14,18 @@ func (e CreateDirectoryError) Error() string {
 	return fmt.Sprintf("cannot create directory: %s", e.Err)
 }
 
+func (e CreateDirectoryError) Code() string {
+	return "CreateDirectoryError"
+}
+
+func (e CreateDirectoryError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e CreateDirectoryError) Retryable() bool {
+	return true
+}
+
 type OpenZipError struct {
 	Source string
 	Err    error
@@ -23,6 +39,18 @@ Once you've confirmed that it's valid, please try again.`
 	return fmt.Sprintf("cannot open zip file: %s: %s\n%s", e.Source, e.Err, niceFixYourZipMessage)
 }
 
+func (e OpenZipError) Code() string {
+	return "OpenZipError"
+}
+
+func (e OpenZipError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e OpenZipError) Retryable() bool {
+	return false
+}
+
 type ExtractFileError struct {
 	FileName string
 	Err      error
@@ -32,6 +60,18 @@ func (e ExtractFileError) Error() string {
 	return fmt.Sprintf("cannot extract file from archive: %s: %s", e.FileName, e.Err)
 }
 
+func (e ExtractFileError) Code() string {
+	return "ExtractFileError"
+}
+
+func (e ExtractFileError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e ExtractFileError) Retryable() bool {
+	return false
+}
+
 type OpenManifestError struct {
 	Err error
 }
@@ -40,6 +80,18 @@ func (e OpenManifestError) Error() string {
 	return fmt.Sprintf("cannot open manifest file: %s", e.Err)
 }
 
+func (e OpenManifestError) Code() string {
+	return "OpenManifestError"
+}
+
+func (e OpenManifestError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e OpenManifestError) Retryable() bool {
+	return true
+}
+
 type PrintToManifestError struct {
 	Err error
 }
@@ -48,6 +100,18 @@ func (e PrintToManifestError) Error() string {
 	return fmt.Sprintf("cannot print to open manifest file: %s", e.Err)
 }
 
+func (e PrintToManifestError) Code() string {
+	return "PrintToManifestError"
+}
+
+func (e PrintToManifestError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e PrintToManifestError) Retryable() bool {
+	return true
+}
+
 type MakeDirectoryError struct {
 	Directory string
 	Err       error
@@ -57,6 +121,18 @@ func (e MakeDirectoryError) Error() string {
 	return fmt.Sprintf("cannot make directory: %s: %s", e.Directory, e.Err)
 }
 
+func (e MakeDirectoryError) Code() string {
+	return "MakeDirectoryError"
+}
+
+func (e MakeDirectoryError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e MakeDirectoryError) Retryable() bool {
+	return true
+}
+
 type OpenFileError struct {
 	SavedLocation string
 	Err           error
@@ -66,6 +142,18 @@ func (e OpenFileError) Error() string {
 	return fmt.Sprintf("cannot open file for writing: %s: %s", e.SavedLocation, e.Err)
 }
 
+func (e OpenFileError) Code() string {
+	return "OpenFileError"
+}
+
+func (e OpenFileError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e OpenFileError) Retryable() bool {
+	return true
+}
+
 type WriteFileError struct {
 	SavedLocation string
 	Err           error
@@ -74,3 +162,15 @@ type WriteFileError struct {
 func (e WriteFileError) Error() string {
 	return fmt.Sprintf("cannot write to file: %s: %s", e.SavedLocation, e.Err)
 }
+
+func (e WriteFileError) Code() string {
+	return "WriteFileError"
+}
+
+func (e WriteFileError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e WriteFileError) Retryable() bool {
+	return true
+}
@@ -4,17 +4,24 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/cloudfoundry-incubator/candiedyaml"
+	C "github.com/compozed/deployadactyl/constants"
 	"github.com/compozed/deployadactyl/controller/deployer/error_finder"
 	"github.com/compozed/deployadactyl/geterrors"
 	"github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/secrets"
 	s "github.com/compozed/deployadactyl/structs"
 )
 
 const defaultConfigPath = "./config.yml"
+const defaultHistoryFile = "./deployment_history.json"
+const defaultCanaryStep = 10
+const defaultCanaryChecks = 3
+const defaultMaxConcurrentDeployments = 10
 
 // Config is a representation of a config yaml. It can contain multiple Environments.
 type Config struct {
@@ -23,6 +30,10 @@ type Config struct {
 	Environments  map[string]s.Environment
 	Port          int
 	ErrorMatchers []interfaces.ErrorMatcher
+	HistoryFile   string
+	LockMode      string
+	MaxConcurrent int
+	QueueOrder    string
 }
 
 type configYaml struct {
@@ -60,30 +71,183 @@ func Custom(getenv func(string) string, configPath string) (Config, error) {
 }
 
 func createConfig(getenv func(string) string, environments map[string]s.Environment, errormatchers []interfaces.ErrorMatcher) (Config, error) {
+	err := getCredentials(environments)
+	if err != nil {
+		return Config{}, err
+	}
+
 	getter := geterrors.WrapFunc(getenv)
 
 	username := getter.Get("CF_USERNAME")
 	password := getter.Get("CF_PASSWORD")
 
-	if err := getter.Err("missing environment variables"); err != nil {
+	// CF_USERNAME and CF_PASSWORD are only used by environments without credentials of their own.
+	if err := getter.Err("missing environment variables"); err != nil && !haveCredentials(environments) {
 		return Config{}, err
 	}
 
+	// Secrets in CF_USERNAME and CF_PASSWORD are looked up at deploy time, like those of the environments.
+	for name, value := range map[string]string{"CF_USERNAME": username, "CF_PASSWORD": password} {
+		if secrets.IsReference(value) {
+			if _, _, _, err := secrets.ParseReference(value); err != nil {
+				return Config{}, InvalidSecretVariableError{name, value}
+			}
+		}
+	}
+
 	port, err := getPortFromEnv(getenv)
 	if err != nil {
 		return Config{}, err
 	}
 
+	historyFile := getenv("DEPLOYMENT_HISTORY_FILE")
+	if historyFile == "" {
+		historyFile = defaultHistoryFile
+	}
+
+	lockMode, err := getLockModeFromEnv(getenv)
+	if err != nil {
+		return Config{}, err
+	}
+
+	maxConcurrent, err := getMaxConcurrentFromEnv(getenv)
+	if err != nil {
+		return Config{}, err
+	}
+
+	queueOrder, err := getQueueOrderFromEnv(getenv)
+	if err != nil {
+		return Config{}, err
+	}
+
 	config := Config{
 		Username:      username,
 		Password:      password,
 		Port:          port,
 		Environments:  environments,
 		ErrorMatchers: errormatchers,
+		HistoryFile:   historyFile,
+		LockMode:      lockMode,
+		MaxConcurrent: maxConcurrent,
+		QueueOrder:    queueOrder,
 	}
 	return config, nil
 }
 
+// reference matches a ${NAME} reference to an environment variable. It is a shorthand for secret://env/NAME,
+// so that the variable is looked up at deploy time like any other secret.
+var reference = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// getCredentials checks the references in the credentials of the environments and their foundations,
+// and makes sure each of them is either a user with a password or a UAA client with a secret.
+func getCredentials(environments map[string]s.Environment) error {
+	for name, environment := range environments {
+		credentials, err := resolveCredentials(environment.Name, "", environment.Credentials)
+		if err != nil {
+			return err
+		}
+		environment.Credentials = credentials
+
+		for foundationURL, credentials := range environment.FoundationCredentials {
+			if !contains(environment.Foundations, foundationURL) {
+				return UnknownFoundationCredentialsError{environment.Name, foundationURL}
+			}
+
+			credentials, err = resolveCredentials(environment.Name, foundationURL, credentials)
+			if err != nil {
+				return err
+			}
+			environment.FoundationCredentials[foundationURL] = credentials
+		}
+
+		environments[name] = environment
+	}
+
+	return nil
+}
+
+func resolveCredentials(environment, foundationURL string, credentials s.Credentials) (s.Credentials, error) {
+	if credentials == (s.Credentials{}) {
+		return credentials, nil
+	}
+
+	for _, value := range []*string{&credentials.Username, &credentials.Password, &credentials.ClientID, &credentials.ClientSecret} {
+		if match := reference.FindStringSubmatch(*value); match != nil {
+			*value = secrets.Scheme + "env/" + match[1]
+		}
+
+		// Secrets are looked up at deploy time, so that they can be rotated without a restart.
+		if secrets.IsReference(*value) {
+			if _, _, _, err := secrets.ParseReference(*value); err != nil {
+				return s.Credentials{}, InvalidSecretReferenceError{environment, foundationURL, *value}
+			}
+		}
+	}
+
+	user := credentials.Username != "" && credentials.Password != ""
+	client := credentials.ClientID != "" && credentials.ClientSecret != ""
+	if user == client || credentials.Username+credentials.Password != "" && credentials.ClientID+credentials.ClientSecret != "" {
+		return s.Credentials{}, InvalidCredentialsError{environment, foundationURL}
+	}
+
+	return credentials, nil
+}
+
+// haveCredentials is true when every environment has credentials of its own.
+func haveCredentials(environments map[string]s.Environment) bool {
+	for _, environment := range environments {
+		if environment.Credentials == (s.Credentials{}) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func getLockModeFromEnv(getenv func(string) string) (string, error) {
+	switch mode := strings.ToLower(getenv("DEPLOYMENT_LOCK_MODE")); mode {
+	case "", C.LockModeReject:
+		return C.LockModeReject, nil
+	case C.LockModeQueue:
+		return C.LockModeQueue, nil
+	default:
+		return "", InvalidLockModeError{mode}
+	}
+}
+
+func getMaxConcurrentFromEnv(getenv func(string) string) (int, error) {
+	envMax := getenv("MAX_CONCURRENT_DEPLOYMENTS")
+	if envMax == "" {
+		return defaultMaxConcurrentDeployments, nil
+	}
+
+	maxConcurrent, err := strconv.Atoi(envMax)
+	if err != nil || maxConcurrent < 1 {
+		return 0, InvalidMaxConcurrentError{envMax}
+	}
+
+	return maxConcurrent, nil
+}
+
+func getQueueOrderFromEnv(getenv func(string) string) (string, error) {
+	switch order := strings.ToLower(getenv("DEPLOYMENT_QUEUE_ORDER")); order {
+	case "", C.QueueOrderFIFO:
+		return C.QueueOrderFIFO, nil
+	case C.QueueOrderPriority:
+		return C.QueueOrderPriority, nil
+	default:
+		return "", InvalidQueueOrderError{order}
+	}
+}
+
 func getPortFromEnv(getenv func(string) string) (int, error) {
 	envPort := getenv("PORT")
 	if envPort == "" {
@@ -130,12 +294,83 @@ func getEnvironmentsFromConfig(foundationConfig configYaml) (map[string]s.Enviro
 			environment.Instances = 1
 		}
 
+		if environment.Canary.Enabled {
+			canary, err := getCanary(environment.Name, environment.Canary)
+			if err != nil {
+				return nil, err
+			}
+			environment.Canary = canary
+		}
+
+		err := checkTimeouts(environment.Name, environment.Timeouts)
+		if err != nil {
+			return nil, err
+		}
+
+		if status := environment.RouteCheck.StatusCode; status != 0 && (status < 100 || status > 599) {
+			return nil, InvalidRouteCheckStatusError{environment.Name, status}
+		}
+
+		switch strings.ToLower(environment.Strategy) {
+		case "", C.StrategyConcurrent:
+			environment.Strategy = C.StrategyConcurrent
+		case C.StrategyRolling:
+			environment.Strategy = C.StrategyRolling
+			if environment.BatchSize < 1 {
+				environment.BatchSize = 1
+			}
+		default:
+			return nil, InvalidStrategyError{environment.Name, environment.Strategy}
+		}
+
 		environments[strings.ToLower(environment.Name)] = environment
 	}
 
 	return environments, nil
 }
 
+// getCanary fills in the defaults of a canary and makes sure its steps only go up, ending at 100 percent.
+func getCanary(environment string, canary s.Canary) (s.Canary, error) {
+	if len(canary.Steps) == 0 {
+		canary.Steps = []uint16{defaultCanaryStep}
+	}
+	if canary.Checks < 1 {
+		canary.Checks = defaultCanaryChecks
+	}
+	if canary.Interval < 0 {
+		canary.Interval = 0
+	}
+
+	var previous uint16
+	for _, step := range canary.Steps {
+		if step <= previous || step > 100 {
+			return s.Canary{}, InvalidCanaryStepsError{environment, canary.Steps}
+		}
+		previous = step
+	}
+
+	if previous != 100 {
+		canary.Steps = append(canary.Steps, 100)
+	}
+
+	return canary, nil
+}
+
+// checkTimeouts makes sure none of the timeouts of an environment are negative.
+func checkTimeouts(environment string, timeouts s.Timeouts) error {
+	for name, seconds := range map[string]int{
+		"login":        timeouts.Login,
+		"push":         timeouts.Push,
+		"health_check": timeouts.HealthCheck,
+		"deployment":   timeouts.Deployment,
+	} {
+		if seconds < 0 {
+			return InvalidTimeoutError{environment, name, seconds}
+		}
+	}
+	return nil
+}
+
 func parseConfig(configPath string) (configYaml, error) {
 	file, err := ioutil.ReadFile(configPath)
 	if err != nil {
@@ -36,6 +36,7 @@ environments:
   - api3.example.com
   - api4.example.com
   skip_ssl: false
+  client_credentials: true
   custom_params:
     service_now_table_name: change_request
     service_now_column_names:
@@ -100,15 +101,18 @@ var _ = Describe("Config", func() {
 				Domain:       "test.example.com",
 				SkipSSL:      true,
 				Instances:    3,
+				Strategy:     "concurrent",
 				CustomParams: testCustomParams,
 			},
 			"prod": {
-				Name:         "Prod",
-				Foundations:  []string{"api3.example.com", "api4.example.com"},
-				Domain:       "example.com",
-				SkipSSL:      false,
-				Instances:    1,
-				CustomParams: prodCustomParams,
+				Name:              "Prod",
+				Foundations:       []string{"api3.example.com", "api4.example.com"},
+				Domain:            "example.com",
+				SkipSSL:           false,
+				ClientCredentials: true,
+				Instances:         1,
+				Strategy:          "concurrent",
+				CustomParams:      prodCustomParams,
 			},
 		}
 
@@ -133,6 +137,113 @@ var _ = Describe("Config", func() {
 			Expect(config.Password).To(Equal(cfPassword))
 			Expect(config.Environments).To(Equal(envMap))
 			Expect(config.Port).To(Equal(8080))
+			Expect(config.HistoryFile).To(Equal("./deployment_history.json"))
+			Expect(config.LockMode).To(Equal("reject"))
+			Expect(config.MaxConcurrent).To(Equal(10))
+			Expect(config.QueueOrder).To(Equal("fifo"))
+		})
+	})
+
+	Context("when CF_USERNAME or CF_PASSWORD reference a secret", func() {
+		It("keeps the reference to look it up at deploy time", func() {
+			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
+			env.GetCall.Returns.Values["CF_PASSWORD"] = "secret://vault/secret/data/deployadactyl#password"
+
+			config, err := Custom(env.Get, customConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Password).To(Equal("secret://vault/secret/data/deployadactyl#password"))
+		})
+
+		It("returns an error when the reference is malformed", func() {
+			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
+			env.GetCall.Returns.Values["CF_PASSWORD"] = "secret://vault"
+
+			_, err := Custom(env.Get, customConfigPath)
+
+			Expect(err).To(MatchError(InvalidSecretVariableError{"CF_PASSWORD", "secret://vault"}))
+		})
+	})
+
+	Context("when DEPLOYMENT_HISTORY_FILE is in the environment", func() {
+		It("uses the value as the history file", func() {
+			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
+			env.GetCall.Returns.Values["CF_PASSWORD"] = cfPassword
+			env.GetCall.Returns.Values["DEPLOYMENT_HISTORY_FILE"] = "/tmp/history.json"
+
+			config, err := Custom(env.Get, customConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.HistoryFile).To(Equal("/tmp/history.json"))
+		})
+	})
+
+	Context("when DEPLOYMENT_LOCK_MODE is in the environment", func() {
+		It("uses the value as the lock mode", func() {
+			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
+			env.GetCall.Returns.Values["CF_PASSWORD"] = cfPassword
+			env.GetCall.Returns.Values["DEPLOYMENT_LOCK_MODE"] = "Queue"
+
+			config, err := Custom(env.Get, customConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.LockMode).To(Equal("queue"))
+		})
+
+		It("returns an error when the value is not a lock mode", func() {
+			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
+			env.GetCall.Returns.Values["CF_PASSWORD"] = cfPassword
+			env.GetCall.Returns.Values["DEPLOYMENT_LOCK_MODE"] = "wait"
+
+			_, err := Custom(env.Get, customConfigPath)
+
+			Expect(err).To(MatchError(InvalidLockModeError{Mode: "wait"}))
+		})
+	})
+
+	Context("when MAX_CONCURRENT_DEPLOYMENTS is in the environment", func() {
+		It("uses the value as the number of deployments that run at once", func() {
+			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
+			env.GetCall.Returns.Values["CF_PASSWORD"] = cfPassword
+			env.GetCall.Returns.Values["MAX_CONCURRENT_DEPLOYMENTS"] = "3"
+
+			config, err := Custom(env.Get, customConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.MaxConcurrent).To(Equal(3))
+		})
+
+		It("returns an error when the value is not a positive number", func() {
+			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
+			env.GetCall.Returns.Values["CF_PASSWORD"] = cfPassword
+			env.GetCall.Returns.Values["MAX_CONCURRENT_DEPLOYMENTS"] = "0"
+
+			_, err := Custom(env.Get, customConfigPath)
+
+			Expect(err).To(MatchError(InvalidMaxConcurrentError{Value: "0"}))
+		})
+	})
+
+	Context("when DEPLOYMENT_QUEUE_ORDER is in the environment", func() {
+		It("uses the value as the queue order", func() {
+			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
+			env.GetCall.Returns.Values["CF_PASSWORD"] = cfPassword
+			env.GetCall.Returns.Values["DEPLOYMENT_QUEUE_ORDER"] = "Priority"
+
+			config, err := Custom(env.Get, customConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.QueueOrder).To(Equal("priority"))
+		})
+
+		It("returns an error when the value is not a queue order", func() {
+			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
+			env.GetCall.Returns.Values["CF_PASSWORD"] = cfPassword
+			env.GetCall.Returns.Values["DEPLOYMENT_QUEUE_ORDER"] = "lifo"
+
+			_, err := Custom(env.Get, customConfigPath)
+
+			Expect(err).To(MatchError(InvalidQueueOrderError{Order: "lifo"}))
 		})
 	})
 
@@ -248,6 +359,236 @@ environments:
 		})
 	})
 
+	Context("when an environment is a canary", func() {
+		BeforeEach(func() {
+			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
+			env.GetCall.Returns.Values["CF_PASSWORD"] = cfPassword
+		})
+
+		It("ends the steps at 100 percent", func() {
+			canaryConfig := `---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  domain: example.com
+  canary:
+    enabled: true
+    steps: [10, 50]
+    checks: 5
+    interval: 30
+`
+			Expect(ioutil.WriteFile(badConfigPath, []byte(canaryConfig), 0644)).To(Succeed())
+
+			config, err := Custom(env.Get, badConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Environments["production"].Canary).To(Equal(S.Canary{
+				Enabled:  true,
+				Steps:    []uint16{10, 50, 100},
+				Checks:   5,
+				Interval: 30,
+			}))
+		})
+
+		It("uses the default steps and checks", func() {
+			canaryConfig := `---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  canary:
+    enabled: true
+`
+			Expect(ioutil.WriteFile(badConfigPath, []byte(canaryConfig), 0644)).To(Succeed())
+
+			config, err := Custom(env.Get, badConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Environments["production"].Canary.Steps).To(Equal([]uint16{10, 100}))
+			Expect(config.Environments["production"].Canary.Checks).To(Equal(3))
+		})
+
+		It("returns an error when the steps do not go up", func() {
+			canaryConfig := `---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  canary:
+    enabled: true
+    steps: [50, 10]
+`
+			Expect(ioutil.WriteFile(badConfigPath, []byte(canaryConfig), 0644)).To(Succeed())
+
+			_, err := Custom(env.Get, badConfigPath)
+			Expect(err).To(MatchError(InvalidCanaryStepsError{Environment: "production", Steps: []uint16{50, 10}}))
+		})
+	})
+
+	Context("when an environment has a strategy", func() {
+		BeforeEach(func() {
+			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
+			env.GetCall.Returns.Values["CF_PASSWORD"] = cfPassword
+		})
+
+		It("deploys to all foundations at once by default", func() {
+			Expect(ioutil.WriteFile(badConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+`), 0644)).To(Succeed())
+
+			config, err := Custom(env.Get, badConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Environments["production"].Strategy).To(Equal("concurrent"))
+		})
+
+		It("deploys one foundation at a time when rolling without a batch size", func() {
+			Expect(ioutil.WriteFile(badConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  - api2.example.com
+  strategy: Rolling
+`), 0644)).To(Succeed())
+
+			config, err := Custom(env.Get, badConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Environments["production"].Strategy).To(Equal("rolling"))
+			Expect(config.Environments["production"].BatchSize).To(Equal(uint16(1)))
+		})
+
+		It("keeps the batch size of a rolling environment", func() {
+			Expect(ioutil.WriteFile(badConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  - api2.example.com
+  - api3.example.com
+  strategy: rolling
+  batch_size: 2
+`), 0644)).To(Succeed())
+
+			config, err := Custom(env.Get, badConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Environments["production"].BatchSize).To(Equal(uint16(2)))
+		})
+
+		It("reads how many deployments may run at once in an environment", func() {
+			Expect(ioutil.WriteFile(badConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  max_concurrent_deployments: 2
+`), 0644)).To(Succeed())
+
+			config, err := Custom(env.Get, badConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Environments["production"].MaxConcurrent).To(Equal(2))
+		})
+
+		It("reads the highest priority deployments to an environment may have", func() {
+			Expect(ioutil.WriteFile(badConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  max_priority: 3
+`), 0644)).To(Succeed())
+
+			config, err := Custom(env.Get, badConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Environments["production"].MaxPriority).To(Equal(3))
+		})
+
+		It("reads the timeouts of an environment", func() {
+			Expect(ioutil.WriteFile(badConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  timeouts:
+    login: 30
+    push: 600
+    health_check: 5
+    deployment: 1800
+`), 0644)).To(Succeed())
+
+			config, err := Custom(env.Get, badConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Environments["production"].Timeouts).To(Equal(S.Timeouts{Login: 30, Push: 600, HealthCheck: 5, Deployment: 1800}))
+		})
+
+		It("returns an error for a negative timeout", func() {
+			Expect(ioutil.WriteFile(badConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  timeouts:
+    push: -1
+`), 0644)).To(Succeed())
+
+			_, err := Custom(env.Get, badConfigPath)
+			Expect(err).To(MatchError(InvalidTimeoutError{Environment: "production", Timeout: "push", Seconds: -1}))
+		})
+
+		It("reads the route check of an environment", func() {
+			Expect(ioutil.WriteFile(badConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  route_check:
+    path: /ready
+    status_code: 204
+`), 0644)).To(Succeed())
+
+			config, err := Custom(env.Get, badConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Environments["production"].RouteCheck).To(Equal(S.RouteCheck{Path: "/ready", StatusCode: 204}))
+		})
+
+		It("returns an error for a route check status code that is not an http status code", func() {
+			Expect(ioutil.WriteFile(badConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  route_check:
+    status_code: 2000
+`), 0644)).To(Succeed())
+
+			_, err := Custom(env.Get, badConfigPath)
+			Expect(err).To(MatchError(InvalidRouteCheckStatusError{Environment: "production", StatusCode: 2000}))
+		})
+
+		It("returns an error for an unknown strategy", func() {
+			Expect(ioutil.WriteFile(badConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  strategy: sideways
+`), 0644)).To(Succeed())
+
+			_, err := Custom(env.Get, badConfigPath)
+			Expect(err).To(MatchError(InvalidStrategyError{Environment: "production", Strategy: "sideways"}))
+		})
+	})
+
 	Context("when no error matchers are present", func() {
 		It("has zero error matchers", func() {
 			env.GetCall.Returns.Values["CF_USERNAME"] = cfUsername
@@ -301,4 +642,122 @@ error_matchers:
 			Expect(config.ErrorMatchers[1].Descriptor()).To(Equal("another matcher: cd: 34: "))
 		})
 	})
+
+	Context("when environments have credentials", func() {
+		const credentialsConfig = `---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  - api2.example.com
+  credentials:
+    username: ${PRODUCTION_USERNAME}
+    password: ${PRODUCTION_PASSWORD}
+  foundation_credentials:
+    api2.example.com:
+      client_id: deployadactyl
+      client_secret: ${API2_CLIENT_SECRET}
+- name: preproduction
+  foundations:
+  - api3.example.com
+  credentials:
+    client_id: ${PREPRODUCTION_CLIENT_ID}
+    client_secret: ${PREPRODUCTION_CLIENT_SECRET}
+`
+
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(customConfigPath, []byte(credentialsConfig), 0644)).To(Succeed())
+		})
+
+		It("turns the references to environment variables into secret references", func() {
+			config, err := Custom(env.Get, customConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Environments["production"].Credentials).To(Equal(S.Credentials{Username: "secret://env/PRODUCTION_USERNAME", Password: "secret://env/PRODUCTION_PASSWORD"}))
+			Expect(config.Environments["production"].FoundationCredentials).To(Equal(map[string]S.Credentials{
+				"api2.example.com": {ClientID: "deployadactyl", ClientSecret: "secret://env/API2_CLIENT_SECRET"},
+			}))
+			Expect(config.Environments["preproduction"].Credentials).To(Equal(S.Credentials{ClientID: "secret://env/PREPRODUCTION_CLIENT_ID", ClientSecret: "secret://env/PREPRODUCTION_CLIENT_SECRET"}))
+		})
+
+		It("does not need CF_USERNAME and CF_PASSWORD", func() {
+			_, err := Custom(env.Get, customConfigPath)
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("does not look up the referenced variables when it starts", func() {
+			_, err := Custom(env.Get, customConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(env.GetCall.Received.Keys).ToNot(ContainElement("API2_CLIENT_SECRET"))
+		})
+
+		It("returns an error when credentials mix a user and a client", func() {
+			Expect(ioutil.WriteFile(customConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  credentials:
+    username: user
+    password: password
+    client_id: client
+`), 0644)).To(Succeed())
+
+			_, err := Custom(env.Get, customConfigPath)
+
+			Expect(err).To(MatchError(InvalidCredentialsError{"production", ""}))
+		})
+
+		It("returns an error when credentials are given for another foundation", func() {
+			Expect(ioutil.WriteFile(customConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  foundation_credentials:
+    api9.example.com:
+      username: user
+      password: password
+`), 0644)).To(Succeed())
+
+			_, err := Custom(env.Get, customConfigPath)
+
+			Expect(err).To(MatchError(UnknownFoundationCredentialsError{"production", "api9.example.com"}))
+		})
+
+		It("keeps references to secrets to look them up at deploy time", func() {
+			Expect(ioutil.WriteFile(customConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  credentials:
+    username: deployadactyl
+    password: secret://vault/secret/data/production#password
+`), 0644)).To(Succeed())
+
+			config, err := Custom(env.Get, customConfigPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.Environments["production"].Credentials).To(Equal(S.Credentials{Username: "deployadactyl", Password: "secret://vault/secret/data/production#password"}))
+		})
+
+		It("returns an error when a reference to a secret is malformed", func() {
+			Expect(ioutil.WriteFile(customConfigPath, []byte(`---
+environments:
+- name: production
+  foundations:
+  - api1.example.com
+  credentials:
+    username: deployadactyl
+    password: secret://vault
+`), 0644)).To(Succeed())
+
+			_, err := Custom(env.Get, customConfigPath)
+
+			Expect(err).To(MatchError(InvalidSecretReferenceError{"production", "", "secret://vault"}))
+		})
+	})
 })
@@ -21,3 +21,108 @@ type ParseYamlError struct {
 func (e ParseYamlError) Error() string {
 	return fmt.Sprintf("cannot parse yaml file: %s", e.Err)
 }
+
+type InvalidCanaryStepsError struct {
+	Environment string
+	Steps       []uint16
+}
+
+func (e InvalidCanaryStepsError) Error() string {
+	return fmt.Sprintf("canary steps of environment %s must go up from 1 to 100 percent: %v", e.Environment, e.Steps)
+}
+
+type InvalidTimeoutError struct {
+	Environment string
+	Timeout     string
+	Seconds     int
+}
+
+func (e InvalidTimeoutError) Error() string {
+	return fmt.Sprintf("%s timeout of environment %s cannot be negative: %d", e.Timeout, e.Environment, e.Seconds)
+}
+
+type InvalidRouteCheckStatusError struct {
+	Environment string
+	StatusCode  int
+}
+
+func (e InvalidRouteCheckStatusError) Error() string {
+	return fmt.Sprintf("route check status code of environment %s is not an http status code: %d", e.Environment, e.StatusCode)
+}
+
+type InvalidStrategyError struct {
+	Environment string
+	Strategy    string
+}
+
+func (e InvalidStrategyError) Error() string {
+	return fmt.Sprintf("strategy of environment %s must be concurrent or rolling: %s", e.Environment, e.Strategy)
+}
+
+type InvalidLockModeError struct {
+	Mode string
+}
+
+func (e InvalidLockModeError) Error() string {
+	return fmt.Sprintf("DEPLOYMENT_LOCK_MODE must be reject or queue: %s", e.Mode)
+}
+
+type InvalidMaxConcurrentError struct {
+	Value string
+}
+
+func (e InvalidMaxConcurrentError) Error() string {
+	return fmt.Sprintf("MAX_CONCURRENT_DEPLOYMENTS must be a number greater than zero: %s", e.Value)
+}
+
+type InvalidQueueOrderError struct {
+	Order string
+}
+
+func (e InvalidQueueOrderError) Error() string {
+	return fmt.Sprintf("DEPLOYMENT_QUEUE_ORDER must be fifo or priority: %s", e.Order)
+}
+
+type InvalidCredentialsError struct {
+	Environment string
+	Foundation  string
+}
+
+func (e InvalidCredentialsError) Error() string {
+	return fmt.Sprintf("credentials of %s must have either a username and password or a client_id and client_secret", credentialsOf(e.Environment, e.Foundation))
+}
+
+type InvalidSecretReferenceError struct {
+	Environment string
+	Foundation  string
+	Reference   string
+}
+
+func (e InvalidSecretReferenceError) Error() string {
+	return fmt.Sprintf("credentials of %s reference secret %s, which must look like secret://<provider>/<path>#<key>", credentialsOf(e.Environment, e.Foundation), e.Reference)
+}
+
+type InvalidSecretVariableError struct {
+	Variable  string
+	Reference string
+}
+
+func (e InvalidSecretVariableError) Error() string {
+	return fmt.Sprintf("%s references secret %s, which must look like secret://<provider>/<path>#<key>", e.Variable, e.Reference)
+}
+
+type UnknownFoundationCredentialsError struct {
+	Environment string
+	Foundation  string
+}
+
+func (e UnknownFoundationCredentialsError) Error() string {
+	return fmt.Sprintf("environment %s has credentials for foundation %s, which is not one of its foundations", e.Environment, e.Foundation)
+}
+
+func credentialsOf(environment, foundation string) string {
+	if foundation == "" {
+		return "environment " + environment
+	}
+	return fmt.Sprintf("foundation %s of environment %s", foundation, environment)
+}
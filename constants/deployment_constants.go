@@ -0,0 +1,55 @@
+package constants
+
+const (
+	DeploymentStatusRunning   = "running"
+	DeploymentStatusSucceeded = "succeeded"
+	DeploymentStatusFailed    = "failed"
+)
+
+const (
+	DeploymentPhaseQueued   = "queued"
+	DeploymentPhaseStarted  = "started"
+	DeploymentPhaseLogin    = "login"
+	DeploymentPhaseExecute  = "execute"
+	DeploymentPhaseVerify   = "verify"
+	DeploymentPhaseUndo     = "undo"
+	DeploymentPhaseSuccess  = "success"
+	DeploymentPhaseFinished = "finished"
+)
+
+const (
+	DeploymentTypePush    = "push"
+	DeploymentTypeStart   = "start"
+	DeploymentTypeStop    = "stop"
+	DeploymentTypeRevert  = "revert"
+	DeploymentTypeRestart = "restart"
+	DeploymentTypeRestage = "restage"
+	DeploymentTypeScale   = "scale"
+	DeploymentTypeDelete  = "delete"
+)
+
+const (
+	StrategyConcurrent = "concurrent"
+	StrategyRolling    = "rolling"
+)
+
+// Error categories tell whether a deployment failed because of the request, a foundation,
+// the artifact or Deployadactyl itself.
+const (
+	ErrorCategoryUser       = "user"
+	ErrorCategoryFoundation = "foundation"
+	ErrorCategoryArtifact   = "artifact"
+	ErrorCategoryInternal   = "internal"
+)
+
+// Lock modes decide what happens to a deployment of an application that another deployment is running against.
+const (
+	LockModeReject = "reject"
+	LockModeQueue  = "queue"
+)
+
+// Queue orders decide which waiting deployment runs next.
+const (
+	QueueOrderFIFO     = "fifo"
+	QueueOrderPriority = "priority"
+)
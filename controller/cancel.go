@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/gin-gonic/gin"
+)
+
+// CancelDeployment cancels a running deployment. Its running Cloud Foundry commands are killed
+// and it is undone on every foundation it has touched. Only the user who started the deployment can cancel it.
+func (c *Controller) CancelDeployment(g *gin.Context) {
+	uuid := g.Param("uuid")
+
+	c.cancelLock.Lock()
+	cancel, ok := c.cancels[uuid]
+	c.cancelLock.Unlock()
+
+	status, tracked := c.Tracker.Get(uuid)
+	if !ok || !tracked {
+		g.String(http.StatusNotFound, "deployment not found or already finished: %s", uuid)
+		return
+	}
+
+	if err := c.authenticate(g, status.Environment, uuid); err != nil {
+		g.String(deployer.StatusCode(err), err.Error())
+		return
+	}
+
+	c.Log.Infof("cancelling deployment %s", uuid)
+	cancel()
+
+	g.JSON(http.StatusAccepted, gin.H{
+		"uuid":       uuid,
+		"status_url": "/v3/deployments/" + uuid,
+	})
+}
+
+// cancellable returns the context of a new deployment, which is cancelled by CancelDeployment.
+func (c *Controller) cancellable(uuid string) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c.cancelLock.Lock()
+	defer c.cancelLock.Unlock()
+
+	if c.cancels == nil {
+		c.cancels = make(map[string]context.CancelFunc)
+	}
+	c.cancels[uuid] = cancel
+
+	return ctx
+}
+
+// forget releases the context of a deployment once it has finished, after which it can no longer be cancelled.
+func (c *Controller) forget(uuid string) {
+	c.cancelLock.Lock()
+	defer c.cancelLock.Unlock()
+
+	if cancel, ok := c.cancels[uuid]; ok {
+		cancel()
+		delete(c.cancels, uuid)
+	}
+}
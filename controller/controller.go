@@ -3,6 +3,7 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -11,24 +12,44 @@ import (
 	I "github.com/compozed/deployadactyl/interfaces"
 
 	"github.com/compozed/deployadactyl/config"
+	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
 	"github.com/compozed/deployadactyl/randomizer"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 type PushControllerFactory func(log I.DeploymentLogger) I.PushController
 type StartControllerFactory func(log I.DeploymentLogger) I.StartController
 type StopControllerFactory func(log I.DeploymentLogger) I.StopController
+type RevertControllerFactory func(log I.DeploymentLogger) I.RevertController
+type CommandControllerFactory func(log I.DeploymentLogger, command string) I.CommandController
+type StatusControllerFactory func(log I.DeploymentLogger) I.StatusController
 
 // Controller is used to determine the type of request and process it accordingly.
 type Controller struct {
-	Log                    I.Logger
-	PushControllerFactory  PushControllerFactory
-	StartControllerFactory StartControllerFactory
-	StopControllerFactory  StopControllerFactory
-	Config                 config.Config
-	EventManager           I.EventManager
-	ErrorFinder            I.ErrorFinder
+	Log                      I.Logger
+	PushControllerFactory    PushControllerFactory
+	StartControllerFactory   StartControllerFactory
+	StopControllerFactory    StopControllerFactory
+	RevertControllerFactory  RevertControllerFactory
+	CommandControllerFactory CommandControllerFactory
+	StatusControllerFactory  StatusControllerFactory
+	Config                   config.Config
+	EventManager             I.EventManager
+	ErrorFinder              I.ErrorFinder
+	Tracker                  I.Tracker
+	DeploymentStore          I.DeploymentStore
+	IdempotencyKeys          I.IdempotencyKeys
+	Locker                   I.Locker
+	Scheduler                I.Scheduler
+
+	cancelLock sync.Mutex
+	cancels    map[string]context.CancelFunc
 }
 
 type PutRequest struct {
@@ -77,17 +98,308 @@ func (c *Controller) RunDeploymentViaHttp(g *gin.Context) {
 	g.Request.Body.Close()
 	deployment.Body = &bodyBuffer
 
-	deployResponse := c.PushControllerFactory(log).RunDeployment(&deployment, response)
+	// A dry run does not change anything, so it is neither queued, tracked nor answered from an Idempotency-Key.
+	if dryRun(g) {
+		deployment.DryRun = true
+		c.writePlan(g, log, c.PushControllerFactory(log).RunDeployment(&deployment, response), response)
+		return
+	}
+
+	key, ok := c.claimIdempotencyKey(g, log, bodyBuffer)
+	if !ok {
+		return
+	}
+
+	c.Tracker.Start(uuid, cfContext, deployment.Authorization)
+	// The response is written once the deployment has finished, so its lines are held until then.
+	defer c.Tracker.Hold(uuid)()
+	deployment.Context = c.cancellable(uuid)
+
+	if g.Query("async") == "true" {
+		go c.deploy(log, &deployment, response, key, priority(g))
+
+		g.Header("Location", "/v3/deployments/"+uuid)
+		g.JSON(http.StatusAccepted, gin.H{
+			"uuid":       uuid,
+			"status_url": "/v3/deployments/" + uuid,
+		})
+		return
+	}
+
+	if g.Query("stream") == "true" || strings.Contains(g.Request.Header.Get("Accept"), "text/event-stream") {
+		c.streamDeployment(g, log, &deployment, response, key, priority(g))
+		return
+	}
+
+	deployResponse := c.deploy(log, &deployment, response, key, priority(g))
+
+	c.writeResponse(g, log, deployResponse, response)
+}
+
+// streamDeployment sends the Cloud Foundry output of each foundation to the client while the deployment runs,
+// as server-sent events when the client accepts them and as plain text otherwise.
+// The rest of the response, including the error summary, follows once the deployment has finished.
+func (c *Controller) streamDeployment(g *gin.Context, log I.DeploymentLogger, deployment *I.Deployment, response *bytes.Buffer, key string, priority int) {
+	lines, unsubscribe := c.Tracker.Subscribe(log.UUID)
+	defer unsubscribe()
+
+	finished := make(chan I.DeployResponse, 1)
+	go func() {
+		finished <- c.deploy(log, deployment, response, key, priority)
+	}()
+
+	events := strings.Contains(g.Request.Header.Get("Accept"), "text/event-stream")
+	if events {
+		g.Header("Content-Type", "text/event-stream")
+		g.Header("Cache-Control", "no-cache")
+	} else {
+		g.Header("Content-Type", "text/plain; charset=utf-8")
+		g.Header("Trailer", "X-Deployment-Status")
+	}
+	g.Header("X-Deployment-Uuid", log.UUID)
+	g.Writer.WriteHeader(http.StatusOK)
+	g.Writer.Flush()
+
+	for line := range lines {
+		if events {
+			writeEvent(g.Writer, "output", line)
+		} else {
+			fmt.Fprintf(g.Writer, "[%s] %s\n", line.FoundationURL, line.Line)
+		}
+		g.Writer.Flush()
+	}
+
+	deployResponse := <-finished
+	summary := withoutCloudFoundryOutput(response.String())
+
+	if events {
+		result := streamResult{StatusCode: deployResponse.StatusCode, Summary: summary}
+		if deployResponse.Error != nil {
+			result.Error = deployResponse.Error.Error()
+		}
+		writeEvent(g.Writer, "result", result)
+	} else {
+		io.WriteString(g.Writer, summary)
+		g.Writer.Header().Set("X-Deployment-Status", strconv.Itoa(deployResponse.StatusCode))
+	}
+	g.Writer.Flush()
+}
+
+type streamResult struct {
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error,omitempty"`
+	Summary    string `json:"summary"`
+}
+
+func writeEvent(w io.Writer, event string, data interface{}) {
+	encoded, _ := json.Marshal(data)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, encoded)
+}
+
+// withoutCloudFoundryOutput removes the output of the foundations, which has already been streamed, from the response.
+func withoutCloudFoundryOutput(output string) string {
+	start := strings.Index(output, "\n"+bluegreen.OutputHeader)
+	end := strings.LastIndex(output, bluegreen.OutputFooter)
+	if start < 0 || end < start {
+		return output
+	}
+
+	return output[:start] + output[end+len(bluegreen.OutputFooter):]
+}
+
+// GetDeploymentStatus returns the phase, the status of each foundation and the result of a deployment.
+func (c *Controller) GetDeploymentStatus(g *gin.Context) {
+	status, ok := c.Tracker.Get(g.Param("uuid"))
+	if !ok {
+		g.String(http.StatusNotFound, "deployment not found: %s", g.Param("uuid"))
+		return
+	}
+
+	if err := c.authenticate(g, status.Environment, status.UUID); err != nil {
+		g.String(deployer.StatusCode(err), err.Error())
+		return
+	}
+
+	if c.Scheduler != nil {
+		status.QueuePosition = c.Scheduler.Position(status.UUID)
+	}
+
+	g.JSON(http.StatusOK, status)
+}
+
+// GetDeploymentHistory returns every recorded deployment of an application, newest first.
+// Manifests and environment variables are left out since they may hold secrets.
+func (c *Controller) GetDeploymentHistory(g *gin.Context) {
+	environment := g.Param("environment")
+	if err := c.authenticate(g, environment, ""); err != nil {
+		g.String(deployer.StatusCode(err), err.Error())
+		return
+	}
+
+	records, err := c.DeploymentStore.Find(I.CFContext{
+		Environment:  environment,
+		Organization: g.Param("org"),
+		Space:        g.Param("space"),
+		Application:  g.Param("appName"),
+	})
+	if err != nil {
+		c.Log.Error(err)
+		g.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for i := range records {
+		records[i].Manifest = ""
+		records[i].EnvironmentVariables = nil
+	}
+
+	g.JSON(http.StatusOK, records)
+}
+
+// GetAppStatus returns the status of an application on every foundation of an environment,
+// along with the UUID of its last deployment.
+func (c *Controller) GetAppStatus(g *gin.Context) {
+	uuid := randomizer.StringRunes(10)
+	log := I.DeploymentLogger{Log: c.Log, UUID: uuid}
+	log.Debugf("GET Request originated from: %+v", g.Request.RemoteAddr)
+
+	cfContext := I.CFContext{
+		Environment:  g.Param("environment"),
+		Organization: g.Param("org"),
+		Space:        g.Param("space"),
+		Application:  g.Param("appName"),
+	}
 
-	defer io.Copy(g.Writer, response)
+	user, pwd, _ := g.Request.BasicAuth()
+	deployment := I.Deployment{
+		Authorization: I.Authorization{
+			Username: user,
+			Password: pwd,
+		},
+		CFContext: cfContext,
+	}
 
+	response := &bytes.Buffer{}
+	report, deployResponse := c.StatusControllerFactory(log).AppStatus(&deployment, response)
 	if deployResponse.Error != nil {
-		g.Writer.WriteHeader(deployResponse.StatusCode)
-		fmt.Fprintf(response, "cannot deploy application: %s\n", deployResponse.Error)
+		g.String(deployResponse.StatusCode, "%s", response.String())
+		return
+	}
+
+	records, err := c.DeploymentStore.Find(cfContext)
+	if err != nil {
+		log.Errorf("could not find the deployments of %s: %s", cfContext.Application, err)
+	} else if len(records) > 0 {
+		report.LastDeploymentUUID = records[0].UUID
+	}
+
+	g.JSON(http.StatusOK, report)
+}
+
+// GetAppDrift compares an application across the foundations of an environment and
+// returns the properties that are not the same everywhere.
+func (c *Controller) GetAppDrift(g *gin.Context) {
+	uuid := randomizer.StringRunes(10)
+	log := I.DeploymentLogger{Log: c.Log, UUID: uuid}
+	log.Debugf("GET Request originated from: %+v", g.Request.RemoteAddr)
+
+	user, pwd, _ := g.Request.BasicAuth()
+	deployment := I.Deployment{
+		Authorization: I.Authorization{
+			Username: user,
+			Password: pwd,
+		},
+		CFContext: I.CFContext{
+			Environment:  g.Param("environment"),
+			Organization: g.Param("org"),
+			Space:        g.Param("space"),
+			Application:  g.Param("appName"),
+		},
+	}
+
+	response := &bytes.Buffer{}
+	report, deployResponse := c.StatusControllerFactory(log).Drift(&deployment, response)
+	if deployResponse.Error != nil {
+		g.String(deployResponse.StatusCode, "%s", response.String())
 		return
 	}
 
-	g.Writer.WriteHeader(deployResponse.StatusCode)
+	g.JSON(http.StatusOK, report)
+}
+
+// GetAppLock returns the deployment that holds the lock of an application, and since when.
+func (c *Controller) GetAppLock(g *gin.Context) {
+	cfContext := I.CFContext{
+		Environment:  g.Param("environment"),
+		Organization: g.Param("org"),
+		Space:        g.Param("space"),
+		Application:  g.Param("appName"),
+	}
+
+	if err := c.authenticate(g, cfContext.Environment, ""); err != nil {
+		g.String(deployer.StatusCode(err), err.Error())
+		return
+	}
+
+	holder, ok := c.Locker.Holder(cfContext)
+	if !ok {
+		g.String(http.StatusNotFound, "%s is not locked", cfContext.Application)
+		return
+	}
+
+	if err := c.authenticate(g, cfContext.Environment, holder.UUID); err != nil {
+		g.String(deployer.StatusCode(err), err.Error())
+		return
+	}
+
+	g.JSON(http.StatusOK, holder)
+}
+
+// authenticate makes the read only routes of an environment ask for basic auth the same way its
+// deployments do. It returns an error when the environment is unknown or authenticates its users
+// and the request carries no credentials. When uuid is given, the credentials have to be the ones
+// the deployment with the UUID was started with, so that users only see their own deployments.
+func (c *Controller) authenticate(g *gin.Context, environment, uuid string) error {
+	env, ok := c.Config.Environments[environment]
+	if !ok {
+		return deployer.EnvironmentNotFoundError{Environment: environment}
+	}
+
+	if !env.Authenticate {
+		return nil
+	}
+
+	user, pwd, _ := g.Request.BasicAuth()
+	if user == "" && pwd == "" {
+		return deployer.BasicAuthError{}
+	}
+
+	if uuid != "" && !c.Tracker.Authorized(uuid, I.Authorization{Username: user, Password: pwd}) {
+		return DeploymentForbiddenError{UUID: uuid}
+	}
+
+	return nil
+}
+
+func (c *Controller) deploy(log I.DeploymentLogger, deployment *I.Deployment, response *bytes.Buffer, key string, priority int) (deployResponse I.DeployResponse) {
+	defer c.forget(log.UUID)
+	defer func() { c.finishIdempotencyKey(key, log.UUID, deployResponse, response) }()
+
+	done, err := c.schedule(log, deployment, C.DeploymentTypePush, priority)
+	if err != nil {
+		deployResponse = I.DeployResponse{StatusCode: deployer.StatusCode(err), Error: err}
+	} else {
+		deployResponse = c.PushControllerFactory(log).RunDeployment(deployment, response)
+		done()
+	}
+
+	if deployResponse.Error != nil {
+		fmt.Fprintf(response, "cannot deploy application: %s\n", deployResponse.Error)
+	}
+
+	c.Tracker.Finish(log.UUID, deployResponse, response.String())
+
+	return deployResponse
 }
 
 func (c *Controller) PutRequestHandler(g *gin.Context) {
@@ -103,7 +415,6 @@ func (c *Controller) PutRequestHandler(g *gin.Context) {
 	}
 
 	response := &bytes.Buffer{}
-	defer io.Copy(g.Writer, response)
 
 	user, pwd, _ := g.Request.BasicAuth()
 	authorization := I.Authorization{
@@ -119,26 +430,144 @@ func (c *Controller) PutRequestHandler(g *gin.Context) {
 	bodyBuffer, _ := ioutil.ReadAll(g.Request.Body)
 	g.Request.Body.Close()
 
+	if dryRun(g) {
+		deployment.DryRun = true
+		c.planPut(g, log, &deployment, bodyBuffer, response)
+		return
+	}
+
+	key, ok := c.claimIdempotencyKey(g, log, bodyBuffer)
+	if !ok {
+		return
+	}
+
+	var deployResponse I.DeployResponse
+	defer func() { c.finishIdempotencyKey(key, uuid, deployResponse, response) }()
+
+	c.Tracker.Start(uuid, cfContext, deployment.Authorization)
+	defer c.Tracker.Hold(uuid)()
+	deployment.Context = c.cancellable(uuid)
+	defer c.forget(uuid)
+
 	putRequest := &PutRequest{}
 	err := json.Unmarshal(bodyBuffer, putRequest)
 	if err != nil {
 		response.Write([]byte("Invalid request body."))
-		g.Writer.WriteHeader(http.StatusBadRequest)
+		err = deployer.InvalidRequestBodyError{Err: err}
+		deployResponse = I.DeployResponse{StatusCode: deployer.StatusCode(err), Error: err}
+		c.Tracker.Finish(uuid, deployResponse, response.String())
+		c.writeResponse(g, log, deployResponse, response)
 		return
 	}
 
-	var deployResponse I.DeployResponse
+	var (
+		run            func() I.DeployResponse
+		deploymentType string
+	)
 
 	if putRequest.State == "stopped" {
-		deployResponse = c.StopControllerFactory(log).StopDeployment(&deployment, putRequest.Data, response)
+		deploymentType = C.DeploymentTypeStop
+		run = func() I.DeployResponse {
+			return c.StopControllerFactory(log).StopDeployment(&deployment, putRequest.Data, response)
+		}
 	} else if putRequest.State == "started" {
-		deployResponse = c.StartControllerFactory(log).StartDeployment(&deployment, putRequest.Data, response)
-	} else {
+		deploymentType = C.DeploymentTypeStart
+		run = func() I.DeployResponse {
+			return c.StartControllerFactory(log).StartDeployment(&deployment, putRequest.Data, response)
+		}
+	} else if putRequest.State == "reverted" {
+		deploymentType = C.DeploymentTypeRevert
+		run = func() I.DeployResponse {
+			return c.RevertControllerFactory(log).RevertDeployment(&deployment, putRequest.Data, response)
+		}
+	} else if putRequest.State == "restarted" {
+		deploymentType = C.DeploymentTypeRestart
+		run = func() I.DeployResponse {
+			return c.CommandControllerFactory(log, C.DeploymentTypeRestart).RunCommand(&deployment, putRequest.Data, response)
+		}
+	} else if putRequest.State == "restaged" {
+		deploymentType = C.DeploymentTypeRestage
+		run = func() I.DeployResponse {
+			return c.CommandControllerFactory(log, C.DeploymentTypeRestage).RunCommand(&deployment, putRequest.Data, response)
+		}
+	} else if putRequest.State == "scaled" {
+		deploymentType = C.DeploymentTypeScale
+		run = func() I.DeployResponse {
+			return c.CommandControllerFactory(log, C.DeploymentTypeScale).RunCommand(&deployment, putRequest.Data, response)
+		}
+	} else if putRequest.State == "rolledback" {
+		deploymentType = C.DeploymentTypePush
+		run = func() I.DeployResponse {
+			return c.rollback(log, &deployment, putRequest.Data, response)
+		}
+	}
+
+	if run == nil {
 		response.Write([]byte("Unknown requested state: " + putRequest.State))
+		err = UnknownStateError{State: putRequest.State}
 		deployResponse = I.DeployResponse{
-			StatusCode: http.StatusBadRequest,
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
 		}
+	} else if done, err := c.schedule(log, &deployment, deploymentType, priority(g)); err != nil {
+		fmt.Fprintln(response, err.Error())
+		deployResponse = I.DeployResponse{StatusCode: deployer.StatusCode(err), Error: err}
+	} else {
+		deployResponse = run()
+		done()
+	}
+
+	c.Tracker.Finish(uuid, deployResponse, response.String())
+
+	c.writeResponse(g, log, deployResponse, response)
+}
+
+// DeleteRequestHandler deletes an application on every foundation of an environment.
+// The user provided services bound only to the application are deleted too when
+// the delete_services query parameter is true.
+func (c *Controller) DeleteRequestHandler(g *gin.Context) {
+	uuid := randomizer.StringRunes(10)
+	log := I.DeploymentLogger{Log: c.Log, UUID: uuid}
+	log.Debugf("DELETE Request originated from: %+v", g.Request.RemoteAddr)
+
+	cfContext := I.CFContext{
+		Environment:  g.Param("environment"),
+		Organization: g.Param("org"),
+		Space:        g.Param("space"),
+		Application:  g.Param("appName"),
 	}
 
-	g.Writer.WriteHeader(deployResponse.StatusCode)
+	response := &bytes.Buffer{}
+
+	user, pwd, _ := g.Request.BasicAuth()
+	deployment := I.Deployment{
+		Authorization: I.Authorization{
+			Username: user,
+			Password: pwd,
+		},
+		CFContext: cfContext,
+	}
+
+	data := map[string]interface{}{}
+	if g.Query("delete_services") == "true" {
+		data["delete_services"] = true
+	}
+
+	c.Tracker.Start(uuid, cfContext, deployment.Authorization)
+	defer c.Tracker.Hold(uuid)()
+	deployment.Context = c.cancellable(uuid)
+	defer c.forget(uuid)
+
+	var deployResponse I.DeployResponse
+	if done, err := c.schedule(log, &deployment, C.DeploymentTypeDelete, priority(g)); err != nil {
+		fmt.Fprintln(response, err.Error())
+		deployResponse = I.DeployResponse{StatusCode: deployer.StatusCode(err), Error: err}
+	} else {
+		deployResponse = c.CommandControllerFactory(log, C.DeploymentTypeDelete).RunCommand(&deployment, data, response)
+		done()
+	}
+
+	c.Tracker.Finish(uuid, deployResponse, response.String())
+
+	c.writeResponse(g, log, deployResponse, response)
 }
@@ -20,8 +20,9 @@ func NewActor(action I.Action) actor {
 }
 
 type actor struct {
-	Commands chan<- ActorCommand
-	Errs     <-chan error
+	Commands      chan<- ActorCommand
+	Errs          <-chan error
+	FoundationURL string
 }
 
 type ActorCommand func(action I.Action) error
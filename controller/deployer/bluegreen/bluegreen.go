@@ -3,63 +3,135 @@ package bluegreen
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
-	"strings"
 
+	C "github.com/compozed/deployadactyl/constants"
 	I "github.com/compozed/deployadactyl/interfaces"
 	S "github.com/compozed/deployadactyl/structs"
 )
 
 // BlueGreen has a PushManager to creater pushers for blue green deployments.
 type BlueGreen struct {
-	Log I.DeploymentLogger
+	Log     I.DeploymentLogger
+	Tracker I.Tracker
 }
 
-// Push will login to all the Cloud Foundry instances provided in the Config and then push the application to all the instances concurrently.
-// If the application fails to start in any of the instances it handles rolling back the application in every instance, unless it is the first deploy.
-func (bg BlueGreen) Execute(actionCreator I.ActionCreator, environment S.Environment, response io.ReadWriter) error {
+// Push will login to all the Cloud Foundry instances provided in the Config and then push the application to all the instances concurrently,
+// or to one batch of instances at a time when the environment has a rolling strategy.
+// If the application fails to start or to verify in any of the instances it handles rolling back the application in every instance, unless it is the first deploy.
+// When the context is cancelled or its deadline passes the running commands are killed and the action is undone on every instance it has touched.
+func (bg BlueGreen) Execute(ctx context.Context, actionCreator I.ActionCreator, environment S.Environment, response io.ReadWriter) error {
 
 	actors := make([]actor, len(environment.Foundations))
 	buffers := make([]*bytes.Buffer, len(environment.Foundations))
 
+	outputs := make([]*foundationOutput, 0, len(environment.Foundations))
+
 	for i, foundationURL := range environment.Foundations {
 		buffers[i] = &bytes.Buffer{}
 
-		action, err := actionCreator.Create(environment, buffers[i], foundationURL)
+		var output io.ReadWriter = buffers[i]
+		if bg.Tracker != nil {
+			o := &foundationOutput{Buffer: buffers[i], uuid: bg.Log.UUID, foundationURL: foundationURL, tracker: bg.Tracker}
+			outputs = append(outputs, o)
+			output = o
+		}
+
+		action, err := actionCreator.Create(ctx, environment, output, foundationURL)
 		if err != nil {
 			return InitializationError{err}
 		}
 		defer action.Finally()
 
 		actors[i] = NewActor(action)
+		actors[i].FoundationURL = foundationURL
 		defer close(actors[i].Commands)
 	}
 
 	defer func() {
+		for _, output := range outputs {
+			output.Flush()
+		}
+
 		for _, buffer := range buffers {
-			fmt.Fprintf(response, "\n%s Cloud Foundry Output %s\n", strings.Repeat("-", 19), strings.Repeat("-", 19))
+			fmt.Fprintf(response, "\n%s\n", OutputHeader)
 			buffer.WriteTo(response)
 		}
 
-		fmt.Fprintf(response, "\n%s End Cloud Foundry Output %s\n", strings.Repeat("-", 17), strings.Repeat("-", 17))
+		fmt.Fprintf(response, "\n%s\n", OutputFooter)
 	}()
 
-	loginErrors := bg.commands(actors, func(action I.Action) error {
+	loginErrors := bg.commands(actors, C.DeploymentPhaseLogin, func(action I.Action) error {
 		return action.Initially()
 	})
 
+	if ctx.Err() == context.DeadlineExceeded {
+		bg.Log.Errorf("deployment timed out while logging in")
+		return DeploymentTimeoutError{}
+	}
+
+	if ctx.Err() != nil {
+		bg.Log.Errorf("deployment was cancelled while logging in")
+		return CancelledError{}
+	}
+
 	if len(loginErrors) != 0 {
 		return actionCreator.InitiallyError(loginErrors)
 	}
 
-	actionErrors := bg.commands(actors, func(action I.Action) error {
+	var err error
+	if environment.Strategy == C.StrategyRolling {
+		err = bg.rollOut(ctx, actionCreator, environment, actors)
+	} else {
+		err = bg.executeAndVerify(ctx, actionCreator, actors, actors)
+	}
+	if err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return bg.cancel(ctx, actors)
+	}
+
+	finishActionErrors := bg.commands(actors, C.DeploymentPhaseSuccess, func(action I.Action) error {
+		return action.Success()
+	})
+	if len(finishActionErrors) != 0 {
+		return actionCreator.SuccessError(finishActionErrors)
+	}
+
+	return nil
+}
+
+// executeAndVerify executes and verifies the action on the given actors. If any of them fails,
+// or the deployment is cancelled, the action is undone on every touched actor.
+func (bg BlueGreen) executeAndVerify(ctx context.Context, actionCreator I.ActionCreator, actors, touched []actor) error {
+	actionErrors := bg.commands(actors, C.DeploymentPhaseExecute, func(action I.Action) error {
 		return action.Execute()
 	})
 
+	if ctx.Err() != nil {
+		return bg.cancel(ctx, touched)
+	}
+
+	if len(actionErrors) == 0 {
+		actionErrors = bg.commands(actors, C.DeploymentPhaseVerify, func(action I.Action) error {
+			return action.Verify()
+		})
+		if len(actionErrors) != 0 {
+			bg.Log.Errorf("failed to verify action against all foundations")
+		}
+
+		if ctx.Err() != nil {
+			return bg.cancel(ctx, touched)
+		}
+	}
+
 	if len(actionErrors) != 0 {
 		bg.Log.Errorf("failed to execute action against all foundations - rolling back action")
-		rollbackErrors := bg.commands(actors, func(action I.Action) error {
+		rollbackErrors := bg.commands(touched, C.DeploymentPhaseUndo, func(action I.Action) error {
 			return action.Undo()
 		})
 
@@ -70,22 +142,42 @@ func (bg BlueGreen) Execute(actionCreator I.ActionCreator, environment S.Environ
 		return actionCreator.ExecuteError(actionErrors)
 	}
 
-	finishActionErrors := bg.commands(actors, func(action I.Action) error {
-		return action.Success()
-	})
-	if len(finishActionErrors) != 0 {
-		return actionCreator.SuccessError(finishActionErrors)
+	return nil
+}
+
+// cancel undoes the action on the given actors after the deployment has been cancelled or has timed out.
+func (bg BlueGreen) cancel(ctx context.Context, touched []actor) error {
+	timedOut := ctx.Err() == context.DeadlineExceeded
+	if timedOut {
+		bg.Log.Errorf("deployment timed out - rolling back action")
+	} else {
+		bg.Log.Errorf("deployment was cancelled - rolling back action")
 	}
 
-	return nil
+	undoErrors := bg.commands(touched, C.DeploymentPhaseUndo, func(action I.Action) error {
+		return action.Undo()
+	})
+
+	if timedOut {
+		return DeploymentTimeoutError{UndoErrors: undoErrors}
+	}
+	return CancelledError{UndoErrors: undoErrors}
 }
 
-func (bg BlueGreen) commands(actors []actor, doFunc ActorCommand) (manyErrors []error) {
+func (bg BlueGreen) commands(actors []actor, phase string, doFunc ActorCommand) (manyErrors []error) {
+	if bg.Tracker != nil {
+		bg.Tracker.SetPhase(bg.Log.UUID, phase)
+	}
+
 	for _, a := range actors {
 		a.Commands <- doFunc
 	}
 	for _, a := range actors {
-		if err := <-a.Errs; err != nil {
+		err := <-a.Errs
+		if bg.Tracker != nil {
+			bg.Tracker.SetFoundationStatus(bg.Log.UUID, a.FoundationURL, phase, err)
+		}
+		if err != nil {
 			manyErrors = append(manyErrors, err)
 		}
 	}
@@ -1,8 +1,11 @@
 package bluegreen_test
 
 import (
+	"context"
 	"errors"
+	"time"
 
+	C "github.com/compozed/deployadactyl/constants"
 	. "github.com/compozed/deployadactyl/controller/deployer/bluegreen"
 	"github.com/compozed/deployadactyl/mocks"
 	"github.com/compozed/deployadactyl/randomizer"
@@ -16,6 +19,28 @@ import (
 	"github.com/compozed/deployadactyl/interfaces"
 )
 
+// cancellingPusher cancels the deployment while it executes.
+type cancellingPusher struct {
+	*mocks.Pusher
+	cancel context.CancelFunc
+}
+
+func (p cancellingPusher) Execute() error {
+	p.cancel()
+	return p.Pusher.Execute()
+}
+
+// waitingPusher waits for the deployment to run out of time while it executes.
+type waitingPusher struct {
+	*mocks.Pusher
+	ctx context.Context
+}
+
+func (p waitingPusher) Execute() error {
+	<-p.ctx.Done()
+	return p.Pusher.Execute()
+}
+
 var _ = Describe("Bluegreen", func() {
 
 	var (
@@ -77,7 +102,7 @@ var _ = Describe("Bluegreen", func() {
 				}
 			}
 
-			err := blueGreen.Execute(pusherCreator, environment, response)
+			err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
 
 			Expect(err).To(MatchError("push creator failed"))
 		})
@@ -93,7 +118,7 @@ var _ = Describe("Bluegreen", func() {
 				}
 			}
 
-			err := blueGreen.Execute(pusherCreator, environment, response)
+			err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
 			Expect(err).ToNot(HaveOccurred())
 
 			for range environment.Foundations {
@@ -110,7 +135,7 @@ var _ = Describe("Bluegreen", func() {
 				}
 			}
 
-			err := blueGreen.Execute(pusherCreator, environment, response)
+			err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
 			Expect(err).To(MatchError(LoginError{[]error{errors.New(loginOutput)}}))
 
 			for range environment.Foundations {
@@ -141,7 +166,7 @@ var _ = Describe("Bluegreen", func() {
 
 			blueGreen = BlueGreen{Log: log}
 
-			Expect(blueGreen.Execute(pusherCreator, environment, response)).To(Succeed())
+			Expect(blueGreen.Execute(context.Background(), pusherCreator, environment, response)).To(Succeed())
 
 			Eventually(response).Should(Say(loginOutput))
 			Eventually(response).Should(Say(pushOutput))
@@ -156,7 +181,7 @@ var _ = Describe("Bluegreen", func() {
 				pusher.ExecuteCall.Write.Output = pushOutput
 			}
 
-			Expect(blueGreen.Execute(pusherCreator, environment, response)).To(Succeed())
+			Expect(blueGreen.Execute(context.Background(), pusherCreator, environment, response)).To(Succeed())
 
 			Eventually(response).Should(Say(loginOutput))
 			Eventually(response).Should(Say(loginOutput))
@@ -186,7 +211,7 @@ var _ = Describe("Bluegreen", func() {
 
 				blueGreen = BlueGreen{Log: log}
 
-				Expect(blueGreen.Execute(pusherCreator, environment, response)).To(Succeed())
+				Expect(blueGreen.Execute(context.Background(), pusherCreator, environment, response)).To(Succeed())
 
 				Eventually(response).Should(Say(loginOutput))
 				Eventually(response).Should(Say(pushOutput))
@@ -213,7 +238,7 @@ var _ = Describe("Bluegreen", func() {
 
 				blueGreen = BlueGreen{Log: log}
 
-				err := blueGreen.Execute(pusherCreator, environment, response)
+				err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
 
 				Expect(err).To(MatchError(FinishPushError{[]error{errors.New("finish push error")}}))
 			})
@@ -234,7 +259,7 @@ var _ = Describe("Bluegreen", func() {
 					}
 				}
 
-				err := blueGreen.Execute(pusherCreator, environment, response)
+				err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
 				Expect(err).To(MatchError(PushError{[]error{pushError}}))
 
 				Eventually(response).Should(Say(loginOutput))
@@ -248,7 +273,7 @@ var _ = Describe("Bluegreen", func() {
 					pushers[0].ExecuteCall.Returns.Error = pushError
 					pushers[0].UndoCall.Returns.Error = rollbackError
 
-					err := blueGreen.Execute(pusherCreator, environment, response)
+					err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
 
 					Expect(err).To(MatchError(RollbackError{[]error{pushError}, []error{rollbackError}}))
 				})
@@ -261,7 +286,7 @@ var _ = Describe("Bluegreen", func() {
 					pusher.ExecuteCall.Returns.Error = pushError
 				}
 
-				err := blueGreen.Execute(pusherCreator, environment, response)
+				err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
 				Expect(err).To(MatchError(PushError{[]error{pushError, pushError}}))
 
 				Eventually(response).Should(Say(loginOutput))
@@ -279,7 +304,7 @@ var _ = Describe("Bluegreen", func() {
 					pusher.ExecuteCall.Returns.Error = pushError
 				}
 
-				err := blueGreen.Execute(pusherCreator, environment, response)
+				err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("push failed: push error: push error"))
@@ -292,13 +317,262 @@ var _ = Describe("Bluegreen", func() {
 					pusher.ExecuteCall.Returns.Error = errors.New("a push execute error")
 				}
 				pushers[0].UndoCall.Returns.Error = errors.New("a push success error")
-				err := blueGreen.Execute(pusherCreator, environment, response)
+				err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
 
 				Expect(err.Error()).To(Equal("push failed: a push execute error: a push execute error: rollback failed: a push success error"))
 			})
 		})
 	})
 
+	Context("when at least one verify command is unsuccessful", func() {
+		var verifyError = errors.New("verify error")
+
+		It("rolls back all pushes and returns the verify error", func() {
+			pushers[1].VerifyCall.Returns.Error = verifyError
+
+			err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(PushError{[]error{verifyError}}))
+			Expect(logBuffer).To(Say("failed to verify action against all foundations"))
+			Expect(logBuffer).To(Say("rolling back action"))
+		})
+
+		It("returns a RollbackError if rolling back fails", func() {
+			pushers[0].VerifyCall.Returns.Error = verifyError
+			pushers[1].UndoCall.Returns.Error = rollbackError
+
+			err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(RollbackError{[]error{verifyError}, []error{rollbackError}}))
+		})
+
+		It("does not verify when any execute fails", func() {
+			pushers[0].ExecuteCall.Returns.Error = pushError
+			pushers[1].VerifyCall.Returns.Error = verifyError
+
+			err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(PushError{[]error{pushError}}))
+		})
+	})
+
+	Context("when the environment has a rolling strategy", func() {
+		BeforeEach(func() {
+			environment.Foundations = []string{randomizer.StringRunes(10), randomizer.StringRunes(10), randomizer.StringRunes(10)}
+			environment.Strategy = C.StrategyRolling
+			environment.BatchSize = 1
+
+			pusherCreator = &mocks.PushManager{}
+			pushers = nil
+			for range environment.Foundations {
+				pusher := &mocks.Pusher{Response: response}
+				pushers = append(pushers, pusher)
+				pusherCreator.CreatePusherCall.Returns.Pushers = append(pusherCreator.CreatePusherCall.Returns.Pushers, pusher)
+				pusherCreator.CreatePusherCall.Returns.Error = append(pusherCreator.CreatePusherCall.Returns.Error, nil)
+			}
+		})
+
+		It("pushes to every foundation one at a time", func() {
+			Expect(blueGreen.Execute(context.Background(), pusherCreator, environment, response)).To(Succeed())
+
+			for _, pusher := range pushers {
+				Expect(pusher.ExecuteCall.Called).To(BeTrue())
+				Expect(pusher.UndoCall.Called).To(BeFalse())
+			}
+			Expect(logBuffer).To(Say("rolling out to foundations 1 to 1 of 3"))
+			Expect(logBuffer).To(Say("rolling out to foundations 2 to 2 of 3"))
+			Expect(logBuffer).To(Say("rolling out to foundations 3 to 3 of 3"))
+		})
+
+		It("pushes to a batch of foundations at a time", func() {
+			environment.BatchSize = 2
+
+			Expect(blueGreen.Execute(context.Background(), pusherCreator, environment, response)).To(Succeed())
+
+			Expect(logBuffer).To(Say("rolling out to foundations 1 to 2 of 3"))
+			Expect(logBuffer).To(Say("rolling out to foundations 3 to 3 of 3"))
+		})
+
+		It("stops at the first failure and only rolls back the foundations already touched", func() {
+			pushers[1].ExecuteCall.Returns.Error = pushError
+
+			err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(PushError{[]error{pushError}}))
+			Expect(pushers[0].UndoCall.Called).To(BeTrue())
+			Expect(pushers[1].UndoCall.Called).To(BeTrue())
+			Expect(pushers[2].ExecuteCall.Called).To(BeFalse())
+			Expect(pushers[2].UndoCall.Called).To(BeFalse())
+			Expect(logBuffer).To(Say("rolling out stopped at foundations 2 to 2 of 3"))
+		})
+
+		It("stops when a foundation fails to verify", func() {
+			pushers[0].VerifyCall.Returns.Error = pushError
+
+			err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(PushError{[]error{pushError}}))
+			Expect(pushers[0].UndoCall.Called).To(BeTrue())
+			Expect(pushers[1].ExecuteCall.Called).To(BeFalse())
+		})
+
+		It("returns a RollbackError when rolling back a touched foundation fails", func() {
+			pushers[1].ExecuteCall.Returns.Error = pushError
+			pushers[0].UndoCall.Returns.Error = rollbackError
+
+			err := blueGreen.Execute(context.Background(), pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(RollbackError{[]error{pushError}, []error{rollbackError}}))
+		})
+	})
+
+	Context("when the deployment is cancelled", func() {
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+			pusherCreator.CreatePusherCall.Returns.Pushers[0] = cancellingPusher{pushers[0], cancel}
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("creates the actions with the context of the deployment", func() {
+			blueGreen.Execute(ctx, pusherCreator, environment, response)
+
+			Expect(pusherCreator.CreatePusherCall.Received.Context).To(Equal(ctx))
+		})
+
+		It("undoes the action on every foundation and returns a CancelledError", func() {
+			err := blueGreen.Execute(ctx, pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(CancelledError{}))
+			Expect(pushers[0].UndoCall.Called).To(BeTrue())
+			Expect(pushers[1].UndoCall.Called).To(BeTrue())
+			Expect(logBuffer).To(Say("deployment was cancelled - rolling back action"))
+		})
+
+		It("returns the errors of the undo", func() {
+			pushers[1].UndoCall.Returns.Error = rollbackError
+
+			err := blueGreen.Execute(ctx, pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(CancelledError{UndoErrors: []error{rollbackError}}))
+		})
+
+		It("does not execute the action when it is cancelled before", func() {
+			cancel()
+
+			err := blueGreen.Execute(ctx, pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(CancelledError{}))
+			Expect(pushers[1].ExecuteCall.Called).To(BeFalse())
+			Expect(pushers[1].UndoCall.Called).To(BeFalse())
+		})
+
+		It("only undoes the foundations that have been touched by a rolling deployment", func() {
+			environment.Strategy = C.StrategyRolling
+			environment.BatchSize = 1
+
+			err := blueGreen.Execute(ctx, pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(CancelledError{}))
+			Expect(pushers[0].UndoCall.Called).To(BeTrue())
+			Expect(pushers[1].ExecuteCall.Called).To(BeFalse())
+			Expect(pushers[1].UndoCall.Called).To(BeFalse())
+		})
+	})
+
+	Context("when the deployment runs out of time", func() {
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+			pusherCreator.CreatePusherCall.Returns.Pushers[0] = waitingPusher{pushers[0], ctx}
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("undoes the action on every foundation and returns a DeploymentTimeoutError", func() {
+			err := blueGreen.Execute(ctx, pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(DeploymentTimeoutError{}))
+			Expect(pushers[0].UndoCall.Called).To(BeTrue())
+			Expect(pushers[1].UndoCall.Called).To(BeTrue())
+			Expect(logBuffer).To(Say("deployment timed out - rolling back action"))
+		})
+
+		It("returns the errors of the undo", func() {
+			pushers[1].UndoCall.Returns.Error = rollbackError
+
+			err := blueGreen.Execute(ctx, pusherCreator, environment, response)
+
+			Expect(err).To(MatchError(DeploymentTimeoutError{UndoErrors: []error{rollbackError}}))
+		})
+	})
+
+	Context("when a tracker is provided", func() {
+		var tracker *mocks.Tracker
+
+		BeforeEach(func() {
+			tracker = &mocks.Tracker{}
+			blueGreen = BlueGreen{Log: log, Tracker: tracker}
+		})
+
+		It("records each phase of the deployment", func() {
+			Expect(blueGreen.Execute(context.Background(), pusherCreator, environment, response)).To(Succeed())
+
+			Expect(tracker.SetPhaseCall.Received.UUID).To(Equal(log.UUID))
+			Expect(tracker.SetPhaseCall.Received.Phases).To(Equal([]string{C.DeploymentPhaseLogin, C.DeploymentPhaseExecute, C.DeploymentPhaseVerify, C.DeploymentPhaseSuccess}))
+		})
+
+		It("records the status of each foundation", func() {
+			pushers[1].ExecuteCall.Returns.Error = pushError
+
+			blueGreen.Execute(context.Background(), pusherCreator, environment, response)
+
+			Expect(tracker.SetPhaseCall.Received.Phases).To(ContainElement(C.DeploymentPhaseUndo))
+			Expect(tracker.SetFoundationStatusCall.Received.Foundations[environment.Foundations[0]]).To(Equal(interfaces.FoundationStatus{Phase: C.DeploymentPhaseUndo}))
+			Expect(tracker.SetFoundationStatusCall.Received.Foundations[environment.Foundations[1]]).To(Equal(interfaces.FoundationStatus{Phase: C.DeploymentPhaseUndo}))
+		})
+
+		It("publishes every line of output with the foundation it came from", func() {
+			stopperFactory := &mocks.StopManager{}
+			for range environment.Foundations {
+				stopperFactory.CreateStopperCall.Returns.Stoppers = append(stopperFactory.CreateStopperCall.Returns.Stoppers, &mocks.StartStopper{})
+				stopperFactory.CreateStopperCall.Returns.Error = append(stopperFactory.CreateStopperCall.Returns.Error, nil)
+			}
+
+			Expect(blueGreen.Execute(context.Background(), stopperFactory, environment, response)).To(Succeed())
+
+			fmt.Fprint(stopperFactory.CreateStopperCall.Received[1].Response, "first line\r\nsecond line\nunfinished")
+
+			Expect(tracker.PublishCall.Received.UUID).To(Equal(log.UUID))
+			Expect(tracker.PublishCall.Received.Lines).To(Equal([]interfaces.OutputLine{
+				{FoundationURL: environment.Foundations[1], Line: "first line"},
+				{FoundationURL: environment.Foundations[1], Line: "second line"},
+			}))
+		})
+
+		It("records the error of a foundation", func() {
+			pushers[1].InitiallyCall.Returns.Error = errors.New("login error")
+
+			blueGreen.Execute(context.Background(), pusherCreator, environment, response)
+
+			Expect(tracker.SetFoundationStatusCall.Received.Foundations[environment.Foundations[0]]).To(Equal(interfaces.FoundationStatus{Phase: C.DeploymentPhaseLogin}))
+			Expect(tracker.SetFoundationStatusCall.Received.Foundations[environment.Foundations[1]]).To(Equal(interfaces.FoundationStatus{Phase: C.DeploymentPhaseLogin, Error: "login error"}))
+		})
+	})
+
 	Describe("Stop", func() {
 		Context("when called", func() {
 			It("creates a stopper for each foundation", func() {
@@ -311,7 +585,7 @@ var _ = Describe("Bluegreen", func() {
 
 				blueGreen = BlueGreen{}
 
-				err := blueGreen.Execute(stopperFactory, environment, NewBuffer())
+				err := blueGreen.Execute(context.Background(), stopperFactory, environment, NewBuffer())
 				Expect(err).ToNot(HaveOccurred())
 
 				for i, foundation := range environment.Foundations {
@@ -325,7 +599,7 @@ var _ = Describe("Bluegreen", func() {
 				stopperFactory.CreateStopperCall.Returns.Error = append(stopperFactory.CreateStopperCall.Returns.Error, errors.New("stop creator failed"))
 
 				blueGreen = BlueGreen{Log: log}
-				err := blueGreen.Execute(stopperFactory, environment, NewBuffer())
+				err := blueGreen.Execute(context.Background(), stopperFactory, environment, NewBuffer())
 
 				Expect(err).To(MatchError("stop creator failed"))
 			})
@@ -343,7 +617,7 @@ var _ = Describe("Bluegreen", func() {
 
 				blueGreen = BlueGreen{}
 
-				err := blueGreen.Execute(stopperFactory, environment, NewBuffer())
+				err := blueGreen.Execute(context.Background(), stopperFactory, environment, NewBuffer())
 				Expect(err).ToNot(HaveOccurred())
 
 			})
@@ -360,7 +634,7 @@ var _ = Describe("Bluegreen", func() {
 				}
 				stoppers[0].InitiallyCall.Returns.Error = errors.New("login to stop failed")
 				blueGreen = BlueGreen{}
-				err := blueGreen.Execute(stopperFactory, environment, NewBuffer())
+				err := blueGreen.Execute(context.Background(), stopperFactory, environment, NewBuffer())
 
 				Expect(err.Error()).To(Equal("login failed: login to stop failed"))
 			})
@@ -378,7 +652,7 @@ var _ = Describe("Bluegreen", func() {
 				}
 
 				blueGreen = BlueGreen{}
-				err := blueGreen.Execute(stopperFactory, environment, NewBuffer())
+				err := blueGreen.Execute(context.Background(), stopperFactory, environment, NewBuffer())
 
 				Expect(err.Error()).To(Equal("login failed: login 0 to stop failed: login 1 to stop failed"))
 			})
@@ -396,7 +670,7 @@ var _ = Describe("Bluegreen", func() {
 
 				blueGreen = BlueGreen{}
 
-				err := blueGreen.Execute(stopperFactory, environment, NewBuffer())
+				err := blueGreen.Execute(context.Background(), stopperFactory, environment, NewBuffer())
 				Expect(err).ToNot(HaveOccurred())
 
 			})
@@ -415,7 +689,7 @@ var _ = Describe("Bluegreen", func() {
 
 				blueGreen = BlueGreen{Log: log}
 
-				err := blueGreen.Execute(stopperFactory, environment, NewBuffer())
+				err := blueGreen.Execute(context.Background(), stopperFactory, environment, NewBuffer())
 				Expect(err).To(MatchError(StopError{[]error{errors.New("stop failed")}}))
 			})
 
@@ -433,7 +707,7 @@ var _ = Describe("Bluegreen", func() {
 
 				blueGreen = BlueGreen{Log: log}
 
-				err := blueGreen.Execute(stopperFactory, environment, NewBuffer())
+				err := blueGreen.Execute(context.Background(), stopperFactory, environment, NewBuffer())
 				Expect(err.Error()).To(Equal("stop failed: stop failed: stop failed"))
 			})
 
@@ -451,7 +725,7 @@ var _ = Describe("Bluegreen", func() {
 
 				blueGreen = BlueGreen{Log: log}
 
-				err := blueGreen.Execute(stopperFactory, environment, NewBuffer())
+				err := blueGreen.Execute(context.Background(), stopperFactory, environment, NewBuffer())
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("stop failed: an error occurred"))
 			})
@@ -472,7 +746,7 @@ var _ = Describe("Bluegreen", func() {
 					Log: log,
 				}
 
-				err := blueGreen.Execute(stopperFactory, environment, NewBuffer())
+				err := blueGreen.Execute(context.Background(), stopperFactory, environment, NewBuffer())
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("stop failed: an error occurred: rollback failed: an error occurred while attempting undo"))
 			})
@@ -492,7 +766,7 @@ var _ = Describe("Bluegreen", func() {
 
 				blueGreen = BlueGreen{}
 
-				err := blueGreen.Execute(stopperFactory, environment, out)
+				err := blueGreen.Execute(context.Background(), stopperFactory, environment, out)
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(out).Should(Say("- Cloud Foundry Output -"))
@@ -0,0 +1,57 @@
+package courier
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+var (
+	instancesPattern     = regexp.MustCompile(`^instances:\s+(\d+)/(\d+)`)
+	instanceStatePattern = regexp.MustCompile(`^#\d+\s+(\S+)`)
+)
+
+// parseAppStatus reads the output of cf app. Older versions of the CLI list the routes as urls.
+func parseAppStatus(output string) S.AppStatus {
+	status := S.AppStatus{}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "requested state:"):
+			status.State = strings.TrimSpace(strings.TrimPrefix(line, "requested state:"))
+
+		case strings.HasPrefix(line, "routes:") || strings.HasPrefix(line, "urls:"):
+			routes := strings.TrimSpace(line[strings.Index(line, ":")+1:])
+			for _, route := range strings.Split(routes, ",") {
+				if route = strings.TrimSpace(route); route != "" {
+					status.Routes = append(status.Routes, route)
+				}
+			}
+
+		case strings.HasPrefix(line, "buildpack:") || strings.HasPrefix(line, "buildpacks:"):
+			status.Buildpack = strings.TrimSpace(line[strings.Index(line, ":")+1:])
+
+		case strings.HasPrefix(line, "stack:"):
+			status.Stack = strings.TrimSpace(strings.TrimPrefix(line, "stack:"))
+
+		case strings.HasPrefix(line, "memory usage:"):
+			status.Memory = strings.TrimSpace(strings.TrimPrefix(line, "memory usage:"))
+
+		case instancesPattern.MatchString(line):
+			match := instancesPattern.FindStringSubmatch(line)
+			running, _ := strconv.Atoi(match[1])
+			instances, _ := strconv.Atoi(match[2])
+			status.Running = uint16(running)
+			status.Instances = uint16(instances)
+
+		case instanceStatePattern.MatchString(line):
+			status.InstanceStates = append(status.InstanceStates, instanceStatePattern.FindStringSubmatch(line)[1])
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,291 @@
+package cloudcontroller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/executor"
+)
+
+// PollAttempts and PollInterval are how many times and how far apart packages, builds and jobs
+// are looked up while the Cloud Controller is still working on them.
+var (
+	PollAttempts = 120
+	PollInterval = 2 * time.Second
+)
+
+// operation bounds the requests of one courier call by the context of the Executor and by the
+// timeout of the cf command the call stands in for.
+type operation struct {
+	context.Context
+	cancel  context.CancelFunc
+	base    context.Context
+	command string
+	limit   time.Duration
+}
+
+// operation starts an operation for the cf command. Like the Executor, an operation that starts
+// after the context has been cancelled, such as one that undoes a cancelled deployment, still runs.
+func (c *Courier) operation(command string) operation {
+	var parent context.Context
+	if e, ok := c.Executor.(interface{ Context() context.Context }); ok {
+		parent = e.Context()
+	}
+
+	base := context.Background()
+	if parent != nil && parent.Err() == nil {
+		base = parent
+	}
+
+	o := operation{base: base, command: command, limit: executor.Timeout(parent, command)}
+	if o.limit > 0 {
+		o.Context, o.cancel = context.WithTimeout(base, o.limit)
+	} else {
+		o.Context, o.cancel = context.WithCancel(base)
+	}
+
+	return o
+}
+
+// done releases the operation and turns the error into an executor.TimeoutError when the operation
+// ran out of time.
+func (o operation) done(err error) error {
+	timedOut := o.limit > 0 && o.Err() == context.DeadlineExceeded && o.base.Err() == nil
+	o.cancel()
+
+	if err != nil && timedOut {
+		return executor.TimeoutError{Command: o.command, Timeout: o.limit}
+	}
+	return err
+}
+
+// do sends a request to the Cloud Controller. Paths that start with a slash are relative to its api.
+// The body is sent as JSON unless it is a multipartBody, and the response is read into the result as JSON.
+func (c *Courier) do(o operation, method, path string, body, result interface{}) (*http.Response, error) {
+	if c.token == "" {
+		return nil, NotLoggedInError{}
+	}
+
+	var (
+		reader      io.Reader
+		contentType string
+	)
+	switch b := body.(type) {
+	case nil:
+	case multipartBody:
+		reader, contentType = b.reader, b.contentType
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		reader, contentType = bytes.NewReader(encoded), "application/json"
+	}
+
+	target := path
+	if strings.HasPrefix(path, "/") {
+		target = c.api + path
+	}
+
+	request, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	request = request.WithContext(o)
+	request.Header.Set("Authorization", c.token)
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	return c.send(request, result)
+}
+
+// send sends the request and reads the response into the result as JSON.
+func (c *Courier) send(request *http.Request, result interface{}) (*http.Response, error) {
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return response, ResponseError{
+			Method:     request.Method,
+			URL:        request.URL.String(),
+			StatusCode: response.StatusCode,
+			Body:       responseBody,
+		}
+	}
+
+	if result != nil && len(responseBody) > 0 {
+		err = json.Unmarshal(responseBody, result)
+		if err != nil {
+			return response, err
+		}
+	}
+
+	return response, nil
+}
+
+type page struct {
+	Pagination struct {
+		Next *struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"pagination"`
+	Resources []json.RawMessage `json:"resources"`
+}
+
+// list reads the resources of every page of a listing into the resources, which must point to a slice.
+func (c *Courier) list(o operation, path string, resources interface{}) error {
+	var all []json.RawMessage
+
+	for next := path; next != ""; {
+		var p page
+		_, err := c.do(o, http.MethodGet, next, nil, &p)
+		if err != nil {
+			return err
+		}
+		all = append(all, p.Resources...)
+
+		next = ""
+		if p.Pagination.Next != nil {
+			next = p.Pagination.Next.Href
+		}
+	}
+
+	if all == nil {
+		all = []json.RawMessage{}
+	}
+	encoded, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, resources)
+}
+
+// poll looks up the resource until it has left its pending states.
+//
+// Returns the state the resource ended in.
+func (c *Courier) poll(o operation, kind, path string, pending ...string) (resource, error) {
+	var r resource
+
+	for attempt := 0; attempt < PollAttempts; attempt++ {
+		_, err := c.do(o, http.MethodGet, path, nil, &r)
+		if err != nil {
+			return r, err
+		}
+
+		if !contains(pending, r.State) {
+			return r, nil
+		}
+
+		select {
+		case <-o.Done():
+			return r, o.Err()
+		case <-time.After(PollInterval):
+		}
+	}
+
+	return r, PollTimeoutError{Kind: kind, GUID: r.GUID}
+}
+
+// wait waits for the asynchronous job of the response, if it started one.
+func (c *Courier) wait(o operation, response *http.Response) error {
+	if response == nil || response.StatusCode != http.StatusAccepted || response.Header.Get("Location") == "" {
+		return nil
+	}
+
+	job, err := c.poll(o, "job", response.Header.Get("Location"), "PROCESSING", "POLLING")
+	if err != nil {
+		return err
+	}
+	if job.State == "FAILED" {
+		return FailedError{Kind: "job", GUID: job.GUID, Reason: job.reason()}
+	}
+
+	return nil
+}
+
+type resource struct {
+	GUID   string `json:"guid"`
+	Name   string `json:"name"`
+	State  string `json:"state"`
+	Type   string `json:"type"`
+	Host   string `json:"host"`
+	Path   string `json:"path"`
+	URL    string `json:"url"`
+	Error  string `json:"error"`
+	Errors []struct {
+		Detail string `json:"detail"`
+	} `json:"errors"`
+	Droplet struct {
+		GUID string `json:"guid"`
+	} `json:"droplet"`
+	Lifecycle struct {
+		Data struct {
+			Buildpacks []string `json:"buildpacks"`
+			Stack      string   `json:"stack"`
+		} `json:"data"`
+	} `json:"lifecycle"`
+	Relationships relationships `json:"relationships"`
+	Links         map[string]struct {
+		Href string `json:"href"`
+	} `json:"links"`
+}
+
+// reason returns why a build or job failed.
+func (r resource) reason() string {
+	if r.Error != "" {
+		return r.Error
+	}
+	var details []string
+	for _, e := range r.Errors {
+		details = append(details, e.Detail)
+	}
+	return strings.Join(details, "; ")
+}
+
+type relationships map[string]relationship
+
+type relationship struct {
+	Data struct {
+		GUID string `json:"guid"`
+	} `json:"data"`
+}
+
+// to returns the relationships of a new resource, given as pairs of names and guids.
+func to(namesAndGUIDs ...string) relationships {
+	r := relationships{}
+	for i := 0; i+1 < len(namesAndGUIDs); i += 2 {
+		var to relationship
+		to.Data.GUID = namesAndGUIDs[i+1]
+		r[namesAndGUIDs[i]] = to
+	}
+	return r
+}
+
+type multipartBody struct {
+	reader      io.Reader
+	contentType string
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
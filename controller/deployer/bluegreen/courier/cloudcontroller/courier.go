@@ -0,0 +1,610 @@
+// Package cloudcontroller talks to the Cloud Foundry v3 Cloud Controller and UAA APIs directly
+// instead of running the Cloud Foundry CLI.
+package cloudcontroller
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/executor"
+	I "github.com/compozed/deployadactyl/interfaces"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+// NewCourier returns a Courier that talks to the Cloud Controller. The Executor is only used for
+// its context, which cancels the requests of the Courier, and to clean up after it.
+//
+// It can be used as the NewCourier of a CreatorModuleProvider.
+func NewCourier(executor I.Executor) I.Courier {
+	return &Courier{
+		Executor: executor,
+	}
+}
+
+// Courier runs Cloud Foundry operations against the v3 Cloud Controller API.
+// Login has to be called before any other operation.
+type Courier struct {
+	Executor I.Executor
+
+	client    *http.Client
+	api       string
+	logCache  string
+	token     string
+	spaceGUID string
+}
+
+type rootLinks struct {
+	Links map[string]struct {
+		Href string `json:"href"`
+	} `json:"links"`
+}
+
+type token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// expiryMargin is how long before it expires a cached access token is refreshed, so that it does
+// not expire in the middle of a deployment.
+const expiryMargin = time.Minute
+
+// Login gets a token from UAA and targets the org and space. The token is taken from the session
+// cache of the Executor while it is valid, and refreshed when it has expired. Without a cached
+// session the token is requested with the password grant of the cf client or, with clientCredentials,
+// with the client credentials grant of the UAA client the username and password belong to. A failed
+// login forgets the cached session.
+func (c *Courier) Login(foundationURL, username, password, org, space string, skipSSL, clientCredentials bool) ([]byte, error) {
+	o := c.operation("login")
+	out, err := c.login(o, foundationURL, username, password, org, space, skipSSL, clientCredentials)
+	return out, o.done(err)
+}
+
+func (c *Courier) login(o operation, foundationURL, username, password, org, space string, skipSSL, clientCredentials bool) ([]byte, error) {
+	c.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: skipSSL},
+		},
+	}
+	c.api = strings.TrimRight(foundationURL, "/")
+	c.token = ""
+
+	request, err := http.NewRequest(http.MethodGet, c.api+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var root rootLinks
+	_, err = c.send(request.WithContext(o), &root)
+	if err != nil {
+		return nil, err
+	}
+
+	uaa := root.Links["login"].Href
+	if uaa == "" {
+		uaa = root.Links["uaa"].Href
+	}
+	uaa = strings.TrimRight(uaa, "/")
+	c.logCache = root.Links["log_cache"].Href
+
+	var sessions *executor.Sessions
+	if e, ok := c.Executor.(interface{ Sessions() *executor.Sessions }); ok {
+		sessions = e.Sessions()
+	}
+	key := executor.SessionKey(foundationURL, username, password, skipSSL, clientCredentials)
+
+	if sessions != nil {
+		session, ok := sessions.Get(key)
+		if ok {
+			session, err = c.refresh(o, uaa, session)
+			if err == nil {
+				out, err := c.target(o, session, username, org, space)
+				if err == nil {
+					sessions.Put(key, session)
+					return out, nil
+				}
+			}
+			sessions.Delete(key)
+		}
+	}
+
+	form := url.Values{"grant_type": {"password"}, "username": {username}, "password": {password}}
+	clientID, clientSecret := "cf", ""
+	if clientCredentials {
+		form = url.Values{"grant_type": {"client_credentials"}}
+		clientID, clientSecret = username, password
+	}
+
+	var out []byte
+	session, err := c.requestToken(o, uaa, form, clientID, clientSecret)
+	if err == nil {
+		out, err = c.target(o, session, username, org, space)
+	}
+
+	if sessions != nil {
+		if err != nil {
+			sessions.Delete(key)
+		} else {
+			sessions.Put(key, session)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// refresh returns the session with a new access token when its access token has expired or is
+// about to. Sessions of UAA clients, which have no refresh token, cannot be refreshed.
+func (c *Courier) refresh(o operation, uaa string, session executor.Session) (executor.Session, error) {
+	if session.Expiry.IsZero() || time.Now().Add(expiryMargin).Before(session.Expiry) {
+		return session, nil
+	}
+	if session.RefreshToken == "" {
+		return executor.Session{}, fmt.Errorf("the access token has expired and cannot be refreshed")
+	}
+
+	refreshed, err := c.requestToken(o, uaa, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {session.RefreshToken}}, "cf", "")
+	if err != nil {
+		return executor.Session{}, err
+	}
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = session.RefreshToken
+	}
+
+	return refreshed, nil
+}
+
+// requestToken gets a token from UAA with the grant of the form.
+func (c *Courier) requestToken(o operation, uaa string, form url.Values, clientID, clientSecret string) (executor.Session, error) {
+	request, err := http.NewRequest(http.MethodPost, uaa+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return executor.Session{}, err
+	}
+	request.SetBasicAuth(clientID, clientSecret)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	var t token
+	_, err = c.send(request.WithContext(o), &t)
+	if err != nil {
+		return executor.Session{}, err
+	}
+
+	session := executor.Session{TokenType: t.TokenType, AccessToken: t.AccessToken, RefreshToken: t.RefreshToken}
+	if t.ExpiresIn > 0 {
+		session.Expiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+
+	return session, nil
+}
+
+// target uses the token of the session to look up the org and space.
+func (c *Courier) target(o operation, session executor.Session, username, org, space string) ([]byte, error) {
+	c.token = session.TokenType + " " + session.AccessToken
+
+	orgGUID, err := c.find(o, "organization", "/v3/organizations?"+url.Values{"names": {org}}.Encode(), org)
+	if err != nil {
+		c.token = ""
+		return nil, err
+	}
+
+	c.spaceGUID, err = c.find(o, "space", "/v3/spaces?"+url.Values{"names": {space}, "organization_guids": {orgGUID}}.Encode(), space)
+	if err != nil {
+		c.token = ""
+		return nil, err
+	}
+
+	return []byte(fmt.Sprintf("API endpoint: %s\nAuthenticated as %s\nTargeted org %s\nTargeted space %s\n", c.api, username, org, space)), nil
+}
+
+// find returns the guid of the only resource of the listing.
+func (c *Courier) find(o operation, kind, path, name string) (string, error) {
+	var resources []resource
+	err := c.list(o, path, &resources)
+	if err != nil {
+		return "", err
+	}
+	if len(resources) == 0 {
+		return "", NotFoundError{Kind: kind, Name: name}
+	}
+
+	return resources[0].GUID, nil
+}
+
+// app looks up the application in the targeted space.
+func (c *Courier) app(o operation, appName string) (resource, error) {
+	var apps []resource
+	err := c.list(o, "/v3/apps?"+url.Values{"names": {appName}, "space_guids": {c.spaceGUID}}.Encode(), &apps)
+	if err != nil {
+		return resource{}, err
+	}
+	if len(apps) == 0 {
+		return resource{}, NotFoundError{Kind: "app", Name: appName}
+	}
+
+	return apps[0], nil
+}
+
+// action looks up the application and runs an action, such as start or stop, against it.
+func (c *Courier) action(command, appName, action, message string) ([]byte, error) {
+	o := c.operation(command)
+
+	app, err := c.app(o, appName)
+	if err == nil {
+		_, err = c.do(o, http.MethodPost, "/v3/apps/"+app.GUID+"/actions/"+action, nil, nil)
+	}
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf(message+"\n", appName)), o.done(nil)
+}
+
+// Start starts the application.
+func (c *Courier) Start(appName string) ([]byte, error) {
+	return c.action("start", appName, "start", "Starting app %s")
+}
+
+// Stop stops the application.
+func (c *Courier) Stop(appName string) ([]byte, error) {
+	return c.action("stop", appName, "stop", "Stopping app %s")
+}
+
+// Restart restarts the application.
+func (c *Courier) Restart(appName string) ([]byte, error) {
+	return c.action("restart", appName, "restart", "Restarting app %s")
+}
+
+// Restage stages the package of the current droplet of the application again and restarts the
+// application with the new droplet.
+func (c *Courier) Restage(appName string) ([]byte, error) {
+	o := c.operation("restage")
+	err := c.restage(o, appName)
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Restaging app %s\n", appName)), o.done(nil)
+}
+
+func (c *Courier) restage(o operation, appName string) error {
+	app, err := c.app(o, appName)
+	if err != nil {
+		return err
+	}
+
+	var droplet, pkg resource
+	_, err = c.do(o, http.MethodGet, "/v3/apps/"+app.GUID+"/droplets/current", nil, &droplet)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(o, http.MethodGet, droplet.Links["package"].Href, nil, &pkg)
+	if err != nil {
+		return err
+	}
+
+	dropletGUID, err := c.stage(o, pkg.GUID)
+	if err != nil {
+		return err
+	}
+	err = c.setDroplet(o, app.GUID, dropletGUID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(o, http.MethodPost, "/v3/apps/"+app.GUID+"/actions/restart", nil, nil)
+	return err
+}
+
+// Delete deletes the application. An application that does not exist is not an error.
+func (c *Courier) Delete(appName string) ([]byte, error) {
+	o := c.operation("delete")
+
+	app, err := c.app(o, appName)
+	if _, ok := err.(NotFoundError); ok {
+		return []byte(fmt.Sprintf("App %s does not exist.\n", appName)), o.done(nil)
+	}
+	if err == nil {
+		var response *http.Response
+		response, err = c.do(o, http.MethodDelete, "/v3/apps/"+app.GUID, nil, nil)
+		if err == nil {
+			err = c.wait(o, response)
+		}
+	}
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Deleting app %s\n", appName)), o.done(nil)
+}
+
+// Rename renames the application.
+func (c *Courier) Rename(oldName, newName string) ([]byte, error) {
+	o := c.operation("rename")
+
+	app, err := c.app(o, oldName)
+	if err == nil {
+		_, err = c.do(o, http.MethodPatch, "/v3/apps/"+app.GUID, map[string]interface{}{"name": newName}, nil)
+	}
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Renaming app %s to %s\n", oldName, newName)), o.done(nil)
+}
+
+// Scale changes the number of instances of the web process of the application.
+func (c *Courier) Scale(appName string, instances uint16) ([]byte, error) {
+	o := c.operation("scale")
+	err := c.scale(o, appName, map[string]interface{}{"instances": instances}, false)
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Scaling app %s to %d instances\n", appName, instances)), o.done(nil)
+}
+
+// ScaleMemory changes the memory limit of the web process of the application, such as 1G or 512M.
+// A started application is restarted so that the limit takes effect, like cf scale -f does.
+func (c *Courier) ScaleMemory(appName, memory string) ([]byte, error) {
+	megabytes, err := toMegabytes(memory)
+	if err != nil {
+		return nil, err
+	}
+
+	o := c.operation("scale")
+	err = c.scale(o, appName, map[string]interface{}{"memory_in_mb": megabytes}, true)
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Scaling app %s to %s of memory\n", appName, memory)), o.done(nil)
+}
+
+func (c *Courier) scale(o operation, appName string, body map[string]interface{}, restart bool) error {
+	app, err := c.app(o, appName)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(o, http.MethodPost, "/v3/apps/"+app.GUID+"/processes/web/actions/scale", body, nil)
+	if err != nil || !restart || app.State != "STARTED" {
+		return err
+	}
+
+	_, err = c.do(o, http.MethodPost, "/v3/apps/"+app.GUID+"/actions/restart", nil, nil)
+	return err
+}
+
+// Exists checks to see whether the application exists in the targeted space.
+func (c *Courier) Exists(appName string) bool {
+	o := c.operation("app")
+	_, err := c.app(o, appName)
+	return o.done(err) == nil
+}
+
+type process struct {
+	Instances  uint16 `json:"instances"`
+	MemoryInMB int    `json:"memory_in_mb"`
+}
+
+type processStats struct {
+	Resources []struct {
+		State string `json:"state"`
+	} `json:"resources"`
+}
+
+// AppStatus reads the requested state, the instances, the routes and the lifecycle of the application.
+func (c *Courier) AppStatus(appName string) (S.AppStatus, error) {
+	o := c.operation("app")
+	status, err := c.appStatus(o, appName)
+	if err = o.done(err); err != nil {
+		return S.AppStatus{}, courier.AppStatusError{ApplicationName: appName, Out: []byte(err.Error())}
+	}
+
+	return status, nil
+}
+
+func (c *Courier) appStatus(o operation, appName string) (S.AppStatus, error) {
+	app, err := c.app(o, appName)
+	if err != nil {
+		return S.AppStatus{}, err
+	}
+
+	var (
+		web    process
+		stats  processStats
+		routes []resource
+	)
+	_, err = c.do(o, http.MethodGet, "/v3/apps/"+app.GUID+"/processes/web", nil, &web)
+	if err != nil {
+		return S.AppStatus{}, err
+	}
+	_, err = c.do(o, http.MethodGet, "/v3/apps/"+app.GUID+"/processes/web/stats", nil, &stats)
+	if err != nil {
+		return S.AppStatus{}, err
+	}
+	err = c.list(o, "/v3/apps/"+app.GUID+"/routes", &routes)
+	if err != nil {
+		return S.AppStatus{}, err
+	}
+
+	status := S.AppStatus{
+		State:     strings.ToLower(app.State),
+		Instances: web.Instances,
+		Memory:    fromMegabytes(web.MemoryInMB),
+		Buildpack: strings.Join(app.Lifecycle.Data.Buildpacks, ", "),
+		Stack:     app.Lifecycle.Data.Stack,
+	}
+	for _, instance := range stats.Resources {
+		state := strings.ToLower(instance.State)
+		if state == "running" {
+			status.Running++
+		}
+		status.InstanceStates = append(status.InstanceStates, state)
+	}
+	for _, route := range routes {
+		status.Routes = append(status.Routes, route.URL)
+	}
+
+	return status, nil
+}
+
+// EnvironmentVariables returns the user provided environment variables of the application.
+func (c *Courier) EnvironmentVariables(appName string) (map[string]string, error) {
+	o := c.operation("env")
+	variables, err := c.environmentVariables(o, appName)
+	if err = o.done(err); err != nil {
+		return nil, courier.EnvironmentVariablesError{ApplicationName: appName, Out: []byte(err.Error())}
+	}
+
+	return variables, nil
+}
+
+func (c *Courier) environmentVariables(o operation, appName string) (map[string]string, error) {
+	app, err := c.app(o, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	var env struct {
+		Var map[string]interface{} `json:"var"`
+	}
+	_, err = c.do(o, http.MethodGet, "/v3/apps/"+app.GUID+"/environment_variables", nil, &env)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := map[string]string{}
+	for name, value := range env.Var {
+		variables[name] = fmt.Sprint(value)
+	}
+
+	return variables, nil
+}
+
+type envelopes struct {
+	Envelopes struct {
+		Batch []struct {
+			Timestamp  string            `json:"timestamp"`
+			InstanceID string            `json:"instance_id"`
+			Tags       map[string]string `json:"tags"`
+			Log        struct {
+				Payload string `json:"payload"`
+				Type    string `json:"type"`
+			} `json:"log"`
+		} `json:"batch"`
+	} `json:"envelopes"`
+}
+
+// Logs reads the recent logs of the application from log cache, oldest first.
+func (c *Courier) Logs(appName string) ([]byte, error) {
+	o := c.operation("logs")
+	logs, err := c.logs(o, appName)
+	return logs, o.done(err)
+}
+
+func (c *Courier) logs(o operation, appName string) ([]byte, error) {
+	if c.logCache == "" {
+		return nil, NotFoundError{Kind: "endpoint", Name: "log_cache"}
+	}
+
+	app, err := c.app(o, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	var e envelopes
+	query := url.Values{"envelope_types": {"LOG"}, "descending": {"true"}, "limit": {"1000"}}
+	_, err = c.do(o, http.MethodGet, strings.TrimRight(c.logCache, "/")+"/api/v1/read/"+app.GUID+"?"+query.Encode(), nil, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := e.Envelopes.Batch
+	lines := make([]string, 0, len(batch))
+	for i := len(batch) - 1; i >= 0; i-- {
+		envelope := batch[i]
+		payload, err := base64.StdEncoding.DecodeString(envelope.Log.Payload)
+		if err != nil {
+			payload = []byte(envelope.Log.Payload)
+		}
+
+		timestamp := envelope.Timestamp
+		if nanoseconds, err := strconv.ParseInt(envelope.Timestamp, 10, 64); err == nil {
+			timestamp = time.Unix(0, nanoseconds).Format("2006-01-02T15:04:05.00-0700")
+		}
+
+		lines = append(lines, fmt.Sprintf("%s [%s/%s] %s %s", timestamp, envelope.Tags["source_type"], envelope.InstanceID, envelope.Log.Type, payload))
+	}
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// Domains returns the names of the domains that are visible to the user, sorted by name.
+func (c *Courier) Domains() ([]string, error) {
+	o := c.operation("domains")
+
+	var domains []resource
+	err := c.list(o, "/v3/domains", &domains)
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	names := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		names = append(names, domain.Name)
+	}
+	sort.Strings(names)
+
+	return names, o.done(nil)
+}
+
+// CleanUp removes the temporary directory created by the Executor.
+func (c *Courier) CleanUp() error {
+	return c.Executor.CleanUp()
+}
+
+// toMegabytes reads a memory limit such as 1G, 1GB, 512M or 512MB.
+func toMegabytes(memory string) (int, error) {
+	m := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(memory)), "B")
+
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(m, "G"):
+		multiplier = 1024
+		m = strings.TrimSuffix(m, "G")
+	case strings.HasSuffix(m, "M"):
+		m = strings.TrimSuffix(m, "M")
+	default:
+		return 0, InvalidMemoryError{Memory: memory}
+	}
+
+	n, err := strconv.Atoi(m)
+	if err != nil || n < 1 {
+		return 0, InvalidMemoryError{Memory: memory}
+	}
+
+	return n * multiplier, nil
+}
+
+// fromMegabytes writes a memory limit the way the Cloud Foundry CLI does, such as 1G or 512M.
+func fromMegabytes(megabytes int) string {
+	if megabytes > 0 && megabytes%1024 == 0 {
+		return fmt.Sprintf("%dG", megabytes/1024)
+	}
+	return fmt.Sprintf("%dM", megabytes)
+}
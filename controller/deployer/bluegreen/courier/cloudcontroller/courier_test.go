@@ -0,0 +1,584 @@
+package cloudcontroller_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/cloudcontroller"
+
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/executor"
+	"github.com/compozed/deployadactyl/interfaces"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+// fakeCloudController answers the requests of the Courier from handlers keyed by method and path,
+// and records every request it receives.
+type fakeCloudController struct {
+	server   *httptest.Server
+	mutex    sync.Mutex
+	handlers map[string]http.HandlerFunc
+	requests []string
+	bodies   map[string][]byte
+}
+
+func newFakeCloudController() *fakeCloudController {
+	f := &fakeCloudController{handlers: map[string]http.HandlerFunc{}, bodies: map[string][]byte{}}
+	f.server = httptest.NewTLSServer(http.HandlerFunc(f.serve))
+
+	f.handle("GET /", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, map[string]interface{}{
+			"links": map[string]interface{}{
+				"login":     map[string]string{"href": f.server.URL},
+				"log_cache": map[string]string{"href": f.server.URL},
+			},
+		})
+	})
+	f.handle("POST /oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, map[string]string{"access_token": "the-token", "token_type": "bearer"})
+	})
+	f.handle("GET /v3/organizations", list(map[string]interface{}{"guid": "org-guid", "name": "org"}))
+	f.handle("GET /v3/spaces", list(map[string]interface{}{"guid": "space-guid", "name": "space"}))
+	f.handle("GET /v3/apps", list(map[string]interface{}{"guid": "app-guid", "name": "app", "state": "STARTED"}))
+	f.handle("GET /v3/domains", list(map[string]interface{}{"guid": "domain-guid", "name": "example.com"}))
+
+	return f
+}
+
+func (f *fakeCloudController) handle(request string, handler http.HandlerFunc) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.handlers[request] = handler
+}
+
+func (f *fakeCloudController) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	f.mutex.Lock()
+	key := r.Method + " " + r.URL.Path
+	f.requests = append(f.requests, key)
+	f.bodies[key] = body
+	handler, ok := f.handlers[key]
+	f.mutex.Unlock()
+
+	if !ok {
+		respond(w, http.StatusNotFound, map[string]interface{}{"errors": []map[string]string{{"detail": "not found"}}})
+		return
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	handler(w, r)
+}
+
+func (f *fakeCloudController) received() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]string{}, f.requests...)
+}
+
+func (f *fakeCloudController) body(request string) map[string]interface{} {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var body map[string]interface{}
+	Expect(json.Unmarshal(f.bodies[request], &body)).To(Succeed())
+	return body
+}
+
+func respond(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func list(resources ...interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if resources == nil {
+			resources = []interface{}{}
+		}
+		respond(w, http.StatusOK, map[string]interface{}{"pagination": map[string]interface{}{"next": nil}, "resources": resources})
+	}
+}
+
+func ok(body interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, body)
+	}
+}
+
+var _ = Describe("Cloud Controller Courier", func() {
+	var (
+		fake     *fakeCloudController
+		ctx      context.Context
+		cc       interfaces.Courier
+		interval time.Duration
+	)
+
+	BeforeEach(func() {
+		interval = PollInterval
+		PollInterval = time.Millisecond
+
+		fake = newFakeCloudController()
+		ctx = context.Background()
+	})
+
+	JustBeforeEach(func() {
+		ex, err := executor.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+		Expect(err).ToNot(HaveOccurred())
+
+		cc = NewCourier(ex.WithContext(ctx))
+		_, err = cc.Login(fake.server.URL, "user", "password", "org", "space", true, false)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		PollInterval = interval
+		fake.server.Close()
+	})
+
+	Describe("logging in", func() {
+		It("gets a token with the password grant of the cf client", func() {
+			var form, clientID string
+			fake.handle("POST /oauth/token", func(w http.ResponseWriter, r *http.Request) {
+				clientID, _, _ = r.BasicAuth()
+				r.ParseForm()
+				form = r.Form.Encode()
+				respond(w, http.StatusOK, map[string]string{"access_token": "the-token", "token_type": "bearer"})
+			})
+
+			_, err := cc.Login(fake.server.URL, "user", "password", "org", "space", true, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(clientID).To(Equal("cf"))
+			Expect(form).To(Equal("grant_type=password&password=password&username=user"))
+		})
+
+		It("sends the token with every Cloud Controller request", func() {
+			var authorization string
+			fake.handle("POST /v3/apps/app-guid/actions/start", func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+				respond(w, http.StatusOK, map[string]string{})
+			})
+
+			_, err := cc.Start("app")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(authorization).To(Equal("bearer the-token"))
+		})
+
+		It("returns an error when the space does not exist", func() {
+			fake.handle("GET /v3/spaces", list())
+
+			_, err := cc.Login(fake.server.URL, "user", "password", "org", "space", true, false)
+
+			Expect(err).To(MatchError(NotFoundError{Kind: "space", Name: "space"}))
+		})
+
+		It("returns an error when the credentials are rejected", func() {
+			fake.handle("POST /oauth/token", func(w http.ResponseWriter, r *http.Request) {
+				respond(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			})
+
+			_, err := cc.Login(fake.server.URL, "user", "password", "org", "space", true, false)
+
+			Expect(err).To(BeAssignableToTypeOf(ResponseError{}))
+			Expect(err.(ResponseError).StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("cannot run operations before logging in", func() {
+			_, err := NewCourier(nil).Rename("app", "new-app")
+
+			Expect(err).To(MatchError(NotLoggedInError{}))
+		})
+
+		It("gets a token with the client credentials grant of a UAA client", func() {
+			var form, clientID, clientSecret string
+			fake.handle("POST /oauth/token", func(w http.ResponseWriter, r *http.Request) {
+				clientID, clientSecret, _ = r.BasicAuth()
+				r.ParseForm()
+				form = r.Form.Encode()
+				respond(w, http.StatusOK, map[string]string{"access_token": "the-token", "token_type": "bearer"})
+			})
+
+			_, err := cc.Login(fake.server.URL, "client", "secret", "org", "space", true, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(clientID).To(Equal("client"))
+			Expect(clientSecret).To(Equal("secret"))
+			Expect(form).To(Equal("grant_type=client_credentials"))
+		})
+
+		Context("with a session cache", func() {
+			var (
+				sessions *executor.Sessions
+				grants   []string
+				token    map[string]interface{}
+			)
+
+			BeforeEach(func() {
+				sessions = executor.NewSessions()
+				token = map[string]interface{}{"access_token": "the-token", "token_type": "bearer", "refresh_token": "the-refresh-token", "expires_in": 3600}
+
+				fake.handle("POST /oauth/token", func(w http.ResponseWriter, r *http.Request) {
+					r.ParseForm()
+					grants = append(grants, r.Form.Get("grant_type"))
+					respond(w, http.StatusOK, token)
+				})
+			})
+
+			JustBeforeEach(func() {
+				grants = nil
+			})
+
+			login := func(password string) error {
+				ex, err := executor.New(&afero.Afero{Fs: afero.NewMemMapFs()})
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = NewCourier(ex.WithSessions(sessions)).Login(fake.server.URL, "user", password, "org", "space", true, false)
+				return err
+			}
+
+			It("reuses the token of an earlier login", func() {
+				Expect(login("password")).To(Succeed())
+				Expect(login("password")).To(Succeed())
+
+				Expect(grants).To(Equal([]string{"password"}))
+			})
+
+			It("does not reuse the token for another password", func() {
+				Expect(login("password")).To(Succeed())
+				Expect(login("another-password")).To(Succeed())
+
+				Expect(grants).To(Equal([]string{"password", "password"}))
+			})
+
+			It("refreshes the token when it has expired", func() {
+				token["expires_in"] = 1
+
+				Expect(login("password")).To(Succeed())
+				Expect(login("password")).To(Succeed())
+
+				Expect(grants).To(Equal([]string{"password", "refresh_token"}))
+			})
+
+			It("logs in again when the token has been revoked", func() {
+				Expect(login("password")).To(Succeed())
+
+				fake.handle("GET /v3/organizations", func(w http.ResponseWriter, r *http.Request) {
+					if r.Header.Get("Authorization") == "bearer the-token" {
+						respond(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+						return
+					}
+					list(map[string]interface{}{"guid": "org-guid", "name": "org"})(w, r)
+				})
+				token["access_token"] = "another-token"
+
+				Expect(login("password")).To(Succeed())
+
+				Expect(grants).To(Equal([]string{"password", "password"}))
+			})
+
+			It("forgets the session when the login fails", func() {
+				Expect(login("password")).To(Succeed())
+
+				fake.handle("GET /v3/organizations", func(w http.ResponseWriter, r *http.Request) {
+					respond(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+				})
+
+				Expect(login("password")).ToNot(Succeed())
+				Expect(sessions.Len()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("pushing", func() {
+		var appLocation string
+
+		BeforeEach(func() {
+			var err error
+			appLocation, err = ioutil.TempDir("", "cloudcontroller-push-")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ioutil.WriteFile(filepath.Join(appLocation, "index.html"), []byte("hello"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(appLocation, "manifest.yml"), []byte("---\napplications:\n- name: ignored\n  memory: 1G\n  buildpack: staticfile_buildpack\n  env:\n    GREETING: hello\n"), 0644)).To(Succeed())
+
+			var packageState = "PROCESSING_UPLOAD"
+			fake.handle("GET /v3/apps", list())
+			fake.handle("POST /v3/apps", ok(map[string]string{"guid": "app-guid", "name": "app-new-build"}))
+			fake.handle("PATCH /v3/apps/app-guid/environment_variables", ok(map[string]string{}))
+			fake.handle("POST /v3/packages", ok(map[string]string{"guid": "package-guid", "state": "AWAITING_UPLOAD"}))
+			fake.handle("POST /v3/packages/package-guid/upload", func(w http.ResponseWriter, r *http.Request) {
+				file, _, err := r.FormFile("bits")
+				Expect(err).ToNot(HaveOccurred())
+				bits, _ := ioutil.ReadAll(file)
+				archive, err := zip.NewReader(bytes.NewReader(bits), int64(len(bits)))
+				Expect(err).ToNot(HaveOccurred())
+				var names []string
+				for _, f := range archive.File {
+					names = append(names, f.Name)
+				}
+				Expect(names).To(ConsistOf("index.html", "manifest.yml"))
+
+				respond(w, http.StatusOK, map[string]string{"guid": "package-guid", "state": "PROCESSING_UPLOAD"})
+			})
+			fake.handle("GET /v3/packages/package-guid", func(w http.ResponseWriter, r *http.Request) {
+				respond(w, http.StatusOK, map[string]string{"guid": "package-guid", "state": packageState})
+				packageState = "READY"
+			})
+			fake.handle("POST /v3/builds", ok(map[string]string{"guid": "build-guid", "state": "STAGING"}))
+			fake.handle("GET /v3/builds/build-guid", ok(map[string]interface{}{"guid": "build-guid", "state": "STAGED", "droplet": map[string]string{"guid": "droplet-guid"}}))
+			fake.handle("PATCH /v3/apps/app-guid/relationships/current_droplet", ok(map[string]string{}))
+			fake.handle("POST /v3/apps/app-guid/processes/web/actions/scale", ok(map[string]string{}))
+			fake.handle("GET /v3/routes", list())
+			fake.handle("POST /v3/routes", ok(map[string]string{"guid": "route-guid"}))
+			fake.handle("POST /v3/routes/route-guid/destinations", ok(map[string]string{}))
+			fake.handle("POST /v3/apps/app-guid/actions/start", ok(map[string]string{}))
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(appLocation)
+		})
+
+		It("creates, uploads, stages, scales, routes and starts the application", func() {
+			_, err := cc.Push("app-new-build", appLocation, "app", 3)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fake.received()).To(ContainElement("POST /v3/apps"))
+			Expect(fake.received()).To(ContainElement("POST /v3/packages/package-guid/upload"))
+			Expect(fake.received()).To(ContainElement("PATCH /v3/apps/app-guid/relationships/current_droplet"))
+			Expect(fake.received()[len(fake.received())-1]).To(Equal("POST /v3/apps/app-guid/actions/start"))
+
+			Expect(fake.body("POST /v3/apps")["lifecycle"]).To(Equal(map[string]interface{}{
+				"type": "buildpack",
+				"data": map[string]interface{}{"buildpacks": []interface{}{"staticfile_buildpack"}},
+			}))
+			Expect(fake.body("PATCH /v3/apps/app-guid/environment_variables")["var"]).To(Equal(map[string]interface{}{"GREETING": "hello"}))
+			Expect(fake.body("PATCH /v3/apps/app-guid/relationships/current_droplet")["data"]).To(Equal(map[string]interface{}{"guid": "droplet-guid"}))
+			Expect(fake.body("POST /v3/apps/app-guid/processes/web/actions/scale")).To(Equal(map[string]interface{}{"instances": 3.0, "memory_in_mb": 1024.0}))
+			Expect(fake.body("POST /v3/routes")["host"]).To(Equal("app"))
+			Expect(fake.body("POST /v3/routes/route-guid/destinations")["destinations"]).To(Equal([]interface{}{
+				map[string]interface{}{"app": map[string]interface{}{"guid": "app-guid"}},
+			}))
+		})
+
+		It("returns an error when staging fails", func() {
+			fake.handle("GET /v3/builds/build-guid", ok(map[string]string{"guid": "build-guid", "state": "FAILED", "error": "NoAppDetectedError"}))
+
+			_, err := cc.Push("app-new-build", appLocation, "app", 3)
+
+			Expect(err).To(MatchError(FailedError{Kind: "build", GUID: "build-guid", Reason: "NoAppDetectedError"}))
+			Expect(fake.received()).ToNot(ContainElement("POST /v3/apps/app-guid/actions/start"))
+		})
+
+		Context("when the push timeout of the environment runs out", func() {
+			BeforeEach(func() {
+				ctx = executor.WithTimeouts(context.Background(), map[string]time.Duration{"push": 50 * time.Millisecond})
+				fake.handle("GET /v3/builds/build-guid", ok(map[string]string{"guid": "build-guid", "state": "STAGING"}))
+			})
+
+			It("returns a timeout error like the cf CLI executor does", func() {
+				_, err := cc.Push("app-new-build", appLocation, "app", 3)
+
+				Expect(err).To(MatchError(executor.TimeoutError{Command: "push", Timeout: 50 * time.Millisecond}))
+			})
+		})
+	})
+
+	Describe("renaming", func() {
+		It("changes the name of the application", func() {
+			fake.handle("PATCH /v3/apps/app-guid", ok(map[string]string{}))
+
+			_, err := cc.Rename("app", "app-venerable")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fake.body("PATCH /v3/apps/app-guid")).To(Equal(map[string]interface{}{"name": "app-venerable"}))
+		})
+
+		It("returns an error when the application does not exist", func() {
+			fake.handle("GET /v3/apps", list())
+
+			_, err := cc.Rename("app", "app-venerable")
+
+			Expect(err).To(MatchError(NotFoundError{Kind: "app", Name: "app"}))
+		})
+	})
+
+	Describe("deleting", func() {
+		It("waits for the job that deletes the application", func() {
+			fake.handle("DELETE /v3/apps/app-guid", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Location", fake.server.URL+"/v3/jobs/job-guid")
+				w.WriteHeader(http.StatusAccepted)
+			})
+			fake.handle("GET /v3/jobs/job-guid", ok(map[string]interface{}{"guid": "job-guid", "state": "FAILED", "errors": []map[string]string{{"detail": "still bound"}}}))
+
+			_, err := cc.Delete("app")
+
+			Expect(err).To(MatchError(FailedError{Kind: "job", GUID: "job-guid", Reason: "still bound"}))
+		})
+
+		It("does not fail when the application does not exist", func() {
+			fake.handle("GET /v3/apps", list())
+
+			_, err := cc.Delete("app")
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("routes", func() {
+		BeforeEach(func() {
+			fake.handle("GET /v3/routes", list(map[string]interface{}{"guid": "route-guid", "host": "app", "path": "/path"}))
+		})
+
+		It("maps a route with a path to the application", func() {
+			var query string
+			fake.handle("GET /v3/routes", func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query().Get("paths")
+				list(map[string]interface{}{"guid": "route-guid"})(w, r)
+			})
+			fake.handle("POST /v3/routes/route-guid/destinations", ok(map[string]string{}))
+
+			_, err := cc.MapRouteWithPath("app", "example.com", "app", "path")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(query).To(Equal("/path"))
+			Expect(fake.received()).To(ContainElement("POST /v3/routes/route-guid/destinations"))
+		})
+
+		It("only unmaps the route from the application", func() {
+			fake.handle("GET /v3/routes/route-guid/destinations", ok(map[string]interface{}{
+				"destinations": []map[string]interface{}{
+					{"guid": "other-destination", "app": map[string]string{"guid": "other-app-guid"}},
+					{"guid": "destination-guid", "app": map[string]string{"guid": "app-guid"}},
+				},
+			}))
+			fake.handle("DELETE /v3/routes/route-guid/destinations/destination-guid", ok(map[string]string{}))
+
+			_, err := cc.UnmapRouteWithPath("app", "example.com", "app", "/path")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fake.received()).To(ContainElement("DELETE /v3/routes/route-guid/destinations/destination-guid"))
+			Expect(fake.received()).ToNot(ContainElement("DELETE /v3/routes/route-guid/destinations/other-destination"))
+		})
+	})
+
+	Describe("listing domains", func() {
+		It("reads every page", func() {
+			fake.handle("GET /v3/domains", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("page") == "2" {
+					list(map[string]string{"name": "b.example.com"})(w, r)
+					return
+				}
+				respond(w, http.StatusOK, map[string]interface{}{
+					"pagination": map[string]interface{}{"next": map[string]string{"href": fake.server.URL + path.Join("/v3/domains") + "?page=2"}},
+					"resources":  []map[string]string{{"name": "a.example.com"}},
+				})
+			})
+
+			domains, err := cc.Domains()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(domains).To(Equal([]string{"a.example.com", "b.example.com"}))
+		})
+	})
+
+	Describe("getting the status of an application", func() {
+		It("reads the state, instances, routes and lifecycle", func() {
+			fake.handle("GET /v3/apps", list(map[string]interface{}{
+				"guid":      "app-guid",
+				"state":     "STARTED",
+				"lifecycle": map[string]interface{}{"data": map[string]interface{}{"buildpacks": []string{"java_buildpack"}, "stack": "cflinuxfs3"}},
+			}))
+			fake.handle("GET /v3/apps/app-guid/processes/web", ok(map[string]interface{}{"instances": 2, "memory_in_mb": 1024}))
+			fake.handle("GET /v3/apps/app-guid/processes/web/stats", ok(map[string]interface{}{
+				"resources": []map[string]string{{"state": "RUNNING"}, {"state": "CRASHED"}},
+			}))
+			fake.handle("GET /v3/apps/app-guid/routes", list(map[string]string{"url": "app.example.com"}))
+
+			status, err := cc.AppStatus("app")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(status).To(Equal(S.AppStatus{
+				State:          "started",
+				Instances:      2,
+				Running:        1,
+				Memory:         "1G",
+				InstanceStates: []string{"running", "crashed"},
+				Routes:         []string{"app.example.com"},
+				Buildpack:      "java_buildpack",
+				Stack:          "cflinuxfs3",
+			}))
+		})
+
+		It("returns an app status error when the application cannot be read", func() {
+			fake.handle("GET /v3/apps", list())
+
+			_, err := cc.AppStatus("app")
+
+			Expect(err).To(BeAssignableToTypeOf(courier.AppStatusError{}))
+		})
+	})
+
+	Describe("scaling memory", func() {
+		It("restarts a started application", func() {
+			fake.handle("POST /v3/apps/app-guid/processes/web/actions/scale", ok(map[string]string{}))
+			fake.handle("POST /v3/apps/app-guid/actions/restart", ok(map[string]string{}))
+
+			_, err := cc.ScaleMemory("app", "512M")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fake.body("POST /v3/apps/app-guid/processes/web/actions/scale")).To(Equal(map[string]interface{}{"memory_in_mb": 512.0}))
+			Expect(fake.received()).To(ContainElement("POST /v3/apps/app-guid/actions/restart"))
+		})
+
+		It("returns an error for an invalid memory limit", func() {
+			_, err := cc.ScaleMemory("app", "lots")
+
+			Expect(err).To(MatchError(InvalidMemoryError{Memory: "lots"}))
+		})
+	})
+
+	Describe("user provided services", func() {
+		It("returns the applications bound to each user provided service", func() {
+			fake.handle("GET /v3/service_instances", list(
+				map[string]string{"guid": "bound-guid", "name": "bound"},
+				map[string]string{"guid": "unbound-guid", "name": "unbound"},
+			))
+			fake.handle("GET /v3/service_credential_bindings", list(map[string]interface{}{
+				"relationships": map[string]interface{}{
+					"app":              map[string]interface{}{"data": map[string]string{"guid": "app-guid"}},
+					"service_instance": map[string]interface{}{"data": map[string]string{"guid": "bound-guid"}},
+				},
+			}))
+
+			services, err := cc.UserProvidedServices()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(services).To(Equal(map[string][]string{"bound": {"app"}, "unbound": {}}))
+		})
+
+		It("creates a user provided service with the credentials", func() {
+			fake.handle("POST /v3/service_instances", ok(map[string]string{}))
+
+			_, err := cc.Cups("app", `{"user":"name"}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			body := fake.body("POST /v3/service_instances")
+			Expect(body["type"]).To(Equal("user-provided"))
+			Expect(body["name"]).To(Equal("app"))
+			Expect(body["credentials"]).To(Equal(map[string]interface{}{"user": "name"}))
+		})
+	})
+})
@@ -0,0 +1,133 @@
+package cloudcontroller
+
+import (
+	"fmt"
+	"net/http"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
+
+type ResponseError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       []byte
+}
+
+func (e ResponseError) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.URL, e.StatusCode, string(e.Body))
+}
+
+func (e ResponseError) Code() string {
+	return "CloudControllerResponseError"
+}
+
+func (e ResponseError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e ResponseError) Retryable() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+}
+
+type NotLoggedInError struct{}
+
+func (e NotLoggedInError) Error() string {
+	return "not logged in to the cloud controller"
+}
+
+func (e NotLoggedInError) Code() string {
+	return "NotLoggedInError"
+}
+
+func (e NotLoggedInError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e NotLoggedInError) Retryable() bool {
+	return false
+}
+
+type NotFoundError struct {
+	Kind string
+	Name string
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Kind, e.Name)
+}
+
+func (e NotFoundError) Code() string {
+	return "CloudControllerNotFoundError"
+}
+
+func (e NotFoundError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e NotFoundError) Retryable() bool {
+	return false
+}
+
+type FailedError struct {
+	Kind   string
+	GUID   string
+	Reason string
+}
+
+func (e FailedError) Error() string {
+	return fmt.Sprintf("%s %s failed: %s", e.Kind, e.GUID, e.Reason)
+}
+
+func (e FailedError) Code() string {
+	return "CloudControllerFailedError"
+}
+
+func (e FailedError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e FailedError) Retryable() bool {
+	return true
+}
+
+type PollTimeoutError struct {
+	Kind string
+	GUID string
+}
+
+func (e PollTimeoutError) Error() string {
+	return fmt.Sprintf("%s %s did not finish in time", e.Kind, e.GUID)
+}
+
+func (e PollTimeoutError) Code() string {
+	return "CloudControllerPollTimeoutError"
+}
+
+func (e PollTimeoutError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e PollTimeoutError) Retryable() bool {
+	return true
+}
+
+type InvalidMemoryError struct {
+	Memory string
+}
+
+func (e InvalidMemoryError) Error() string {
+	return fmt.Sprintf("invalid memory limit: %s", e.Memory)
+}
+
+func (e InvalidMemoryError) Code() string {
+	return "InvalidMemoryError"
+}
+
+func (e InvalidMemoryError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e InvalidMemoryError) Retryable() bool {
+	return false
+}
@@ -0,0 +1,276 @@
+package cloudcontroller
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry-incubator/candiedyaml"
+)
+
+// pushManifest holds the attributes of the first application of a manifest that Push applies.
+type pushManifest struct {
+	Memory     string            `yaml:"memory"`
+	DiskQuota  string            `yaml:"disk_quota"`
+	Buildpack  string            `yaml:"buildpack"`
+	Buildpacks []string          `yaml:"buildpacks"`
+	Stack      string            `yaml:"stack"`
+	Env        map[string]string `yaml:"env"`
+}
+
+// Push creates the application if it does not exist, uploads the contents of the application
+// location as a new package, stages it, scales the web process to the instances and starts the
+// application on a route with the hostname on the first shared domain.
+//
+// The memory, disk quota, buildpacks, stack and env of the first application in the manifest.yml of
+// the application location are applied. Its name, instances and routes are left out.
+func (c *Courier) Push(appName, appLocation, hostname string, instances uint16) ([]byte, error) {
+	o := c.operation("push")
+	out := &bytes.Buffer{}
+	err := c.push(o, out, appName, appLocation, hostname, instances)
+	return out.Bytes(), o.done(err)
+}
+
+func (c *Courier) push(o operation, out io.Writer, appName, appLocation, hostname string, instances uint16) error {
+	manifest, err := readManifest(appLocation)
+	if err != nil {
+		return err
+	}
+
+	lifecycle := map[string]interface{}{}
+	if manifest.Buildpack != "" {
+		lifecycle["buildpacks"] = []string{manifest.Buildpack}
+	}
+	if len(manifest.Buildpacks) > 0 {
+		lifecycle["buildpacks"] = manifest.Buildpacks
+	}
+	if manifest.Stack != "" {
+		lifecycle["stack"] = manifest.Stack
+	}
+
+	app, err := c.app(o, appName)
+	if _, ok := err.(NotFoundError); ok {
+		fmt.Fprintf(out, "Creating app %s\n", appName)
+
+		body := map[string]interface{}{"name": appName, "relationships": to("space", c.spaceGUID)}
+		if len(lifecycle) > 0 {
+			body["lifecycle"] = map[string]interface{}{"type": "buildpack", "data": lifecycle}
+		}
+		_, err = c.do(o, http.MethodPost, "/v3/apps", body, &app)
+	} else if err == nil && len(lifecycle) > 0 {
+		fmt.Fprintf(out, "Updating app %s\n", appName)
+
+		body := map[string]interface{}{"lifecycle": map[string]interface{}{"type": "buildpack", "data": lifecycle}}
+		_, err = c.do(o, http.MethodPatch, "/v3/apps/"+app.GUID, body, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(manifest.Env) > 0 {
+		_, err = c.do(o, http.MethodPatch, "/v3/apps/"+app.GUID+"/environment_variables", map[string]interface{}{"var": manifest.Env}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "Uploading %s\n", appName)
+	packageGUID, err := c.upload(o, app.GUID, appLocation)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Staging %s\n", appName)
+	dropletGUID, err := c.stage(o, packageGUID)
+	if err != nil {
+		return err
+	}
+	err = c.setDroplet(o, app.GUID, dropletGUID)
+	if err != nil {
+		return err
+	}
+
+	scale := map[string]interface{}{"instances": instances}
+	if manifest.Memory != "" {
+		scale["memory_in_mb"], err = toMegabytes(manifest.Memory)
+		if err != nil {
+			return err
+		}
+	}
+	if manifest.DiskQuota != "" {
+		scale["disk_in_mb"], err = toMegabytes(manifest.DiskQuota)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = c.do(o, http.MethodPost, "/v3/apps/"+app.GUID+"/processes/web/actions/scale", scale, nil)
+	if err != nil {
+		return err
+	}
+
+	if hostname != "" {
+		domain, err := c.sharedDomain(o)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "Mapping route %s.%s to %s\n", hostname, domain.Name, appName)
+		err = c.mapRoute(o, app, domain, hostname, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "Starting app %s\n", appName)
+	_, err = c.do(o, http.MethodPost, "/v3/apps/"+app.GUID+"/actions/start", nil, nil)
+	return err
+}
+
+// upload creates a package for the application and uploads the contents of the application location into it.
+//
+// Returns the guid of the package once it is ready.
+func (c *Courier) upload(o operation, appGUID, appLocation string) (string, error) {
+	bits, err := zipDirectory(appLocation)
+	if err != nil {
+		return "", err
+	}
+
+	var pkg resource
+	_, err = c.do(o, http.MethodPost, "/v3/packages", map[string]interface{}{"type": "bits", "relationships": to("app", appGUID)}, &pkg)
+	if err != nil {
+		return "", err
+	}
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, err := form.CreateFormFile("bits", "application.zip")
+	if err != nil {
+		return "", err
+	}
+	_, err = part.Write(bits)
+	if err != nil {
+		return "", err
+	}
+	err = form.Close()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = c.do(o, http.MethodPost, "/v3/packages/"+pkg.GUID+"/upload", multipartBody{reader: body, contentType: form.FormDataContentType()}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	pkg, err = c.poll(o, "package", "/v3/packages/"+pkg.GUID, "AWAITING_UPLOAD", "PROCESSING_UPLOAD", "COPYING")
+	if err != nil {
+		return "", err
+	}
+	if pkg.State != "READY" {
+		return "", FailedError{Kind: "package", GUID: pkg.GUID, Reason: pkg.State}
+	}
+
+	return pkg.GUID, nil
+}
+
+// stage builds the package.
+//
+// Returns the guid of the droplet once it is staged.
+func (c *Courier) stage(o operation, packageGUID string) (string, error) {
+	var build resource
+	_, err := c.do(o, http.MethodPost, "/v3/builds", map[string]interface{}{"package": map[string]string{"guid": packageGUID}}, &build)
+	if err != nil {
+		return "", err
+	}
+
+	build, err = c.poll(o, "build", "/v3/builds/"+build.GUID, "STAGING")
+	if err != nil {
+		return "", err
+	}
+	if build.State != "STAGED" {
+		return "", FailedError{Kind: "build", GUID: build.GUID, Reason: build.reason()}
+	}
+
+	return build.Droplet.GUID, nil
+}
+
+// setDroplet makes the droplet the current droplet of the application.
+func (c *Courier) setDroplet(o operation, appGUID, dropletGUID string) error {
+	_, err := c.do(o, http.MethodPatch, "/v3/apps/"+appGUID+"/relationships/current_droplet", map[string]interface{}{"data": map[string]string{"guid": dropletGUID}}, nil)
+	return err
+}
+
+// readManifest reads the first application of the manifest.yml in the application location, if there is one.
+func readManifest(appLocation string) (pushManifest, error) {
+	var m struct {
+		Applications []pushManifest `yaml:"applications"`
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(appLocation, "manifest.yml"))
+	if os.IsNotExist(err) {
+		return pushManifest{}, nil
+	}
+	if err != nil {
+		return pushManifest{}, err
+	}
+
+	err = candiedyaml.Unmarshal(contents, &m)
+	if err != nil || len(m.Applications) == 0 {
+		return pushManifest{}, err
+	}
+
+	return m.Applications[0], nil
+}
+
+// zipDirectory zips the files under the directory with paths relative to it.
+func zipDirectory(directory string) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	archive := zip.NewWriter(buffer)
+
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == directory {
+			return err
+		}
+
+		relative, err := filepath.Rel(directory, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relative)
+		if info.IsDir() {
+			header.Name += "/"
+		} else {
+			header.Method = zip.Deflate
+		}
+
+		writer, err := archive.CreateHeader(header)
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(writer, file)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = archive.Close()
+	return buffer.Bytes(), err
+}
@@ -0,0 +1,202 @@
+package cloudcontroller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type destinations struct {
+	Destinations []struct {
+		GUID string `json:"guid"`
+		App  struct {
+			GUID string `json:"guid"`
+		} `json:"app"`
+	} `json:"destinations"`
+}
+
+// MapRoute maps the route with the hostname on the domain to the application, creating the route if it does not exist.
+func (c *Courier) MapRoute(appName, domain, hostname string) ([]byte, error) {
+	return c.MapRouteWithPath(appName, domain, hostname, "")
+}
+
+// MapRouteWithPath maps the route with the hostname and path on the domain to the application,
+// creating the route if it does not exist.
+func (c *Courier) MapRouteWithPath(appName, domain, hostname, path string) ([]byte, error) {
+	o := c.operation("map-route")
+
+	app, err := c.app(o, appName)
+	if err != nil {
+		return nil, o.done(err)
+	}
+	d, err := c.domain(o, domain)
+	if err != nil {
+		return nil, o.done(err)
+	}
+	err = c.mapRoute(o, app, d, hostname, path)
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Mapping route %s.%s%s to app %s\n", hostname, domain, path, appName)), o.done(nil)
+}
+
+// UnmapRoute unmaps the route with the hostname on the domain from the application.
+func (c *Courier) UnmapRoute(appName, domain, hostname string) ([]byte, error) {
+	return c.UnmapRouteWithPath(appName, domain, hostname, "")
+}
+
+// UnmapRouteWithPath unmaps the route with the hostname and path on the domain from the application.
+func (c *Courier) UnmapRouteWithPath(appName, domain, hostname, path string) ([]byte, error) {
+	o := c.operation("unmap-route")
+	err := c.unmapRoute(o, appName, domain, hostname, path)
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Removing route %s.%s%s from app %s\n", hostname, domain, path, appName)), o.done(nil)
+}
+
+func (c *Courier) unmapRoute(o operation, appName, domain, hostname, path string) error {
+	app, err := c.app(o, appName)
+	if err != nil {
+		return err
+	}
+	d, err := c.domain(o, domain)
+	if err != nil {
+		return err
+	}
+	route, err := c.route(o, d, hostname, path)
+	if err != nil {
+		return err
+	}
+
+	var mapped destinations
+	_, err = c.do(o, http.MethodGet, "/v3/routes/"+route.GUID+"/destinations", nil, &mapped)
+	if err != nil {
+		return err
+	}
+
+	for _, destination := range mapped.Destinations {
+		if destination.App.GUID != app.GUID {
+			continue
+		}
+
+		_, err = c.do(o, http.MethodDelete, "/v3/routes/"+route.GUID+"/destinations/"+destination.GUID, nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteRoute deletes the route with the hostname on the domain. A route that does not exist is not an error.
+func (c *Courier) DeleteRoute(domain, hostname string) ([]byte, error) {
+	o := c.operation("delete-route")
+
+	d, err := c.domain(o, domain)
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	route, err := c.route(o, d, hostname, "")
+	if _, ok := err.(NotFoundError); ok {
+		return []byte(fmt.Sprintf("Route %s.%s does not exist.\n", hostname, domain)), o.done(nil)
+	}
+	if err == nil {
+		var response *http.Response
+		response, err = c.do(o, http.MethodDelete, "/v3/routes/"+route.GUID, nil, nil)
+		if err == nil {
+			err = c.wait(o, response)
+		}
+	}
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Deleting route %s.%s\n", hostname, domain)), o.done(nil)
+}
+
+// mapRoute maps the route to the application, creating the route if it does not exist.
+func (c *Courier) mapRoute(o operation, app, domain resource, hostname, path string) error {
+	path = routePath(path)
+
+	route, err := c.route(o, domain, hostname, path)
+	if _, ok := err.(NotFoundError); ok {
+		body := map[string]interface{}{"host": hostname, "relationships": to("space", c.spaceGUID, "domain", domain.GUID)}
+		if path != "" {
+			body["path"] = path
+		}
+		_, err = c.do(o, http.MethodPost, "/v3/routes", body, &route)
+	}
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"destinations": []interface{}{
+			map[string]interface{}{"app": map[string]string{"guid": app.GUID}},
+		},
+	}
+	_, err = c.do(o, http.MethodPost, "/v3/routes/"+route.GUID+"/destinations", body, nil)
+	return err
+}
+
+// route looks up the route with the hostname and path on the domain in the targeted space.
+func (c *Courier) route(o operation, domain resource, hostname, path string) (resource, error) {
+	path = routePath(path)
+	query := url.Values{"hosts": {hostname}, "domain_guids": {domain.GUID}, "space_guids": {c.spaceGUID}, "paths": {path}}
+
+	var routes []resource
+	err := c.list(o, "/v3/routes?"+query.Encode(), &routes)
+	if err != nil {
+		return resource{}, err
+	}
+	if len(routes) == 0 {
+		return resource{}, NotFoundError{Kind: "route", Name: hostname + "." + domain.Name + path}
+	}
+
+	return routes[0], nil
+}
+
+// domain looks up the domain by name.
+func (c *Courier) domain(o operation, name string) (resource, error) {
+	var domains []resource
+	err := c.list(o, "/v3/domains?"+url.Values{"names": {name}}.Encode(), &domains)
+	if err != nil {
+		return resource{}, err
+	}
+	if len(domains) == 0 {
+		return resource{}, NotFoundError{Kind: "domain", Name: name}
+	}
+
+	return domains[0], nil
+}
+
+// sharedDomain returns the first shared domain, which is where cf push puts routes by default.
+func (c *Courier) sharedDomain(o operation) (resource, error) {
+	var domains []resource
+	err := c.list(o, "/v3/domains", &domains)
+	if err != nil {
+		return resource{}, err
+	}
+
+	for _, domain := range domains {
+		if domain.Relationships["organization"].Data.GUID == "" {
+			return domain, nil
+		}
+	}
+
+	return resource{}, NotFoundError{Kind: "domain", Name: "shared"}
+}
+
+// routePath returns the path the way the Cloud Controller stores it, with a leading slash.
+// The Cloud Foundry CLI accepts paths without one.
+func routePath(path string) string {
+	if path == "" || strings.HasPrefix(path, "/") {
+		return path
+	}
+	return "/" + path
+}
@@ -0,0 +1,240 @@
+package cloudcontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier"
+)
+
+// CreateService creates a managed service instance of the plan of the service offering.
+func (c *Courier) CreateService(service, plan, name string) ([]byte, error) {
+	o := c.operation("create-service")
+	err := c.createService(o, service, plan, name)
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Creating service instance %s\n", name)), o.done(nil)
+}
+
+func (c *Courier) createService(o operation, service, plan, name string) error {
+	planGUID, err := c.find(o, "service plan", "/v3/service_plans?"+url.Values{"names": {plan}, "service_offering_names": {service}}.Encode(), service+" "+plan)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"type":          "managed",
+		"name":          name,
+		"relationships": to("space", c.spaceGUID, "service_plan", planGUID),
+	}
+	response, err := c.do(o, http.MethodPost, "/v3/service_instances", body, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.wait(o, response)
+}
+
+// BindService binds the service instance to the application.
+func (c *Courier) BindService(appName, serviceName string) ([]byte, error) {
+	o := c.operation("bind-service")
+	err := c.bindService(o, appName, serviceName)
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Binding service %s to app %s\n", serviceName, appName)), o.done(nil)
+}
+
+func (c *Courier) bindService(o operation, appName, serviceName string) error {
+	app, err := c.app(o, appName)
+	if err != nil {
+		return err
+	}
+	instance, err := c.serviceInstance(o, serviceName)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"type":          "app",
+		"relationships": to("app", app.GUID, "service_instance", instance.GUID),
+	}
+	response, err := c.do(o, http.MethodPost, "/v3/service_credential_bindings", body, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.wait(o, response)
+}
+
+// UnbindService unbinds the service instance from the application.
+func (c *Courier) UnbindService(appName, serviceName string) ([]byte, error) {
+	o := c.operation("unbind-service")
+	err := c.unbindService(o, appName, serviceName)
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Unbinding app %s from service %s\n", appName, serviceName)), o.done(nil)
+}
+
+func (c *Courier) unbindService(o operation, appName, serviceName string) error {
+	app, err := c.app(o, appName)
+	if err != nil {
+		return err
+	}
+	instance, err := c.serviceInstance(o, serviceName)
+	if err != nil {
+		return err
+	}
+
+	var bindings []resource
+	err = c.list(o, "/v3/service_credential_bindings?"+url.Values{"app_guids": {app.GUID}, "service_instance_guids": {instance.GUID}}.Encode(), &bindings)
+	if err != nil {
+		return err
+	}
+
+	for _, binding := range bindings {
+		response, err := c.do(o, http.MethodDelete, "/v3/service_credential_bindings/"+binding.GUID, nil, nil)
+		if err == nil {
+			err = c.wait(o, response)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteService deletes the service instance. A service instance that does not exist is not an error.
+func (c *Courier) DeleteService(serviceName string) ([]byte, error) {
+	o := c.operation("delete-service")
+
+	instance, err := c.serviceInstance(o, serviceName)
+	if _, ok := err.(NotFoundError); ok {
+		return []byte(fmt.Sprintf("Service %s does not exist.\n", serviceName)), o.done(nil)
+	}
+	if err == nil {
+		var response *http.Response
+		response, err = c.do(o, http.MethodDelete, "/v3/service_instances/"+instance.GUID, nil, nil)
+		if err == nil {
+			err = c.wait(o, response)
+		}
+	}
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Deleting service %s\n", serviceName)), o.done(nil)
+}
+
+// UserProvidedServices returns the applications bound to each user provided service in the targeted space.
+func (c *Courier) UserProvidedServices() (map[string][]string, error) {
+	o := c.operation("services")
+	services, err := c.userProvidedServices(o)
+	if err = o.done(err); err != nil {
+		return nil, courier.ServicesError{Out: []byte(err.Error())}
+	}
+
+	return services, nil
+}
+
+func (c *Courier) userProvidedServices(o operation) (map[string][]string, error) {
+	var instances []resource
+	err := c.list(o, "/v3/service_instances?"+url.Values{"type": {"user-provided"}, "space_guids": {c.spaceGUID}}.Encode(), &instances)
+	if err != nil {
+		return nil, err
+	}
+
+	services := map[string][]string{}
+	if len(instances) == 0 {
+		return services, nil
+	}
+
+	names := map[string]string{}
+	guids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		services[instance.Name] = []string{}
+		names[instance.GUID] = instance.Name
+		guids = append(guids, instance.GUID)
+	}
+
+	var bindings []resource
+	err = c.list(o, "/v3/service_credential_bindings?"+url.Values{"type": {"app"}, "service_instance_guids": {strings.Join(guids, ",")}}.Encode(), &bindings)
+	if err != nil {
+		return nil, err
+	}
+
+	var apps []resource
+	err = c.list(o, "/v3/apps?"+url.Values{"space_guids": {c.spaceGUID}}.Encode(), &apps)
+	if err != nil {
+		return nil, err
+	}
+	appNames := map[string]string{}
+	for _, app := range apps {
+		appNames[app.GUID] = app.Name
+	}
+
+	for _, binding := range bindings {
+		service := names[binding.Relationships["service_instance"].Data.GUID]
+		if app, ok := appNames[binding.Relationships["app"].Data.GUID]; ok {
+			services[service] = append(services[service], app)
+		}
+	}
+
+	return services, nil
+}
+
+// Cups creates a user provided service with the name of the application and the credentials of the JSON body.
+func (c *Courier) Cups(appName string, body string) ([]byte, error) {
+	o := c.operation("cups")
+
+	request := map[string]interface{}{
+		"type":          "user-provided",
+		"name":          appName,
+		"credentials":   json.RawMessage(body),
+		"relationships": to("space", c.spaceGUID),
+	}
+	_, err := c.do(o, http.MethodPost, "/v3/service_instances", request, nil)
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Creating user provided service %s\n", appName)), o.done(nil)
+}
+
+// Uups replaces the credentials of the user provided service with the name of the application.
+func (c *Courier) Uups(appName string, body string) ([]byte, error) {
+	o := c.operation("uups")
+
+	instance, err := c.serviceInstance(o, appName)
+	if err == nil {
+		_, err = c.do(o, http.MethodPatch, "/v3/service_instances/"+instance.GUID, map[string]interface{}{"credentials": json.RawMessage(body)}, nil)
+	}
+	if err != nil {
+		return nil, o.done(err)
+	}
+
+	return []byte(fmt.Sprintf("Updating user provided service %s\n", appName)), o.done(nil)
+}
+
+// serviceInstance looks up the service instance in the targeted space.
+func (c *Courier) serviceInstance(o operation, name string) (resource, error) {
+	var instances []resource
+	err := c.list(o, "/v3/service_instances?"+url.Values{"names": {name}, "space_guids": {c.spaceGUID}}.Encode(), &instances)
+	if err != nil {
+		return resource{}, err
+	}
+	if len(instances) == 0 {
+		return resource{}, NotFoundError{Kind: "service instance", Name: name}
+	}
+
+	return instances[0], nil
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/executor"
 	I "github.com/compozed/deployadactyl/interfaces"
+	S "github.com/compozed/deployadactyl/structs"
 )
 
 type CourierConstructor func(executor I.Executor) I.Courier
@@ -21,16 +23,71 @@ type Courier struct {
 	Executor I.Executor
 }
 
-// Login runs the Cloud Foundry login command.
+// sessionExecutor is an Executor that can keep the session of the Cloud Foundry CLI across deployments.
+type sessionExecutor interface {
+	SaveSession(key string) error
+	RestoreSession(key string) (bool, error)
+	ForgetSession(key string)
+}
+
+// Login targets the org and space with the session cached for the user on the foundation. The
+// Cloud Foundry CLI refreshes its access token when it has expired. Without a cached session, or
+// when it can no longer be used, Login runs the Cloud Foundry login command, or the auth command
+// with the client credentials of a UAA client. A failed login forgets the cached session.
 //
 // Returns the combined standard output and standard error.
-func (c Courier) Login(foundationURL, username, password, org, space string, skipSSL bool) ([]byte, error) {
+func (c Courier) Login(foundationURL, username, password, org, space string, skipSSL, clientCredentials bool) ([]byte, error) {
+	key := executor.SessionKey(foundationURL, username, password, skipSSL, clientCredentials)
+
+	sessions, caching := c.Executor.(sessionExecutor)
+	if caching {
+		restored, err := sessions.RestoreSession(key)
+		if err == nil && restored {
+			output, err := c.Executor.Execute("target", "-o", org, "-s", space)
+			if err == nil {
+				sessions.SaveSession(key)
+				return output, nil
+			}
+			sessions.ForgetSession(key)
+		}
+	}
+
+	output, err := c.login(foundationURL, username, password, org, space, skipSSL, clientCredentials)
+	if caching {
+		if err != nil {
+			sessions.ForgetSession(key)
+		} else {
+			sessions.SaveSession(key)
+		}
+	}
+
+	return output, err
+}
+
+func (c Courier) login(foundationURL, username, password, org, space string, skipSSL, clientCredentials bool) ([]byte, error) {
 	var s string
 	if skipSSL {
 		s = "--skip-ssl-validation"
 	}
 
-	return c.Executor.Execute("login", "-a", foundationURL, "-u", username, "-p", password, "-o", org, "-s", space, s)
+	if !clientCredentials {
+		return c.Executor.Execute("login", "-a", foundationURL, "-u", username, "-p", password, "-o", org, "-s", space, s)
+	}
+
+	var output []byte
+	for _, args := range [][]string{
+		{"api", foundationURL, s},
+		{"auth", username, password, "--client-credentials"},
+		{"target", "-o", org, "-s", space},
+	} {
+		out, err := c.Executor.Execute(args...)
+		output = append(output, out...)
+		if err != nil {
+			return output, err
+		}
+	}
+
+	return output, nil
 }
 
 func (c Courier) CreateService(service, plan, name string) ([]byte, error) {
@@ -49,10 +106,27 @@ func (c Courier) DeleteService(serviceName string) ([]byte, error) {
 	return c.Executor.Execute("delete-service", serviceName, "-f")
 }
 
+// UserProvidedServices runs the Cloud Foundry services command.
+//
+// Returns the applications bound to each user provided service in the space.
+func (c Courier) UserProvidedServices() (map[string][]string, error) {
+	output, err := c.Executor.Execute("services")
+	if err != nil {
+		return nil, ServicesError{Out: output}
+	}
+
+	return parseUserProvidedServices(string(output)), nil
+}
+
 func (c Courier) Restage(appName string) ([]byte, error) {
 	return c.Executor.Execute("restage", appName)
 }
 
+// Restart runs the Cloud Foundry restart command.
+func (c Courier) Restart(appName string) ([]byte, error) {
+	return c.Executor.Execute("restart", appName)
+}
+
 func (c Courier) Start(appName string) ([]byte, error) {
 	return c.Executor.Execute("start", appName)
 }
@@ -61,6 +135,17 @@ func (c Courier) Stop(appName string) ([]byte, error) {
 	return c.Executor.Execute("stop", appName)
 }
 
+// Scale runs the Cloud Foundry scale command to change the number of instances of an application.
+func (c Courier) Scale(appName string, instances uint16) ([]byte, error) {
+	return c.Executor.Execute("scale", appName, "-i", fmt.Sprint(instances))
+}
+
+// ScaleMemory runs the Cloud Foundry scale command to change the memory limit of an application.
+// The application is restarted by Cloud Foundry without asking for confirmation.
+func (c Courier) ScaleMemory(appName, memory string) ([]byte, error) {
+	return c.Executor.Execute("scale", appName, "-m", memory, "-f")
+}
+
 // Delete runs the Cloud Foundry delete command.
 // Returns the combined standard output and standard error.
 func (c Courier) Delete(appName string) ([]byte, error) {
@@ -142,15 +227,45 @@ func (c Courier) Exists(appName string) bool {
 	return err == nil
 }
 
+// AppStatus runs the Cloud Foundry app command and reads the requested state, the instances
+// and the routes of the application from its output.
+func (c Courier) AppStatus(appName string) (S.AppStatus, error) {
+	output, err := c.Executor.Execute("app", appName)
+	if err != nil {
+		return S.AppStatus{}, AppStatusError{appName, output}
+	}
+
+	return parseAppStatus(string(output)), nil
+}
+
+// EnvironmentVariables runs the Cloud Foundry env command.
+//
+// Returns the user provided environment variables of the application.
+func (c Courier) EnvironmentVariables(appName string) (map[string]string, error) {
+	output, err := c.Executor.Execute("env", appName)
+	if err != nil {
+		return nil, EnvironmentVariablesError{ApplicationName: appName, Out: output}
+	}
+
+	return parseEnvironmentVariables(string(output)), nil
+}
+
 // Domains returns a list of domain in a foundation.
 //
 // Returns the combined standard output and standard error.
 func (c Courier) Domains() ([]string, error) {
 	output, err := c.Executor.Execute("domains")
 
-	domains := strings.Split(string(output), "\n")[2:]
-	for i, domain := range domains {
-		domains[i] = strings.Split(domain, " ")[0]
+	lines := strings.Split(string(output), "\n")
+	if len(lines) < 2 {
+		return []string{}, err
+	}
+
+	domains := []string{}
+	for _, line := range lines[2:] {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			domains = append(domains, fields[0])
+		}
 	}
 
 	return domains, err
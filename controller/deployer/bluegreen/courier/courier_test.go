@@ -1,6 +1,7 @@
 package courier_test
 
 import (
+	"errors"
 	"fmt"
 	. "github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier"
 	"math/rand"
@@ -12,6 +13,26 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+// cachingExecutor is an Executor with a session cache that holds no sessions.
+type cachingExecutor struct {
+	*mocks.Executor
+	saved     []string
+	forgotten []string
+}
+
+func (e *cachingExecutor) SaveSession(key string) error {
+	e.saved = append(e.saved, key)
+	return nil
+}
+
+func (e *cachingExecutor) RestoreSession(key string) (bool, error) {
+	return false, nil
+}
+
+func (e *cachingExecutor) ForgetSession(key string) {
+	e.forgotten = append(e.forgotten, key)
+}
+
 var _ = Describe("Courier", func() {
 	var (
 		appName  string
@@ -46,7 +67,7 @@ var _ = Describe("Courier", func() {
 			executor.ExecuteCall.Returns.Output = []byte(output)
 			executor.ExecuteCall.Returns.Error = nil
 
-			out, err := courier.Login(foundationURL, user, password, org, space, skipSSL)
+			out, err := courier.Login(foundationURL, user, password, org, space, skipSSL, false)
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(executor.ExecuteCall.Received.Args).To(Equal(expectedArgs))
@@ -67,12 +88,61 @@ var _ = Describe("Courier", func() {
 			executor.ExecuteCall.Returns.Output = []byte(output)
 			executor.ExecuteCall.Returns.Error = nil
 
-			out, err := courier.Login(foundationURL, user, password, org, space, skipSSL)
+			out, err := courier.Login(foundationURL, user, password, org, space, skipSSL, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(executor.ExecuteCall.Received.Args).To(Equal(expectedArgs))
+			Expect(string(out)).To(Equal(output))
+		})
+
+		It("can authenticate with client credentials and target the org and space afterwards", func() {
+			var (
+				org          = "org-" + randomizer.StringRunes(10)
+				space        = "space-" + randomizer.StringRunes(10)
+				expectedArgs = []string{"target", "-o", org, "-s", space}
+			)
+
+			executor.ExecuteCall.Returns.Output = []byte(output)
+			executor.ExecuteCall.Returns.Error = nil
+
+			out, err := courier.Login("foundationURL", "client", "secret", org, space, false, true)
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(executor.ExecuteCall.Received.Args).To(Equal(expectedArgs))
+			Expect(string(out)).To(Equal(output + output + output))
+		})
+
+		It("stops when the client credentials are rejected", func() {
+			executor.ExecuteCall.Returns.Output = []byte(output)
+			executor.ExecuteCall.Returns.Error = errors.New("rejected")
+
+			out, err := courier.Login("foundationURL", "client", "secret", "org", "space", false, true)
+			Expect(err).To(MatchError("rejected"))
+
+			Expect(executor.ExecuteCall.Received.Args).To(Equal([]string{"api", "foundationURL", ""}))
 			Expect(string(out)).To(Equal(output))
 		})
+
+		It("caches the session of a successful login", func() {
+			sessions := &cachingExecutor{Executor: executor}
+
+			_, err := Courier{Executor: sessions}.Login("foundationURL", "user", "password", "org", "space", false, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(sessions.saved).To(HaveLen(1))
+			Expect(sessions.forgotten).To(BeEmpty())
+		})
+
+		It("forgets the cached session when the login fails", func() {
+			sessions := &cachingExecutor{Executor: executor}
+			executor.ExecuteCall.Returns.Error = errors.New("rejected")
+
+			_, err := Courier{Executor: sessions}.Login("foundationURL", "user", "password", "org", "space", false, false)
+			Expect(err).To(MatchError("rejected"))
+
+			Expect(sessions.saved).To(BeEmpty())
+			Expect(sessions.forgotten).To(HaveLen(1))
+		})
 	})
 
 	Describe("starting an app", func() {
@@ -105,6 +175,51 @@ var _ = Describe("Courier", func() {
 		})
 	})
 
+	Describe("scaling an app", func() {
+		It("should send a valid Cloud Foundry scale command", func() {
+			expectedArgs := []string{"scale", appName, "-i", "3"}
+
+			executor.ExecuteCall.Returns.Output = []byte(output)
+			executor.ExecuteCall.Returns.Error = nil
+
+			out, err := courier.Scale(appName, 3)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(executor.ExecuteCall.Received.Args).To(Equal(expectedArgs))
+			Expect(string(out)).To(Equal(output))
+		})
+	})
+
+	Describe("scaling the memory of an app", func() {
+		It("should send a valid Cloud Foundry scale command without a prompt", func() {
+			expectedArgs := []string{"scale", appName, "-m", "1G", "-f"}
+
+			executor.ExecuteCall.Returns.Output = []byte(output)
+			executor.ExecuteCall.Returns.Error = nil
+
+			out, err := courier.ScaleMemory(appName, "1G")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(executor.ExecuteCall.Received.Args).To(Equal(expectedArgs))
+			Expect(string(out)).To(Equal(output))
+		})
+	})
+
+	Describe("restarting an app", func() {
+		It("should send a valid Cloud Foundry restart command", func() {
+			expectedArgs := []string{"restart", appName}
+
+			executor.ExecuteCall.Returns.Output = []byte(output)
+			executor.ExecuteCall.Returns.Error = nil
+
+			out, err := courier.Restart(appName)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(executor.ExecuteCall.Received.Args).To(Equal(expectedArgs))
+			Expect(string(out)).To(Equal(output))
+		})
+	})
+
 	Describe("deleting an app", func() {
 		It("should get a valid Cloud Foundry delete command", func() {
 			expectedArgs := []string{"delete", appName, "-f"}
@@ -271,6 +386,126 @@ var _ = Describe("Courier", func() {
 		})
 	})
 
+	Describe("getting the status of an app", func() {
+		It("reads the state, instances and routes", func() {
+			executor.ExecuteCall.Returns.Output = []byte(`Showing health and status for app ` + appName + ` in org org / space space as user...
+
+name:              ` + appName + `
+requested state:   started
+routes:            ` + appName + `.example.com, ` + appName + `.apps.example.com
+last uploaded:     Mon 01 Jan 12:00:00 UTC 2018
+stack:             cflinuxfs2
+buildpack:         java_buildpack
+
+type:           web
+instances:      1/2
+memory usage:   1024M
+     state      since                  cpu    memory         disk           details
+#0   running    2018-01-01T12:00:00Z   0.3%   120M of 1G     150M of 1G
+#1   starting   2018-01-01T12:00:00Z   0.0%   0 of 1G        0 of 1G
+`)
+
+			status, err := courier.AppStatus(appName)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(executor.ExecuteCall.Received.Args).To(Equal([]string{"app", appName}))
+			Expect(status.State).To(Equal("started"))
+			Expect(status.Running).To(Equal(uint16(1)))
+			Expect(status.Instances).To(Equal(uint16(2)))
+			Expect(status.Memory).To(Equal("1024M"))
+			Expect(status.InstanceStates).To(Equal([]string{"running", "starting"}))
+			Expect(status.Routes).To(Equal([]string{appName + ".example.com", appName + ".apps.example.com"}))
+			Expect(status.Stack).To(Equal("cflinuxfs2"))
+			Expect(status.Buildpack).To(Equal("java_buildpack"))
+		})
+
+		It("reads the urls of older versions of the cli", func() {
+			executor.ExecuteCall.Returns.Output = []byte("requested state: stopped\ninstances: 0/1\nurls: " + appName + ".example.com\n")
+
+			status, err := courier.AppStatus(appName)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(status.State).To(Equal("stopped"))
+			Expect(status.Instances).To(Equal(uint16(1)))
+			Expect(status.Routes).To(Equal([]string{appName + ".example.com"}))
+		})
+
+		It("returns an error when the app cannot be found", func() {
+			executor.ExecuteCall.Returns.Output = []byte("App " + appName + " not found")
+			executor.ExecuteCall.Returns.Error = fmt.Errorf("exit status 1")
+
+			_, err := courier.AppStatus(appName)
+			Expect(err).To(MatchError(AppStatusError{ApplicationName: appName, Out: []byte("App " + appName + " not found")}))
+		})
+	})
+
+	Describe("getting the environment variables of an app", func() {
+		It("returns the user provided environment variables", func() {
+			executor.ExecuteCall.Returns.Output = []byte(`Getting env variables for app ` + appName + ` in org org / space space as user...
+OK
+
+System-Provided:
+{
+ "VCAP_APPLICATION": {
+  "application_name": "` + appName + `"
+ }
+}
+
+User-Provided:
+FOO: bar
+URL: https://example.com
+
+Running Environment Variable Groups:
+BLUEMIX_REGION: ibm:yp:us-south
+`)
+
+			variables, err := courier.EnvironmentVariables(appName)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(executor.ExecuteCall.Received.Args).To(Equal([]string{"env", appName}))
+			Expect(variables).To(Equal(map[string]string{"FOO": "bar", "URL": "https://example.com"}))
+		})
+
+		It("returns an error when the app cannot be found", func() {
+			executor.ExecuteCall.Returns.Output = []byte("App " + appName + " not found")
+			executor.ExecuteCall.Returns.Error = fmt.Errorf("exit status 1")
+
+			_, err := courier.EnvironmentVariables(appName)
+			Expect(err).To(MatchError(EnvironmentVariablesError{ApplicationName: appName, Out: []byte("App " + appName + " not found")}))
+		})
+	})
+
+	Describe("listing user provided services", func() {
+		It("returns the apps bound to each user provided service", func() {
+			executor.ExecuteCall.Returns.Output = []byte(`Getting services in org org / space space as user...
+
+name         service         plan    bound apps                last operation
+first-ups    user-provided           ` + appName + `
+shared-ups   user-provided           ` + appName + `, other-app
+unbound-ups  user-provided
+mysql        p-mysql         100mb   ` + appName + `             create succeeded
+`)
+
+			services, err := courier.UserProvidedServices()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(executor.ExecuteCall.Received.Args).To(Equal([]string{"services"}))
+			Expect(services).To(Equal(map[string][]string{
+				"first-ups":   {appName},
+				"shared-ups":  {appName, "other-app"},
+				"unbound-ups": {},
+			}))
+		})
+
+		It("returns an error when the services cannot be listed", func() {
+			executor.ExecuteCall.Returns.Output = []byte("not logged in")
+			executor.ExecuteCall.Returns.Error = fmt.Errorf("exit status 1")
+
+			_, err := courier.UserProvidedServices()
+			Expect(err).To(MatchError(ServicesError{Out: []byte("not logged in")}))
+		})
+	})
+
 	Describe("creating user provided services", func() {
 		It("should get a valid Cloud Foundry Cups command", func() {
 			var (
@@ -418,6 +653,25 @@ var _ = Describe("Courier", func() {
 			Expect(domains[1]).To(Equal("example1.com"))
 			Expect(domains[2]).To(Equal("example2.com"))
 		})
+
+		It("leaves out blank lines", func() {
+			executor.ExecuteCall.Returns.Output = []byte("getting domains in org\nname status\nexample0.com shared\n\n")
+
+			domains, err := courier.Domains()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(domains).To(Equal([]string{"example0.com"}))
+		})
+
+		It("returns the error of a failed command without any domains", func() {
+			executor.ExecuteCall.Returns.Output = []byte("FAILED")
+			executor.ExecuteCall.Returns.Error = errors.New("exit status 1")
+
+			domains, err := courier.Domains()
+
+			Expect(err).To(MatchError("exit status 1"))
+			Expect(domains).To(BeEmpty())
+		})
 	})
 
 	Describe("cleaning up executor directories", func() {
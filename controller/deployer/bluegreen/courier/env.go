@@ -0,0 +1,31 @@
+package courier
+
+import "strings"
+
+// parseEnvironmentVariables reads the User-Provided section of the output of cf env.
+// The system provided variables and the environment variable groups are left out.
+func parseEnvironmentVariables(output string) map[string]string {
+	variables := map[string]string{}
+
+	userProvided := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "User-Provided:" {
+			userProvided = true
+			continue
+		}
+		if !userProvided {
+			continue
+		}
+		if line == "" {
+			break
+		}
+
+		if i := strings.Index(line, ":"); i > 0 {
+			variables[line[:i]] = strings.TrimSpace(line[i+1:])
+		}
+	}
+
+	return variables
+}
@@ -0,0 +1,69 @@
+package courier
+
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
+
+type AppStatusError struct {
+	ApplicationName string
+	Out             []byte
+}
+
+func (e AppStatusError) Error() string {
+	return fmt.Sprintf("cannot get the status of %s: %s", e.ApplicationName, string(e.Out))
+}
+
+func (e AppStatusError) Code() string {
+	return "AppStatusError"
+}
+
+func (e AppStatusError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e AppStatusError) Retryable() bool {
+	return true
+}
+
+type ServicesError struct {
+	Out []byte
+}
+
+func (e ServicesError) Error() string {
+	return fmt.Sprintf("cannot list services: %s", string(e.Out))
+}
+
+func (e ServicesError) Code() string {
+	return "ServicesError"
+}
+
+func (e ServicesError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e ServicesError) Retryable() bool {
+	return true
+}
+
+type EnvironmentVariablesError struct {
+	ApplicationName string
+	Out             []byte
+}
+
+func (e EnvironmentVariablesError) Error() string {
+	return fmt.Sprintf("cannot get the environment variables of %s: %s", e.ApplicationName, string(e.Out))
+}
+
+func (e EnvironmentVariablesError) Code() string {
+	return "EnvironmentVariablesError"
+}
+
+func (e EnvironmentVariablesError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e EnvironmentVariablesError) Retryable() bool {
+	return true
+}
@@ -0,0 +1,29 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
+
+type TimeoutError struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("cf %s did not finish within %s", e.Command, e.Timeout)
+}
+
+func (e TimeoutError) Code() string {
+	return "CommandTimeoutError"
+}
+
+func (e TimeoutError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e TimeoutError) Retryable() bool {
+	return true
+}
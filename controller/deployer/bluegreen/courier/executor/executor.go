@@ -2,9 +2,14 @@
 package executor
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/afero"
 )
@@ -26,6 +31,20 @@ func New(fileSystem *afero.Afero) (Executor, error) {
 type Executor struct {
 	tempDir    string
 	fileSystem *afero.Afero
+	context    context.Context
+	sessions   *Sessions
+}
+
+// WithContext returns a copy of the Executor that kills the commands which are running when the context is cancelled.
+// Commands that start after the context has been cancelled, such as those that undo a cancelled deployment, still run.
+func (e Executor) WithContext(ctx context.Context) Executor {
+	e.context = ctx
+	return e
+}
+
+// Context returns the context of the Executor, or nil when it has none.
+func (e Executor) Context() context.Context {
+	return e.context
 }
 
 // Execute takes a slice of string args and runs them together against the cf command on the Cloud Foundry binary.
@@ -34,7 +53,7 @@ type Executor struct {
 func (e Executor) Execute(args ...string) ([]byte, error) {
 	command := exec.Command("cf", args...)
 	command.Env = setEnv(os.Environ(), "CF_HOME", e.tempDir)
-	return command.CombinedOutput()
+	return e.run(command)
 }
 
 // ExecuteInDirectory does the same thing as Execute does, but does it in a specific directory.
@@ -44,7 +63,7 @@ func (e Executor) ExecuteInDirectory(directory string, args ...string) ([]byte,
 	command := exec.Command("cf", args...)
 	command.Env = setEnv(os.Environ(), "CF_HOME", e.tempDir)
 	command.Dir = directory
-	return command.CombinedOutput()
+	return e.run(command)
 }
 
 // CleanUp removes the temporary directory of the Executor.
@@ -52,6 +71,111 @@ func (e Executor) CleanUp() error {
 	return e.fileSystem.RemoveAll(e.tempDir)
 }
 
+// run runs the command and returns its combined output. The command is killed if the context
+// of the Executor is cancelled while it runs, or if it runs for longer than the context allows.
+func (e Executor) run(command *exec.Cmd) ([]byte, error) {
+	var cancelled <-chan struct{}
+	if e.context != nil && e.context.Err() == nil {
+		cancelled = e.context.Done()
+	}
+
+	var limit time.Duration
+	if len(command.Args) > 1 {
+		limit = Timeout(e.context, command.Args[1])
+	}
+
+	if cancelled == nil && limit == 0 {
+		return command.CombinedOutput()
+	}
+
+	output := &buffer{}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	command.Stdout = writer
+	command.Stderr = writer
+	setProcessGroup(command)
+
+	err = command.Start()
+	writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(output, reader)
+		close(copied)
+	}()
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- command.Wait()
+	}()
+
+	var expired <-chan time.Time
+	if limit > 0 {
+		timer := time.NewTimer(limit)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	var stopReading <-chan time.Time
+	timedOut := false
+
+	for exited != nil || copied != nil {
+		select {
+		case err = <-exited:
+			exited = nil
+		case <-copied:
+			copied = nil
+		case <-cancelled:
+			killProcessGroup(command)
+			cancelled, expired, stopReading = nil, nil, time.After(waitDelay)
+		case <-expired:
+			timedOut = true
+			killProcessGroup(command)
+			cancelled, expired, stopReading = nil, nil, time.After(waitDelay)
+		case <-stopReading:
+			// Children of a killed command that left its process group can keep its output open.
+			reader.Close()
+			copied, stopReading = nil, nil
+		}
+	}
+
+	if timedOut {
+		return output.Bytes(), TimeoutError{Command: command.Args[1], Timeout: limit}
+	}
+
+	return output.Bytes(), err
+}
+
+// waitDelay is how long the output of a killed command is read for.
+const waitDelay = time.Second
+
+// buffer is the output of a command, which can still be written to after a killed command was given up on.
+type buffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *buffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.buffer.Write(p)
+}
+
+func (b *buffer) Bytes() []byte {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return append([]byte(nil), b.buffer.Bytes()...)
+}
+
 func setEnv(env []string, key, value string) []string {
 	keyValuePair := key + "=" + value
 
@@ -0,0 +1,100 @@
+package executor_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/executor"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+// hangingCF is a cf that hangs on push and login, together with a child that keeps its output open.
+const hangingCF = `#!/bin/sh
+echo "running $1"
+case "$1" in
+  push|login) sleep 60 & sleep 60 ;;
+esac
+`
+
+var _ = Describe("Executor", func() {
+	var (
+		binDirectory string
+		path         string
+		ex           Executor
+	)
+
+	BeforeEach(func() {
+		var err error
+		binDirectory, err = ioutil.TempDir("", "executor-bin-")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(binDirectory, "cf"), []byte(hangingCF), 0755)).To(Succeed())
+
+		path = os.Getenv("PATH")
+		os.Setenv("PATH", binDirectory+string(os.PathListSeparator)+path)
+
+		ex, err = New(&afero.Afero{Fs: afero.NewOsFs()})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		ex.CleanUp()
+		os.Setenv("PATH", path)
+		os.RemoveAll(binDirectory)
+	})
+
+	It("returns the output of the command", func() {
+		output, err := ex.Execute("apps")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(Equal("running apps\n"))
+	})
+
+	Describe("timeouts", func() {
+		BeforeEach(func() {
+			ctx := WithTimeouts(context.Background(), map[string]time.Duration{"push": 200 * time.Millisecond, "login": 200 * time.Millisecond})
+			ex = ex.WithContext(ctx)
+		})
+
+		It("kills a hanging cf push at its timeout", func() {
+			started := time.Now()
+			output, err := ex.ExecuteInDirectory(binDirectory, "push", "app")
+
+			Expect(err).To(MatchError(TimeoutError{Command: "push", Timeout: 200 * time.Millisecond}))
+			Expect(string(output)).To(Equal("running push\n"))
+			Expect(time.Since(started)).To(BeNumerically("<", 5*time.Second))
+		})
+
+		It("kills a hanging cf login at its timeout", func() {
+			started := time.Now()
+			_, err := ex.Execute("login", "-a", "api.example.com")
+
+			Expect(err).To(MatchError(TimeoutError{Command: "login", Timeout: 200 * time.Millisecond}))
+			Expect(time.Since(started)).To(BeNumerically("<", 5*time.Second))
+		})
+
+		It("does not limit the commands without a timeout", func() {
+			output, err := ex.Execute("apps")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(output)).To(Equal("running apps\n"))
+		})
+	})
+
+	It("kills the command when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		ex = ex.WithContext(ctx)
+		time.AfterFunc(200*time.Millisecond, cancel)
+
+		started := time.Now()
+		_, err := ex.Execute("push", "app")
+
+		Expect(err).To(HaveOccurred())
+		Expect(err).ToNot(BeAssignableToTypeOf(TimeoutError{}))
+		Expect(time.Since(started)).To(BeNumerically("<", 5*time.Second))
+	})
+})
@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package executor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a process group of its own, so that it can be killed together
+// with the commands it starts.
+func setProcessGroup(command *exec.Cmd) {
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the command and the commands it started.
+func killProcessGroup(command *exec.Cmd) {
+	err := syscall.Kill(-command.Process.Pid, syscall.SIGKILL)
+	if err != nil {
+		command.Process.Kill()
+	}
+}
@@ -0,0 +1,11 @@
+package executor
+
+import "os/exec"
+
+// setProcessGroup does nothing, as processes on Windows have no process groups to kill.
+func setProcessGroup(command *exec.Cmd) {}
+
+// killProcessGroup kills the command.
+func killProcessGroup(command *exec.Cmd) {
+	command.Process.Kill()
+}
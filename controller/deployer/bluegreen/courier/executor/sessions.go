@@ -0,0 +1,252 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session is what a Cloud Foundry login leaves behind, so that later deployments can reuse it
+// instead of authenticating against UAA again.
+type Session struct {
+	// Config is the configuration the Cloud Foundry CLI keeps its tokens and target in.
+	Config []byte
+
+	// TokenType, AccessToken and RefreshToken are the tokens the Cloud Controller courier sends its
+	// requests with. Sessions of the Cloud Foundry CLI take them from its configuration.
+	TokenType    string
+	AccessToken  string
+	RefreshToken string
+	// Expiry is when the access token expires. The zero time means it is unknown.
+	Expiry time.Time
+}
+
+// Expired is true when the access token of the session has expired and there is no refresh token
+// to get a new one with.
+func (s Session) Expired(now time.Time) bool {
+	return s.RefreshToken == "" && !s.Expiry.IsZero() && !now.Before(s.Expiry)
+}
+
+// DefaultSessionIdleTimeout is how long a session is cached after it was last used.
+const DefaultSessionIdleTimeout = 12 * time.Hour
+
+// DefaultMaxSessions is how many sessions are cached at most.
+const DefaultMaxSessions = 1000
+
+// Sessions is a cache of Cloud Foundry sessions that is safe for concurrent use.
+// The sessions are keyed by SessionKey.
+type Sessions struct {
+	// IdleTimeout is how long a session is cached after it was last used. Zero keeps it forever.
+	IdleTimeout time.Duration
+	// MaxSessions is how many sessions are cached at most. The session that was used least recently
+	// is evicted to make room for a new one. Zero caches any number of sessions.
+	MaxSessions int
+
+	mutex    sync.Mutex
+	sessions map[string]*cachedSession
+}
+
+type cachedSession struct {
+	session Session
+	usedAt  time.Time
+}
+
+// NewSessions returns an empty session cache with the DefaultSessionIdleTimeout and DefaultMaxSessions.
+func NewSessions() *Sessions {
+	return &Sessions{
+		IdleTimeout: DefaultSessionIdleTimeout,
+		MaxSessions: DefaultMaxSessions,
+		sessions:    map[string]*cachedSession{},
+	}
+}
+
+// Get returns the cached session. Sessions that have been idle for too long or expired are forgotten.
+func (s *Sessions) Get(key string) (Session, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.evict(now)
+
+	cached, ok := s.sessions[key]
+	if !ok {
+		return Session{}, false
+	}
+
+	if cached.session.Expired(now) {
+		delete(s.sessions, key)
+		return Session{}, false
+	}
+
+	cached.usedAt = now
+	return cached.session, true
+}
+
+// Put caches the session, replacing the one cached under the same key.
+func (s *Sessions) Put(key string, session Session) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.evict(now)
+
+	s.sessions[key] = &cachedSession{session: session, usedAt: now}
+
+	for s.MaxSessions > 0 && len(s.sessions) > s.MaxSessions {
+		var oldest string
+		for k, cached := range s.sessions {
+			if oldest == "" || cached.usedAt.Before(s.sessions[oldest].usedAt) {
+				oldest = k
+			}
+		}
+		delete(s.sessions, oldest)
+	}
+}
+
+// Delete forgets the cached session.
+func (s *Sessions) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.sessions, key)
+}
+
+// Len returns how many sessions are cached.
+func (s *Sessions) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.sessions)
+}
+
+// evict forgets the sessions that have not been used for longer than the IdleTimeout.
+func (s *Sessions) evict(now time.Time) {
+	if s.IdleTimeout <= 0 {
+		return
+	}
+
+	for key, cached := range s.sessions {
+		if now.Sub(cached.usedAt) > s.IdleTimeout {
+			delete(s.sessions, key)
+		}
+	}
+}
+
+// SessionKey returns the key of the session of a user on a foundation. The password is part of
+// the key so that a session is never handed to someone who could not have logged in.
+func SessionKey(foundationURL, username, password string, skipSSL, clientCredentials bool) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%t\x00%t", foundationURL, username, password, skipSSL, clientCredentials)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// WithSessions returns a copy of the Executor that can save the session of its Cloud Foundry CLI
+// to the cache and restore it from there.
+func (e Executor) WithSessions(sessions *Sessions) Executor {
+	e.sessions = sessions
+	return e
+}
+
+// Sessions returns the session cache of the Executor, or nil when it has none.
+func (e Executor) Sessions() *Sessions {
+	return e.sessions
+}
+
+// SaveSession caches the configuration of the Cloud Foundry CLI of the Executor under the key.
+func (e Executor) SaveSession(key string) error {
+	if e.sessions == nil {
+		return nil
+	}
+
+	config, err := e.fileSystem.ReadFile(e.configPath())
+	if err != nil {
+		return err
+	}
+
+	e.sessions.Put(key, cliSession(config))
+	return nil
+}
+
+// RestoreSession writes the cached configuration of the Cloud Foundry CLI into the CF_HOME of the Executor.
+//
+// Returns false when no session is cached under the key.
+func (e Executor) RestoreSession(key string) (bool, error) {
+	if e.sessions == nil {
+		return false, nil
+	}
+
+	session, ok := e.sessions.Get(key)
+	if !ok || len(session.Config) == 0 {
+		return false, nil
+	}
+
+	err := e.fileSystem.MkdirAll(filepath.Dir(e.configPath()), 0700)
+	if err != nil {
+		return false, err
+	}
+
+	err = e.fileSystem.WriteFile(e.configPath(), session.Config, 0600)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ForgetSession removes the session cached under the key.
+func (e Executor) ForgetSession(key string) {
+	if e.sessions != nil {
+		e.sessions.Delete(key)
+	}
+}
+
+// configPath is where the Cloud Foundry CLI keeps its configuration inside CF_HOME.
+func (e Executor) configPath() string {
+	return filepath.Join(e.tempDir, ".cf", "config.json")
+}
+
+// cliSession returns the session kept in the configuration of the Cloud Foundry CLI, with the expiry
+// of its access token when the token is a JWT.
+func cliSession(config []byte) Session {
+	session := Session{Config: config}
+
+	var cli struct {
+		AccessToken  string
+		RefreshToken string
+	}
+	if json.Unmarshal(config, &cli) != nil {
+		return session
+	}
+
+	session.RefreshToken = cli.RefreshToken
+	if fields := strings.SplitN(cli.AccessToken, " ", 2); len(fields) == 2 {
+		session.TokenType, session.AccessToken = fields[0], fields[1]
+	} else {
+		session.AccessToken = cli.AccessToken
+	}
+
+	parts := strings.Split(session.AccessToken, ".")
+	if len(parts) != 3 {
+		return session
+	}
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return session
+	}
+
+	var jwt struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(claims, &jwt) == nil && jwt.Exp > 0 {
+		session.Expiry = time.Unix(jwt.Exp, 0)
+	}
+
+	return session
+}
@@ -0,0 +1,106 @@
+package executor_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	. "github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/executor"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Sessions", func() {
+	var sessions *Sessions
+
+	BeforeEach(func() {
+		sessions = NewSessions()
+	})
+
+	It("returns the session cached under the key", func() {
+		sessions.Put("key", Session{AccessToken: "token"})
+
+		session, ok := sessions.Get("key")
+
+		Expect(ok).To(BeTrue())
+		Expect(session.AccessToken).To(Equal("token"))
+	})
+
+	It("forgets a deleted session", func() {
+		sessions.Put("key", Session{AccessToken: "token"})
+		sessions.Delete("key")
+
+		_, ok := sessions.Get("key")
+
+		Expect(ok).To(BeFalse())
+	})
+
+	It("forgets sessions that have not been used for longer than the idle timeout", func() {
+		sessions.IdleTimeout = time.Millisecond
+		sessions.Put("key", Session{AccessToken: "token"})
+		time.Sleep(5 * time.Millisecond)
+
+		_, ok := sessions.Get("key")
+
+		Expect(ok).To(BeFalse())
+		Expect(sessions.Len()).To(Equal(0))
+	})
+
+	It("evicts the session used least recently when it is full", func() {
+		sessions.MaxSessions = 2
+		sessions.Put("first", Session{})
+		sessions.Put("second", Session{})
+		sessions.Get("first")
+
+		sessions.Put("third", Session{})
+
+		Expect(sessions.Len()).To(Equal(2))
+		_, ok := sessions.Get("second")
+		Expect(ok).To(BeFalse())
+		_, ok = sessions.Get("first")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("forgets sessions with an expired access token and no refresh token", func() {
+		sessions.Put("key", Session{AccessToken: "token", Expiry: time.Now().Add(-time.Second)})
+
+		_, ok := sessions.Get("key")
+
+		Expect(ok).To(BeFalse())
+	})
+
+	It("keeps sessions with an expired access token that can be refreshed", func() {
+		sessions.Put("key", Session{AccessToken: "token", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Second)})
+
+		_, ok := sessions.Get("key")
+
+		Expect(ok).To(BeTrue())
+	})
+
+	Describe("sessions of the Cloud Foundry CLI", func() {
+		It("reads the tokens and the expiry of the access token from the configuration", func() {
+			expiry := time.Now().Add(time.Hour)
+			claims := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, expiry.Unix())))
+			config := fmt.Sprintf(`{"AccessToken":"bearer header.%s.signature","RefreshToken":"refresh"}`, claims)
+			sessions.Put("restored", Session{Config: []byte(config)})
+
+			ex, err := New(&afero.Afero{Fs: afero.NewMemMapFs()})
+			Expect(err).ToNot(HaveOccurred())
+			ex = ex.WithSessions(sessions)
+
+			restored, err := ex.RestoreSession("restored")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restored).To(BeTrue())
+			Expect(ex.SaveSession("saved")).To(Succeed())
+
+			session, ok := sessions.Get("saved")
+			Expect(ok).To(BeTrue())
+			Expect(string(session.Config)).To(Equal(config))
+			Expect(session.TokenType).To(Equal("bearer"))
+			Expect(session.AccessToken).To(Equal("header." + claims + ".signature"))
+			Expect(session.RefreshToken).To(Equal("refresh"))
+			Expect(session.Expiry.Unix()).To(Equal(expiry.Unix()))
+		})
+	})
+})
@@ -0,0 +1,24 @@
+package executor
+
+import (
+	"context"
+	"time"
+)
+
+type timeoutsKey struct{}
+
+// WithTimeouts returns a copy of the context that limits how long the cf commands of an Executor
+// using it may run. The timeouts are keyed by the cf command, such as "login" or "push".
+func WithTimeouts(ctx context.Context, timeouts map[string]time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutsKey{}, timeouts)
+}
+
+// Timeout returns how long the cf command may run, or zero when it may run for as long as it takes.
+func Timeout(ctx context.Context, command string) time.Duration {
+	if ctx == nil {
+		return 0
+	}
+
+	timeouts, _ := ctx.Value(timeoutsKey{}).(map[string]time.Duration)
+	return timeouts[command]
+}
@@ -0,0 +1,28 @@
+package courier
+
+import "strings"
+
+// parseUserProvidedServices reads the output of cf services and returns the applications bound
+// to each user provided service. Other columns that can follow the bound apps are left out.
+func parseUserProvidedServices(output string) map[string][]string {
+	services := map[string][]string{}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[1] != "user-provided" {
+			continue
+		}
+
+		boundApps := []string{}
+		rest := strings.TrimSpace(line[strings.Index(line, "user-provided")+len("user-provided"):])
+		for _, app := range strings.Split(rest, ",") {
+			if app := strings.Fields(app); len(app) > 0 {
+				boundApps = append(boundApps, app[0])
+			}
+		}
+
+		services[fields[0]] = boundApps
+	}
+
+	return services
+}
@@ -3,6 +3,9 @@ package bluegreen
 import (
 	"errors"
 	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+	I "github.com/compozed/deployadactyl/interfaces"
 )
 
 type LoginError struct {
@@ -18,6 +21,14 @@ func (e LoginError) Code() string {
 	return "LoginError"
 }
 
+func (e LoginError) Category() string {
+	return categoryOf(e.LoginErrors, C.ErrorCategoryUser)
+}
+
+func (e LoginError) Retryable() bool {
+	return allRetryable(e.LoginErrors)
+}
+
 type PushError struct {
 	PushErrors []error
 }
@@ -31,6 +42,14 @@ func (e PushError) Code() string {
 	return "PushError"
 }
 
+func (e PushError) Category() string {
+	return categoryOf(e.PushErrors, C.ErrorCategoryFoundation)
+}
+
+func (e PushError) Retryable() bool {
+	return allRetryable(e.PushErrors)
+}
+
 type RollbackError struct {
 	PushErrors     []error
 	RollbackErrors []error
@@ -59,10 +78,30 @@ func (e RollbackStopError) Error() string {
 	return fmt.Sprintf("stop failed: %s: rollback failed: %s", stopErrs, rollbackStopErrors)
 }
 
+func (e RollbackStopError) Code() string {
+	return "RollbackStopError"
+}
+
+func (e RollbackStopError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e RollbackStopError) Retryable() bool {
+	return false
+}
+
 func (e RollbackError) Code() string {
 	return "RollbackError"
 }
 
+func (e RollbackError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e RollbackError) Retryable() bool {
+	return false
+}
+
 type FinishPushError struct {
 	FinishPushError []error
 }
@@ -79,6 +118,14 @@ func (e FinishPushError) Code() string {
 	return "FinishPushError"
 }
 
+func (e FinishPushError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e FinishPushError) Retryable() bool {
+	return false
+}
+
 type StartStopError struct {
 	Err error
 }
@@ -87,6 +134,18 @@ func (e StartStopError) Error() string {
 	return e.Err.Error()
 }
 
+func (e StartStopError) Code() string {
+	return "StartStopError"
+}
+
+func (e StartStopError) Category() string {
+	return categoryOf([]error{e.Err}, C.ErrorCategoryInternal)
+}
+
+func (e StartStopError) Retryable() bool {
+	return allRetryable([]error{e.Err})
+}
+
 type InitializationError struct {
 	Err error
 }
@@ -99,6 +158,14 @@ func (e InitializationError) Code() string {
 	return "InitError"
 }
 
+func (e InitializationError) Category() string {
+	return categoryOf([]error{e.Err}, C.ErrorCategoryInternal)
+}
+
+func (e InitializationError) Retryable() bool {
+	return allRetryable([]error{e.Err})
+}
+
 type FinishStopError struct {
 	FinishStopErrors []error
 }
@@ -109,6 +176,18 @@ func (e FinishStopError) Error() string {
 	return fmt.Sprintf("finish stop failed: %s", finishStopErrors)
 }
 
+func (e FinishStopError) Code() string {
+	return "FinishStopError"
+}
+
+func (e FinishStopError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e FinishStopError) Retryable() bool {
+	return false
+}
+
 type StopError struct {
 	Errors []error
 }
@@ -122,6 +201,14 @@ func (e StopError) Code() string {
 	return "StopError"
 }
 
+func (e StopError) Category() string {
+	return categoryOf(e.Errors, C.ErrorCategoryFoundation)
+}
+
+func (e StopError) Retryable() bool {
+	return allRetryable(e.Errors)
+}
+
 type FinishDeployError struct {
 	Err error
 }
@@ -134,6 +221,14 @@ func (e FinishDeployError) Code() string {
 	return "FinishDeployError"
 }
 
+func (e FinishDeployError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e FinishDeployError) Retryable() bool {
+	return false
+}
+
 func makeErrorString(manyErrors []error) error {
 	var result string
 	for i, e := range manyErrors {
@@ -159,6 +254,18 @@ func (e FinishStartError) Error() string {
 	return fmt.Sprintf("finish stop failed: %s", finishStartErrors)
 }
 
+func (e FinishStartError) Code() string {
+	return "FinishStartError"
+}
+
+func (e FinishStartError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e FinishStartError) Retryable() bool {
+	return false
+}
+
 type StartError struct {
 	Errors []error
 }
@@ -172,6 +279,14 @@ func (e StartError) Code() string {
 	return "StartError"
 }
 
+func (e StartError) Category() string {
+	return categoryOf(e.Errors, C.ErrorCategoryFoundation)
+}
+
+func (e StartError) Retryable() bool {
+	return allRetryable(e.Errors)
+}
+
 type RollbackStartError struct {
 	StartErrors    []error
 	RollbackErrors []error
@@ -185,3 +300,467 @@ func (e RollbackStartError) Error() string {
 
 	return fmt.Sprintf("start failed: %s: rollback failed: %s", startErrs, rollbackStartErrors)
 }
+
+func (e RollbackStartError) Code() string {
+	return "RollbackStartError"
+}
+
+func (e RollbackStartError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e RollbackStartError) Retryable() bool {
+	return false
+}
+
+type FinishRevertError struct {
+	FinishRevertErrors []error
+}
+
+func (e FinishRevertError) Error() string {
+	finishRevertErrors := makeErrorString(e.FinishRevertErrors)
+
+	return fmt.Sprintf("finish revert failed: %s", finishRevertErrors)
+}
+
+func (e FinishRevertError) Code() string {
+	return "FinishRevertError"
+}
+
+func (e FinishRevertError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e FinishRevertError) Retryable() bool {
+	return false
+}
+
+type RevertError struct {
+	Errors []error
+}
+
+func (e RevertError) Error() string {
+	errs := makeErrorString(e.Errors)
+	return fmt.Sprintf("revert failed: %s", errs)
+}
+
+func (e RevertError) Code() string {
+	return "RevertError"
+}
+
+func (e RevertError) Category() string {
+	return categoryOf(e.Errors, C.ErrorCategoryFoundation)
+}
+
+func (e RevertError) Retryable() bool {
+	return allRetryable(e.Errors)
+}
+
+type RollbackRevertError struct {
+	RevertErrors   []error
+	RollbackErrors []error
+}
+
+func (e RollbackRevertError) Error() string {
+	var (
+		revertErrs   = makeErrorString(e.RevertErrors)
+		rollbackErrs = makeErrorString(e.RollbackErrors)
+	)
+
+	return fmt.Sprintf("revert failed: %s: rollback failed: %s", revertErrs, rollbackErrs)
+}
+
+func (e RollbackRevertError) Code() string {
+	return "RollbackRevertError"
+}
+
+func (e RollbackRevertError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e RollbackRevertError) Retryable() bool {
+	return false
+}
+
+type FinishRestartError struct {
+	FinishRestartErrors []error
+}
+
+func (e FinishRestartError) Error() string {
+	finishRestartErrors := makeErrorString(e.FinishRestartErrors)
+
+	return fmt.Sprintf("finish restart failed: %s", finishRestartErrors)
+}
+
+func (e FinishRestartError) Code() string {
+	return "FinishRestartError"
+}
+
+func (e FinishRestartError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e FinishRestartError) Retryable() bool {
+	return false
+}
+
+type RestartError struct {
+	Errors []error
+}
+
+func (e RestartError) Error() string {
+	errs := makeErrorString(e.Errors)
+	return fmt.Sprintf("restart failed: %s", errs)
+}
+
+func (e RestartError) Code() string {
+	return "RestartError"
+}
+
+func (e RestartError) Category() string {
+	return categoryOf(e.Errors, C.ErrorCategoryFoundation)
+}
+
+func (e RestartError) Retryable() bool {
+	return allRetryable(e.Errors)
+}
+
+type RollbackRestartError struct {
+	RestartErrors  []error
+	RollbackErrors []error
+}
+
+func (e RollbackRestartError) Error() string {
+	var (
+		restartErrs  = makeErrorString(e.RestartErrors)
+		rollbackErrs = makeErrorString(e.RollbackErrors)
+	)
+
+	return fmt.Sprintf("restart failed: %s: rollback failed: %s", restartErrs, rollbackErrs)
+}
+
+func (e RollbackRestartError) Code() string {
+	return "RollbackRestartError"
+}
+
+func (e RollbackRestartError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e RollbackRestartError) Retryable() bool {
+	return false
+}
+
+type FinishRestageError struct {
+	FinishRestageErrors []error
+}
+
+func (e FinishRestageError) Error() string {
+	finishRestageErrors := makeErrorString(e.FinishRestageErrors)
+
+	return fmt.Sprintf("finish restage failed: %s", finishRestageErrors)
+}
+
+func (e FinishRestageError) Code() string {
+	return "FinishRestageError"
+}
+
+func (e FinishRestageError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e FinishRestageError) Retryable() bool {
+	return false
+}
+
+type RestageError struct {
+	Errors []error
+}
+
+func (e RestageError) Error() string {
+	errs := makeErrorString(e.Errors)
+	return fmt.Sprintf("restage failed: %s", errs)
+}
+
+func (e RestageError) Code() string {
+	return "RestageError"
+}
+
+func (e RestageError) Category() string {
+	return categoryOf(e.Errors, C.ErrorCategoryFoundation)
+}
+
+func (e RestageError) Retryable() bool {
+	return allRetryable(e.Errors)
+}
+
+type RollbackRestageError struct {
+	RestageErrors  []error
+	RollbackErrors []error
+}
+
+func (e RollbackRestageError) Error() string {
+	var (
+		restageErrs  = makeErrorString(e.RestageErrors)
+		rollbackErrs = makeErrorString(e.RollbackErrors)
+	)
+
+	return fmt.Sprintf("restage failed: %s: rollback failed: %s", restageErrs, rollbackErrs)
+}
+
+func (e RollbackRestageError) Code() string {
+	return "RollbackRestageError"
+}
+
+func (e RollbackRestageError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e RollbackRestageError) Retryable() bool {
+	return false
+}
+
+type FinishScaleError struct {
+	FinishScaleErrors []error
+}
+
+func (e FinishScaleError) Error() string {
+	finishScaleErrors := makeErrorString(e.FinishScaleErrors)
+
+	return fmt.Sprintf("finish scale failed: %s", finishScaleErrors)
+}
+
+func (e FinishScaleError) Code() string {
+	return "FinishScaleError"
+}
+
+func (e FinishScaleError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e FinishScaleError) Retryable() bool {
+	return false
+}
+
+type ScaleError struct {
+	Errors []error
+}
+
+func (e ScaleError) Error() string {
+	errs := makeErrorString(e.Errors)
+	return fmt.Sprintf("scale failed: %s", errs)
+}
+
+func (e ScaleError) Code() string {
+	return "ScaleError"
+}
+
+func (e ScaleError) Category() string {
+	return categoryOf(e.Errors, C.ErrorCategoryFoundation)
+}
+
+func (e ScaleError) Retryable() bool {
+	return allRetryable(e.Errors)
+}
+
+type RollbackScaleError struct {
+	ScaleErrors    []error
+	RollbackErrors []error
+}
+
+func (e RollbackScaleError) Error() string {
+	var (
+		scaleErrs    = makeErrorString(e.ScaleErrors)
+		rollbackErrs = makeErrorString(e.RollbackErrors)
+	)
+
+	return fmt.Sprintf("scale failed: %s: rollback failed: %s", scaleErrs, rollbackErrs)
+}
+
+func (e RollbackScaleError) Code() string {
+	return "RollbackScaleError"
+}
+
+func (e RollbackScaleError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e RollbackScaleError) Retryable() bool {
+	return false
+}
+
+type FinishDeleteError struct {
+	FinishDeleteErrors []error
+}
+
+func (e FinishDeleteError) Error() string {
+	finishDeleteErrors := makeErrorString(e.FinishDeleteErrors)
+
+	return fmt.Sprintf("finish delete failed: %s", finishDeleteErrors)
+}
+
+func (e FinishDeleteError) Code() string {
+	return "FinishDeleteError"
+}
+
+func (e FinishDeleteError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e FinishDeleteError) Retryable() bool {
+	return false
+}
+
+type DeleteError struct {
+	Errors []error
+}
+
+func (e DeleteError) Error() string {
+	errs := makeErrorString(e.Errors)
+	return fmt.Sprintf("delete failed: %s", errs)
+}
+
+func (e DeleteError) Code() string {
+	return "DeleteError"
+}
+
+func (e DeleteError) Category() string {
+	return categoryOf(e.Errors, C.ErrorCategoryFoundation)
+}
+
+func (e DeleteError) Retryable() bool {
+	return allRetryable(e.Errors)
+}
+
+type RollbackDeleteError struct {
+	DeleteErrors   []error
+	RollbackErrors []error
+}
+
+func (e RollbackDeleteError) Error() string {
+	var (
+		deleteErrs   = makeErrorString(e.DeleteErrors)
+		rollbackErrs = makeErrorString(e.RollbackErrors)
+	)
+
+	return fmt.Sprintf("delete failed: %s: rollback failed: %s", deleteErrs, rollbackErrs)
+}
+
+func (e RollbackDeleteError) Code() string {
+	return "RollbackDeleteError"
+}
+
+func (e RollbackDeleteError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e RollbackDeleteError) Retryable() bool {
+	return false
+}
+
+type StatusError struct {
+	Errors []error
+}
+
+func (e StatusError) Error() string {
+	errs := makeErrorString(e.Errors)
+	return fmt.Sprintf("status failed: %s", errs)
+}
+
+func (e StatusError) Code() string {
+	return "StatusError"
+}
+
+func (e StatusError) Category() string {
+	return categoryOf(e.Errors, C.ErrorCategoryFoundation)
+}
+
+func (e StatusError) Retryable() bool {
+	return allRetryable(e.Errors)
+}
+
+type CancelledError struct {
+	UndoErrors []error
+}
+
+func (e CancelledError) Error() string {
+	if len(e.UndoErrors) != 0 {
+		return fmt.Sprintf("deployment was cancelled: undo failed: %s", makeErrorString(e.UndoErrors))
+	}
+	return "deployment was cancelled"
+}
+
+func (e CancelledError) Code() string {
+	return "DeploymentCancelledError"
+}
+
+func (e CancelledError) Category() string {
+	if len(e.UndoErrors) != 0 {
+		return C.ErrorCategoryFoundation
+	}
+	return C.ErrorCategoryUser
+}
+
+func (e CancelledError) Retryable() bool {
+	return len(e.UndoErrors) == 0
+}
+
+type DeploymentTimeoutError struct {
+	UndoErrors []error
+}
+
+func (e DeploymentTimeoutError) Error() string {
+	if len(e.UndoErrors) != 0 {
+		return fmt.Sprintf("deployment timed out: undo failed: %s", makeErrorString(e.UndoErrors))
+	}
+	return "deployment timed out"
+}
+
+func (e DeploymentTimeoutError) Code() string {
+	return "DeploymentTimeoutError"
+}
+
+func (e DeploymentTimeoutError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e DeploymentTimeoutError) Retryable() bool {
+	return len(e.UndoErrors) == 0
+}
+
+// categoryOf returns the category shared by the given errors. Errors that do
+// not carry a category, or that disagree with each other, fall back to the
+// supplied category.
+func categoryOf(errs []error, fallback string) string {
+	category := ""
+	for _, err := range errs {
+		deploymentError, ok := err.(I.DeploymentError)
+		if !ok {
+			return fallback
+		}
+		if category != "" && category != deploymentError.Category() {
+			return fallback
+		}
+		category = deploymentError.Category()
+	}
+
+	if category == "" {
+		return fallback
+	}
+	return category
+}
+
+// allRetryable reports whether every one of the given errors is retryable.
+func allRetryable(errs []error) bool {
+	if len(errs) == 0 {
+		return false
+	}
+
+	for _, err := range errs {
+		deploymentError, ok := err.(I.DeploymentError)
+		if !ok || !deploymentError.Retryable() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package bluegreen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+var (
+	// OutputHeader precedes the Cloud Foundry output of each foundation in the response.
+	OutputHeader = fmt.Sprintf("%s Cloud Foundry Output %s", strings.Repeat("-", 19), strings.Repeat("-", 19))
+
+	// OutputFooter follows the Cloud Foundry output of the last foundation in the response.
+	OutputFooter = fmt.Sprintf("%s End Cloud Foundry Output %s", strings.Repeat("-", 17), strings.Repeat("-", 17))
+)
+
+// foundationOutput buffers the output of a single foundation and publishes every complete line to the Tracker as soon as it is written.
+type foundationOutput struct {
+	*bytes.Buffer
+	uuid          string
+	foundationURL string
+	tracker       I.Tracker
+	partial       []byte
+}
+
+func (o *foundationOutput) Write(p []byte) (int, error) {
+	n, err := o.Buffer.Write(p)
+
+	o.partial = append(o.partial, p[:n]...)
+	for {
+		i := bytes.IndexByte(o.partial, '\n')
+		if i < 0 {
+			break
+		}
+
+		o.publish(o.partial[:i])
+		o.partial = o.partial[i+1:]
+	}
+
+	return n, err
+}
+
+// Flush publishes what is left of an unterminated last line.
+func (o *foundationOutput) Flush() {
+	if len(o.partial) > 0 {
+		o.publish(o.partial)
+		o.partial = nil
+	}
+}
+
+func (o *foundationOutput) publish(line []byte) {
+	o.tracker.Publish(o.uuid, I.OutputLine{
+		FoundationURL: o.foundationURL,
+		Line:          strings.TrimSuffix(string(line), "\r"),
+	})
+}
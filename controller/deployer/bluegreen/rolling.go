@@ -0,0 +1,33 @@
+package bluegreen
+
+import (
+	"context"
+	I "github.com/compozed/deployadactyl/interfaces"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+// rollOut executes and verifies the action on one batch of foundations at a time, in the order of the environment.
+// It stops at the first batch that fails and only undoes the foundations that have been touched so far.
+func (bg BlueGreen) rollOut(ctx context.Context, actionCreator I.ActionCreator, environment S.Environment, actors []actor) error {
+	batchSize := int(environment.BatchSize)
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	for start := 0; start < len(actors); start += batchSize {
+		end := start + batchSize
+		if end > len(actors) {
+			end = len(actors)
+		}
+
+		bg.Log.Infof("rolling out to foundations %d to %d of %d", start+1, end, len(actors))
+
+		err := bg.executeAndVerify(ctx, actionCreator, actors[start:end], actors[:end])
+		if err != nil {
+			bg.Log.Errorf("rolling out stopped at foundations %d to %d of %d", start+1, end, len(actors))
+			return err
+		}
+	}
+
+	return nil
+}
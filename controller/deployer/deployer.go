@@ -2,6 +2,7 @@
 package deployer
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,9 +10,13 @@ import (
 	"crypto/tls"
 	"log"
 	"os"
+	"time"
 
 	"encoding/base64"
 	"github.com/compozed/deployadactyl/config"
+	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/executor"
 	I "github.com/compozed/deployadactyl/interfaces"
 	S "github.com/compozed/deployadactyl/structs"
 )
@@ -36,7 +41,7 @@ AppName:      %s`
 type SilentDeployer struct {
 }
 
-func (d SilentDeployer) Deploy(deploymentInfo *S.DeploymentInfo, env S.Environment, actionCreator I.ActionCreator, response io.ReadWriter) *I.DeployResponse {
+func (d SilentDeployer) Deploy(ctx context.Context, deploymentInfo *S.DeploymentInfo, env S.Environment, actionCreator I.ActionCreator, response io.ReadWriter) *I.DeployResponse {
 	url := os.Getenv("SILENT_DEPLOY_URL")
 	deployResponse := &I.DeployResponse{}
 
@@ -75,20 +80,49 @@ type Deployer struct {
 	EventManager I.EventManager
 	Randomizer   I.Randomizer
 	ErrorFinder  I.ErrorFinder
+	Secrets      I.SecretResolver
 	Log          I.DeploymentLogger
 }
 
-func (d Deployer) Deploy(deploymentInfo *S.DeploymentInfo, env S.Environment, actionCreator I.ActionCreator, response io.ReadWriter) *I.DeployResponse {
+func (d Deployer) Deploy(ctx context.Context, deploymentInfo *S.DeploymentInfo, env S.Environment, actionCreator I.ActionCreator, response io.ReadWriter) *I.DeployResponse {
 
 	deployResponse := &I.DeployResponse{
 		DeploymentInfo: deploymentInfo,
 	}
 
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := withTimeouts(ctx, env.Timeouts)
+	defer cancel()
+
+	// Secrets are looked up on every deployment so that rotating them does not need a restart.
+	if deploymentInfo.ServiceAccount && d.Secrets != nil {
+		// An environment without credentials of its own logs in with CF_USERNAME and CF_PASSWORD,
+		// which may reference secrets as well.
+		if env.Credentials == (S.Credentials{}) && deploymentInfo.Username+deploymentInfo.Password != "" {
+			if deploymentInfo.ClientCredentials {
+				env.Credentials = S.Credentials{ClientID: deploymentInfo.Username, ClientSecret: deploymentInfo.Password}
+			} else {
+				env.Credentials = S.Credentials{Username: deploymentInfo.Username, Password: deploymentInfo.Password}
+			}
+		}
+
+		resolved, err := d.Secrets.ResolveCredentials(env)
+		if err != nil {
+			d.Log.Errorf("cannot resolve the secrets of the %s environment: %s", env.Name, err)
+			deployResponse.StatusCode = StatusCode(err)
+			deployResponse.Error = err
+			return deployResponse
+		}
+		env = resolved
+	}
+
 	d.Log.Debug("prechecking the foundations")
 	err := d.Prechecker.AssertAllFoundationsUp(env)
 	if err != nil {
 		d.Log.Error(err)
-		deployResponse.StatusCode = http.StatusInternalServerError
+		deployResponse.StatusCode = StatusCode(err)
 		deployResponse.Error = err
 		return deployResponse
 	}
@@ -96,21 +130,127 @@ func (d Deployer) Deploy(deploymentInfo *S.DeploymentInfo, env S.Environment, ac
 	defer func() { actionCreator.CleanUp() }()
 	err = actionCreator.SetUp()
 	if err != nil {
-		deployResponse.StatusCode = http.StatusInternalServerError
+		deployResponse.StatusCode = StatusCode(err)
 		deployResponse.Error = err
 		return deployResponse
 	}
 
+	if deploymentInfo.DryRun {
+		return d.plan(ctx, deploymentInfo, env, actionCreator, response)
+	}
+
 	err = actionCreator.OnStart()
 	if err != nil {
-		deployResponse.StatusCode = http.StatusInternalServerError
+		deployResponse.StatusCode = StatusCode(err)
 		deployResponse.Error = err
 		return deployResponse
 	}
 
-	err = d.BlueGreener.Execute(actionCreator, env, response)
+	if ctx.Err() == context.DeadlineExceeded {
+		err = bluegreen.DeploymentTimeoutError{}
+	} else if ctx.Err() != nil {
+		err = bluegreen.CancelledError{}
+	} else {
+		err = d.BlueGreener.Execute(ctx, actionCreator, env, response)
+	}
+	if _, ok := err.(bluegreen.CancelledError); ok {
+		d.emitDeployCancelled(deploymentInfo, env, response, err)
+	}
 
 	resp := actionCreator.OnFinish(env, response, err)
 	resp.DeploymentInfo = deploymentInfo
 	return &resp
 }
+
+// plan logs into every foundation and asks its action what it would do there, without doing it.
+func (d Deployer) plan(ctx context.Context, deploymentInfo *S.DeploymentInfo, env S.Environment, actionCreator I.ActionCreator, response io.ReadWriter) *I.DeployResponse {
+	deployResponse := &I.DeployResponse{
+		DeploymentInfo: deploymentInfo,
+	}
+
+	var loginErrors, planErrors []error
+	for _, foundationURL := range env.Foundations {
+		d.Log.Debugf("planning the deployment on %s", foundationURL)
+
+		action, err := actionCreator.Create(ctx, env, response, foundationURL)
+		if err != nil {
+			err = bluegreen.InitializationError{Err: err}
+			deployResponse.StatusCode = StatusCode(err)
+			deployResponse.Error = err
+			return deployResponse
+		}
+
+		planner, ok := action.(I.Planner)
+		if !ok {
+			action.Finally()
+			err = DryRunNotSupportedError{Action: fmt.Sprintf("%T", action)}
+			deployResponse.StatusCode = StatusCode(err)
+			deployResponse.Error = err
+			return deployResponse
+		}
+
+		err = action.Initially()
+		if err != nil {
+			action.Finally()
+			loginErrors = append(loginErrors, err)
+			continue
+		}
+
+		plan, err := planner.Plan()
+		action.Finally()
+		if err != nil {
+			planErrors = append(planErrors, err)
+			continue
+		}
+
+		plan.FoundationURL = foundationURL
+		deployResponse.Plan = append(deployResponse.Plan, plan)
+	}
+
+	var err error
+	if len(loginErrors) != 0 {
+		err = actionCreator.InitiallyError(loginErrors)
+	} else if len(planErrors) != 0 {
+		err = actionCreator.ExecuteError(planErrors)
+	}
+
+	deployResponse.StatusCode = StatusCode(err)
+	deployResponse.Error = err
+	return deployResponse
+}
+
+// withTimeouts returns a copy of the context that limits how long the deployment and its cf commands may run.
+func withTimeouts(ctx context.Context, timeouts S.Timeouts) (context.Context, context.CancelFunc) {
+	ctx = executor.WithTimeouts(ctx, map[string]time.Duration{
+		"login":  seconds(timeouts.Login),
+		"api":    seconds(timeouts.Login),
+		"auth":   seconds(timeouts.Login),
+		"target": seconds(timeouts.Login),
+		"push":   seconds(timeouts.Push),
+	})
+
+	if timeouts.Deployment > 0 {
+		return context.WithTimeout(ctx, seconds(timeouts.Deployment))
+	}
+	return context.WithCancel(ctx)
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+func (d Deployer) emitDeployCancelled(deploymentInfo *S.DeploymentInfo, env S.Environment, response io.ReadWriter, err error) {
+	d.Log.Debugf("emitting a %s event", C.DeployCancelledEvent)
+
+	eventErr := d.EventManager.EmitEvent(DeployCancelledEvent{
+		DeploymentInfo: deploymentInfo,
+		Environment:    env,
+		Response:       response,
+		Error:          err,
+		Log:            d.Log,
+	})
+	if eventErr != nil {
+		d.Log.Errorf("an error occurred when emitting a %s event: %s", C.DeployCancelledEvent, eventErr)
+		fmt.Fprintln(response, eventErr)
+	}
+}
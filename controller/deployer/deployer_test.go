@@ -2,11 +2,13 @@ package deployer_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"math/rand"
 	"net/http"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -16,6 +18,7 @@ import (
 
 	"github.com/compozed/deployadactyl/config"
 	. "github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
 	"github.com/compozed/deployadactyl/interfaces"
 	"github.com/compozed/deployadactyl/mocks"
 	"github.com/compozed/deployadactyl/randomizer"
@@ -183,6 +186,7 @@ var _ = Describe("Deployer", func() {
 			eventManager,
 			randomizerMock,
 			nil,
+			nil,
 			log,
 		}
 	})
@@ -192,7 +196,7 @@ var _ = Describe("Deployer", func() {
 			It("rejects the request with a http.StatusInternalServerError", func() {
 				prechecker.AssertAllFoundationsUpCall.Returns.Error = errors.New("prechecker failed")
 
-				deployResponse := deployer.Deploy(&deploymentInfo, deployer.Config.Environments[environment], pusherCreator, response)
+				deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, deployer.Config.Environments[environment], pusherCreator, response)
 				Expect(deployResponse.Error).To(MatchError("prechecker failed"))
 
 				Expect(deployResponse.StatusCode).To(Equal(http.StatusInternalServerError))
@@ -201,6 +205,58 @@ var _ = Describe("Deployer", func() {
 		})
 	})
 
+	Describe("resolving secrets", func() {
+		var secretResolver *mocks.SecretResolver
+
+		BeforeEach(func() {
+			secretResolver = &mocks.SecretResolver{}
+			deployer.Secrets = secretResolver
+			deploymentInfo.ServiceAccount = true
+		})
+
+		It("deploys with the credentials the secrets resolve to", func() {
+			env := S.Environment{Name: environment, Credentials: S.Credentials{Username: "deployadactyl", Password: "secret://env/PASSWORD"}}
+			resolved := S.Environment{Name: environment, Credentials: S.Credentials{Username: "deployadactyl", Password: "password"}}
+			secretResolver.ResolveCredentialsCall.Returns.Environment = resolved
+
+			deployer.Deploy(context.Background(), &deploymentInfo, env, pusherCreator, response)
+
+			Expect(secretResolver.ResolveCredentialsCall.Received.Environment).To(Equal(env))
+			Expect(prechecker.AssertAllFoundationsUpCall.Received.Environment).To(Equal(resolved))
+		})
+
+		It("resolves the secrets of CF_USERNAME and CF_PASSWORD for environments without credentials of their own", func() {
+			deploymentInfo.Username = "deployadactyl"
+			deploymentInfo.Password = "secret://env/CF_PASSWORD"
+			resolved := S.Environment{Name: environment, Credentials: S.Credentials{Username: "deployadactyl", Password: "password"}}
+			secretResolver.ResolveCredentialsCall.Returns.Environment = resolved
+
+			deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{Name: environment}, pusherCreator, response)
+
+			Expect(secretResolver.ResolveCredentialsCall.Received.Environment.Credentials).To(Equal(S.Credentials{Username: "deployadactyl", Password: "secret://env/CF_PASSWORD"}))
+			Expect(prechecker.AssertAllFoundationsUpCall.Received.Environment).To(Equal(resolved))
+			Expect(deploymentInfo.Password).To(Equal("secret://env/CF_PASSWORD"))
+		})
+
+		It("does not resolve secrets when the request has credentials of its own", func() {
+			deploymentInfo.ServiceAccount = false
+
+			deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreator, response)
+
+			Expect(secretResolver.ResolveCredentialsCall.Called).To(BeFalse())
+		})
+
+		It("fails the deployment when a secret cannot be resolved", func() {
+			secretResolver.ResolveCredentialsCall.Returns.Error = errors.New("secret not found")
+
+			deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreator, response)
+
+			Expect(deployResponse.Error).To(MatchError("secret not found"))
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(pusherCreator.SetUpCall.Called).To(BeFalse())
+		})
+	})
+
 	Describe("authentication", func() {
 		Context("a username and password are not provided", func() {
 			Context("when authenticate in the config is not true", func() {
@@ -214,7 +270,7 @@ var _ = Describe("Deployer", func() {
 
 					By("not setting basic auth")
 
-					deployResponse := deployer.Deploy(&deploymentInfo, env, pusherCreator, response)
+					deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, env, pusherCreator, response)
 
 					Expect(deployResponse.Error).ToNot(HaveOccurred())
 					Expect(deployResponse.StatusCode).To(Equal(http.StatusOK))
@@ -243,7 +299,7 @@ var _ = Describe("Deployer", func() {
 						StatusCode: http.StatusOK,
 					}
 
-					deployResponse := deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreator, response)
+					deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreator, response)
 
 					Expect(deployResponse.Error).ToNot(HaveOccurred())
 
@@ -267,7 +323,7 @@ var _ = Describe("Deployer", func() {
 						StatusCode: http.StatusOK,
 					}
 
-					deployResponse := deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreator, response)
+					deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreator, response)
 
 					Expect(deployResponse.Error).ToNot(HaveOccurred())
 
@@ -287,7 +343,7 @@ var _ = Describe("Deployer", func() {
 						base64Manifest,
 					))
 
-					deployResponse := deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreator, response)
+					deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreator, response)
 
 					Expect(deployResponse.StatusCode).To(Equal(http.StatusInternalServerError))
 
@@ -310,7 +366,7 @@ var _ = Describe("Deployer", func() {
 					base64Manifest,
 				))
 
-				deployResponse := deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreator, response)
+				deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreator, response)
 
 				Expect(deployResponse.Error).ToNot(HaveOccurred())
 
@@ -336,7 +392,7 @@ var _ = Describe("Deployer", func() {
 				))
 
 				uuid = ""
-				deployResponse := deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreator, response)
+				deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreator, response)
 
 				Expect(deployResponse.Error).ToNot(HaveOccurred())
 
@@ -353,7 +409,7 @@ var _ = Describe("Deployer", func() {
 				It("returns an error and http.StatusInternalServerError", func() {
 					pusherCreator.SetUpCall.Returns.Err = errors.New("a test error")
 
-					deployResponse := deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreator, response)
+					deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreator, response)
 
 					Expect(deployResponse.Error.Error()).To(ContainSubstring("a test error"))
 
@@ -375,7 +431,7 @@ var _ = Describe("Deployer", func() {
 				eventManager.EmitCall.Returns.Error = append(eventManager.EmitCall.Returns.Error, nil)
 				eventManager.EmitCall.Returns.Error = append(eventManager.EmitCall.Returns.Error, nil)
 
-				deployResponse := deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreator, response)
+				deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreator, response)
 
 				Expect(deployResponse.DeploymentInfo.UUID).ToNot(Equal(""))
 				manifest := deployResponse.DeploymentInfo.Manifest
@@ -392,7 +448,7 @@ var _ = Describe("Deployer", func() {
 					StatusCode: http.StatusOK,
 				}
 
-				deployResponse := deployer.Deploy(&deploymentInfo, environments[environment], pusherCreator, response)
+				deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, environments[environment], pusherCreator, response)
 
 				Expect(deployResponse.Error).To(BeNil())
 
@@ -414,7 +470,7 @@ var _ = Describe("Deployer", func() {
 					StatusCode: http.StatusOK,
 				}
 
-				deployResponse := deployer.Deploy(&deploymentInfo, environments[environment], pusherCreator, response)
+				deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, environments[environment], pusherCreator, response)
 				Expect(deployResponse.Error).To(BeNil())
 
 				Expect(deployResponse.StatusCode).To(Equal(http.StatusOK))
@@ -451,13 +507,14 @@ var _ = Describe("Deployer", func() {
 					eventManager,
 					randomizerMock,
 					nil,
+					nil,
 					log,
 				}
 			})
 
 			It("doesn't return an error", func() {
 
-				deployResponse := deployer.Deploy(&deploymentInfoNoCustomParams, environmentsNoCustomParams[environment], pusherCreator, response)
+				deployResponse := deployer.Deploy(context.Background(), &deploymentInfoNoCustomParams, environmentsNoCustomParams[environment], pusherCreator, response)
 
 				Expect(deployResponse.Error).ToNot(HaveOccurred())
 				Expect(blueGreener.ExecuteCall.Received.Environment).To(Equal(environmentsNoCustomParams[environment]))
@@ -478,13 +535,14 @@ var _ = Describe("Deployer", func() {
 				eventManager,
 				randomizerMock,
 				nil,
+				nil,
 				log,
 			}
 		})
 		Context("when no initialization errors occur", func() {
 			It("it calls setup on the provided action creator", func() {
 
-				deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreatorMock, response)
+				deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreatorMock, response)
 
 				Expect(pusherCreatorMock.SetUpCall.Called).To(Equal(true))
 			})
@@ -492,7 +550,7 @@ var _ = Describe("Deployer", func() {
 
 		It("calls Start on the provided action creator", func() {
 
-			deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreatorMock, response)
+			deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreatorMock, response)
 
 			Expect(pusherCreatorMock.OnStartCall.Called).To(Equal(true))
 		})
@@ -501,22 +559,158 @@ var _ = Describe("Deployer", func() {
 			It("returns an error", func() {
 				pusherCreatorMock.OnStartCall.Returns.Err = errors.New("a test error")
 
-				deployResponse := deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreatorMock, response)
+				deployResponse := deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreatorMock, response)
 
 				Expect(deployResponse.Error).To(Equal(pusherCreatorMock.OnStartCall.Returns.Err))
 			})
 		})
 
 		It("calls CleanUp on the provided action creator", func() {
-			deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreatorMock, response)
+			deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreatorMock, response)
 
 			Expect(pusherCreatorMock.CleanUpCall.Called).To(Equal(true))
 		})
 
 		It("calls OnFinish on the provided action creator", func() {
-			deployer.Deploy(&deploymentInfo, S.Environment{}, pusherCreatorMock, response)
+			deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreatorMock, response)
 
 			Expect(pusherCreatorMock.OnFinishCall.Called).To(Equal(true))
 		})
+
+		It("executes with the context of the deployment", func() {
+			ctx := context.WithValue(context.Background(), contextKey{}, "a deployment")
+
+			deployer.Deploy(ctx, &deploymentInfo, S.Environment{}, pusherCreatorMock, response)
+
+			Expect(blueGreener.ExecuteCall.Received.Context.Value(contextKey{})).To(Equal("a deployment"))
+		})
+
+		It("gives the execution the deadline of the environment", func() {
+			environment := S.Environment{Timeouts: S.Timeouts{Deployment: 60}}
+
+			deployer.Deploy(context.Background(), &deploymentInfo, environment, pusherCreatorMock, response)
+
+			deadline, ok := blueGreener.ExecuteCall.Received.Context.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(deadline).To(BeTemporally("~", time.Now().Add(60*time.Second), time.Second))
+		})
+
+		It("does not give the execution a deadline when the environment has none", func() {
+			deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreatorMock, response)
+
+			_, ok := blueGreener.ExecuteCall.Received.Context.Deadline()
+			Expect(ok).To(BeFalse())
+		})
+
+		Context("when the deployment is cancelled", func() {
+			It("emits a DeployCancelledEvent", func() {
+				blueGreener.ExecuteCall.Returns.Error = bluegreen.CancelledError{}
+
+				deployer.Deploy(context.Background(), &deploymentInfo, S.Environment{}, pusherCreatorMock, response)
+
+				Expect(pusherCreatorMock.OnFinishCall.Received.Error).To(MatchError(bluegreen.CancelledError{}))
+				Expect(eventManager.EmitEventCall.Received.Events).To(HaveLen(1))
+				event, ok := eventManager.EmitEventCall.Received.Events[0].(DeployCancelledEvent)
+				Expect(ok).To(BeTrue())
+				Expect(event.DeploymentInfo).To(Equal(&deploymentInfo))
+				Expect(event.Error).To(MatchError(bluegreen.CancelledError{}))
+			})
+
+			It("does not execute when it is cancelled before", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				deployer.Deploy(ctx, &deploymentInfo, S.Environment{}, pusherCreatorMock, response)
+
+				Expect(blueGreener.ExecuteCall.Received.ActionCreator).To(BeNil())
+				Expect(pusherCreatorMock.OnFinishCall.Received.Error).To(MatchError(bluegreen.CancelledError{}))
+			})
+		})
+
+		Context("when it is a dry run", func() {
+			var (
+				pushers     []*mocks.Pusher
+				environment S.Environment
+				info        S.DeploymentInfo
+			)
+
+			BeforeEach(func() {
+				pushers = []*mocks.Pusher{{Response: response}, {Response: response}}
+				pushers[0].PlanCall.Returns.Plan = interfaces.FoundationPlan{AppExists: true, Steps: []string{"push"}}
+				pushers[1].PlanCall.Returns.Plan = interfaces.FoundationPlan{Steps: []string{"push", "rename"}}
+
+				pusherCreatorMock.CreatePusherCall.Returns.Pushers = []interfaces.Action{pushers[0], pushers[1]}
+				pusherCreatorMock.CreatePusherCall.Returns.Error = []error{nil, nil}
+
+				environment = S.Environment{Foundations: []string{"api1.example.com", "api2.example.com"}}
+				info = deploymentInfo
+				info.DryRun = true
+			})
+
+			It("returns the plan of every foundation without executing", func() {
+				deployResponse := deployer.Deploy(context.Background(), &info, environment, pusherCreatorMock, response)
+
+				Expect(deployResponse.Error).ToNot(HaveOccurred())
+				Expect(deployResponse.StatusCode).To(Equal(http.StatusOK))
+				Expect(deployResponse.Plan).To(Equal([]interfaces.FoundationPlan{
+					{FoundationURL: "api1.example.com", AppExists: true, Steps: []string{"push"}},
+					{FoundationURL: "api2.example.com", Steps: []string{"push", "rename"}},
+				}))
+
+				Expect(pusherCreatorMock.SetUpCall.Called).To(BeTrue())
+				Expect(pusherCreatorMock.OnStartCall.Called).To(BeFalse())
+				Expect(pusherCreatorMock.OnFinishCall.Called).To(BeFalse())
+				Expect(blueGreener.ExecuteCall.Received.ActionCreator).To(BeNil())
+				Expect(pushers[0].ExecuteCall.Called).To(BeFalse())
+			})
+
+			It("returns the login errors of the foundations", func() {
+				loginErr := errors.New("login error")
+				pushers[1].InitiallyCall.Returns.Error = loginErr
+
+				deployResponse := deployer.Deploy(context.Background(), &info, environment, pusherCreatorMock, response)
+
+				Expect(deployResponse.Error).To(MatchError(bluegreen.LoginError{LoginErrors: []error{loginErr}}))
+				Expect(pushers[1].PlanCall.Called).To(BeFalse())
+			})
+
+			It("returns the errors of the plans", func() {
+				planErr := errors.New("plan error")
+				pushers[0].PlanCall.Returns.Error = planErr
+
+				deployResponse := deployer.Deploy(context.Background(), &info, environment, pusherCreatorMock, response)
+
+				Expect(deployResponse.Error).To(MatchError(bluegreen.PushError{PushErrors: []error{planErr}}))
+			})
+
+			It("returns a DryRunNotSupportedError for actions that cannot plan", func() {
+				pusherCreatorMock.CreatePusherCall.Returns.Pushers[0] = actionWithoutPlan{pushers[0]}
+
+				deployResponse := deployer.Deploy(context.Background(), &info, environment, pusherCreatorMock, response)
+
+				Expect(deployResponse.Error).To(BeAssignableToTypeOf(DryRunNotSupportedError{}))
+				Expect(deployResponse.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("when the deployment runs out of time", func() {
+			It("does not execute and finishes with a DeploymentTimeoutError", func() {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+				defer cancel()
+				<-ctx.Done()
+
+				deployer.Deploy(ctx, &deploymentInfo, S.Environment{}, pusherCreatorMock, response)
+
+				Expect(blueGreener.ExecuteCall.Received.ActionCreator).To(BeNil())
+				Expect(pusherCreatorMock.OnFinishCall.Received.Error).To(MatchError(bluegreen.DeploymentTimeoutError{}))
+			})
+		})
 	})
 })
+
+type contextKey struct{}
+
+// actionWithoutPlan is an action that cannot tell what it would do.
+type actionWithoutPlan struct {
+	interfaces.Action
+}
@@ -1,6 +1,10 @@
 package deployer
 
-import "fmt"
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
 
 type BasicAuthError struct{}
 
@@ -8,6 +12,38 @@ func (e BasicAuthError) Error() string {
 	return "basic auth header not found"
 }
 
+func (e BasicAuthError) Code() string {
+	return "BasicAuthError"
+}
+
+func (e BasicAuthError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e BasicAuthError) Retryable() bool {
+	return false
+}
+
+type DryRunNotSupportedError struct {
+	Action string
+}
+
+func (e DryRunNotSupportedError) Error() string {
+	return fmt.Sprintf("a dry run is not supported for %s", e.Action)
+}
+
+func (e DryRunNotSupportedError) Code() string {
+	return "DryRunNotSupportedError"
+}
+
+func (e DryRunNotSupportedError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e DryRunNotSupportedError) Retryable() bool {
+	return false
+}
+
 type ManifestError struct {
 	Err error
 }
@@ -16,12 +52,36 @@ func (e ManifestError) Error() string {
 	return fmt.Sprintf("base64 encoded manifest could not be decoded: %s", e.Err)
 }
 
+func (e ManifestError) Code() string {
+	return "ManifestError"
+}
+
+func (e ManifestError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e ManifestError) Retryable() bool {
+	return false
+}
+
 type InvalidContentTypeError struct{}
 
 func (e InvalidContentTypeError) Error() string {
 	return "must be application/json or application/zip"
 }
 
+func (e InvalidContentTypeError) Code() string {
+	return "InvalidContentTypeError"
+}
+
+func (e InvalidContentTypeError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e InvalidContentTypeError) Retryable() bool {
+	return false
+}
+
 type EventError struct {
 	Type string
 	Err  error
@@ -31,6 +91,18 @@ func (e EventError) Error() string {
 	return fmt.Sprintf("an error occurred in the %s event: %s", e.Type, e.Err)
 }
 
+func (e EventError) Code() string {
+	return "EventError"
+}
+
+func (e EventError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e EventError) Retryable() bool {
+	return false
+}
+
 type EnvironmentNotFoundError struct {
 	Environment string
 }
@@ -38,3 +110,35 @@ type EnvironmentNotFoundError struct {
 func (e EnvironmentNotFoundError) Error() string {
 	return fmt.Sprintf("environment not found: %s", e.Environment)
 }
+
+func (e EnvironmentNotFoundError) Code() string {
+	return "EnvironmentNotFoundError"
+}
+
+func (e EnvironmentNotFoundError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e EnvironmentNotFoundError) Retryable() bool {
+	return false
+}
+
+type InvalidRequestBodyError struct {
+	Err error
+}
+
+func (e InvalidRequestBodyError) Error() string {
+	return fmt.Sprintf("invalid request body: %s", e.Err)
+}
+
+func (e InvalidRequestBodyError) Code() string {
+	return "InvalidRequestBodyError"
+}
+
+func (e InvalidRequestBodyError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e InvalidRequestBodyError) Retryable() bool {
+	return false
+}
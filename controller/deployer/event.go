@@ -0,0 +1,51 @@
+package deployer
+
+import (
+	"errors"
+	"io"
+	"reflect"
+
+	"github.com/compozed/deployadactyl/eventmanager"
+	I "github.com/compozed/deployadactyl/interfaces"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+type eventBinding struct {
+	etype   reflect.Type
+	handler func(event interface{}) error
+}
+
+func (b eventBinding) Accepts(event interface{}) bool {
+	return reflect.TypeOf(event) == b.etype
+}
+
+func (b eventBinding) Emit(event interface{}) error {
+	return b.handler(event)
+}
+
+// DeployCancelledEvent is emitted when a deployment has been cancelled and undone.
+type DeployCancelledEvent struct {
+	DeploymentInfo *S.DeploymentInfo
+	Environment    S.Environment
+	Response       io.ReadWriter
+	Error          error
+	Log            I.DeploymentLogger
+}
+
+func (d DeployCancelledEvent) Name() string {
+	return "DeployCancelledEvent"
+}
+
+func NewDeployCancelledEventBinding(handler func(event DeployCancelledEvent) error) I.Binding {
+	return eventBinding{
+		etype: reflect.TypeOf(DeployCancelledEvent{}),
+		handler: func(gevent interface{}) error {
+			event, ok := gevent.(DeployCancelledEvent)
+			if ok {
+				return handler(event)
+			} else {
+				return eventmanager.InvalidEventType{Err: errors.New("invalid event type")}
+			}
+		},
+	}
+}
@@ -1,6 +1,10 @@
 package prechecker
 
-import "fmt"
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
 
 type NoFoundationsConfiguredError struct{}
 
@@ -8,6 +12,18 @@ func (e NoFoundationsConfiguredError) Error() string {
 	return "no foundations configured"
 }
 
+func (e NoFoundationsConfiguredError) Code() string {
+	return "NoFoundationsConfiguredError"
+}
+
+func (e NoFoundationsConfiguredError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e NoFoundationsConfiguredError) Retryable() bool {
+	return false
+}
+
 type InvalidGetRequestError struct {
 	FoundationURL string
 	Err           error
@@ -17,6 +33,18 @@ func (e InvalidGetRequestError) Error() string {
 	return fmt.Sprintf("error building request to url %s: %s", e.FoundationURL, e.Err)
 }
 
+func (e InvalidGetRequestError) Code() string {
+	return "InvalidGetRequestError"
+}
+
+func (e InvalidGetRequestError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e InvalidGetRequestError) Retryable() bool {
+	return false
+}
+
 type FoundationUnavailableError struct {
 	FoundationURL string
 	Status        string
@@ -25,3 +53,15 @@ type FoundationUnavailableError struct {
 func (e FoundationUnavailableError) Error() string {
 	return fmt.Sprintf("deploy aborted: one or more CF foundations unavailable: %s: %s", e.FoundationURL, e.Status)
 }
+
+func (e FoundationUnavailableError) Code() string {
+	return "FoundationUnavailableError"
+}
+
+func (e FoundationUnavailableError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e FoundationUnavailableError) Retryable() bool {
+	return true
+}
@@ -0,0 +1,49 @@
+package deployer
+
+import (
+	"net/http"
+
+	C "github.com/compozed/deployadactyl/constants"
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+var categoryStatusCodes = map[string]int{
+	C.ErrorCategoryUser:       http.StatusBadRequest,
+	C.ErrorCategoryArtifact:   http.StatusUnprocessableEntity,
+	C.ErrorCategoryFoundation: http.StatusBadGateway,
+	C.ErrorCategoryInternal:   http.StatusInternalServerError,
+}
+
+var codeStatusCodes = map[string]int{
+	"BasicAuthError":             http.StatusUnauthorized,
+	"DeploymentForbiddenError":   http.StatusForbidden,
+	"EnvironmentNotFoundError":   http.StatusNotFound,
+	"DeploymentNotFoundError":    http.StatusNotFound,
+	"IdempotencyKeyReusedError":  http.StatusUnprocessableEntity,
+	"IdempotencyKeyExpiredError": http.StatusGone,
+	"ApplicationLockedError":     http.StatusConflict,
+	"DeploymentTimeoutError":     http.StatusGatewayTimeout,
+}
+
+// StatusCode is the one place that decides which HTTP status a deployment error is reported with.
+// Errors that are not deployment errors are treated as internal.
+func StatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	deploymentError, ok := err.(I.DeploymentError)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+
+	if statusCode, ok := codeStatusCodes[deploymentError.Code()]; ok {
+		return statusCode
+	}
+
+	if statusCode, ok := categoryStatusCodes[deploymentError.Category()]; ok {
+		return statusCode
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,111 @@
+package deployer_test
+
+import (
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	C "github.com/compozed/deployadactyl/constants"
+	. "github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	"github.com/compozed/deployadactyl/eventmanager/handlers/healthchecker"
+	"github.com/compozed/deployadactyl/eventmanager/handlers/routemapper"
+	"github.com/compozed/deployadactyl/locker"
+	"github.com/compozed/deployadactyl/state"
+)
+
+var _ = Describe("StatusCode", func() {
+	It("returns StatusOK when there is no error", func() {
+		Expect(StatusCode(nil)).To(Equal(http.StatusOK))
+	})
+
+	It("returns StatusInternalServerError for errors without a category", func() {
+		Expect(StatusCode(errors.New("a test error"))).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("returns StatusBadRequest for user errors", func() {
+		Expect(StatusCode(ManifestError{Err: errors.New("bad yaml")})).To(Equal(http.StatusBadRequest))
+	})
+
+	It("returns StatusUnprocessableEntity for artifact errors", func() {
+		err := bluegreen.PushError{PushErrors: []error{state.PushError{}, state.PushError{}}}
+
+		Expect(StatusCode(err)).To(Equal(http.StatusUnprocessableEntity))
+	})
+
+	It("returns StatusBadGateway for foundation errors", func() {
+		err := bluegreen.StartError{Errors: []error{state.StartError{ApplicationName: "app"}}}
+
+		Expect(StatusCode(err)).To(Equal(http.StatusBadGateway))
+	})
+
+	It("returns StatusInternalServerError for internal errors", func() {
+		Expect(StatusCode(EventError{Type: "an event", Err: errors.New("a test error")})).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("returns StatusUnauthorized for missing basic auth", func() {
+		Expect(StatusCode(BasicAuthError{})).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("returns StatusNotFound for unknown environments", func() {
+		Expect(StatusCode(EnvironmentNotFoundError{Environment: "unknown"})).To(Equal(http.StatusNotFound))
+	})
+
+	It("returns StatusConflict for applications that are locked by another deployment", func() {
+		Expect(StatusCode(locker.LockedError{})).To(Equal(http.StatusConflict))
+	})
+
+	It("returns StatusGatewayTimeout for deployments that run out of time", func() {
+		Expect(StatusCode(bluegreen.DeploymentTimeoutError{})).To(Equal(http.StatusGatewayTimeout))
+	})
+
+	It("returns StatusUnprocessableEntity for applications that fail their health check", func() {
+		err := healthchecker.HealthCheckError{StatusCode: http.StatusServiceUnavailable, Endpoint: "/health"}
+
+		Expect(err.Code()).To(Equal("HealthCheckError"))
+		Expect(StatusCode(err)).To(Equal(http.StatusUnprocessableEntity))
+	})
+
+	It("returns StatusBadGateway for routes that cannot be mapped", func() {
+		err := routemapper.MapRouteError{Route: "app.example.com"}
+
+		Expect(err.Code()).To(Equal("MapRouteError"))
+		Expect(err.Retryable()).To(BeTrue())
+		Expect(StatusCode(err)).To(Equal(http.StatusBadGateway))
+	})
+
+	It("returns StatusBadRequest for routes on unknown domains", func() {
+		Expect(StatusCode(routemapper.InvalidRouteError{Route: "app.unknown.com"})).To(Equal(http.StatusBadRequest))
+	})
+
+	It("uses the category of the error an initialization error wraps", func() {
+		err := bluegreen.InitializationError{Err: ManifestError{Err: errors.New("bad yaml")}}
+
+		Expect(StatusCode(err)).To(Equal(http.StatusBadRequest))
+	})
+
+	Context("when the foundations fail for different reasons", func() {
+		It("reports the aggregate as a foundation error", func() {
+			err := bluegreen.PushError{PushErrors: []error{state.PushError{}, state.MapRouteError{Out: []byte("route is taken")}}}
+
+			Expect(err.Category()).To(Equal(C.ErrorCategoryFoundation))
+			Expect(StatusCode(err)).To(Equal(http.StatusBadGateway))
+		})
+	})
+
+	Describe("retryability", func() {
+		It("is retryable when every foundation failed with a retryable error", func() {
+			err := bluegreen.StartError{Errors: []error{state.StartError{}, state.StartError{}}}
+
+			Expect(err.Retryable()).To(BeTrue())
+		})
+
+		It("is not retryable when any foundation failed with an error that is not", func() {
+			err := bluegreen.StartError{Errors: []error{state.StartError{}, errors.New("a test error")}}
+
+			Expect(err.Retryable()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+
+	C "github.com/compozed/deployadactyl/constants"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/gin-gonic/gin"
+)
+
+// deploymentDocument is the response to a deployment for clients that accept application/json.
+type deploymentDocument struct {
+	UUID          string               `json:"uuid"`
+	Status        string               `json:"status"`
+	StatusCode    int                  `json:"status_code"`
+	Error         string               `json:"error,omitempty"`
+	ErrorCode     string               `json:"error_code,omitempty"`
+	ErrorCategory string               `json:"error_category,omitempty"`
+	Retryable     bool                 `json:"retryable"`
+	Foundations   []foundationDocument `json:"foundations"`
+	Errors        []matchedError       `json:"errors"`
+}
+
+// foundationDocument is the last phase a foundation went through, its error and its Cloud Foundry output.
+type foundationDocument struct {
+	FoundationURL string   `json:"foundation_url"`
+	Phase         string   `json:"phase,omitempty"`
+	Error         string   `json:"error,omitempty"`
+	Logs          []string `json:"logs"`
+}
+
+// matchedError is a LogMatchedError found in the output of the deployment.
+type matchedError struct {
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	Details  []string `json:"details"`
+	Solution string   `json:"solution"`
+}
+
+func acceptsJSON(g *gin.Context) bool {
+	return strings.Contains(g.Request.Header.Get("Accept"), "application/json")
+}
+
+// writeResponse writes the deployment response as plain text, or as a deploymentDocument when the client accepts JSON.
+func (c *Controller) writeResponse(g *gin.Context, log I.DeploymentLogger, deployResponse I.DeployResponse, response *bytes.Buffer) {
+	if !acceptsJSON(g) {
+		g.Writer.WriteHeader(deployResponse.StatusCode)
+		io.Copy(g.Writer, response)
+		return
+	}
+
+	g.JSON(deployResponse.StatusCode, c.document(log.UUID, deployResponse, response.String()))
+}
+
+func (c *Controller) document(uuid string, deployResponse I.DeployResponse, output string) deploymentDocument {
+	document := deploymentDocument{
+		UUID:        uuid,
+		Status:      C.DeploymentStatusSucceeded,
+		StatusCode:  deployResponse.StatusCode,
+		Foundations: []foundationDocument{},
+		Errors:      []matchedError{},
+	}
+	if deployResponse.Error != nil {
+		document.Status = C.DeploymentStatusFailed
+		document.Error = deployResponse.Error.Error()
+		document.ErrorCategory = C.ErrorCategoryInternal
+
+		if deploymentError, ok := deployResponse.Error.(I.DeploymentError); ok {
+			document.ErrorCode = deploymentError.Code()
+			document.ErrorCategory = deploymentError.Category()
+			document.Retryable = deploymentError.Retryable()
+		}
+	}
+
+	foundations := map[string]*foundationDocument{}
+	foundation := func(foundationURL string) *foundationDocument {
+		if _, ok := foundations[foundationURL]; !ok {
+			foundations[foundationURL] = &foundationDocument{FoundationURL: foundationURL, Logs: []string{}}
+		}
+		return foundations[foundationURL]
+	}
+
+	if status, ok := c.Tracker.Get(uuid); ok {
+		for foundationURL, foundationStatus := range status.Foundations {
+			f := foundation(foundationURL)
+			f.Phase = foundationStatus.Phase
+			f.Error = foundationStatus.Error
+		}
+	}
+
+	// The deployment has finished, so the subscription replays its output and ends.
+	lines, unsubscribe := c.Tracker.Subscribe(uuid)
+	for line := range lines {
+		f := foundation(line.FoundationURL)
+		f.Logs = append(f.Logs, line.Line)
+	}
+	unsubscribe()
+
+	for _, f := range foundations {
+		document.Foundations = append(document.Foundations, *f)
+	}
+	sort.Slice(document.Foundations, func(i, j int) bool {
+		return document.Foundations[i].FoundationURL < document.Foundations[j].FoundationURL
+	})
+
+	for _, err := range c.ErrorFinder.FindErrors(output) {
+		document.Errors = append(document.Errors, matchedError{
+			Code:     err.Code(),
+			Message:  err.Error(),
+			Details:  err.Details(),
+			Solution: err.Solution(),
+		})
+	}
+
+	return document
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/compozed/deployadactyl/controller/deployer"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/gin-gonic/gin"
+)
+
+// planDocument is the response to a dry run: the steps the deployment would take on each foundation.
+type planDocument struct {
+	UUID        string             `json:"uuid"`
+	DryRun      bool               `json:"dry_run"`
+	StatusCode  int                `json:"status_code"`
+	Foundations []I.FoundationPlan `json:"foundations"`
+}
+
+func dryRun(g *gin.Context) bool {
+	return g.Query("dryRun") == "true"
+}
+
+// planPut runs a dry run of a PUT request. Only starts and stops can be planned.
+func (c *Controller) planPut(g *gin.Context, log I.DeploymentLogger, deployment *I.Deployment, body []byte, response *bytes.Buffer) {
+	putRequest := &PutRequest{}
+	err := json.Unmarshal(body, putRequest)
+	if err != nil {
+		response.Write([]byte("Invalid request body."))
+		err = deployer.InvalidRequestBodyError{Err: err}
+		c.writeResponse(g, log, I.DeployResponse{StatusCode: deployer.StatusCode(err), Error: err}, response)
+		return
+	}
+
+	var deployResponse I.DeployResponse
+	switch putRequest.State {
+	case "stopped":
+		deployResponse = c.StopControllerFactory(log).StopDeployment(deployment, putRequest.Data, response)
+	case "started":
+		deployResponse = c.StartControllerFactory(log).StartDeployment(deployment, putRequest.Data, response)
+	default:
+		err = deployer.DryRunNotSupportedError{Action: "state " + putRequest.State}
+		response.WriteString(err.Error())
+		deployResponse = I.DeployResponse{StatusCode: deployer.StatusCode(err), Error: err}
+	}
+
+	c.writePlan(g, log, deployResponse, response)
+}
+
+// writePlan writes the steps a dry run planned on each foundation, or the error that stopped it.
+func (c *Controller) writePlan(g *gin.Context, log I.DeploymentLogger, deployResponse I.DeployResponse, response *bytes.Buffer) {
+	if deployResponse.Error != nil {
+		c.writeResponse(g, log, deployResponse, response)
+		return
+	}
+
+	foundations := deployResponse.Plan
+	if foundations == nil {
+		foundations = []I.FoundationPlan{}
+	}
+
+	g.JSON(http.StatusOK, planDocument{
+		UUID:        log.UUID,
+		DryRun:      true,
+		StatusCode:  http.StatusOK,
+		Foundations: foundations,
+	})
+}
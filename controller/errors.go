@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
+
+type RollbackTargetMissingError struct{}
+
+func (e RollbackTargetMissingError) Error() string {
+	return `cannot roll back: the deployment to roll back to is missing from "data": {"to": "<uuid>"}`
+}
+
+func (e RollbackTargetMissingError) Code() string {
+	return "RollbackTargetMissingError"
+}
+
+func (e RollbackTargetMissingError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e RollbackTargetMissingError) Retryable() bool {
+	return false
+}
+
+type DeploymentNotFoundError struct {
+	UUID string
+}
+
+func (e DeploymentNotFoundError) Error() string {
+	return fmt.Sprintf("cannot roll back: deployment %s of this application was not found", e.UUID)
+}
+
+func (e DeploymentNotFoundError) Code() string {
+	return "DeploymentNotFoundError"
+}
+
+func (e DeploymentNotFoundError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e DeploymentNotFoundError) Retryable() bool {
+	return false
+}
+
+type DeploymentNotReplayableError struct {
+	UUID   string
+	Reason string
+}
+
+func (e DeploymentNotReplayableError) Error() string {
+	return fmt.Sprintf("cannot roll back: deployment %s %s", e.UUID, e.Reason)
+}
+
+func (e DeploymentNotReplayableError) Code() string {
+	return "DeploymentNotReplayableError"
+}
+
+func (e DeploymentNotReplayableError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e DeploymentNotReplayableError) Retryable() bool {
+	return false
+}
+
+type UnknownStateError struct {
+	State string
+}
+
+func (e UnknownStateError) Error() string {
+	return fmt.Sprintf("unknown requested state: %s", e.State)
+}
+
+func (e UnknownStateError) Code() string {
+	return "UnknownStateError"
+}
+
+func (e UnknownStateError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e UnknownStateError) Retryable() bool {
+	return false
+}
+
+type DeploymentInterruptedError struct {
+	UUID string
+}
+
+func (e DeploymentInterruptedError) Error() string {
+	return fmt.Sprintf("deployment %s stopped before it had a result", e.UUID)
+}
+
+func (e DeploymentInterruptedError) Code() string {
+	return "DeploymentInterruptedError"
+}
+
+func (e DeploymentInterruptedError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e DeploymentInterruptedError) Retryable() bool {
+	return true
+}
+
+type DeploymentForbiddenError struct {
+	UUID string
+}
+
+func (e DeploymentForbiddenError) Error() string {
+	return fmt.Sprintf("deployment %s was started with other credentials", e.UUID)
+}
+
+func (e DeploymentForbiddenError) Code() string {
+	return "DeploymentForbiddenError"
+}
+
+func (e DeploymentForbiddenError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e DeploymentForbiddenError) Retryable() bool {
+	return false
+}
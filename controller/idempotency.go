@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/compozed/deployadactyl/idempotency"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/gin-gonic/gin"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// claimIdempotencyKey registers the request under the key of its Idempotency-Key header and the user of its
+// basic auth, and returns the key. It answers the request itself and returns false when the user sent the key
+// with an earlier request: a repeat of that request gets its result, and a different request is rejected.
+func (c *Controller) claimIdempotencyKey(g *gin.Context, log I.DeploymentLogger, body []byte) (string, bool) {
+	key := g.Request.Header.Get(idempotencyKeyHeader)
+	if key == "" || c.IdempotencyKeys == nil {
+		return "", true
+	}
+
+	user, _, _ := g.Request.BasicAuth()
+	fingerprint := idempotency.Fingerprint(g.Request.Method, g.Request.URL.Path, body)
+	owner, err := c.IdempotencyKeys.Claim(user, key, fingerprint, log.UUID)
+	if err != nil {
+		log.Error(err)
+		response := bytes.NewBufferString(err.Error() + "\n")
+		c.writeResponse(g, log, I.DeployResponse{StatusCode: deployer.StatusCode(err), Error: err}, response)
+		return "", false
+	}
+
+	if owner == log.UUID {
+		return key, true
+	}
+
+	log.Infof("idempotency key %s was used by deployment %s, returning its result", key, owner)
+	g.Header("Idempotent-Replayed", "true")
+
+	if g.Query("async") == "true" {
+		g.Header("Location", "/v3/deployments/"+owner)
+		g.JSON(http.StatusAccepted, gin.H{
+			"uuid":       owner,
+			"status_url": "/v3/deployments/" + owner,
+		})
+		return "", false
+	}
+
+	deployResponse, output, err := c.IdempotencyKeys.Wait(g.Request.Context(), owner)
+	if _, ok := err.(idempotency.KeyExpiredError); ok {
+		log.Error(err)
+		c.writeResponse(g, log, I.DeployResponse{StatusCode: deployer.StatusCode(err), Error: err}, bytes.NewBufferString(err.Error()+"\n"))
+		return "", false
+	}
+	if err != nil {
+		log.Errorf("stopped waiting for deployment %s: %s", owner, err)
+		return "", false
+	}
+	c.writeResponse(g, I.DeploymentLogger{Log: c.Log, UUID: owner}, deployResponse, bytes.NewBufferString(output))
+
+	return "", false
+}
+
+// finishIdempotencyKey records the result of the deployment that claimed the key for the requests that repeat it.
+// It is deferred by the deployment, so that a deployment that never got to a response still releases them.
+func (c *Controller) finishIdempotencyKey(key, uuid string, deployResponse I.DeployResponse, response *bytes.Buffer) {
+	if key == "" {
+		return
+	}
+
+	if deployResponse.StatusCode == 0 {
+		err := DeploymentInterruptedError{UUID: uuid}
+		deployResponse = I.DeployResponse{StatusCode: deployer.StatusCode(err), Error: err}
+	}
+
+	c.IdempotencyKeys.Finish(uuid, deployResponse, response.String())
+}
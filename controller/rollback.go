@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/compozed/deployadactyl/history"
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+type rollbackRequest struct {
+	ArtifactURL          string            `json:"artifact_url"`
+	Manifest             string            `json:"manifest,omitempty"`
+	EnvironmentVariables map[string]string `json:"environment_variables,omitempty"`
+	HealthCheckEndpoint  string            `json:"health_check_endpoint,omitempty"`
+}
+
+// rollback looks up an earlier push of the application and pushes its artifact, manifest and environment variables again.
+func (c *Controller) rollback(log I.DeploymentLogger, deployment *I.Deployment, data map[string]interface{}, response *bytes.Buffer) I.DeployResponse {
+	to, _ := data["to"].(string)
+	if to == "" {
+		return rollbackFailed(response, RollbackTargetMissingError{})
+	}
+
+	record, found, err := c.DeploymentStore.Get(to)
+	if err != nil {
+		return rollbackFailed(response, err)
+	}
+
+	cf := deployment.CFContext
+	if !found || record.Environment != cf.Environment || record.Organization != cf.Organization || record.Space != cf.Space || record.Application != cf.Application {
+		return rollbackFailed(response, DeploymentNotFoundError{to})
+	}
+
+	if record.Type != C.DeploymentTypePush {
+		return rollbackFailed(response, DeploymentNotReplayableError{to, "is not a push"})
+	}
+	if record.Status != C.DeploymentStatusSucceeded {
+		return rollbackFailed(response, DeploymentNotReplayableError{to, "did not succeed"})
+	}
+	if record.ArtifactURL == "" {
+		return rollbackFailed(response, DeploymentNotReplayableError{to, "was pushed from a zip file and has no artifact url"})
+	}
+	redacted := strings.Contains(record.Manifest, history.Redacted)
+	for _, value := range record.EnvironmentVariables {
+		redacted = redacted || value == history.Redacted
+	}
+	if redacted {
+		return rollbackFailed(response, DeploymentNotReplayableError{to, "had secret environment variables, which are not kept in the history"})
+	}
+
+	request := rollbackRequest{
+		ArtifactURL:          record.ArtifactURL,
+		EnvironmentVariables: record.EnvironmentVariables,
+		HealthCheckEndpoint:  record.HealthCheckEndpoint,
+	}
+	if record.Manifest != "" {
+		request.Manifest = base64.StdEncoding.EncodeToString([]byte(record.Manifest))
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return rollbackFailed(response, err)
+	}
+
+	deployment.Body = &body
+	deployment.Type = I.DeploymentType{JSON: true}
+
+	log.Infof("rolling back to deployment %s of %s", to, record.ArtifactURL)
+	fmt.Fprintf(response, "Rolling back to deployment %s of %s\n\n", to, record.ArtifactURL)
+
+	return c.PushControllerFactory(log).RunDeployment(deployment, response)
+}
+
+func rollbackFailed(response *bytes.Buffer, err error) I.DeployResponse {
+	fmt.Fprintln(response, err)
+
+	return I.DeployResponse{
+		StatusCode: deployer.StatusCode(err),
+		Error:      err,
+	}
+}
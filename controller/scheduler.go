@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"strconv"
+
+	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/gin-gonic/gin"
+)
+
+// schedule takes the lock of the application for the deployment and then waits until the Scheduler lets it run.
+// It returns the function that frees its place and releases the lock. The lock is taken first, so that a deployment
+// waiting for another deployment of the same application does not keep a place of the Scheduler while it waits.
+// The deployment is in the queued phase while it waits for the Scheduler, and leaves the queue with an error if it is cancelled.
+func (c *Controller) schedule(log I.DeploymentLogger, deployment *I.Deployment, deploymentType string, priority int) (func(), error) {
+	unlock := func() {}
+	if c.Locker != nil {
+		var err error
+		unlock, err = c.Locker.Lock(deployment.Context, deployment.CFContext, I.LockHolder{
+			UUID: log.UUID,
+			Type: deploymentType,
+			User: deployment.Authorization.Username,
+		})
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+	}
+
+	if c.Scheduler == nil {
+		return unlock, nil
+	}
+
+	priority = c.allowedPriority(deployment.CFContext.Environment, priority)
+	ready, leave := c.Scheduler.Enqueue(log.UUID, deployment.CFContext.Environment, priority)
+	done := func() {
+		leave()
+		unlock()
+	}
+
+	select {
+	case <-ready:
+		return done, nil
+	default:
+	}
+
+	log.Infof("deployment is queued at position %d", c.Scheduler.Position(log.UUID))
+	c.Tracker.SetPhase(log.UUID, C.DeploymentPhaseQueued)
+
+	var cancelled <-chan struct{}
+	if deployment.Context != nil {
+		cancelled = deployment.Context.Done()
+	}
+
+	select {
+	case <-ready:
+	case <-cancelled:
+		done()
+		log.Info("deployment was cancelled while it was queued")
+		return nil, bluegreen.CancelledError{}
+	}
+
+	log.Info("deployment left the queue")
+	c.Tracker.SetPhase(log.UUID, C.DeploymentPhaseStarted)
+
+	return done, nil
+}
+
+// priority returns the priority query parameter of a request, or zero when it is missing or not a number.
+func priority(g *gin.Context) int {
+	p, err := strconv.Atoi(g.Query("priority"))
+	if err != nil {
+		return 0
+	}
+	return p
+}
+
+// allowedPriority keeps the priority a client asked for between zero and the max_priority of the environment,
+// so that callers can only move ahead of others as far as the environment allows.
+func (c *Controller) allowedPriority(environment string, priority int) int {
+	if max := c.Config.Environments[environment].MaxPriority; priority > max {
+		return max
+	}
+	if priority < 0 {
+		return 0
+	}
+	return priority
+}
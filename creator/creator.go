@@ -2,11 +2,13 @@
 package creator
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"github.com/compozed/deployadactyl/artifetcher"
 	"github.com/compozed/deployadactyl/artifetcher/extractor"
 	"github.com/compozed/deployadactyl/config"
+	C "github.com/compozed/deployadactyl/constants"
 	"github.com/compozed/deployadactyl/controller"
 	"github.com/compozed/deployadactyl/controller/deployer"
 	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
@@ -20,11 +22,22 @@ import (
 	"github.com/compozed/deployadactyl/eventmanager/handlers/envvar"
 	"github.com/compozed/deployadactyl/eventmanager/handlers/healthchecker"
 	"github.com/compozed/deployadactyl/eventmanager/handlers/routemapper"
+	"github.com/compozed/deployadactyl/history"
+	"github.com/compozed/deployadactyl/idempotency"
 	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/locker"
 	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/compozed/deployadactyl/scheduler"
+	"github.com/compozed/deployadactyl/secrets"
+	"github.com/compozed/deployadactyl/state/command"
+	"github.com/compozed/deployadactyl/state/deletion"
+	"github.com/compozed/deployadactyl/state/revert"
+	"github.com/compozed/deployadactyl/state/scale"
 	"github.com/compozed/deployadactyl/state/start"
+	"github.com/compozed/deployadactyl/state/status"
 	"github.com/compozed/deployadactyl/state/stop"
 	"github.com/compozed/deployadactyl/structs"
+	"github.com/compozed/deployadactyl/tracker"
 	"github.com/gin-gonic/gin"
 	"github.com/op/go-logging"
 	"github.com/spf13/afero"
@@ -39,16 +52,31 @@ import (
 // ENDPOINT is used by the handler to define the deployment endpoint.
 const v2ENDPOINT = "/v2/deploy/:environment/:org/:space/:appName"
 const ENDPOINT = "/v3/apps/:environment/:org/:space/:appName"
+const DEPLOYMENTS_ENDPOINT = "/v3/deployments/:uuid"
+
+// commands are the commands that the command controller runs, by their type.
+var commands = map[string]command.Command{
+	C.DeploymentTypeRestart: command.Restart,
+	C.DeploymentTypeRestage: command.Restage,
+	C.DeploymentTypeScale:   scale.Command,
+	C.DeploymentTypeDelete:  deletion.Command,
+}
 
 type CreatorModuleProvider struct {
-	NewCourier         courier.CourierConstructor
-	NewPrechecker      prechecker.PrecheckerConstructor
-	NewFetcher         artifetcher.ArtifetcherConstructor
-	NewExtractor       extractor.ExtractorConstructor
-	NewEventManager    eventmanager.EventManagerConstructor
-	NewPushController  push.PushControllerConstructor
-	NewStartController start.StartControllerConstructor
-	NewStopController  stop.StopControllerConstructor
+	NewCourier           courier.CourierConstructor
+	NewPrechecker        prechecker.PrecheckerConstructor
+	NewFetcher           artifetcher.ArtifetcherConstructor
+	NewExtractor         extractor.ExtractorConstructor
+	NewEventManager      eventmanager.EventManagerConstructor
+	NewPushController    push.PushControllerConstructor
+	NewStartController   start.StartControllerConstructor
+	NewStopController    stop.StopControllerConstructor
+	NewRevertController  revert.RevertControllerConstructor
+	NewCommandController command.ControllerConstructor
+	NewStatusController  status.StatusControllerConstructor
+	NewDeploymentStore   history.DeploymentStoreConstructor
+	SecretProviders      map[string]I.SecretProvider
+	HTTPClient           *http.Client
 }
 
 // Creator has a config, eventManager, logger and writer for creating dependencies.
@@ -59,6 +87,13 @@ type Creator struct {
 	writer       io.Writer
 	fileSystem   *afero.Afero
 	provider     CreatorModuleProvider
+	tracker      I.Tracker
+	store        I.DeploymentStore
+	keys         I.IdempotencyKeys
+	locker       I.Locker
+	scheduler    I.Scheduler
+	sessions     *executor.Sessions
+	secrets      I.SecretResolver
 }
 
 // Default returns a default Creator and an Error.
@@ -96,6 +131,13 @@ func (c Creator) CreateControllerHandler(controller I.Controller) *gin.Engine {
 	r.POST(v2ENDPOINT, controller.RunDeploymentViaHttp)
 	r.POST(ENDPOINT, controller.RunDeploymentViaHttp)
 	r.PUT(ENDPOINT, controller.PutRequestHandler)
+	r.DELETE(ENDPOINT, controller.DeleteRequestHandler)
+	r.GET(DEPLOYMENTS_ENDPOINT, controller.GetDeploymentStatus)
+	r.DELETE(DEPLOYMENTS_ENDPOINT, controller.CancelDeployment)
+	r.GET(ENDPOINT, controller.GetAppStatus)
+	r.GET(ENDPOINT+"/history", controller.GetDeploymentHistory)
+	r.GET(ENDPOINT+"/drift", controller.GetAppDrift)
+	r.GET(ENDPOINT+"/lock", controller.GetAppLock)
 
 	return r
 }
@@ -113,12 +155,14 @@ func (c Creator) CreateListener() net.Listener {
 	return ls
 }
 
-// CreateCourier returns a courier with an executor.
-func (c Creator) CreateCourier() (I.Courier, error) {
+// CreateCourier returns a courier with an executor whose running commands are killed when the context is cancelled.
+// The executor shares the Cloud Foundry sessions of the Creator, so that couriers reuse the logins of earlier deployments.
+func (c Creator) CreateCourier(ctx context.Context) (I.Courier, error) {
 	ex, err := executor.New(c.CreateFileSystem())
 	if err != nil {
 		return nil, err
 	}
+	ex = ex.WithContext(ctx).WithSessions(c.sessions)
 
 	if c.provider.NewCourier != nil {
 		return c.provider.NewCourier(ex), nil
@@ -146,8 +190,42 @@ func (c Creator) CreateFileSystem() *afero.Afero {
 	return c.fileSystem
 }
 
+// CreateTracker returns the Tracker that holds the status of every deployment.
+func (c Creator) CreateTracker() I.Tracker {
+	return c.tracker
+}
+
+// CreateDeploymentStore returns the DeploymentStore that the history of every deployment is kept in.
+func (c Creator) CreateDeploymentStore() I.DeploymentStore {
+	return c.store
+}
+
+// CreateIdempotencyKeys returns the IdempotencyKeys that repeated deployment requests are answered from.
+func (c Creator) CreateIdempotencyKeys() I.IdempotencyKeys {
+	return c.keys
+}
+
+// CreateLocker returns the Locker that keeps deployments of the same application from running at the same time.
+func (c Creator) CreateLocker() I.Locker {
+	return c.locker
+}
+
+// CreateScheduler returns the Scheduler that bounds how many deployments run at the same time.
+func (c Creator) CreateScheduler() I.Scheduler {
+	return c.scheduler
+}
+
+// CreateHistoryRecorder returns a Recorder that saves every finished deployment in the DeploymentStore.
+func (c Creator) CreateHistoryRecorder() *history.Recorder {
+	return history.NewRecorder(c.CreateDeploymentStore())
+}
+
 // CreateHTTPClient return an http client.
 func (c Creator) CreateHTTPClient() *http.Client {
+	if c.provider.HTTPClient != nil {
+		return c.provider.HTTPClient
+	}
+
 	insecureClient := &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
@@ -159,35 +237,65 @@ func (c Creator) CreateHTTPClient() *http.Client {
 
 func (c Creator) CreateController() I.Controller {
 	return &controller.Controller{
-		Log: c.logger,
-		PushControllerFactory:  c.CreatePushController,
-		StopControllerFactory:  c.CreateStopController,
-		StartControllerFactory: c.CreateStartController,
-		Config:                 c.CreateConfig(),
-		EventManager:           c.CreateEventManager(),
-		ErrorFinder:            c.createErrorFinder(),
+		Log:                      c.logger,
+		PushControllerFactory:    c.CreatePushController,
+		StopControllerFactory:    c.CreateStopController,
+		StartControllerFactory:   c.CreateStartController,
+		RevertControllerFactory:  c.CreateRevertController,
+		CommandControllerFactory: c.CreateCommandController,
+		StatusControllerFactory:  c.CreateStatusController,
+		Config:                   c.CreateConfig(),
+		EventManager:             c.CreateEventManager(),
+		ErrorFinder:              c.createErrorFinder(),
+		Tracker:                  c.CreateTracker(),
+		DeploymentStore:          c.CreateDeploymentStore(),
+		IdempotencyKeys:          c.CreateIdempotencyKeys(),
+		Locker:                   c.CreateLocker(),
+		Scheduler:                c.CreateScheduler(),
 	}
 }
 
 func (c Creator) CreatePushController(log I.DeploymentLogger) I.PushController {
 	if c.provider.NewPushController != nil {
-		return c.provider.NewPushController(log, c.createDeployer(log), c.createSilentDeployer(), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c)
+		return c.provider.NewPushController(log, c.createDeployer(log), c.createSilentDeployer(), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c, c.CreateLocker())
 	}
-	return push.NewPushController(log, c.createDeployer(log), c.createSilentDeployer(), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c)
+	return push.NewPushController(log, c.createDeployer(log), c.createSilentDeployer(), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c, c.CreateLocker())
 }
 
 func (c Creator) CreateStopController(log I.DeploymentLogger) I.StopController {
 	if c.provider.NewStopController != nil {
-		return c.provider.NewStopController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c)
+		return c.provider.NewStopController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c, c.CreateLocker())
 	}
-	return stop.NewStopController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c)
+	return stop.NewStopController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c, c.CreateLocker())
 }
 
 func (c Creator) CreateStartController(log I.DeploymentLogger) I.StartController {
 	if c.provider.NewStartController != nil {
-		return c.provider.NewStartController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c)
+		return c.provider.NewStartController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c, c.CreateLocker())
+	}
+	return start.NewStartController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c, c.CreateLocker())
+}
+
+func (c Creator) CreateRevertController(log I.DeploymentLogger) I.RevertController {
+	if c.provider.NewRevertController != nil {
+		return c.provider.NewRevertController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c, c.CreateLocker())
+	}
+	return revert.NewRevertController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c, c.CreateLocker())
+}
+
+// CreateCommandController returns the controller that runs the command of the given type, such as restart.
+func (c Creator) CreateCommandController(log I.DeploymentLogger, cmd string) I.CommandController {
+	if c.provider.NewCommandController != nil {
+		return c.provider.NewCommandController(log, commands[cmd], c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c, c.CreateLocker())
 	}
-	return start.NewStartController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c)
+	return command.NewController(log, commands[cmd], c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c.createErrorFinder(), c, c.CreateLocker())
+}
+
+func (c Creator) CreateStatusController(log I.DeploymentLogger) I.StatusController {
+	if c.provider.NewStatusController != nil {
+		return c.provider.NewStatusController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c)
+	}
+	return status.NewStatusController(log, c.createDeployer(log), c.CreateConfig(), c.CreateEventManager(), c)
 }
 
 func (c Creator) createDeployer(log I.DeploymentLogger) I.Deployer {
@@ -198,6 +306,7 @@ func (c Creator) createDeployer(log I.DeploymentLogger) I.Deployer {
 		EventManager: c.CreateEventManager(),
 		Randomizer:   c.createRandomizer(),
 		ErrorFinder:  c.createErrorFinder(),
+		Secrets:      c.secrets,
 		Log:          log,
 	}
 }
@@ -214,6 +323,7 @@ func (c Creator) PushManager(log I.DeploymentLogger, deployEventData structs.Dep
 		Auth:                 auth,
 		Environment:          env,
 		EnvironmentVariables: envVars,
+		Client:               c.CreateHTTPClient(),
 	}
 }
 
@@ -236,6 +346,34 @@ func (c Creator) StartManager(log I.DeploymentLogger, deployEventData structs.De
 	}
 }
 
+func (c Creator) RevertManager(log I.DeploymentLogger, deployEventData structs.DeployEventData) I.ActionCreator {
+	return revert.RevertManager{
+		CourierCreator:  c,
+		EventManager:    c.CreateEventManager(),
+		Logger:          log,
+		DeployEventData: deployEventData,
+	}
+}
+
+func (c Creator) CommandManager(log I.DeploymentLogger, deployEventData structs.DeployEventData, cmd string) I.ActionCreator {
+	return command.Manager{
+		Command:         commands[cmd],
+		CourierCreator:  c,
+		EventManager:    c.CreateEventManager(),
+		Logger:          log,
+		DeployEventData: deployEventData,
+	}
+}
+
+func (c Creator) StatusManager(log I.DeploymentLogger, deployEventData structs.DeployEventData, report *structs.StatusReport) I.ActionCreator {
+	return &status.StatusManager{
+		CourierCreator:  c,
+		Logger:          log,
+		DeployEventData: deployEventData,
+		Report:          report,
+	}
+}
+
 func (c Creator) CreateEnvVarHandler() envvar.Envvarhandler {
 	return envvar.Envvarhandler{FileSystem: c.CreateFileSystem()}
 }
@@ -289,7 +427,8 @@ func (c Creator) createWriter() io.Writer {
 
 func (c Creator) createBlueGreener(log I.DeploymentLogger) I.BlueGreener {
 	return bluegreen.BlueGreen{
-		Log: log,
+		Log:     log,
+		Tracker: c.CreateTracker(),
 	}
 }
 
@@ -300,9 +439,12 @@ func (c Creator) createErrorFinder() I.ErrorFinder {
 }
 
 func createCreator(l logging.Level, cfg config.Config, provider CreatorModuleProvider) (Creator, error) {
-	err := ensureCLI()
-	if err != nil {
-		return Creator{}, err
+	// A custom courier, such as the Cloud Controller courier, may not need the cf CLI.
+	if provider.NewCourier == nil {
+		err := ensureCLI()
+		if err != nil {
+			return Creator{}, err
+		}
 	}
 
 	logger := I.DefaultLogger(os.Stdout, l, "controller")
@@ -313,13 +455,39 @@ func createCreator(l logging.Level, cfg config.Config, provider CreatorModulePro
 		eventManager = eventmanager.NewEventManager(logger)
 	}
 
+	fileSystem := &afero.Afero{Fs: afero.NewOsFs()}
+
+	var store I.DeploymentStore
+	if provider.NewDeploymentStore != nil {
+		store = provider.NewDeploymentStore(cfg.HistoryFile, fileSystem)
+	} else {
+		store = history.NewFileStore(cfg.HistoryFile, fileSystem)
+	}
+
+	secretProviders := secrets.Providers(os.Getenv)
+	for name, secretProvider := range provider.SecretProviders {
+		secretProviders[name] = secretProvider
+	}
+
+	environmentLimits := make(map[string]int)
+	for name, environment := range cfg.Environments {
+		environmentLimits[name] = environment.MaxConcurrent
+	}
+
 	return Creator{
 		cfg,
 		eventManager,
 		logger,
 		os.Stdout,
-		&afero.Afero{Fs: afero.NewOsFs()},
+		fileSystem,
 		provider,
+		tracker.NewTracker(),
+		store,
+		idempotency.NewKeys(),
+		locker.NewLocker(cfg.LockMode),
+		scheduler.NewScheduler(cfg.MaxConcurrent, cfg.QueueOrder, environmentLimits),
+		executor.NewSessions(),
+		secrets.NewResolver(secretProviders),
 	}, nil
 
 }
@@ -3,6 +3,7 @@ package creator
 import (
 	"os"
 
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/cloudcontroller"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"runtime"
@@ -57,4 +58,23 @@ var _ = Describe("Custom creator", func() {
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(Equal("missing environment variables: CF_USERNAME, CF_PASSWORD"))
 	})
+	Context("when the cf CLI is not installed", func() {
+		BeforeEach(func() {
+			os.Setenv("CF_USERNAME", "test user")
+			os.Setenv("CF_PASSWORD", "test pwd")
+			os.Setenv("PATH", "")
+		})
+
+		It("fails with the cf CLI courier", func() {
+			_, err := Custom("DEBUG", "./testconfig.yml", CreatorModuleProvider{})
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("creates the creator with a custom courier", func() {
+			_, err := Custom("DEBUG", "./testconfig.yml", CreatorModuleProvider{NewCourier: cloudcontroller.NewCourier})
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
 })
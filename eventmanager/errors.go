@@ -1,7 +1,21 @@
 package eventmanager
 
+import C "github.com/compozed/deployadactyl/constants"
+
 type InvalidArgumentError struct{}
 
 func (e InvalidArgumentError) Error() string {
 	return "invalid argument: error handler does not exist"
 }
+
+func (e InvalidArgumentError) Code() string {
+	return "InvalidArgumentError"
+}
+
+func (e InvalidArgumentError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e InvalidArgumentError) Retryable() bool {
+	return false
+}
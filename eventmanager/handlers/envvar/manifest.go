@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/cloudfoundry-incubator/candiedyaml"
+	C "github.com/compozed/deployadactyl/constants"
 	I "github.com/compozed/deployadactyl/interfaces"
 	"github.com/spf13/afero"
 )
@@ -57,6 +58,18 @@ func (e ManifestError) Error() string {
 	return fmt.Sprintf("cannot open or write m file: %s", e.Err)
 }
 
+func (e ManifestError) Code() string {
+	return "ManifestFileError"
+}
+
+func (e ManifestError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e ManifestError) Retryable() bool {
+	return false
+}
+
 func CreateManifest(appName string, content string, filesystem *afero.Afero, logger I.DeploymentLogger) (manifest *Manifest, err error) {
 	manifest = &Manifest{Name: appName, Yaml: content, FileSystem: filesystem, Log: logger}
 	_, err = manifest.UnMarshal()
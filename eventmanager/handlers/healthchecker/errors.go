@@ -2,6 +2,8 @@ package healthchecker
 
 import (
 	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
 )
 
 type HealthCheckError struct {
@@ -23,6 +25,18 @@ health check failed:
 	)
 }
 
+func (e HealthCheckError) Code() string {
+	return "HealthCheckError"
+}
+
+func (e HealthCheckError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e HealthCheckError) Retryable() bool {
+	return false
+}
+
 type MapRouteError struct {
 	AppName string
 	Domain  string
@@ -32,6 +46,18 @@ func (e MapRouteError) Error() string {
 	return fmt.Sprintf("could not map temporary health check route %s.%s", e.AppName, e.Domain)
 }
 
+func (e MapRouteError) Code() string {
+	return "HealthCheckMapRouteError"
+}
+
+func (e MapRouteError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e MapRouteError) Retryable() bool {
+	return true
+}
+
 type DeleteRouteError struct {
 	Domain   string
 	Hostname string
@@ -41,6 +67,18 @@ func (e DeleteRouteError) Error() string {
 	return fmt.Sprintf("could not delete temporary health check route %s.%s", e.Hostname, e.Domain)
 }
 
+func (e DeleteRouteError) Code() string {
+	return "HealthCheckDeleteRouteError"
+}
+
+func (e DeleteRouteError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e DeleteRouteError) Retryable() bool {
+	return true
+}
+
 type ClientError struct {
 	Err error
 }
@@ -49,6 +87,18 @@ func (e ClientError) Error() string {
 	return fmt.Sprintf("could not perform GET request: %s", e.Err.Error())
 }
 
+func (e ClientError) Code() string {
+	return "HealthCheckRequestError"
+}
+
+func (e ClientError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e ClientError) Retryable() bool {
+	return true
+}
+
 type LoginError struct {
 	FoundationURL string
 }
@@ -57,6 +107,18 @@ func (e LoginError) Error() string {
 	return fmt.Sprintf("could not login to %s", e.FoundationURL)
 }
 
+func (e LoginError) Code() string {
+	return "HealthCheckLoginError"
+}
+
+func (e LoginError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e LoginError) Retryable() bool {
+	return false
+}
+
 type WrongEventTypeError struct {
 	Type string
 }
@@ -64,3 +126,15 @@ type WrongEventTypeError struct {
 func (e WrongEventTypeError) Error() string {
 	return fmt.Sprintf("wrong event type for healthchecker: %s", e.Type)
 }
+
+func (e WrongEventTypeError) Code() string {
+	return "WrongEventTypeError"
+}
+
+func (e WrongEventTypeError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e WrongEventTypeError) Retryable() bool {
+	return false
+}
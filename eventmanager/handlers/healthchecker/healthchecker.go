@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
 	"github.com/compozed/deployadactyl/state/push"
 )
 
@@ -64,17 +66,26 @@ func (h HealthChecker) PushFinishedEventHandler(event push.PushFinishedEvent) er
 
 	newFoundationURL = strings.Replace(newFoundationURL, h.NewURL, fmt.Sprintf("%s.%s", event.TempAppWithUUID, h.NewURL), 1)
 
-	return h.Check(newFoundationURL, event.HealthCheckEndpoint, event.Log)
+	return h.check(newFoundationURL, event.HealthCheckEndpoint, event.HealthCheckTimeout, event.Log)
 }
 
 // Check takes a url and endpoint. It does an http.Get to get the response
 // status and returns an error if it is not http.StatusOK.
 func (h HealthChecker) Check(url, endpoint string, log I.DeploymentLogger) error {
+	return h.check(url, endpoint, 0, log)
+}
+
+// check does the same as Check, but gives up when the endpoint does not answer within the timeout.
+func (h HealthChecker) check(url, endpoint string, timeout time.Duration, log I.DeploymentLogger) error {
 	trimmedEndpoint := strings.TrimPrefix(endpoint, "/")
 
 	log.Debugf("checking route %s%s", url, endpoint)
 
-	resp, err := h.Client.Get(fmt.Sprintf("%s/%s", url, trimmedEndpoint))
+	resp, err := state.HealthCheck(h.Client, fmt.Sprintf("%s/%s", url, trimmedEndpoint), timeout)
+	if timeoutErr, ok := err.(state.HealthCheckTimeoutError); ok {
+		log.Error(timeoutErr)
+		return timeoutErr
+	}
 	if err != nil {
 		log.Error(ClientError{err})
 		return ClientError{err}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	. "github.com/compozed/deployadactyl/eventmanager/handlers/healthchecker"
 	"github.com/compozed/deployadactyl/mocks"
@@ -14,10 +15,18 @@ import (
 	. "github.com/onsi/gomega/gbytes"
 
 	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
 	"github.com/compozed/deployadactyl/state/push"
 	"github.com/op/go-logging"
 )
 
+// timeoutError is a network error that reports a timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
 var _ = Describe("Healthchecker", func() {
 
 	var (
@@ -249,6 +258,17 @@ var _ = Describe("Healthchecker", func() {
 			})
 		})
 
+		Context("when the client times out", func() {
+			It("returns a HealthCheckTimeoutError", func() {
+				ievent.HealthCheckTimeout = 5 * time.Second
+				client.GetCall.Returns.Error = timeoutError{}
+
+				err := healthchecker.PushFinishedEventHandler(ievent)
+
+				Expect(err).To(MatchError(state.HealthCheckTimeoutError{URL: client.GetCall.Received.URL, Timeout: 5 * time.Second}))
+			})
+		})
+
 		Context("when a health check endpoint is not provided", func() {
 			It("returns nil", func() {
 				ievent = push.PushFinishedEvent{
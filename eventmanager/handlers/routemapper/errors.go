@@ -1,6 +1,10 @@
 package routemapper
 
-import "fmt"
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
 
 type MapRouteError struct {
 	Route string
@@ -11,6 +15,18 @@ func (e MapRouteError) Error() string {
 	return fmt.Sprintf("failed to map route: %s: %s", e.Route, string(e.Out))
 }
 
+func (e MapRouteError) Code() string {
+	return "MapRouteError"
+}
+
+func (e MapRouteError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e MapRouteError) Retryable() bool {
+	return true
+}
+
 type InvalidRouteError struct {
 	Route string
 }
@@ -19,6 +35,18 @@ func (e InvalidRouteError) Error() string {
 	return fmt.Sprintf("invalid route provided, check that the domain exists in the foundation: %s", e.Route)
 }
 
+func (e InvalidRouteError) Code() string {
+	return "InvalidRouteError"
+}
+
+func (e InvalidRouteError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e InvalidRouteError) Retryable() bool {
+	return false
+}
+
 type ReadFileError struct {
 	Err error
 }
@@ -26,3 +54,15 @@ type ReadFileError struct {
 func (e ReadFileError) Error() string {
 	return fmt.Sprintf("failed to read manifest file: %s", e.Err.Error())
 }
+
+func (e ReadFileError) Code() string {
+	return "ManifestReadError"
+}
+
+func (e ReadFileError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e ReadFileError) Retryable() bool {
+	return false
+}
@@ -0,0 +1,49 @@
+package history
+
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
+
+type ReadError struct {
+	Path string
+	Err  error
+}
+
+func (e ReadError) Error() string {
+	return fmt.Sprintf("cannot read deployment history from %s: %s", e.Path, e.Err)
+}
+
+func (e ReadError) Code() string {
+	return "HistoryReadError"
+}
+
+func (e ReadError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e ReadError) Retryable() bool {
+	return false
+}
+
+type WriteError struct {
+	Path string
+	Err  error
+}
+
+func (e WriteError) Error() string {
+	return fmt.Sprintf("cannot write deployment history to %s: %s", e.Path, e.Err)
+}
+
+func (e WriteError) Code() string {
+	return "HistoryWriteError"
+}
+
+func (e WriteError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e WriteError) Retryable() bool {
+	return false
+}
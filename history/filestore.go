@@ -0,0 +1,228 @@
+// Package history keeps a record of every deployment once it has finished.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/spf13/afero"
+)
+
+// DefaultMaxRecords is how many records a FileStore keeps by default.
+const DefaultMaxRecords = 10000
+
+// DeploymentStoreConstructor creates the DeploymentStore that the history is kept in.
+type DeploymentStoreConstructor func(path string, fileSystem *afero.Afero) I.DeploymentStore
+
+// FileStore is a DeploymentStore that appends every record to a file as a line of JSON.
+// The file is read once and its records are kept in memory, indexed by UUID and by application.
+type FileStore struct {
+	Path       string
+	FileSystem *afero.Afero
+	// MaxRecords is how many records are kept. Once the file holds a tenth more than that,
+	// it is rewritten without the oldest records. Zero keeps every record.
+	MaxRecords int
+
+	lock    sync.Mutex
+	loaded  bool
+	records []I.DeploymentRecord
+	byUUID  map[string]int
+	byApp   map[I.CFContext][]int
+}
+
+// NewFileStore returns a FileStore that keeps the last DefaultMaxRecords records in the file at path.
+func NewFileStore(path string, fileSystem *afero.Afero) I.DeploymentStore {
+	return &FileStore{
+		Path:       path,
+		FileSystem: fileSystem,
+		MaxRecords: DefaultMaxRecords,
+	}
+}
+
+// Save appends the record to the file.
+func (s *FileStore) Save(record I.DeploymentRecord) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return WriteError{s.Path, err}
+	}
+
+	file, err := s.FileSystem.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return WriteError{s.Path, err}
+	}
+
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return WriteError{s.Path, err}
+	}
+
+	s.add(record)
+
+	if s.MaxRecords > 0 && len(s.records) > s.MaxRecords+s.MaxRecords/10 {
+		return s.rotate()
+	}
+
+	return nil
+}
+
+// Get returns the record of the deployment with the UUID.
+func (s *FileStore) Get(uuid string) (I.DeploymentRecord, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.load(); err != nil {
+		return I.DeploymentRecord{}, false, err
+	}
+
+	i, ok := s.byUUID[uuid]
+	if !ok {
+		return I.DeploymentRecord{}, false, nil
+	}
+
+	return s.records[i], true, nil
+}
+
+// Find returns the records of an application, newest first.
+func (s *FileStore) Find(cfContext I.CFContext) ([]I.DeploymentRecord, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	positions := s.byApp[application(cfContext.Environment, cfContext.Organization, cfContext.Space, cfContext.Application)]
+
+	found := []I.DeploymentRecord{}
+	for i := len(positions) - 1; i >= 0; i-- {
+		found = append(found, s.records[positions[i]])
+	}
+
+	return found, nil
+}
+
+// load reads the records of the file the first time the store is used.
+func (s *FileStore) load() error {
+	if s.loaded {
+		return nil
+	}
+
+	records, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	s.index(records)
+	s.loaded = true
+
+	return nil
+}
+
+func (s *FileStore) read() ([]I.DeploymentRecord, error) {
+	file, err := s.FileSystem.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, ReadError{s.Path, err}
+	}
+	defer file.Close()
+
+	var records []I.DeploymentRecord
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var record I.DeploymentRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, ReadError{s.Path, err}
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, ReadError{s.Path, err}
+	}
+
+	return records, nil
+}
+
+// index replaces the records in memory and indexes them.
+func (s *FileStore) index(records []I.DeploymentRecord) {
+	s.records = nil
+	s.byUUID = make(map[string]int)
+	s.byApp = make(map[I.CFContext][]int)
+
+	for _, record := range records {
+		s.add(record)
+	}
+}
+
+func (s *FileStore) add(record I.DeploymentRecord) {
+	i := len(s.records)
+	s.records = append(s.records, record)
+	s.byUUID[record.UUID] = i
+
+	key := application(record.Environment, record.Organization, record.Space, record.Application)
+	s.byApp[key] = append(s.byApp[key], i)
+}
+
+// rotate rewrites the file with only the newest MaxRecords records. The records are written to
+// a temporary file first, so that the history is not lost when the rewrite fails half way.
+func (s *FileStore) rotate() error {
+	kept := s.records[len(s.records)-s.MaxRecords:]
+
+	temporary := s.Path + ".tmp"
+	file, err := s.FileSystem.OpenFile(temporary, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return WriteError{s.Path, err}
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, record := range kept {
+		if err = encoder.Encode(record); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = s.FileSystem.Rename(temporary, s.Path)
+	}
+	if err != nil {
+		s.FileSystem.Remove(temporary)
+		return WriteError{s.Path, err}
+	}
+
+	s.index(append([]I.DeploymentRecord(nil), kept...))
+
+	return nil
+}
+
+// application is the key of the records of an application in the index.
+func application(environment, org, space, appName string) I.CFContext {
+	return I.CFContext{
+		Environment:  environment,
+		Organization: org,
+		Space:        space,
+		Application:  appName,
+	}
+}
@@ -0,0 +1,177 @@
+package history_test
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/compozed/deployadactyl/history"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/spf13/afero"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileStore", func() {
+	var (
+		store      I.DeploymentStore
+		fileSystem *afero.Afero
+		path       string
+		cfContext  I.CFContext
+	)
+
+	BeforeEach(func() {
+		fileSystem = &afero.Afero{Fs: afero.NewMemMapFs()}
+		path = "/history-" + randomizer.StringRunes(10) + ".json"
+		cfContext = I.CFContext{
+			Environment:  "environment-" + randomizer.StringRunes(10),
+			Organization: "org-" + randomizer.StringRunes(10),
+			Space:        "space-" + randomizer.StringRunes(10),
+			Application:  "appName-" + randomizer.StringRunes(10),
+		}
+
+		store = NewFileStore(path, fileSystem)
+	})
+
+	record := func(uuid string, cfContext I.CFContext) I.DeploymentRecord {
+		return I.DeploymentRecord{
+			UUID:         uuid,
+			Environment:  cfContext.Environment,
+			Organization: cfContext.Organization,
+			Space:        cfContext.Space,
+			Application:  cfContext.Application,
+			ArtifactURL:  "artifactURL-" + randomizer.StringRunes(10),
+		}
+	}
+
+	Describe("Save", func() {
+		It("appends the record to the file", func() {
+			Expect(store.Save(record("uuid-1", cfContext))).To(Succeed())
+			Expect(store.Save(record("uuid-2", cfContext))).To(Succeed())
+
+			contents, err := fileSystem.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(string(contents)).To(MatchRegexp(`^{"uuid":"uuid-1".*}\n{"uuid":"uuid-2".*}\n$`))
+		})
+
+		It("returns an error when the file cannot be written", func() {
+			store = NewFileStore(path, &afero.Afero{Fs: afero.NewReadOnlyFs(afero.NewMemMapFs())})
+
+			err := store.Save(record("uuid-1", cfContext))
+
+			Expect(err).To(BeAssignableToTypeOf(WriteError{}))
+		})
+	})
+
+	Describe("Find", func() {
+		It("returns the records of the application newest first", func() {
+			first := record("uuid-1", cfContext)
+			second := record("uuid-2", cfContext)
+			otherApplication := cfContext
+			otherApplication.Application = "otherApp-" + randomizer.StringRunes(10)
+
+			Expect(store.Save(first)).To(Succeed())
+			Expect(store.Save(record("uuid-3", otherApplication))).To(Succeed())
+			Expect(store.Save(second)).To(Succeed())
+
+			records, err := store.Find(cfContext)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(records).To(Equal([]I.DeploymentRecord{second, first}))
+		})
+
+		It("returns no records when nothing has been saved yet", func() {
+			records, err := store.Find(cfContext)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(records).To(BeEmpty())
+		})
+
+		It("returns an error when the file is corrupt", func() {
+			Expect(fileSystem.WriteFile(path, []byte("{"), 0600)).To(Succeed())
+
+			_, err := store.Find(cfContext)
+
+			Expect(err).To(BeAssignableToTypeOf(ReadError{}))
+			Expect(err.Error()).To(Equal("cannot read deployment history from " + path + ": unexpected end of JSON input"))
+		})
+	})
+
+	Describe("the records in memory", func() {
+		It("reads the records of an existing file", func() {
+			Expect(store.Save(record("uuid-1", cfContext))).To(Succeed())
+
+			_, found, err := NewFileStore(path, fileSystem).Get("uuid-1")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+		})
+
+		It("does not read the file again once it has been read", func() {
+			Expect(store.Save(record("uuid-1", cfContext))).To(Succeed())
+			Expect(fileSystem.WriteFile(path, []byte("{"), 0600)).To(Succeed())
+
+			records, err := store.Find(cfContext)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(records).To(HaveLen(1))
+		})
+	})
+
+	Describe("retention", func() {
+		BeforeEach(func() {
+			store.(*FileStore).MaxRecords = 10
+		})
+
+		It("drops the oldest records once there are a tenth more than it keeps", func() {
+			for i := 1; i <= 11; i++ {
+				Expect(store.Save(record(fmt.Sprintf("uuid-%d", i), cfContext))).To(Succeed())
+			}
+
+			records, err := store.Find(cfContext)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(records).To(HaveLen(11))
+
+			Expect(store.Save(record("uuid-12", cfContext))).To(Succeed())
+
+			records, err = store.Find(cfContext)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(records).To(HaveLen(10))
+			Expect(records[0].UUID).To(Equal("uuid-12"))
+			Expect(records[9].UUID).To(Equal("uuid-3"))
+
+			_, found, err := store.Get("uuid-2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+
+			contents, err := fileSystem.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(strings.Count(string(contents), "\n")).To(Equal(10))
+			Expect(string(contents)).To(HavePrefix(`{"uuid":"uuid-3"`))
+		})
+	})
+
+	Describe("Get", func() {
+		It("returns the record with the uuid", func() {
+			expected := record("uuid-2", cfContext)
+
+			Expect(store.Save(record("uuid-1", cfContext))).To(Succeed())
+			Expect(store.Save(expected)).To(Succeed())
+
+			actual, found, err := store.Get("uuid-2")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("does not find an unknown uuid", func() {
+			_, found, err := store.Get("uuid-1")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+	})
+})
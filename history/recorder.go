@@ -0,0 +1,192 @@
+package history
+
+import (
+	"sync"
+	"time"
+
+	C "github.com/compozed/deployadactyl/constants"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state/command"
+	"github.com/compozed/deployadactyl/state/push"
+	"github.com/compozed/deployadactyl/state/revert"
+	"github.com/compozed/deployadactyl/state/start"
+	"github.com/compozed/deployadactyl/state/stop"
+)
+
+// Recorder handles the started, success and failure events of pushes, starts, stops, reverts and commands
+// and saves a record of each of them in the Store once they have finished. The finished events, which are
+// emitted even when emitting the success or failure event failed, drop what is left of a deployment.
+type Recorder struct {
+	Store   I.DeploymentStore
+	running map[string]I.DeploymentRecord
+	lock    sync.Mutex
+}
+
+// NewRecorder returns a Recorder that saves records in the store.
+func NewRecorder(store I.DeploymentStore) *Recorder {
+	return &Recorder{
+		Store:   store,
+		running: make(map[string]I.DeploymentRecord),
+	}
+}
+
+func (r *Recorder) DeployStartedEventHandler(event push.DeployStartedEvent) error {
+	r.started(event.Log.UUID, C.DeploymentTypePush, event.CFContext, event.Auth, event.ArtifactURL)
+	return nil
+}
+
+// ArtifactRetrievalSuccessEventHandler keeps the manifest and the environment variables of a push so that it can be replayed later on.
+func (r *Recorder) ArtifactRetrievalSuccessEventHandler(event push.ArtifactRetrievalSuccessEvent) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if record, ok := r.running[event.Log.UUID]; ok {
+		record.Manifest = event.Manifest
+		record.EnvironmentVariables = event.EnvironmentVariables
+		r.running[event.Log.UUID] = record
+	}
+
+	return nil
+}
+
+func (r *Recorder) DeploySuccessEventHandler(event push.DeploySuccessEvent) error {
+	r.lock.Lock()
+	if record, ok := r.running[event.Log.UUID]; ok {
+		record.HealthCheckEndpoint = event.HealthCheckEndpoint
+		r.running[event.Log.UUID] = record
+	}
+	r.lock.Unlock()
+
+	return r.finished(event.Log.UUID, nil)
+}
+
+func (r *Recorder) DeployFailureEventHandler(event push.DeployFailureEvent) error {
+	return r.finished(event.Log.UUID, event.Error)
+}
+
+func (r *Recorder) DeployFinishedEventHandler(event push.DeployFinishedEvent) error {
+	r.forget(event.Log.UUID)
+	return nil
+}
+
+func (r *Recorder) StartStartedEventHandler(event start.StartStartedEvent) error {
+	r.started(event.Log.UUID, C.DeploymentTypeStart, event.CFContext, event.Authorization, "")
+	return nil
+}
+
+func (r *Recorder) StartSuccessEventHandler(event start.StartSuccessEvent) error {
+	return r.finished(event.Log.UUID, nil)
+}
+
+func (r *Recorder) StartFailureEventHandler(event start.StartFailureEvent) error {
+	return r.finished(event.Log.UUID, event.Error)
+}
+
+func (r *Recorder) StartFinishedEventHandler(event start.StartFinishedEvent) error {
+	r.forget(event.Log.UUID)
+	return nil
+}
+
+func (r *Recorder) StopStartedEventHandler(event stop.StopStartedEvent) error {
+	r.started(event.Log.UUID, C.DeploymentTypeStop, event.CFContext, event.Authorization, "")
+	return nil
+}
+
+func (r *Recorder) StopSuccessEventHandler(event stop.StopSuccessEvent) error {
+	return r.finished(event.Log.UUID, nil)
+}
+
+func (r *Recorder) StopFailureEventHandler(event stop.StopFailureEvent) error {
+	return r.finished(event.Log.UUID, event.Error)
+}
+
+func (r *Recorder) StopFinishedEventHandler(event stop.StopFinishedEvent) error {
+	r.forget(event.Log.UUID)
+	return nil
+}
+
+func (r *Recorder) RevertStartedEventHandler(event revert.RevertStartedEvent) error {
+	r.started(event.Log.UUID, C.DeploymentTypeRevert, event.CFContext, event.Authorization, "")
+	return nil
+}
+
+func (r *Recorder) RevertSuccessEventHandler(event revert.RevertSuccessEvent) error {
+	return r.finished(event.Log.UUID, nil)
+}
+
+func (r *Recorder) RevertFailureEventHandler(event revert.RevertFailureEvent) error {
+	return r.finished(event.Log.UUID, event.Error)
+}
+
+func (r *Recorder) RevertFinishedEventHandler(event revert.RevertFinishedEvent) error {
+	r.forget(event.Log.UUID)
+	return nil
+}
+
+func (r *Recorder) CommandStartedEventHandler(event command.StartedEvent) error {
+	r.started(event.Log.UUID, event.Type, event.CFContext, event.Authorization, "")
+	return nil
+}
+
+func (r *Recorder) CommandSuccessEventHandler(event command.SuccessEvent) error {
+	return r.finished(event.Log.UUID, nil)
+}
+
+func (r *Recorder) CommandFailureEventHandler(event command.FailureEvent) error {
+	return r.finished(event.Log.UUID, event.Error)
+}
+
+func (r *Recorder) CommandFinishedEventHandler(event command.FinishedEvent) error {
+	r.forget(event.Log.UUID)
+	return nil
+}
+
+func (r *Recorder) started(uuid, deploymentType string, cfContext I.CFContext, auth I.Authorization, artifactURL string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.running[uuid] = I.DeploymentRecord{
+		UUID:         uuid,
+		Type:         deploymentType,
+		Environment:  cfContext.Environment,
+		Organization: cfContext.Organization,
+		Space:        cfContext.Space,
+		Application:  cfContext.Application,
+		ArtifactURL:  artifactURL,
+		User:         auth.Username,
+		StartedAt:    time.Now(),
+	}
+}
+
+func (r *Recorder) finished(uuid string, err error) error {
+	defer r.forget(uuid)
+
+	r.lock.Lock()
+	record, ok := r.running[uuid]
+	r.lock.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	record.EnvironmentVariables = Redact(record.EnvironmentVariables)
+	record.Manifest = RedactManifest(record.Manifest)
+
+	record.FinishedAt = time.Now()
+	record.DurationSeconds = record.FinishedAt.Sub(record.StartedAt).Seconds()
+	record.Status = C.DeploymentStatusSucceeded
+	if err != nil {
+		record.Status = C.DeploymentStatusFailed
+		record.Error = err.Error()
+	}
+
+	return r.Store.Save(record)
+}
+
+// forget drops the deployment with the uuid from the running deployments.
+func (r *Recorder) forget(uuid string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.running, uuid)
+}
@@ -0,0 +1,240 @@
+package history_test
+
+import (
+	"errors"
+
+	C "github.com/compozed/deployadactyl/constants"
+	. "github.com/compozed/deployadactyl/history"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/compozed/deployadactyl/state/command"
+	"github.com/compozed/deployadactyl/state/push"
+	"github.com/compozed/deployadactyl/state/revert"
+	"github.com/compozed/deployadactyl/state/start"
+	"github.com/compozed/deployadactyl/state/stop"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		store     *mocks.DeploymentStore
+		recorder  *Recorder
+		log       I.DeploymentLogger
+		cfContext I.CFContext
+		auth      I.Authorization
+	)
+
+	BeforeEach(func() {
+		store = &mocks.DeploymentStore{}
+		recorder = NewRecorder(store)
+		log = I.DeploymentLogger{UUID: "uuid-" + randomizer.StringRunes(10)}
+		cfContext = I.CFContext{
+			Environment:  "environment-" + randomizer.StringRunes(10),
+			Organization: "org-" + randomizer.StringRunes(10),
+			Space:        "space-" + randomizer.StringRunes(10),
+			Application:  "appName-" + randomizer.StringRunes(10),
+		}
+		auth = I.Authorization{Username: "username-" + randomizer.StringRunes(10)}
+	})
+
+	Context("when a push succeeds", func() {
+		It("saves a succeeded record", func() {
+			Expect(recorder.DeployStartedEventHandler(push.DeployStartedEvent{CFContext: cfContext, Auth: auth, ArtifactURL: "artifactURL", Log: log})).To(Succeed())
+			Expect(store.SaveCall.Received.Records).To(BeEmpty())
+
+			Expect(recorder.DeploySuccessEventHandler(push.DeploySuccessEvent{Log: log})).To(Succeed())
+
+			Expect(store.SaveCall.Received.Records).To(HaveLen(1))
+			record := store.SaveCall.Received.Records[0]
+			Expect(record.UUID).To(Equal(log.UUID))
+			Expect(record.Type).To(Equal(C.DeploymentTypePush))
+			Expect(record.Environment).To(Equal(cfContext.Environment))
+			Expect(record.Organization).To(Equal(cfContext.Organization))
+			Expect(record.Space).To(Equal(cfContext.Space))
+			Expect(record.Application).To(Equal(cfContext.Application))
+			Expect(record.ArtifactURL).To(Equal("artifactURL"))
+			Expect(record.User).To(Equal(auth.Username))
+			Expect(record.Status).To(Equal(C.DeploymentStatusSucceeded))
+			Expect(record.FinishedAt).ToNot(BeTemporally("<", record.StartedAt))
+			Expect(record.DurationSeconds).To(BeNumerically(">=", 0))
+		})
+	})
+
+	Context("when the artifact of a push was retrieved", func() {
+		It("saves what is needed to replay the push", func() {
+			recorder.DeployStartedEventHandler(push.DeployStartedEvent{CFContext: cfContext, Auth: auth, ArtifactURL: "artifactURL", Log: log})
+
+			Expect(recorder.ArtifactRetrievalSuccessEventHandler(push.ArtifactRetrievalSuccessEvent{
+				Manifest:             "applications:\n- name: app",
+				EnvironmentVariables: map[string]string{"FOO": "bar"},
+				Log:                  log,
+			})).To(Succeed())
+			Expect(recorder.DeploySuccessEventHandler(push.DeploySuccessEvent{HealthCheckEndpoint: "/health", Log: log})).To(Succeed())
+
+			record := store.SaveCall.Received.Records[0]
+			Expect(record.Manifest).To(Equal("applications:\n- name: app"))
+			Expect(record.EnvironmentVariables).To(Equal(map[string]string{"FOO": "bar"}))
+			Expect(record.HealthCheckEndpoint).To(Equal("/health"))
+		})
+
+		It("redacts the values of environment variables that look like secrets", func() {
+			recorder.DeployStartedEventHandler(push.DeployStartedEvent{CFContext: cfContext, Auth: auth, ArtifactURL: "artifactURL", Log: log})
+			recorder.ArtifactRetrievalSuccessEventHandler(push.ArtifactRetrievalSuccessEvent{
+				EnvironmentVariables: map[string]string{"FOO": "bar", "DB_PASSWORD": "hunter2", "api_token": "abc"},
+				Log:                  log,
+			})
+
+			Expect(recorder.DeploySuccessEventHandler(push.DeploySuccessEvent{Log: log})).To(Succeed())
+
+			Expect(store.SaveCall.Received.Records[0].EnvironmentVariables).To(Equal(map[string]string{"FOO": "bar", "DB_PASSWORD": Redacted, "api_token": Redacted}))
+		})
+
+		It("redacts the values of environment variables that look like secrets in the manifest", func() {
+			recorder.DeployStartedEventHandler(push.DeployStartedEvent{CFContext: cfContext, Auth: auth, ArtifactURL: "artifactURL", Log: log})
+			recorder.ArtifactRetrievalSuccessEventHandler(push.ArtifactRetrievalSuccessEvent{
+				Manifest: "env:\n  API_TOKEN: abc\napplications:\n- name: app\n  env:\n    FOO: bar\n    DB_PASSWORD: hunter2\n",
+				Log:      log,
+			})
+
+			Expect(recorder.DeploySuccessEventHandler(push.DeploySuccessEvent{Log: log})).To(Succeed())
+
+			manifest := store.SaveCall.Received.Records[0].Manifest
+			Expect(manifest).ToNot(ContainSubstring("hunter2"))
+			Expect(manifest).ToNot(ContainSubstring("abc"))
+			Expect(manifest).To(ContainSubstring("bar"))
+			Expect(manifest).To(ContainSubstring("name: app"))
+			Expect(manifest).To(ContainSubstring(Redacted))
+		})
+
+		It("leaves out a manifest that cannot be read", func() {
+			recorder.DeployStartedEventHandler(push.DeployStartedEvent{CFContext: cfContext, Auth: auth, ArtifactURL: "artifactURL", Log: log})
+			recorder.ArtifactRetrievalSuccessEventHandler(push.ArtifactRetrievalSuccessEvent{Manifest: "applications: [", Log: log})
+
+			Expect(recorder.DeploySuccessEventHandler(push.DeploySuccessEvent{Log: log})).To(Succeed())
+
+			Expect(store.SaveCall.Received.Records[0].Manifest).To(BeEmpty())
+		})
+	})
+
+	Context("when a push fails", func() {
+		It("saves a failed record with the error", func() {
+			recorder.DeployStartedEventHandler(push.DeployStartedEvent{CFContext: cfContext, Auth: auth, Log: log})
+
+			Expect(recorder.DeployFailureEventHandler(push.DeployFailureEvent{Log: log, Error: errors.New("push failed")})).To(Succeed())
+
+			Expect(store.SaveCall.Received.Records[0].Status).To(Equal(C.DeploymentStatusFailed))
+			Expect(store.SaveCall.Received.Records[0].Error).To(Equal("push failed"))
+		})
+	})
+
+	Context("when a start finishes", func() {
+		It("saves a start record", func() {
+			recorder.StartStartedEventHandler(start.StartStartedEvent{CFContext: cfContext, Authorization: auth, Log: log})
+
+			Expect(recorder.StartFailureEventHandler(start.StartFailureEvent{Log: log, Error: errors.New("start failed")})).To(Succeed())
+
+			Expect(store.SaveCall.Received.Records[0].Type).To(Equal(C.DeploymentTypeStart))
+			Expect(store.SaveCall.Received.Records[0].User).To(Equal(auth.Username))
+			Expect(store.SaveCall.Received.Records[0].Status).To(Equal(C.DeploymentStatusFailed))
+		})
+	})
+
+	Context("when a stop finishes", func() {
+		It("saves a stop record", func() {
+			recorder.StopStartedEventHandler(stop.StopStartedEvent{CFContext: cfContext, Authorization: auth, Log: log})
+
+			Expect(recorder.StopSuccessEventHandler(stop.StopSuccessEvent{Log: log})).To(Succeed())
+
+			Expect(store.SaveCall.Received.Records[0].Type).To(Equal(C.DeploymentTypeStop))
+			Expect(store.SaveCall.Received.Records[0].Status).To(Equal(C.DeploymentStatusSucceeded))
+		})
+	})
+
+	Context("when a revert finishes", func() {
+		It("saves a revert record", func() {
+			recorder.RevertStartedEventHandler(revert.RevertStartedEvent{CFContext: cfContext, Authorization: auth, Log: log})
+
+			Expect(recorder.RevertSuccessEventHandler(revert.RevertSuccessEvent{Log: log})).To(Succeed())
+
+			Expect(store.SaveCall.Received.Records[0].Type).To(Equal(C.DeploymentTypeRevert))
+			Expect(store.SaveCall.Received.Records[0].Status).To(Equal(C.DeploymentStatusSucceeded))
+		})
+	})
+
+	Context("when a restart, a restage or a scale finishes", func() {
+		It("saves a record of each type", func() {
+			recorder.CommandStartedEventHandler(command.StartedEvent{Type: C.DeploymentTypeRestart, CFContext: cfContext, Authorization: auth, Log: log})
+			Expect(recorder.CommandSuccessEventHandler(command.SuccessEvent{Type: C.DeploymentTypeRestart, Log: log})).To(Succeed())
+
+			recorder.CommandStartedEventHandler(command.StartedEvent{Type: C.DeploymentTypeRestage, CFContext: cfContext, Authorization: auth, Log: log})
+			Expect(recorder.CommandFailureEventHandler(command.FailureEvent{Type: C.DeploymentTypeRestage, Log: log, Error: errors.New("restage failed")})).To(Succeed())
+
+			recorder.CommandStartedEventHandler(command.StartedEvent{Type: C.DeploymentTypeScale, CFContext: cfContext, Authorization: auth, Log: log})
+			Expect(recorder.CommandSuccessEventHandler(command.SuccessEvent{Type: C.DeploymentTypeScale, Log: log})).To(Succeed())
+
+			Expect(store.SaveCall.Received.Records[0].Type).To(Equal(C.DeploymentTypeRestart))
+			Expect(store.SaveCall.Received.Records[1].Type).To(Equal(C.DeploymentTypeRestage))
+			Expect(store.SaveCall.Received.Records[1].Status).To(Equal(C.DeploymentStatusFailed))
+			Expect(store.SaveCall.Received.Records[2].Type).To(Equal(C.DeploymentTypeScale))
+		})
+	})
+
+	Context("when a delete fails", func() {
+		It("saves a failed delete record", func() {
+			recorder.CommandStartedEventHandler(command.StartedEvent{Type: C.DeploymentTypeDelete, CFContext: cfContext, Authorization: auth, Log: log})
+			Expect(recorder.CommandFailureEventHandler(command.FailureEvent{Type: C.DeploymentTypeDelete, Log: log, Error: errors.New("delete failed")})).To(Succeed())
+
+			Expect(store.SaveCall.Received.Records[0].Type).To(Equal(C.DeploymentTypeDelete))
+			Expect(store.SaveCall.Received.Records[0].Status).To(Equal(C.DeploymentStatusFailed))
+			Expect(store.SaveCall.Received.Records[0].Error).To(Equal("delete failed"))
+		})
+	})
+
+	Context("when the deployment was never started", func() {
+		It("does not save anything", func() {
+			Expect(recorder.StopSuccessEventHandler(stop.StopSuccessEvent{Log: log})).To(Succeed())
+
+			Expect(store.SaveCall.Received.Records).To(BeEmpty())
+		})
+	})
+
+	Context("when the store fails", func() {
+		It("returns the error and forgets the deployment", func() {
+			store.SaveCall.Returns.Error = errors.New("store failed")
+			recorder.StartStartedEventHandler(start.StartStartedEvent{CFContext: cfContext, Log: log})
+
+			Expect(recorder.StartSuccessEventHandler(start.StartSuccessEvent{Log: log})).To(MatchError("store failed"))
+
+			store.SaveCall.Returns.Error = nil
+			Expect(recorder.StartSuccessEventHandler(start.StartSuccessEvent{Log: log})).To(Succeed())
+			Expect(store.SaveCall.Received.Records).To(HaveLen(1))
+		})
+	})
+
+	Context("when a deployment finishes without a success or failure event", func() {
+		It("forgets the deployment", func() {
+			recorder.DeployStartedEventHandler(push.DeployStartedEvent{CFContext: cfContext, Auth: auth, Log: log})
+			Expect(recorder.DeployFinishedEventHandler(push.DeployFinishedEvent{Log: log})).To(Succeed())
+
+			Expect(recorder.DeploySuccessEventHandler(push.DeploySuccessEvent{Log: log})).To(Succeed())
+			Expect(store.SaveCall.Received.Records).To(BeEmpty())
+		})
+
+		It("forgets starts, stops, reverts and commands", func() {
+			recorder.StartStartedEventHandler(start.StartStartedEvent{CFContext: cfContext, Log: log})
+			recorder.StartFinishedEventHandler(start.StartFinishedEvent{Log: log})
+			recorder.StopStartedEventHandler(stop.StopStartedEvent{CFContext: cfContext, Log: log})
+			recorder.StopFinishedEventHandler(stop.StopFinishedEvent{Log: log})
+			recorder.RevertStartedEventHandler(revert.RevertStartedEvent{CFContext: cfContext, Log: log})
+			recorder.RevertFinishedEventHandler(revert.RevertFinishedEvent{Log: log})
+			recorder.CommandStartedEventHandler(command.StartedEvent{Type: C.DeploymentTypeRestart, CFContext: cfContext, Log: log})
+			recorder.CommandFinishedEventHandler(command.FinishedEvent{Type: C.DeploymentTypeRestart, Log: log})
+
+			Expect(recorder.CommandSuccessEventHandler(command.SuccessEvent{Log: log})).To(Succeed())
+			Expect(store.SaveCall.Received.Records).To(BeEmpty())
+		})
+	})
+})
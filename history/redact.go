@@ -0,0 +1,82 @@
+package history
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/candiedyaml"
+)
+
+// Redacted replaces the values of environment variables that look like secrets in the history.
+const Redacted = "[REDACTED]"
+
+var secretName = regexp.MustCompile(`(?i)pass|secret|token|key|credential|private|auth`)
+
+// Redact returns a copy of the environment variables in which the values of those whose name
+// looks like that of a secret, such as DB_PASSWORD or API_TOKEN, are replaced with Redacted.
+func Redact(environmentVariables map[string]string) map[string]string {
+	if environmentVariables == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(environmentVariables))
+	for name, value := range environmentVariables {
+		if secretName.MatchString(name) {
+			value = Redacted
+		}
+		redacted[name] = value
+	}
+
+	return redacted
+}
+
+// RedactManifest returns the manifest with the values of the env blocks, at its top and in each of its
+// applications, redacted the same way. A manifest without secrets is returned as it is, and a manifest
+// that cannot be read is left out, since its secrets cannot be found.
+func RedactManifest(manifest string) string {
+	if manifest == "" {
+		return ""
+	}
+
+	var content map[interface{}]interface{}
+	if err := candiedyaml.Unmarshal([]byte(manifest), &content); err != nil {
+		return ""
+	}
+
+	redacted := redactEnv(content)
+	if applications, ok := content["applications"].([]interface{}); ok {
+		for _, application := range applications {
+			if application, ok := application.(map[interface{}]interface{}); ok {
+				redacted = redactEnv(application) || redacted
+			}
+		}
+	}
+
+	if !redacted {
+		return manifest
+	}
+
+	out, err := candiedyaml.Marshal(content)
+	if err != nil {
+		return ""
+	}
+
+	return string(out)
+}
+
+func redactEnv(block map[interface{}]interface{}) bool {
+	env, ok := block["env"].(map[interface{}]interface{})
+	if !ok {
+		return false
+	}
+
+	redacted := false
+	for name := range env {
+		if secretName.MatchString(fmt.Sprint(name)) {
+			env[name] = Redacted
+			redacted = true
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,48 @@
+package idempotency
+
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
+
+type KeyReusedError struct {
+	Key string
+}
+
+func (e KeyReusedError) Error() string {
+	return fmt.Sprintf("idempotency key %s was already used for a different request", e.Key)
+}
+
+func (e KeyReusedError) Code() string {
+	return "IdempotencyKeyReusedError"
+}
+
+func (e KeyReusedError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e KeyReusedError) Retryable() bool {
+	return false
+}
+
+type KeyExpiredError struct {
+	UUID string
+}
+
+func (e KeyExpiredError) Error() string {
+	return fmt.Sprintf("the result of deployment %s is no longer kept for its idempotency key", e.UUID)
+}
+
+func (e KeyExpiredError) Code() string {
+	return "IdempotencyKeyExpiredError"
+}
+
+func (e KeyExpiredError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+// Retryable is true because the key is free again, so sending the request once more runs it.
+func (e KeyExpiredError) Retryable() bool {
+	return true
+}
@@ -0,0 +1,131 @@
+// Package idempotency lets a client send the same deployment request more than once without it running more than once.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+// DefaultRetention is how long the result of a finished request is kept for its key.
+const DefaultRetention = 24 * time.Hour
+
+// Keys holds the request and the result behind every Idempotency-Key. Keys are scoped to the user
+// that sent them, so that the same key sent by two users names two different requests.
+type Keys struct {
+	Retention time.Duration
+	keys      map[scope]*claim
+	claims    map[string]*claim
+	lock      sync.Mutex
+}
+
+type scope struct {
+	user string
+	key  string
+}
+
+type claim struct {
+	fingerprint    string
+	uuid           string
+	done           chan struct{}
+	deployResponse I.DeployResponse
+	output         string
+	finishedAt     *time.Time
+}
+
+// NewKeys returns Keys that keep finished requests for the DefaultRetention.
+func NewKeys() *Keys {
+	return &Keys{
+		Retention: DefaultRetention,
+		keys:      make(map[scope]*claim),
+		claims:    make(map[string]*claim),
+	}
+}
+
+// Fingerprint identifies a request by its method, path and body.
+func Fingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Claim registers the deployment with the given uuid for the key of the user and returns uuid.
+// When the user claimed the key before, the uuid of the deployment that claimed it is returned instead,
+// or a KeyReusedError when it was claimed by a different request.
+func (k *Keys) Claim(user, key, fingerprint, uuid string) (string, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	now := time.Now()
+	for id, c := range k.keys {
+		if c.finishedAt != nil && now.Sub(*c.finishedAt) > k.Retention {
+			delete(k.keys, id)
+			delete(k.claims, c.uuid)
+		}
+	}
+
+	s := scope{user: user, key: key}
+	if c, ok := k.keys[s]; ok {
+		if c.fingerprint != fingerprint {
+			return "", KeyReusedError{Key: key}
+		}
+		return c.uuid, nil
+	}
+
+	c := &claim{
+		fingerprint: fingerprint,
+		uuid:        uuid,
+		done:        make(chan struct{}),
+	}
+	k.keys[s] = c
+	k.claims[uuid] = c
+
+	return uuid, nil
+}
+
+// Finish records the result of the deployment with the given uuid and releases everyone waiting for it.
+func (k *Keys) Finish(uuid string, deployResponse I.DeployResponse, output string) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	c, ok := k.claims[uuid]
+	if !ok || c.finishedAt != nil {
+		return
+	}
+
+	now := time.Now()
+	c.deployResponse = deployResponse
+	c.output = output
+	c.finishedAt = &now
+	close(c.done)
+}
+
+// Wait blocks until the deployment with the given uuid has finished and returns its result.
+// It returns the error of the context when the context is done first, and a KeyExpiredError when
+// the result is no longer kept.
+func (k *Keys) Wait(ctx context.Context, uuid string) (I.DeployResponse, string, error) {
+	k.lock.Lock()
+	c, ok := k.claims[uuid]
+	k.lock.Unlock()
+
+	if !ok {
+		return I.DeployResponse{}, "", KeyExpiredError{UUID: uuid}
+	}
+
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		return I.DeployResponse{}, "", ctx.Err()
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	return c.deployResponse, c.output, nil
+}
@@ -0,0 +1,140 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/compozed/deployadactyl/idempotency"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/randomizer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Keys", func() {
+	var (
+		keys        *Keys
+		key         string
+		uuid        string
+		fingerprint string
+	)
+
+	BeforeEach(func() {
+		key = "key-" + randomizer.StringRunes(10)
+		uuid = "uuid-" + randomizer.StringRunes(10)
+		fingerprint = Fingerprint("POST", "/v3/apps/env/org/space/app", []byte("body-"+randomizer.StringRunes(10)))
+
+		keys = NewKeys()
+	})
+
+	Describe("Fingerprint", func() {
+		It("is the same for the same request", func() {
+			Expect(Fingerprint("POST", "/path", []byte("body"))).To(Equal(Fingerprint("POST", "/path", []byte("body"))))
+		})
+
+		It("differs when the body differs", func() {
+			Expect(Fingerprint("POST", "/path", []byte("body"))).ToNot(Equal(Fingerprint("POST", "/path", []byte("other body"))))
+		})
+
+		It("differs when the path differs", func() {
+			Expect(Fingerprint("POST", "/path", []byte("body"))).ToNot(Equal(Fingerprint("POST", "/other/path", []byte("body"))))
+		})
+	})
+
+	Describe("Claim", func() {
+		It("returns the uuid of the first request", func() {
+			owner, err := keys.Claim("user", key, fingerprint, uuid)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(owner).To(Equal(uuid))
+		})
+
+		It("returns the uuid of the first request to a repeated request", func() {
+			keys.Claim("user", key, fingerprint, uuid)
+
+			owner, err := keys.Claim("user", key, fingerprint, "another uuid")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(owner).To(Equal(uuid))
+		})
+
+		It("rejects a different request with the same key", func() {
+			keys.Claim("user", key, fingerprint, uuid)
+
+			_, err := keys.Claim("user", key, "another fingerprint", "another uuid")
+
+			Expect(err).To(MatchError(KeyReusedError{Key: key}))
+		})
+
+		It("keeps the keys of different users apart", func() {
+			keys.Claim("user", key, fingerprint, uuid)
+
+			owner, err := keys.Claim("another user", key, "another fingerprint", "another uuid")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(owner).To(Equal("another uuid"))
+		})
+
+		It("forgets keys that finished longer ago than the retention", func() {
+			keys.Retention = time.Nanosecond
+			keys.Claim("user", key, fingerprint, uuid)
+			keys.Finish(uuid, I.DeployResponse{StatusCode: http.StatusOK}, "output")
+			time.Sleep(time.Millisecond)
+
+			owner, err := keys.Claim("user", key, "another fingerprint", "another uuid")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(owner).To(Equal("another uuid"))
+		})
+	})
+
+	Describe("Wait", func() {
+		It("returns the result once the request has finished", func() {
+			keys.Claim("user", key, fingerprint, uuid)
+
+			results := make(chan string)
+			go func() {
+				deployResponse, output, _ := keys.Wait(context.Background(), uuid)
+				results <- fmt.Sprintf("%d %s %s", deployResponse.StatusCode, deployResponse.Error, output)
+			}()
+
+			Consistently(results).ShouldNot(Receive())
+
+			keys.Finish(uuid, I.DeployResponse{StatusCode: http.StatusBadGateway, Error: errors.New("push failed")}, "output")
+
+			Eventually(results).Should(Receive(Equal("502 push failed output")))
+		})
+
+		It("returns an error for deployments that never claimed a key", func() {
+			_, _, err := keys.Wait(context.Background(), uuid)
+
+			Expect(err).To(MatchError(KeyExpiredError{UUID: uuid}))
+		})
+
+		It("returns an error once the result is no longer kept", func() {
+			keys.Retention = time.Nanosecond
+			keys.Claim("user", key, fingerprint, uuid)
+			keys.Finish(uuid, I.DeployResponse{StatusCode: http.StatusOK}, "output")
+			time.Sleep(time.Millisecond)
+			keys.Claim("another user", key, fingerprint, "another uuid")
+
+			_, _, err := keys.Wait(context.Background(), uuid)
+
+			Expect(err).To(MatchError(KeyExpiredError{UUID: uuid}))
+		})
+
+		It("stops waiting when the context is done", func() {
+			keys.Claim("user", key, fingerprint, uuid)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			_, _, err := keys.Wait(ctx, uuid)
+
+			Expect(err).To(Equal(context.DeadlineExceeded))
+		})
+	})
+})
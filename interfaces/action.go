@@ -1,8 +1,10 @@
 package interfaces
 
 import (
-	S "github.com/compozed/deployadactyl/structs"
+	"context"
 	"io"
+
+	S "github.com/compozed/deployadactyl/structs"
 )
 
 type Action interface {
@@ -14,12 +16,25 @@ type Action interface {
 	Finally() error
 }
 
+// Planner is an Action that can tell what it would do on its foundation without doing it.
+// It is called after Initially, for a dry run.
+type Planner interface {
+	Plan() (FoundationPlan, error)
+}
+
+// FoundationPlan is what a deployment would do on a foundation.
+type FoundationPlan struct {
+	FoundationURL string   `json:"foundation_url"`
+	AppExists     bool     `json:"app_exists"`
+	Steps         []string `json:"steps"`
+}
+
 type ActionCreator interface {
 	SetUp() error
 	CleanUp()
 	OnStart() error
 	OnFinish(environment S.Environment, response io.ReadWriter, err error) DeployResponse
-	Create(environment S.Environment, response io.ReadWriter, foundationURL string) (Action, error)
+	Create(ctx context.Context, environment S.Environment, response io.ReadWriter, foundationURL string) (Action, error)
 	InitiallyError(initiallyErrors []error) error
 	ExecuteError(executeErrors []error) error
 	UndoError(executeErrors, undoErrors []error) error
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"bytes"
+
+	"github.com/compozed/deployadactyl/structs"
+)
+
+// CommandManagerFactory creates the ActionCreator of a command, such as restart, by its type.
+type CommandManagerFactory interface {
+	CommandManager(log DeploymentLogger, deployEventData structs.DeployEventData, command string) ActionCreator
+}
+
+// CommandController runs a single cf command, such as restart or scale, against an application on every foundation.
+type CommandController interface {
+	RunCommand(deployment *Deployment, data map[string]interface{}, response *bytes.Buffer) (deployResponse DeployResponse)
+}
@@ -2,6 +2,8 @@ package interfaces
 
 import (
 	"bytes"
+	"context"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,6 +17,8 @@ type Deployment struct {
 	Type          DeploymentType
 	Authorization Authorization
 	CFContext     CFContext
+	Context       context.Context
+	DryRun        bool
 }
 
 type Authorization struct {
@@ -23,11 +27,12 @@ type Authorization struct {
 }
 
 type CFContext struct {
-	Environment  string
-	Organization string
-	Space        string
-	Application  string
-	SkipSSL      bool
+	Environment       string
+	Organization      string
+	Space             string
+	Application       string
+	SkipSSL           bool
+	ClientCredentials bool
 }
 
 type Controller interface {
@@ -36,4 +41,18 @@ type Controller interface {
 	RunDeploymentViaHttp(g *gin.Context)
 
 	PutRequestHandler(g *gin.Context)
+
+	DeleteRequestHandler(g *gin.Context)
+
+	GetDeploymentStatus(g *gin.Context)
+
+	CancelDeployment(g *gin.Context)
+
+	GetDeploymentHistory(g *gin.Context)
+
+	GetAppStatus(g *gin.Context)
+
+	GetAppDrift(g *gin.Context)
+
+	GetAppLock(g *gin.Context)
 }
@@ -1,8 +1,10 @@
 package interfaces
 
+import S "github.com/compozed/deployadactyl/structs"
+
 // Courier interface.
 type Courier interface {
-	Login(foundationURL, username, password, org, space string, skipSSL bool) ([]byte, error)
+	Login(foundationURL, username, password, org, space string, skipSSL, clientCredentials bool) ([]byte, error)
 	Delete(appName string) ([]byte, error)
 	Push(appName, appLocation, hostname string, instances uint16) ([]byte, error)
 	Rename(oldName, newName string) ([]byte, error)
@@ -15,11 +17,17 @@ type Courier interface {
 	BindService(appName, serviceName string) ([]byte, error)
 	UnbindService(appName, serviceName string) ([]byte, error)
 	DeleteService(serviceName string) ([]byte, error)
+	UserProvidedServices() (map[string][]string, error)
 	Start(appName string) ([]byte, error)
 	Stop(appName string) ([]byte, error)
+	Scale(appName string, instances uint16) ([]byte, error)
+	ScaleMemory(appName, memory string) ([]byte, error)
+	Restart(appName string) ([]byte, error)
 	Restage(appName string) ([]byte, error)
 	Logs(appName string) ([]byte, error)
 	Exists(appName string) bool
+	AppStatus(appName string) (S.AppStatus, error)
+	EnvironmentVariables(appName string) (map[string]string, error)
 	Cups(appName string, body string) ([]byte, error)
 	Uups(appName string, body string) ([]byte, error)
 	Domains() ([]string, error)
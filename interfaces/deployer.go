@@ -1,6 +1,7 @@
 package interfaces
 
 import (
+	"context"
 	"io"
 
 	"github.com/compozed/deployadactyl/structs"
@@ -10,11 +11,13 @@ type DeployResponse struct {
 	StatusCode     int
 	DeploymentInfo *structs.DeploymentInfo
 	Error          error
+	Plan           []FoundationPlan
 }
 
 // Deployer interface.
 type Deployer interface {
 	Deploy(
+		ctx context.Context,
 		deploymentInfo *structs.DeploymentInfo,
 		environment structs.Environment,
 		actionCreator ActionCreator,
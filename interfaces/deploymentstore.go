@@ -0,0 +1,30 @@
+package interfaces
+
+import "time"
+
+// DeploymentRecord is what is kept about a deployment once it has finished.
+type DeploymentRecord struct {
+	UUID                 string            `json:"uuid"`
+	Type                 string            `json:"type"`
+	Environment          string            `json:"environment"`
+	Organization         string            `json:"organization"`
+	Space                string            `json:"space"`
+	Application          string            `json:"application"`
+	ArtifactURL          string            `json:"artifact_url,omitempty"`
+	Manifest             string            `json:"manifest,omitempty"`
+	EnvironmentVariables map[string]string `json:"environment_variables,omitempty"`
+	HealthCheckEndpoint  string            `json:"health_check_endpoint,omitempty"`
+	User                 string            `json:"user"`
+	Status               string            `json:"status"`
+	Error                string            `json:"error,omitempty"`
+	StartedAt            time.Time         `json:"started_at"`
+	FinishedAt           time.Time         `json:"finished_at"`
+	DurationSeconds      float64           `json:"duration_seconds"`
+}
+
+// DeploymentStore interface.
+type DeploymentStore interface {
+	Save(record DeploymentRecord) error
+	Get(uuid string) (DeploymentRecord, bool, error)
+	Find(cfContext CFContext) ([]DeploymentRecord, error)
+}
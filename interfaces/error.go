@@ -1,7 +1,11 @@
 package interfaces
 
+// DeploymentError is an error with a stable code, the category of what caused it
+// and whether the same request can succeed when it is sent again.
 type DeploymentError interface {
 	Code() string
+	Category() string
+	Retryable() bool
 	Error() string
 }
 
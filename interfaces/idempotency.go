@@ -0,0 +1,10 @@
+package interfaces
+
+import "context"
+
+// IdempotencyKeys remembers the deployment each Idempotency-Key of a user started and its result.
+type IdempotencyKeys interface {
+	Claim(user, key, fingerprint, uuid string) (owner string, err error)
+	Finish(uuid string, deployResponse DeployResponse, output string)
+	Wait(ctx context.Context, uuid string) (deployResponse DeployResponse, output string, err error)
+}
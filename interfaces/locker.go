@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+// LockHolder is the deployment that holds the lock of an application.
+type LockHolder struct {
+	UUID  string    `json:"uuid"`
+	Type  string    `json:"type"`
+	User  string    `json:"user"`
+	Since time.Time `json:"since"`
+}
+
+// Locker interface.
+type Locker interface {
+	Lock(ctx context.Context, cfContext CFContext, holder LockHolder) (unlock func(), err error)
+	Holder(cfContext CFContext) (LockHolder, bool)
+}
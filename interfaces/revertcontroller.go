@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"bytes"
+
+	"github.com/compozed/deployadactyl/structs"
+)
+
+type RevertManagerFactory interface {
+	RevertManager(log DeploymentLogger, deployEventData structs.DeployEventData) ActionCreator
+}
+
+type RevertController interface {
+	RevertDeployment(deployment *Deployment, data map[string]interface{}, response *bytes.Buffer) (deployResponse DeployResponse)
+}
@@ -0,0 +1,7 @@
+package interfaces
+
+// Scheduler interface.
+type Scheduler interface {
+	Enqueue(uuid, environment string, priority int) (ready <-chan struct{}, done func())
+	Position(uuid string) int
+}
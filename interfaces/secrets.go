@@ -0,0 +1,14 @@
+package interfaces
+
+import S "github.com/compozed/deployadactyl/structs"
+
+// SecretProvider looks up secrets, such as the passwords of service accounts, in a secret store.
+type SecretProvider interface {
+	// Secret returns the secret stored under the key at the path.
+	Secret(path, key string) (string, error)
+}
+
+// SecretResolver interface.
+type SecretResolver interface {
+	ResolveCredentials(environment S.Environment) (S.Environment, error)
+}
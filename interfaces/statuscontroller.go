@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"bytes"
+
+	"github.com/compozed/deployadactyl/structs"
+)
+
+type StatusManagerFactory interface {
+	StatusManager(log DeploymentLogger, deployEventData structs.DeployEventData, report *structs.StatusReport) ActionCreator
+}
+
+type StatusController interface {
+	AppStatus(deployment *Deployment, response *bytes.Buffer) (report structs.StatusReport, deployResponse DeployResponse)
+	Drift(deployment *Deployment, response *bytes.Buffer) (report structs.DriftReport, deployResponse DeployResponse)
+}
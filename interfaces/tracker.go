@@ -0,0 +1,51 @@
+package interfaces
+
+import "time"
+
+// DeploymentStatus is a snapshot of a deployment that is running or has finished.
+type DeploymentStatus struct {
+	UUID          string                      `json:"uuid"`
+	Environment   string                      `json:"environment"`
+	Organization  string                      `json:"organization"`
+	Space         string                      `json:"space"`
+	Application   string                      `json:"application"`
+	Status        string                      `json:"status"`
+	Phase         string                      `json:"phase"`
+	QueuePosition int                         `json:"queue_position,omitempty"`
+	Foundations   map[string]FoundationStatus `json:"foundations"`
+	StartedAt     time.Time                   `json:"started_at"`
+	FinishedAt    *time.Time                  `json:"finished_at,omitempty"`
+	Result        *DeploymentResult           `json:"result,omitempty"`
+}
+
+// FoundationStatus is the last phase a foundation went through and the error it returned, if any.
+type FoundationStatus struct {
+	Phase string `json:"phase"`
+	Error string `json:"error,omitempty"`
+}
+
+// DeploymentResult is the final DeployResponse of a deployment along with its output.
+type DeploymentResult struct {
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error,omitempty"`
+	Output     string `json:"output"`
+}
+
+// OutputLine is a line of Cloud Foundry output from a foundation.
+type OutputLine struct {
+	FoundationURL string `json:"foundation"`
+	Line          string `json:"line"`
+}
+
+// Tracker interface.
+type Tracker interface {
+	Start(uuid string, cfContext CFContext, authorization Authorization)
+	Authorized(uuid string, authorization Authorization) bool
+	SetPhase(uuid, phase string)
+	SetFoundationStatus(uuid, foundationURL, phase string, err error)
+	Finish(uuid string, deployResponse DeployResponse, output string)
+	Get(uuid string) (DeploymentStatus, bool)
+	Publish(uuid string, line OutputLine)
+	Hold(uuid string) (release func())
+	Subscribe(uuid string) (lines <-chan OutputLine, unsubscribe func())
+}
@@ -0,0 +1,32 @@
+package locker
+
+import (
+	"fmt"
+	"time"
+
+	C "github.com/compozed/deployadactyl/constants"
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+type LockedError struct {
+	CFContext I.CFContext
+	Holder    I.LockHolder
+}
+
+func (e LockedError) Error() string {
+	return fmt.Sprintf("%s in %s/%s/%s is locked by %s %s of %s since %s",
+		e.CFContext.Application, e.CFContext.Environment, e.CFContext.Organization, e.CFContext.Space,
+		e.Holder.Type, e.Holder.UUID, e.Holder.User, e.Holder.Since.Format(time.RFC3339))
+}
+
+func (e LockedError) Code() string {
+	return "ApplicationLockedError"
+}
+
+func (e LockedError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e LockedError) Retryable() bool {
+	return true
+}
@@ -0,0 +1,115 @@
+// Package locker keeps deployments of the same application from running at the same time.
+package locker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+// Locker holds a lock for every application that a deployment is running against.
+// In the LockModeQueue mode a deployment waits for the lock, otherwise it is rejected.
+type Locker struct {
+	Mode  string
+	locks map[string]*appLock
+	lock  sync.Mutex
+}
+
+type appLock struct {
+	holder   I.LockHolder
+	released chan struct{}
+}
+
+// NewLocker returns a Locker in the given mode.
+func NewLocker(mode string) *Locker {
+	return &Locker{
+		Mode:  mode,
+		locks: make(map[string]*appLock),
+	}
+}
+
+// Lock takes the lock of the application for the holder and returns the function that releases it.
+// When another deployment holds the lock, Lock waits for it in the LockModeQueue mode until the lock is
+// released or the context is done, and returns a LockedError otherwise. A deployment that already holds
+// the lock gets it again with a release that does nothing, and its holder is updated.
+func (l *Locker) Lock(ctx context.Context, cfContext I.CFContext, holder I.LockHolder) (func(), error) {
+	key := lockKey(cfContext)
+
+	var cancelled <-chan struct{}
+	if ctx != nil {
+		cancelled = ctx.Done()
+	}
+
+	l.lock.Lock()
+	for {
+		current, ok := l.locks[key]
+		if !ok {
+			break
+		}
+
+		if current.holder.UUID == holder.UUID {
+			holder.Since = current.holder.Since
+			current.holder = holder
+			l.lock.Unlock()
+			return func() {}, nil
+		}
+
+		if l.Mode != C.LockModeQueue {
+			l.lock.Unlock()
+			return nil, LockedError{CFContext: cfContext, Holder: current.holder}
+		}
+
+		l.lock.Unlock()
+		select {
+		case <-current.released:
+		case <-cancelled:
+			return nil, bluegreen.CancelledError{}
+		}
+		l.lock.Lock()
+	}
+
+	holder.Since = time.Now()
+	acquired := &appLock{holder: holder, released: make(chan struct{})}
+	l.locks[key] = acquired
+	l.lock.Unlock()
+
+	var once sync.Once
+	unlock := func() {
+		once.Do(func() {
+			l.lock.Lock()
+			defer l.lock.Unlock()
+
+			delete(l.locks, key)
+			close(acquired.released)
+		})
+	}
+
+	return unlock, nil
+}
+
+// Holder returns the deployment that holds the lock of the application, if any.
+func (l *Locker) Holder(cfContext I.CFContext) (I.LockHolder, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	current, ok := l.locks[lockKey(cfContext)]
+	if !ok {
+		return I.LockHolder{}, false
+	}
+
+	return current.holder, true
+}
+
+func lockKey(cfContext I.CFContext) string {
+	return strings.Join([]string{
+		strings.ToLower(cfContext.Environment),
+		cfContext.Organization,
+		cfContext.Space,
+		cfContext.Application,
+	}, "/")
+}
@@ -0,0 +1,144 @@
+package locker_test
+
+import (
+	"context"
+	"time"
+
+	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	. "github.com/compozed/deployadactyl/locker"
+	"github.com/compozed/deployadactyl/randomizer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Locker", func() {
+	var (
+		locker    *Locker
+		cfContext I.CFContext
+		holder    I.LockHolder
+	)
+
+	BeforeEach(func() {
+		cfContext = I.CFContext{
+			Environment:  "environment-" + randomizer.StringRunes(10),
+			Organization: "org-" + randomizer.StringRunes(10),
+			Space:        "space-" + randomizer.StringRunes(10),
+			Application:  "appName-" + randomizer.StringRunes(10),
+		}
+		holder = I.LockHolder{UUID: "uuid-" + randomizer.StringRunes(10), Type: C.DeploymentTypePush, User: "user"}
+
+		locker = NewLocker(C.LockModeReject)
+	})
+
+	It("lets one deployment hold the lock of an application", func() {
+		unlock, err := locker.Lock(context.Background(), cfContext, holder)
+		Expect(err).ToNot(HaveOccurred())
+
+		current, ok := locker.Holder(cfContext)
+		Expect(ok).To(BeTrue())
+		Expect(current.UUID).To(Equal(holder.UUID))
+		Expect(current.Type).To(Equal(C.DeploymentTypePush))
+		Expect(current.User).To(Equal("user"))
+		Expect(current.Since).ToNot(BeZero())
+
+		unlock()
+
+		_, ok = locker.Holder(cfContext)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("does not lock other applications", func() {
+		locker.Lock(context.Background(), cfContext, holder)
+
+		other := cfContext
+		other.Application = "another app"
+
+		_, err := locker.Lock(context.Background(), other, holder)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("lets the lock be released more than once", func() {
+		unlock, _ := locker.Lock(context.Background(), cfContext, holder)
+		unlock()
+
+		second, _ := locker.Lock(context.Background(), cfContext, holder)
+		unlock()
+
+		_, ok := locker.Holder(cfContext)
+		Expect(ok).To(BeTrue())
+		second()
+	})
+
+	It("lets the deployment that holds the lock take it again", func() {
+		unlock, _ := locker.Lock(context.Background(), cfContext, holder)
+
+		again, err := locker.Lock(context.Background(), cfContext, I.LockHolder{UUID: holder.UUID, Type: C.DeploymentTypeStop, User: "another user"})
+		Expect(err).ToNot(HaveOccurred())
+
+		current, _ := locker.Holder(cfContext)
+		Expect(current.Type).To(Equal(C.DeploymentTypeStop))
+		Expect(current.User).To(Equal("another user"))
+
+		again()
+		_, ok := locker.Holder(cfContext)
+		Expect(ok).To(BeTrue())
+
+		unlock()
+		_, ok = locker.Holder(cfContext)
+		Expect(ok).To(BeFalse())
+	})
+
+	Context("when the mode is reject", func() {
+		It("rejects a deployment of a locked application", func() {
+			locker.Lock(context.Background(), cfContext, holder)
+			current, _ := locker.Holder(cfContext)
+
+			_, err := locker.Lock(context.Background(), cfContext, I.LockHolder{UUID: "another uuid", Type: C.DeploymentTypeStop})
+
+			Expect(err).To(MatchError(LockedError{CFContext: cfContext, Holder: current}))
+		})
+	})
+
+	Context("when the mode is queue", func() {
+		It("waits for the lock to be released", func() {
+			locker.Mode = C.LockModeQueue
+			unlock, _ := locker.Lock(context.Background(), cfContext, holder)
+
+			acquired := make(chan string)
+			go func() {
+				defer GinkgoRecover()
+
+				next, err := locker.Lock(context.Background(), cfContext, I.LockHolder{UUID: "another uuid", Type: C.DeploymentTypeStop})
+				Expect(err).ToNot(HaveOccurred())
+
+				current, _ := locker.Holder(cfContext)
+				next()
+				acquired <- current.UUID
+			}()
+
+			Consistently(acquired).ShouldNot(Receive())
+
+			unlock()
+
+			Eventually(acquired).Should(Receive(Equal("another uuid")))
+		})
+
+		It("stops waiting when the context is done", func() {
+			locker.Mode = C.LockModeQueue
+			unlock, _ := locker.Lock(context.Background(), cfContext, holder)
+			defer unlock()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			_, err := locker.Lock(ctx, cfContext, I.LockHolder{UUID: "another uuid", Type: C.DeploymentTypeStop})
+
+			Expect(err).To(MatchError(bluegreen.CancelledError{}))
+			current, _ := locker.Holder(cfContext)
+			Expect(current.UUID).To(Equal(holder.UUID))
+		})
+	})
+})
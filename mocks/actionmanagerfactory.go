@@ -78,3 +78,70 @@ func (t *StartManagerFactory) StartManager(log interfaces.DeploymentLogger, Depl
 
 	return t.StartManagerCall.Returns.ActionCreater
 }
+
+type RevertManagerFactory struct {
+	RevertManagerCall struct {
+		Called   bool
+		Received struct {
+			Log             interfaces.DeploymentLogger
+			DeployEventData structs.DeployEventData
+		}
+		Returns struct {
+			ActionCreator interfaces.ActionCreator
+		}
+	}
+}
+
+func (r *RevertManagerFactory) RevertManager(log interfaces.DeploymentLogger, deployEventData structs.DeployEventData) interfaces.ActionCreator {
+	r.RevertManagerCall.Called = true
+	r.RevertManagerCall.Received.Log = log
+	r.RevertManagerCall.Received.DeployEventData = deployEventData
+
+	return r.RevertManagerCall.Returns.ActionCreator
+}
+
+type CommandManagerFactory struct {
+	CommandManagerCall struct {
+		Called   bool
+		Received struct {
+			Log             interfaces.DeploymentLogger
+			DeployEventData structs.DeployEventData
+			Command         string
+		}
+		Returns struct {
+			ActionCreator interfaces.ActionCreator
+		}
+	}
+}
+
+func (c *CommandManagerFactory) CommandManager(log interfaces.DeploymentLogger, deployEventData structs.DeployEventData, command string) interfaces.ActionCreator {
+	c.CommandManagerCall.Called = true
+	c.CommandManagerCall.Received.Log = log
+	c.CommandManagerCall.Received.DeployEventData = deployEventData
+	c.CommandManagerCall.Received.Command = command
+
+	return c.CommandManagerCall.Returns.ActionCreator
+}
+
+type StatusManagerFactory struct {
+	StatusManagerCall struct {
+		Called   bool
+		Received struct {
+			Log             interfaces.DeploymentLogger
+			DeployEventData structs.DeployEventData
+			Report          *structs.StatusReport
+		}
+		Returns struct {
+			ActionCreator interfaces.ActionCreator
+		}
+	}
+}
+
+func (r *StatusManagerFactory) StatusManager(log interfaces.DeploymentLogger, deployEventData structs.DeployEventData, report *structs.StatusReport) interfaces.ActionCreator {
+	r.StatusManagerCall.Called = true
+	r.StatusManagerCall.Received.Log = log
+	r.StatusManagerCall.Received.DeployEventData = deployEventData
+	r.StatusManagerCall.Received.Report = report
+
+	return r.StatusManagerCall.Returns.ActionCreator
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"io"
 
 	"bytes"
@@ -14,6 +15,7 @@ type BlueGreener struct {
 	ExecuteCall struct {
 		Write    string
 		Received struct {
+			Context       context.Context
 			ActionCreator I.ActionCreator
 			Environment   S.Environment
 			Out           io.Writer
@@ -25,7 +27,8 @@ type BlueGreener struct {
 }
 
 // Push mock method.
-func (b *BlueGreener) Execute(actionCreator I.ActionCreator, environment S.Environment, out io.ReadWriter) error {
+func (b *BlueGreener) Execute(ctx context.Context, actionCreator I.ActionCreator, environment S.Environment, out io.ReadWriter) error {
+	b.ExecuteCall.Received.Context = ctx
 	b.ExecuteCall.Received.ActionCreator = actionCreator
 	b.ExecuteCall.Received.Environment = environment
 	b.ExecuteCall.Received.Out = out
@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"bytes"
+	"github.com/compozed/deployadactyl/interfaces"
+)
+
+type CommandController struct {
+	RunCommandCall struct {
+		Received struct {
+			Deployment *interfaces.Deployment
+			Data       map[string]interface{}
+			Response   *bytes.Buffer
+		}
+		Returns struct {
+			DeployResponse interfaces.DeployResponse
+		}
+		Writes string
+		Called bool
+	}
+}
+
+func (c *CommandController) RunCommand(deployment *interfaces.Deployment, data map[string]interface{}, response *bytes.Buffer) (deployResponse interfaces.DeployResponse) {
+	c.RunCommandCall.Called = true
+	c.RunCommandCall.Received.Deployment = deployment
+	c.RunCommandCall.Received.Data = data
+	c.RunCommandCall.Received.Response = response
+
+	if c.RunCommandCall.Writes != "" {
+		response.Write([]byte(c.RunCommandCall.Writes))
+	}
+
+	return c.RunCommandCall.Returns.DeployResponse
+}
@@ -33,6 +33,48 @@ type Controller struct {
 			Context *gin.Context
 		}
 	}
+	DeleteRequestHandlerCall struct {
+		Called   bool
+		Received struct {
+			Context *gin.Context
+		}
+	}
+	GetDeploymentStatusCall struct {
+		Called   bool
+		Received struct {
+			Context *gin.Context
+		}
+	}
+	CancelDeploymentCall struct {
+		Called   bool
+		Received struct {
+			Context *gin.Context
+		}
+	}
+	GetDeploymentHistoryCall struct {
+		Called   bool
+		Received struct {
+			Context *gin.Context
+		}
+	}
+	GetAppStatusCall struct {
+		Called   bool
+		Received struct {
+			Context *gin.Context
+		}
+	}
+	GetAppDriftCall struct {
+		Called   bool
+		Received struct {
+			Context *gin.Context
+		}
+	}
+	GetAppLockCall struct {
+		Called   bool
+		Received struct {
+			Context *gin.Context
+		}
+	}
 }
 
 func (c *Controller) RunDeployment(deployment *I.Deployment, response *bytes.Buffer) I.DeployResponse {
@@ -57,3 +99,45 @@ func (c *Controller) PutRequestHandler(g *gin.Context) {
 
 	c.PutRequestHandlerCall.Received.Context = g
 }
+
+func (c *Controller) DeleteRequestHandler(g *gin.Context) {
+	c.DeleteRequestHandlerCall.Called = true
+
+	c.DeleteRequestHandlerCall.Received.Context = g
+}
+
+func (c *Controller) GetDeploymentStatus(g *gin.Context) {
+	c.GetDeploymentStatusCall.Called = true
+
+	c.GetDeploymentStatusCall.Received.Context = g
+}
+
+func (c *Controller) CancelDeployment(g *gin.Context) {
+	c.CancelDeploymentCall.Called = true
+
+	c.CancelDeploymentCall.Received.Context = g
+}
+
+func (c *Controller) GetDeploymentHistory(g *gin.Context) {
+	c.GetDeploymentHistoryCall.Called = true
+
+	c.GetDeploymentHistoryCall.Received.Context = g
+}
+
+func (c *Controller) GetAppStatus(g *gin.Context) {
+	c.GetAppStatusCall.Called = true
+
+	c.GetAppStatusCall.Received.Context = g
+}
+
+func (c *Controller) GetAppDrift(g *gin.Context) {
+	c.GetAppDriftCall.Called = true
+
+	c.GetAppDriftCall.Received.Context = g
+}
+
+func (c *Controller) GetAppLock(g *gin.Context) {
+	c.GetAppLockCall.Called = true
+
+	c.GetAppLockCall.Received.Context = g
+}
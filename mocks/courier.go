@@ -1,16 +1,23 @@
 package mocks
 
+import (
+	"fmt"
+
+	S "github.com/compozed/deployadactyl/structs"
+)
+
 // Courier handmade mock for tests.
 type Courier struct {
 	TimesCourierCalled int
 	LoginCall          struct {
 		Received struct {
-			FoundationURL string
-			Username      string
-			Password      string
-			Org           string
-			Space         string
-			SkipSSL       bool
+			FoundationURL     string
+			Username          string
+			Password          string
+			Org               string
+			Space             string
+			SkipSSL           bool
+			ClientCredentials bool
 		}
 		Returns struct {
 			Output []byte
@@ -20,7 +27,8 @@ type Courier struct {
 
 	StartCall struct {
 		Received struct {
-			AppName string
+			AppName  string
+			AppNames []string
 		}
 		Returns struct {
 			Output []byte
@@ -29,19 +37,104 @@ type Courier struct {
 	}
 
 	StopCall struct {
+		Received struct {
+			AppName  string
+			AppNames []string
+		}
+		Returns struct {
+			Output []byte
+			Error  error
+		}
+	}
+
+	AppStatusCall struct {
 		Received struct {
 			AppName string
 		}
+		Returns struct {
+			Status S.AppStatus
+			Error  error
+		}
+	}
+
+	ScaleCall struct {
+		Received struct {
+			AppName   string
+			Instances uint16
+			Scales    []string
+		}
 		Returns struct {
 			Output []byte
 			Error  error
 		}
 	}
 
-	DeleteCall struct {
+	DeleteServiceCall struct {
+		Received struct {
+			ServiceNames []string
+		}
+		Returns struct {
+			Output []byte
+			Error  error
+		}
+	}
+
+	EnvironmentVariablesCall struct {
 		Received struct {
 			AppName string
 		}
+		Returns struct {
+			Variables map[string]string
+			Error     error
+		}
+	}
+
+	UserProvidedServicesCall struct {
+		Returns struct {
+			Services map[string][]string
+			Error    error
+		}
+	}
+
+	ScaleMemoryCall struct {
+		Received struct {
+			AppName string
+			Memory  string
+			Scales  []string
+		}
+		Returns struct {
+			Output []byte
+			Error  error
+		}
+	}
+
+	RestartCall struct {
+		Received struct {
+			AppName  string
+			AppNames []string
+		}
+		Returns struct {
+			Output []byte
+			Error  error
+		}
+	}
+
+	RestageCall struct {
+		Received struct {
+			AppName  string
+			AppNames []string
+		}
+		Returns struct {
+			Output []byte
+			Error  error
+		}
+	}
+
+	DeleteCall struct {
+		Received struct {
+			AppName  string
+			AppNames []string
+		}
 		Returns struct {
 			Output []byte
 			Error  error
@@ -65,6 +158,8 @@ type Courier struct {
 		Received struct {
 			AppName          string
 			AppNameVenerable string
+			OldNames         []string
+			NewNames         []string
 		}
 		Returns struct {
 			Output []byte
@@ -143,6 +238,7 @@ type Courier struct {
 		}
 		Returns struct {
 			Bool bool
+			Apps map[string]bool
 		}
 	}
 
@@ -184,32 +280,59 @@ type Courier struct {
 }
 
 // Login mock method.
-func (c *Courier) Login(foundationURL, username, password, org, space string, skipSSL bool) ([]byte, error) {
+func (c *Courier) Login(foundationURL, username, password, org, space string, skipSSL, clientCredentials bool) ([]byte, error) {
 	c.LoginCall.Received.FoundationURL = foundationURL
 	c.LoginCall.Received.Username = username
 	c.LoginCall.Received.Password = password
 	c.LoginCall.Received.Org = org
 	c.LoginCall.Received.Space = space
 	c.LoginCall.Received.SkipSSL = skipSSL
+	c.LoginCall.Received.ClientCredentials = clientCredentials
 
 	return c.LoginCall.Returns.Output, c.LoginCall.Returns.Error
 }
 
 func (c *Courier) Start(appName string) ([]byte, error) {
 	c.StartCall.Received.AppName = appName
+	c.StartCall.Received.AppNames = append(c.StartCall.Received.AppNames, appName)
 
 	return c.StartCall.Returns.Output, c.StartCall.Returns.Error
 }
 
 func (c *Courier) Stop(appName string) ([]byte, error) {
 	c.StopCall.Received.AppName = appName
+	c.StopCall.Received.AppNames = append(c.StopCall.Received.AppNames, appName)
 
 	return c.StopCall.Returns.Output, c.StopCall.Returns.Error
 }
 
+// AppStatus mock method.
+func (c *Courier) AppStatus(appName string) (S.AppStatus, error) {
+	c.AppStatusCall.Received.AppName = appName
+
+	return c.AppStatusCall.Returns.Status, c.AppStatusCall.Returns.Error
+}
+
+// EnvironmentVariables mock method.
+func (c *Courier) EnvironmentVariables(appName string) (map[string]string, error) {
+	c.EnvironmentVariablesCall.Received.AppName = appName
+
+	return c.EnvironmentVariablesCall.Returns.Variables, c.EnvironmentVariablesCall.Returns.Error
+}
+
+// Scale mock method. Every call is also recorded in Scales as "appName=instances".
+func (c *Courier) Scale(appName string, instances uint16) ([]byte, error) {
+	c.ScaleCall.Received.AppName = appName
+	c.ScaleCall.Received.Instances = instances
+	c.ScaleCall.Received.Scales = append(c.ScaleCall.Received.Scales, fmt.Sprintf("%s=%d", appName, instances))
+
+	return c.ScaleCall.Returns.Output, c.ScaleCall.Returns.Error
+}
+
 // Delete mock method.
 func (c *Courier) Delete(appName string) ([]byte, error) {
 	c.DeleteCall.Received.AppName = appName
+	c.DeleteCall.Received.AppNames = append(c.DeleteCall.Received.AppNames, appName)
 
 	return c.DeleteCall.Returns.Output, c.DeleteCall.Returns.Error
 }
@@ -228,6 +351,8 @@ func (c *Courier) Push(appName, appLocation, hostname string, instances uint16)
 func (c *Courier) Rename(appName, newAppName string) ([]byte, error) {
 	c.RenameCall.Received.AppName = appName
 	c.RenameCall.Received.AppNameVenerable = newAppName
+	c.RenameCall.Received.OldNames = append(c.RenameCall.Received.OldNames, appName)
+	c.RenameCall.Received.NewNames = append(c.RenameCall.Received.NewNames, newAppName)
 
 	return c.RenameCall.Returns.Output, c.RenameCall.Returns.Error
 }
@@ -309,6 +434,10 @@ func (c *Courier) Logs(appName string) ([]byte, error) {
 func (c *Courier) Exists(appName string) bool {
 	c.ExistsCall.Received.AppName = appName
 
+	if c.ExistsCall.Returns.Apps != nil {
+		return c.ExistsCall.Returns.Apps[appName]
+	}
+
 	return c.ExistsCall.Returns.Bool
 }
 
@@ -347,12 +476,41 @@ func (c *Courier) UnbindService(appName, serviceName string) ([]byte, error) {
 	panic("Mock not implemented.")
 }
 
+// DeleteService mock method.
 func (c *Courier) DeleteService(serviceName string) ([]byte, error) {
-	panic("Mock not implemented.")
+	c.DeleteServiceCall.Received.ServiceNames = append(c.DeleteServiceCall.Received.ServiceNames, serviceName)
+
+	return c.DeleteServiceCall.Returns.Output, c.DeleteServiceCall.Returns.Error
+}
+
+// UserProvidedServices mock method.
+func (c *Courier) UserProvidedServices() (map[string][]string, error) {
+	return c.UserProvidedServicesCall.Returns.Services, c.UserProvidedServicesCall.Returns.Error
 }
 
+// Restage mock method.
 func (c *Courier) Restage(appName string) ([]byte, error) {
-	panic("Mock not implemented.")
+	c.RestageCall.Received.AppName = appName
+	c.RestageCall.Received.AppNames = append(c.RestageCall.Received.AppNames, appName)
+
+	return c.RestageCall.Returns.Output, c.RestageCall.Returns.Error
+}
+
+// Restart mock method.
+func (c *Courier) Restart(appName string) ([]byte, error) {
+	c.RestartCall.Received.AppName = appName
+	c.RestartCall.Received.AppNames = append(c.RestartCall.Received.AppNames, appName)
+
+	return c.RestartCall.Returns.Output, c.RestartCall.Returns.Error
+}
+
+// ScaleMemory mock method. Every call is also recorded in Scales as "appName=memory".
+func (c *Courier) ScaleMemory(appName, memory string) ([]byte, error) {
+	c.ScaleMemoryCall.Received.AppName = appName
+	c.ScaleMemoryCall.Received.Memory = memory
+	c.ScaleMemoryCall.Received.Scales = append(c.ScaleMemoryCall.Received.Scales, fmt.Sprintf("%s=%s", appName, memory))
+
+	return c.ScaleMemoryCall.Returns.Output, c.ScaleMemoryCall.Returns.Error
 }
 
 // CleanUp mock method.
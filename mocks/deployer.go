@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"fmt"
 	"io"
 
@@ -13,6 +14,7 @@ type Deployer struct {
 	DeployCall struct {
 		Called   int
 		Received struct {
+			Context        context.Context
 			DeploymentInfo *structs.DeploymentInfo
 			Env            structs.Environment
 			ActionCreator  I.ActionCreator
@@ -29,9 +31,10 @@ type Deployer struct {
 }
 
 // Deploy mock method.
-func (d *Deployer) Deploy(deploymentInfo *structs.DeploymentInfo, env structs.Environment, actionCreator I.ActionCreator, out io.ReadWriter) *I.DeployResponse {
+func (d *Deployer) Deploy(ctx context.Context, deploymentInfo *structs.DeploymentInfo, env structs.Environment, actionCreator I.ActionCreator, out io.ReadWriter) *I.DeployResponse {
 	d.DeployCall.Called++
 
+	d.DeployCall.Received.Context = ctx
 	d.DeployCall.Received.DeploymentInfo = deploymentInfo
 	d.DeployCall.Received.Env = env
 	d.DeployCall.Received.ActionCreator = actionCreator
@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+// DeploymentStore handmade mock for tests.
+type DeploymentStore struct {
+	SaveCall struct {
+		Received struct {
+			Records []I.DeploymentRecord
+		}
+		Returns struct {
+			Error error
+		}
+	}
+	GetCall struct {
+		Received struct {
+			UUID string
+		}
+		Returns struct {
+			Record I.DeploymentRecord
+			Found  bool
+			Error  error
+		}
+	}
+	FindCall struct {
+		Received struct {
+			CFContext I.CFContext
+		}
+		Returns struct {
+			Records []I.DeploymentRecord
+			Error   error
+		}
+	}
+}
+
+// Save mock method.
+func (s *DeploymentStore) Save(record I.DeploymentRecord) error {
+	s.SaveCall.Received.Records = append(s.SaveCall.Received.Records, record)
+
+	return s.SaveCall.Returns.Error
+}
+
+// Get mock method.
+func (s *DeploymentStore) Get(uuid string) (I.DeploymentRecord, bool, error) {
+	s.GetCall.Received.UUID = uuid
+
+	return s.GetCall.Returns.Record, s.GetCall.Returns.Found, s.GetCall.Returns.Error
+}
+
+// Find mock method.
+func (s *DeploymentStore) Find(cfContext I.CFContext) ([]I.DeploymentRecord, error) {
+	s.FindCall.Received.CFContext = cfContext
+
+	return s.FindCall.Returns.Records, s.FindCall.Returns.Error
+}
@@ -0,0 +1,84 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+// IdempotencyKeys handmade mock for tests.
+type IdempotencyKeys struct {
+	lock sync.Mutex
+
+	ClaimCall struct {
+		Received struct {
+			User        string
+			Key         string
+			Fingerprint string
+			UUID        string
+		}
+		Returns struct {
+			Owner string
+			Error error
+		}
+	}
+	FinishCall struct {
+		Called   bool
+		Received struct {
+			UUID           string
+			DeployResponse I.DeployResponse
+			Output         string
+		}
+	}
+	WaitCall struct {
+		Received struct {
+			Context context.Context
+			UUID    string
+		}
+		Returns struct {
+			DeployResponse I.DeployResponse
+			Output         string
+			Error          error
+		}
+	}
+}
+
+// Claim mock method. It returns the uuid it receives unless an Owner is set.
+func (k *IdempotencyKeys) Claim(user, key, fingerprint, uuid string) (string, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.ClaimCall.Received.User = user
+	k.ClaimCall.Received.Key = key
+	k.ClaimCall.Received.Fingerprint = fingerprint
+	k.ClaimCall.Received.UUID = uuid
+
+	if k.ClaimCall.Returns.Owner == "" && k.ClaimCall.Returns.Error == nil {
+		return uuid, nil
+	}
+
+	return k.ClaimCall.Returns.Owner, k.ClaimCall.Returns.Error
+}
+
+// Finish mock method.
+func (k *IdempotencyKeys) Finish(uuid string, deployResponse I.DeployResponse, output string) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.FinishCall.Called = true
+	k.FinishCall.Received.UUID = uuid
+	k.FinishCall.Received.DeployResponse = deployResponse
+	k.FinishCall.Received.Output = output
+}
+
+// Wait mock method.
+func (k *IdempotencyKeys) Wait(ctx context.Context, uuid string) (I.DeployResponse, string, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.WaitCall.Received.Context = ctx
+	k.WaitCall.Received.UUID = uuid
+
+	return k.WaitCall.Returns.DeployResponse, k.WaitCall.Returns.Output, k.WaitCall.Returns.Error
+}
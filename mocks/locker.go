@@ -0,0 +1,67 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+// Locker handmade mock for tests.
+type Locker struct {
+	lock sync.Mutex
+
+	LockCall struct {
+		Received struct {
+			Context   context.Context
+			CFContext I.CFContext
+			Holder    I.LockHolder
+		}
+		Returns struct {
+			Error error
+		}
+		Unlocked bool
+	}
+	HolderCall struct {
+		Received struct {
+			CFContext I.CFContext
+		}
+		Returns struct {
+			Holder I.LockHolder
+			Ok     bool
+		}
+	}
+}
+
+// Lock mock method.
+func (l *Locker) Lock(ctx context.Context, cfContext I.CFContext, holder I.LockHolder) (func(), error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.LockCall.Received.Context = ctx
+	l.LockCall.Received.CFContext = cfContext
+	l.LockCall.Received.Holder = holder
+
+	if l.LockCall.Returns.Error != nil {
+		return nil, l.LockCall.Returns.Error
+	}
+
+	unlock := func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		l.LockCall.Unlocked = true
+	}
+
+	return unlock, nil
+}
+
+// Holder mock method.
+func (l *Locker) Holder(cfContext I.CFContext) (I.LockHolder, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.HolderCall.Received.CFContext = cfContext
+
+	return l.HolderCall.Returns.Holder, l.HolderCall.Returns.Ok
+}
@@ -3,6 +3,8 @@ package mocks
 import (
 	"fmt"
 	"io"
+
+	"github.com/compozed/deployadactyl/interfaces"
 )
 
 // Pusher handmade mock for tests.
@@ -19,7 +21,8 @@ type Pusher struct {
 	}
 
 	ExecuteCall struct {
-		Write struct {
+		Called bool
+		Write  struct {
 			Output string
 		}
 		Returns struct {
@@ -33,6 +36,7 @@ type Pusher struct {
 	}
 
 	UndoCall struct {
+		Called  bool
 		Returns struct {
 			Error error
 		}
@@ -49,6 +53,14 @@ type Pusher struct {
 			Error error
 		}
 	}
+
+	PlanCall struct {
+		Called  bool
+		Returns struct {
+			Plan  interfaces.FoundationPlan
+			Error error
+		}
+	}
 }
 
 // Login mock method.
@@ -61,6 +73,7 @@ func (p *Pusher) Initially() error {
 
 // Push mock method.
 func (p *Pusher) Execute() error {
+	p.ExecuteCall.Called = true
 
 	fmt.Fprint(p.Response, p.ExecuteCall.Write.Output)
 
@@ -78,6 +91,7 @@ func (p *Pusher) Success() error {
 
 // UndoPush mock method.
 func (p *Pusher) Undo() error {
+	p.UndoCall.Called = true
 	return p.UndoCall.Returns.Error
 }
 
@@ -85,3 +99,9 @@ func (p *Pusher) Undo() error {
 func (p *Pusher) Finally() error {
 	return p.FinallyCall.Returns.Error
 }
+
+// Plan mock method.
+func (p *Pusher) Plan() (interfaces.FoundationPlan, error) {
+	p.PlanCall.Called = true
+	return p.PlanCall.Returns.Plan, p.PlanCall.Returns.Error
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"io"
 
 	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
@@ -24,7 +25,10 @@ type PushManager struct {
 	}
 	CreatePusherCall struct {
 		TimesCalled int
-		Returns     struct {
+		Received    struct {
+			Context context.Context
+		}
+		Returns struct {
 			Pushers []interfaces.Action
 			Error   []error
 		}
@@ -91,9 +95,11 @@ func (p *PushManager) OnFinish(env S.Environment, response io.ReadWriter, err er
 	return p.OnFinishCall.Returns.DeployResponse
 }
 
-func (p *PushManager) Create(environment S.Environment, response io.ReadWriter, foundationURL string) (interfaces.Action, error) {
+func (p *PushManager) Create(ctx context.Context, environment S.Environment, response io.ReadWriter, foundationURL string) (interfaces.Action, error) {
 	defer func() { p.CreatePusherCall.TimesCalled++ }()
 
+	p.CreatePusherCall.Received.Context = ctx
+
 	return p.CreatePusherCall.Returns.Pushers[p.CreatePusherCall.TimesCalled], p.CreatePusherCall.Returns.Error[p.CreatePusherCall.TimesCalled]
 }
 
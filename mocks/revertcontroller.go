@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"bytes"
+	"github.com/compozed/deployadactyl/interfaces"
+)
+
+type RevertController struct {
+	RevertDeploymentCall struct {
+		Received struct {
+			Deployment *interfaces.Deployment
+			Data       map[string]interface{}
+			Response   *bytes.Buffer
+		}
+		Returns struct {
+			DeployResponse interfaces.DeployResponse
+		}
+		Writes string
+		Called bool
+	}
+}
+
+func (c *RevertController) RevertDeployment(deployment *interfaces.Deployment, data map[string]interface{}, response *bytes.Buffer) (deployResponse interfaces.DeployResponse) {
+	c.RevertDeploymentCall.Called = true
+	c.RevertDeploymentCall.Received.Deployment = deployment
+	c.RevertDeploymentCall.Received.Data = data
+	c.RevertDeploymentCall.Received.Response = response
+
+	if c.RevertDeploymentCall.Writes != "" {
+		response.Write([]byte(c.RevertDeploymentCall.Writes))
+	}
+
+	return c.RevertDeploymentCall.Returns.DeployResponse
+}
@@ -0,0 +1,63 @@
+package mocks
+
+import (
+	"sync"
+)
+
+// Scheduler handmade mock for tests.
+type Scheduler struct {
+	lock sync.Mutex
+
+	EnqueueCall struct {
+		Received struct {
+			UUID        string
+			Environment string
+			Priority    int
+		}
+		Returns struct {
+			Ready chan struct{}
+		}
+		Done bool
+	}
+	PositionCall struct {
+		Received struct {
+			UUID string
+		}
+		Returns struct {
+			Position int
+		}
+	}
+}
+
+// Enqueue mock method. The deployment may run right away unless Returns.Ready is set.
+func (s *Scheduler) Enqueue(uuid, environment string, priority int) (<-chan struct{}, func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.EnqueueCall.Received.UUID = uuid
+	s.EnqueueCall.Received.Environment = environment
+	s.EnqueueCall.Received.Priority = priority
+
+	ready := s.EnqueueCall.Returns.Ready
+	if ready == nil {
+		ready = make(chan struct{})
+		close(ready)
+	}
+
+	return ready, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		s.EnqueueCall.Done = true
+	}
+}
+
+// Position mock method.
+func (s *Scheduler) Position(uuid string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.PositionCall.Received.UUID = uuid
+
+	return s.PositionCall.Returns.Position
+}
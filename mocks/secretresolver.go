@@ -0,0 +1,25 @@
+package mocks
+
+import S "github.com/compozed/deployadactyl/structs"
+
+// SecretResolver handmade mock for tests.
+type SecretResolver struct {
+	ResolveCredentialsCall struct {
+		Called   bool
+		Received struct {
+			Environment S.Environment
+		}
+		Returns struct {
+			Environment S.Environment
+			Error       error
+		}
+	}
+}
+
+// ResolveCredentials mock method.
+func (s *SecretResolver) ResolveCredentials(environment S.Environment) (S.Environment, error) {
+	s.ResolveCredentialsCall.Called = true
+	s.ResolveCredentialsCall.Received.Environment = environment
+
+	return s.ResolveCredentialsCall.Returns.Environment, s.ResolveCredentialsCall.Returns.Error
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"github.com/compozed/deployadactyl/interfaces"
 	S "github.com/compozed/deployadactyl/structs"
 
@@ -38,7 +39,7 @@ func (s *StartManager) InitiallyError(initiallyErrors []error) error {
 	return bluegreen.LoginError{LoginErrors: initiallyErrors}
 }
 
-func (s *StartManager) Create(environment S.Environment, response io.ReadWriter, foundationURL string) (interfaces.Action, error) {
+func (s *StartManager) Create(ctx context.Context, environment S.Environment, response io.ReadWriter, foundationURL string) (interfaces.Action, error) {
 	defer func() { s.CreateStarterCall.TimesCalled++ }()
 
 	received := receivedCall{
@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"bytes"
+
+	"github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/structs"
+)
+
+type StatusController struct {
+	AppStatusCall struct {
+		Received struct {
+			Deployment *interfaces.Deployment
+			Response   *bytes.Buffer
+		}
+		Returns struct {
+			Report         structs.StatusReport
+			DeployResponse interfaces.DeployResponse
+		}
+		Writes string
+		Called bool
+	}
+	DriftCall struct {
+		Received struct {
+			Deployment *interfaces.Deployment
+			Response   *bytes.Buffer
+		}
+		Returns struct {
+			Report         structs.DriftReport
+			DeployResponse interfaces.DeployResponse
+		}
+		Called bool
+	}
+}
+
+func (c *StatusController) AppStatus(deployment *interfaces.Deployment, response *bytes.Buffer) (structs.StatusReport, interfaces.DeployResponse) {
+	c.AppStatusCall.Called = true
+	c.AppStatusCall.Received.Deployment = deployment
+	c.AppStatusCall.Received.Response = response
+
+	if c.AppStatusCall.Writes != "" {
+		response.Write([]byte(c.AppStatusCall.Writes))
+	}
+
+	return c.AppStatusCall.Returns.Report, c.AppStatusCall.Returns.DeployResponse
+}
+
+func (c *StatusController) Drift(deployment *interfaces.Deployment, response *bytes.Buffer) (structs.DriftReport, interfaces.DeployResponse) {
+	c.DriftCall.Called = true
+	c.DriftCall.Received.Deployment = deployment
+	c.DriftCall.Received.Response = response
+
+	return c.DriftCall.Returns.Report, c.DriftCall.Returns.DeployResponse
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"github.com/compozed/deployadactyl/interfaces"
 	S "github.com/compozed/deployadactyl/structs"
 
@@ -42,7 +43,7 @@ func (s *StopManager) InitiallyError(initiallyErrors []error) error {
 	return bluegreen.LoginError{LoginErrors: initiallyErrors}
 }
 
-func (s *StopManager) Create(environment S.Environment, response io.ReadWriter, foundationURL string) (interfaces.Action, error) {
+func (s *StopManager) Create(ctx context.Context, environment S.Environment, response io.ReadWriter, foundationURL string) (interfaces.Action, error) {
 	defer func() { s.CreateStopperCall.TimesCalled++ }()
 
 	received := receivedCall{
@@ -0,0 +1,204 @@
+package mocks
+
+import (
+	"sync"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+// Tracker handmade mock for tests.
+type Tracker struct {
+	lock sync.Mutex
+
+	StartCall struct {
+		Called   bool
+		Received struct {
+			UUID          string
+			CFContext     I.CFContext
+			Authorization I.Authorization
+		}
+	}
+	AuthorizedCall struct {
+		Received struct {
+			UUID          string
+			Authorization I.Authorization
+		}
+		Returns struct {
+			Authorized bool
+		}
+	}
+	SetPhaseCall struct {
+		Received struct {
+			UUID   string
+			Phases []string
+		}
+	}
+	SetFoundationStatusCall struct {
+		Received struct {
+			UUID        string
+			Foundations map[string]I.FoundationStatus
+		}
+	}
+	FinishCall struct {
+		Called   bool
+		Received struct {
+			UUID           string
+			DeployResponse I.DeployResponse
+			Output         string
+		}
+	}
+	PublishCall struct {
+		Received struct {
+			UUID  string
+			Lines []I.OutputLine
+		}
+	}
+	HoldCall struct {
+		Received struct {
+			UUID string
+		}
+		Released bool
+	}
+	SubscribeCall struct {
+		Received struct {
+			UUID string
+		}
+		Returns struct {
+			Lines []I.OutputLine
+		}
+		Unsubscribed bool
+	}
+	GetCall struct {
+		Received struct {
+			UUID string
+		}
+		Returns struct {
+			Status I.DeploymentStatus
+			Ok     bool
+		}
+	}
+}
+
+// Start mock method.
+func (t *Tracker) Start(uuid string, cfContext I.CFContext, authorization I.Authorization) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.StartCall.Called = true
+	t.StartCall.Received.UUID = uuid
+	t.StartCall.Received.CFContext = cfContext
+	t.StartCall.Received.Authorization = authorization
+}
+
+// Authorized mock method.
+func (t *Tracker) Authorized(uuid string, authorization I.Authorization) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.AuthorizedCall.Received.UUID = uuid
+	t.AuthorizedCall.Received.Authorization = authorization
+
+	return t.AuthorizedCall.Returns.Authorized
+}
+
+// SetPhase mock method.
+func (t *Tracker) SetPhase(uuid, phase string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.SetPhaseCall.Received.UUID = uuid
+	t.SetPhaseCall.Received.Phases = append(t.SetPhaseCall.Received.Phases, phase)
+}
+
+// SetFoundationStatus mock method.
+func (t *Tracker) SetFoundationStatus(uuid, foundationURL, phase string, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.SetFoundationStatusCall.Received.Foundations == nil {
+		t.SetFoundationStatusCall.Received.Foundations = make(map[string]I.FoundationStatus)
+	}
+
+	status := I.FoundationStatus{Phase: phase}
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	t.SetFoundationStatusCall.Received.UUID = uuid
+	t.SetFoundationStatusCall.Received.Foundations[foundationURL] = status
+}
+
+// Finish mock method.
+func (t *Tracker) Finish(uuid string, deployResponse I.DeployResponse, output string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.FinishCall.Called = true
+	t.FinishCall.Received.UUID = uuid
+	t.FinishCall.Received.DeployResponse = deployResponse
+	t.FinishCall.Received.Output = output
+}
+
+// Finished tells whether Finish was called, so that tests can wait for a deployment that runs in the background
+// before they look at the calls it made.
+func (t *Tracker) Finished() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.FinishCall.Called
+}
+
+// Get mock method.
+func (t *Tracker) Get(uuid string) (I.DeploymentStatus, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.GetCall.Received.UUID = uuid
+
+	return t.GetCall.Returns.Status, t.GetCall.Returns.Ok
+}
+
+// Publish mock method.
+func (t *Tracker) Publish(uuid string, line I.OutputLine) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.PublishCall.Received.UUID = uuid
+	t.PublishCall.Received.Lines = append(t.PublishCall.Received.Lines, line)
+}
+
+// Hold mock method.
+func (t *Tracker) Hold(uuid string) func() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.HoldCall.Received.UUID = uuid
+
+	return func() {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+
+		t.HoldCall.Released = true
+	}
+}
+
+// Subscribe mock method.
+func (t *Tracker) Subscribe(uuid string) (<-chan I.OutputLine, func()) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.SubscribeCall.Received.UUID = uuid
+
+	lines := make(chan I.OutputLine, len(t.SubscribeCall.Returns.Lines))
+	for _, line := range t.SubscribeCall.Returns.Lines {
+		lines <- line
+	}
+	close(lines)
+
+	return lines, func() {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+
+		t.SubscribeCall.Unsubscribed = true
+	}
+}
@@ -0,0 +1,145 @@
+// Package scheduler bounds how many deployments run at the same time.
+package scheduler
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
+
+// Scheduler runs at most Workers deployments at a time, and at most the limit of an environment
+// in that environment. Deployments that cannot run yet wait in a queue that is first in, first out,
+// or ordered by priority first in the QueueOrderPriority order.
+// A Workers or environment limit below one means no limit.
+type Scheduler struct {
+	Workers           int
+	Order             string
+	EnvironmentLimits map[string]int
+
+	lock     sync.Mutex
+	queue    []*job
+	running  int
+	perEnv   map[string]int
+	sequence uint64
+}
+
+type job struct {
+	uuid        string
+	environment string
+	priority    int
+	sequence    uint64
+	ready       chan struct{}
+	started     bool
+}
+
+// NewScheduler returns a Scheduler with the given number of workers, queue order and limits per environment.
+func NewScheduler(workers int, order string, environmentLimits map[string]int) *Scheduler {
+	limits := make(map[string]int, len(environmentLimits))
+	for environment, limit := range environmentLimits {
+		limits[strings.ToLower(environment)] = limit
+	}
+
+	return &Scheduler{
+		Workers:           workers,
+		Order:             order,
+		EnvironmentLimits: limits,
+		perEnv:            make(map[string]int),
+	}
+}
+
+// Enqueue queues a deployment. The ready channel is closed once the deployment may run,
+// and done must be called when it has finished to let the next one run.
+// Calling done before the deployment may run takes it out of the queue.
+func (s *Scheduler) Enqueue(uuid, environment string, priority int) (<-chan struct{}, func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sequence++
+	j := &job{
+		uuid:        uuid,
+		environment: strings.ToLower(environment),
+		priority:    priority,
+		sequence:    s.sequence,
+		ready:       make(chan struct{}),
+	}
+	s.queue = append(s.queue, j)
+	s.dispatch()
+
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+
+			if !j.started {
+				s.remove(j)
+				return
+			}
+
+			s.running--
+			s.perEnv[j.environment]--
+			s.dispatch()
+		})
+	}
+
+	return j.ready, done
+}
+
+// Position returns the place of a waiting deployment in the queue, starting at one.
+// It returns zero for deployments that are not waiting.
+func (s *Scheduler) Position(uuid string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, j := range s.queue {
+		if j.uuid == uuid {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// dispatch starts every queued deployment that fits within the limits, in queue order.
+// A deployment waiting for its environment does not hold up deployments to other environments.
+func (s *Scheduler) dispatch() {
+	sort.SliceStable(s.queue, func(a, b int) bool {
+		if s.Order == C.QueueOrderPriority && s.queue[a].priority != s.queue[b].priority {
+			return s.queue[a].priority > s.queue[b].priority
+		}
+		return s.queue[a].sequence < s.queue[b].sequence
+	})
+
+	waiting := s.queue[:0]
+	for _, j := range s.queue {
+		if s.full() || s.environmentFull(j.environment) {
+			waiting = append(waiting, j)
+			continue
+		}
+
+		s.running++
+		s.perEnv[j.environment]++
+		j.started = true
+		close(j.ready)
+	}
+	s.queue = waiting
+}
+
+func (s *Scheduler) remove(removed *job) {
+	for i, j := range s.queue {
+		if j == removed {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Scheduler) full() bool {
+	return s.Workers > 0 && s.running >= s.Workers
+}
+
+func (s *Scheduler) environmentFull(environment string) bool {
+	limit := s.EnvironmentLimits[environment]
+	return limit > 0 && s.perEnv[environment] >= limit
+}
@@ -0,0 +1,137 @@
+package scheduler_test
+
+import (
+	C "github.com/compozed/deployadactyl/constants"
+	. "github.com/compozed/deployadactyl/scheduler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scheduler", func() {
+	var scheduler *Scheduler
+
+	BeforeEach(func() {
+		scheduler = NewScheduler(2, C.QueueOrderFIFO, map[string]int{"Production": 1})
+	})
+
+	It("lets deployments run while there are free workers", func() {
+		first, _ := scheduler.Enqueue("first", "test", 0)
+		second, _ := scheduler.Enqueue("second", "test", 0)
+
+		Eventually(first).Should(BeClosed())
+		Eventually(second).Should(BeClosed())
+		Expect(scheduler.Position("first")).To(BeZero())
+	})
+
+	It("queues deployments when every worker is busy", func() {
+		scheduler.Enqueue("first", "test", 0)
+		_, done := scheduler.Enqueue("second", "test", 0)
+		third, _ := scheduler.Enqueue("third", "test", 0)
+		fourth, _ := scheduler.Enqueue("fourth", "test", 0)
+
+		Consistently(third).ShouldNot(BeClosed())
+		Expect(scheduler.Position("third")).To(Equal(1))
+		Expect(scheduler.Position("fourth")).To(Equal(2))
+
+		done()
+
+		Eventually(third).Should(BeClosed())
+		Consistently(fourth).ShouldNot(BeClosed())
+		Expect(scheduler.Position("fourth")).To(Equal(1))
+	})
+
+	It("frees a worker only once when done is called more than once", func() {
+		_, done := scheduler.Enqueue("first", "test", 0)
+		scheduler.Enqueue("second", "test", 0)
+		third, _ := scheduler.Enqueue("third", "test", 0)
+		fourth, _ := scheduler.Enqueue("fourth", "test", 0)
+
+		done()
+		done()
+
+		Eventually(third).Should(BeClosed())
+		Consistently(fourth).ShouldNot(BeClosed())
+	})
+
+	It("takes a deployment out of the queue when it is done before it could run", func() {
+		scheduler.Enqueue("first", "test", 0)
+		_, done := scheduler.Enqueue("second", "test", 0)
+		_, leave := scheduler.Enqueue("third", "test", 0)
+		fourth, _ := scheduler.Enqueue("fourth", "test", 0)
+
+		leave()
+
+		Expect(scheduler.Position("third")).To(BeZero())
+		Expect(scheduler.Position("fourth")).To(Equal(1))
+		Consistently(fourth).ShouldNot(BeClosed())
+
+		done()
+
+		Eventually(fourth).Should(BeClosed())
+	})
+
+	It("keeps to the limit of an environment", func() {
+		_, done := scheduler.Enqueue("first", "production", 0)
+		second, _ := scheduler.Enqueue("second", "production", 0)
+
+		Consistently(second).ShouldNot(BeClosed())
+		Expect(scheduler.Position("second")).To(Equal(1))
+
+		done()
+
+		Eventually(second).Should(BeClosed())
+	})
+
+	It("does not hold up other environments behind an environment at its limit", func() {
+		scheduler.Enqueue("first", "production", 0)
+		second, _ := scheduler.Enqueue("second", "production", 0)
+		third, _ := scheduler.Enqueue("third", "test", 0)
+
+		Eventually(third).Should(BeClosed())
+		Consistently(second).ShouldNot(BeClosed())
+	})
+
+	It("ignores priorities in the fifo order", func() {
+		_, done := scheduler.Enqueue("first", "test", 0)
+		scheduler.Enqueue("second", "test", 0)
+		third, _ := scheduler.Enqueue("third", "test", 0)
+		fourth, _ := scheduler.Enqueue("fourth", "test", 10)
+
+		done()
+
+		Eventually(third).Should(BeClosed())
+		Consistently(fourth).ShouldNot(BeClosed())
+	})
+
+	Context("in the priority order", func() {
+		BeforeEach(func() {
+			scheduler = NewScheduler(1, C.QueueOrderPriority, nil)
+		})
+
+		It("runs the deployment with the highest priority first", func() {
+			_, done := scheduler.Enqueue("first", "test", 0)
+			low, _ := scheduler.Enqueue("low", "test", 1)
+			high, _ := scheduler.Enqueue("high", "test", 5)
+
+			Expect(scheduler.Position("high")).To(Equal(1))
+			Expect(scheduler.Position("low")).To(Equal(2))
+
+			done()
+
+			Eventually(high).Should(BeClosed())
+			Consistently(low).ShouldNot(BeClosed())
+		})
+
+		It("runs deployments with the same priority in the order they came in", func() {
+			_, done := scheduler.Enqueue("first", "test", 0)
+			second, _ := scheduler.Enqueue("second", "test", 1)
+			third, _ := scheduler.Enqueue("third", "test", 1)
+
+			done()
+
+			Eventually(second).Should(BeClosed())
+			Consistently(third).ShouldNot(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
+
+type InvalidReferenceError struct {
+	Reference string
+}
+
+func (e InvalidReferenceError) Error() string {
+	return fmt.Sprintf("secret reference %s must look like secret://<provider>/<path>#<key>", e.Reference)
+}
+
+func (e InvalidReferenceError) Code() string {
+	return "InvalidSecretReferenceError"
+}
+
+func (e InvalidReferenceError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e InvalidReferenceError) Retryable() bool {
+	return false
+}
+
+type UnknownProviderError struct {
+	Provider string
+}
+
+func (e UnknownProviderError) Error() string {
+	return fmt.Sprintf("no secret provider is registered as %s", e.Provider)
+}
+
+func (e UnknownProviderError) Code() string {
+	return "UnknownSecretProviderError"
+}
+
+func (e UnknownProviderError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e UnknownProviderError) Retryable() bool {
+	return false
+}
+
+type SecretNotFoundError struct {
+	Provider string
+	Path     string
+	Key      string
+}
+
+func (e SecretNotFoundError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("secret %s not found by the %s secret provider", e.Path, e.Provider)
+	}
+	return fmt.Sprintf("secret %s#%s not found by the %s secret provider", e.Path, e.Key, e.Provider)
+}
+
+func (e SecretNotFoundError) Code() string {
+	return "SecretNotFoundError"
+}
+
+func (e SecretNotFoundError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e SecretNotFoundError) Retryable() bool {
+	return false
+}
+
+type ProviderError struct {
+	Provider string
+	Path     string
+	Err      error
+}
+
+func (e ProviderError) Error() string {
+	return fmt.Sprintf("the %s secret provider cannot look up %s: %s", e.Provider, e.Path, e.Err)
+}
+
+func (e ProviderError) Code() string {
+	return "SecretProviderError"
+}
+
+func (e ProviderError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e ProviderError) Retryable() bool {
+	return true
+}
@@ -0,0 +1,132 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+// EnvProvider looks up secrets in environment variables. The path of a secret is the name of its variable.
+type EnvProvider struct {
+	Getenv func(string) string
+}
+
+func (p EnvProvider) Secret(path, key string) (string, error) {
+	name := path
+	if key != "" {
+		name = path + "_" + key
+	}
+
+	value := p.Getenv(name)
+	if value == "" {
+		return "", SecretNotFoundError{"env", path, key}
+	}
+	return value, nil
+}
+
+// FileProvider looks up secrets in files below a directory, such as the secrets mounted into a container.
+// The secret is the content of the file at the path, or of the file named after the key in the directory
+// at the path.
+type FileProvider struct {
+	Directory string
+}
+
+func (p FileProvider) Secret(path, key string) (string, error) {
+	name := filepath.Join(p.Directory, filepath.Clean("/"+path))
+	if key != "" {
+		name = filepath.Join(name, filepath.Clean("/"+key))
+	}
+
+	content, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		return "", SecretNotFoundError{"file", path, key}
+	}
+	if err != nil {
+		return "", ProviderError{"file", path, err}
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// HTTPProvider looks up secrets with the HTTP API of a Vault-style key/value store.
+// It reads both version 1 and version 2 of the key/value secrets engine.
+type HTTPProvider struct {
+	Address string
+	Token   string
+	Client  *http.Client
+}
+
+func (p HTTPProvider) Secret(path, key string) (string, error) {
+	request, err := http.NewRequest("GET", strings.TrimRight(p.Address, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", ProviderError{"vault", path, err}
+	}
+	if p.Token != "" {
+		request.Header.Set("X-Vault-Token", p.Token)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", ProviderError{"vault", path, err}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return "", SecretNotFoundError{"vault", path, key}
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", ProviderError{"vault", path, fmt.Errorf("unexpected response status %s", response.Status)}
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&secret); err != nil {
+		return "", ProviderError{"vault", path, err}
+	}
+
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	if key == "" {
+		key = "value"
+	}
+	value, ok := data[key].(string)
+	if !ok {
+		return "", SecretNotFoundError{"vault", path, key}
+	}
+	return value, nil
+}
+
+// Providers returns the built-in secret providers configured by the environment variables.
+// The env provider is always available, the file provider when SECRETS_DIRECTORY is set and the vault
+// provider when VAULT_ADDR is set.
+func Providers(getenv func(string) string) map[string]I.SecretProvider {
+	providers := map[string]I.SecretProvider{
+		"env": EnvProvider{Getenv: getenv},
+	}
+
+	if directory := getenv("SECRETS_DIRECTORY"); directory != "" {
+		providers["file"] = FileProvider{Directory: directory}
+	}
+
+	if address := getenv("VAULT_ADDR"); address != "" {
+		providers["vault"] = HTTPProvider{Address: address, Token: getenv("VAULT_TOKEN")}
+	}
+
+	return providers
+}
@@ -0,0 +1,156 @@
+package secrets_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/compozed/deployadactyl/secrets"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Providers", func() {
+	Describe("EnvProvider", func() {
+		var provider EnvProvider
+
+		BeforeEach(func() {
+			variables := map[string]string{"PASSWORD": "password", "PRODUCTION_PASSWORD": "production-password"}
+			provider = EnvProvider{Getenv: func(name string) string { return variables[name] }}
+		})
+
+		It("reads the variable named by the path", func() {
+			Expect(provider.Secret("PASSWORD", "")).To(Equal("password"))
+		})
+
+		It("appends the key to the name of the variable", func() {
+			Expect(provider.Secret("PRODUCTION", "PASSWORD")).To(Equal("production-password"))
+		})
+
+		It("returns an error when the variable is not set", func() {
+			_, err := provider.Secret("TOKEN", "")
+
+			Expect(err).To(MatchError(SecretNotFoundError{"env", "TOKEN", ""}))
+		})
+	})
+
+	Describe("FileProvider", func() {
+		var (
+			directory string
+			provider  FileProvider
+		)
+
+		BeforeEach(func() {
+			var err error
+			directory, err = ioutil.TempDir("", "secrets-")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Join(directory, "production"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(directory, "production", "password"), []byte("production-password\n"), 0600)).To(Succeed())
+
+			provider = FileProvider{Directory: directory}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(directory)
+		})
+
+		It("reads the file named by the path and the key", func() {
+			Expect(provider.Secret("production", "password")).To(Equal("production-password"))
+			Expect(provider.Secret("production/password", "")).To(Equal("production-password"))
+		})
+
+		It("reads the file again when it changes", func() {
+			Expect(ioutil.WriteFile(filepath.Join(directory, "production", "password"), []byte("rotated-password"), 0600)).To(Succeed())
+
+			Expect(provider.Secret("production", "password")).To(Equal("rotated-password"))
+		})
+
+		It("does not read files outside of its directory", func() {
+			_, err := provider.Secret("../"+filepath.Base(directory)+"/production", "password")
+
+			Expect(err).To(MatchError(SecretNotFoundError{"file", "../" + filepath.Base(directory) + "/production", "password"}))
+		})
+
+		It("returns an error when the file does not exist", func() {
+			_, err := provider.Secret("production", "token")
+
+			Expect(err).To(MatchError(SecretNotFoundError{"file", "production", "token"}))
+		})
+	})
+
+	Describe("HTTPProvider", func() {
+		var (
+			server   *httptest.Server
+			provider HTTPProvider
+			token    string
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				token = r.Header.Get("X-Vault-Token")
+
+				switch r.URL.Path {
+				case "/v1/secret/production":
+					w.Write([]byte(`{"data":{"password":"production-password"}}`))
+				case "/v1/secret/data/production":
+					w.Write([]byte(`{"data":{"data":{"password":"production-password"},"metadata":{"version":2}}}`))
+				case "/v1/secret/broken":
+					w.WriteHeader(http.StatusInternalServerError)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+
+			provider = HTTPProvider{Address: server.URL, Token: "vault-token"}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("reads secrets of version 1 of the key/value secrets engine", func() {
+			Expect(provider.Secret("secret/production", "password")).To(Equal("production-password"))
+			Expect(token).To(Equal("vault-token"))
+		})
+
+		It("reads secrets of version 2 of the key/value secrets engine", func() {
+			Expect(provider.Secret("secret/data/production", "password")).To(Equal("production-password"))
+		})
+
+		It("returns an error when the secret or its key does not exist", func() {
+			_, err := provider.Secret("secret/staging", "password")
+			Expect(err).To(MatchError(SecretNotFoundError{"vault", "secret/staging", "password"}))
+
+			_, err = provider.Secret("secret/production", "token")
+			Expect(err).To(MatchError(SecretNotFoundError{"vault", "secret/production", "token"}))
+		})
+
+		It("returns a retryable error when the store fails", func() {
+			_, err := provider.Secret("secret/broken", "password")
+
+			Expect(err).To(BeAssignableToTypeOf(ProviderError{}))
+			Expect(err.(ProviderError).Retryable()).To(BeTrue())
+		})
+	})
+
+	Describe("Providers", func() {
+		It("configures the providers from the environment", func() {
+			variables := map[string]string{"SECRETS_DIRECTORY": "/etc/secrets", "VAULT_ADDR": "http://127.0.0.1:8200", "VAULT_TOKEN": "vault-token"}
+
+			providers := Providers(func(name string) string { return variables[name] })
+
+			Expect(providers).To(HaveKey("env"))
+			Expect(providers["file"]).To(Equal(FileProvider{Directory: "/etc/secrets"}))
+			Expect(providers["vault"]).To(Equal(HTTPProvider{Address: "http://127.0.0.1:8200", Token: "vault-token"}))
+		})
+
+		It("only configures the env provider by default", func() {
+			providers := Providers(func(string) string { return "" })
+
+			Expect(providers).To(HaveLen(1))
+			Expect(providers).To(HaveKey("env"))
+		})
+	})
+})
@@ -0,0 +1,103 @@
+// Package secrets resolves secret references in the credentials of environments.
+package secrets
+
+import (
+	"strings"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+// Scheme prefixes the values in the configuration that are references to secrets.
+const Scheme = "secret://"
+
+// IsReference tells whether the value is a reference to a secret.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Scheme)
+}
+
+// ParseReference splits a secret reference of the form secret://<provider>/<path>#<key> into its parts.
+// The key is optional.
+func ParseReference(reference string) (provider, path, key string, err error) {
+	if !IsReference(reference) {
+		return "", "", "", InvalidReferenceError{reference}
+	}
+
+	rest := strings.TrimPrefix(reference, Scheme)
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		rest, key = rest[:i], rest[i+1:]
+		if key == "" {
+			return "", "", "", InvalidReferenceError{reference}
+		}
+	}
+
+	i := strings.Index(rest, "/")
+	if i <= 0 || i == len(rest)-1 {
+		return "", "", "", InvalidReferenceError{reference}
+	}
+
+	return rest[:i], rest[i+1:], key, nil
+}
+
+// Resolver looks up the secrets referenced by credentials with the providers they name.
+type Resolver struct {
+	Providers map[string]I.SecretProvider
+}
+
+// NewResolver returns a Resolver with the providers.
+func NewResolver(providers map[string]I.SecretProvider) Resolver {
+	return Resolver{Providers: providers}
+}
+
+// Resolve returns the secret the value references, or the value itself when it is not a reference.
+func (r Resolver) Resolve(value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	name, path, key, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+
+	provider, ok := r.Providers[name]
+	if !ok {
+		return "", UnknownProviderError{name}
+	}
+
+	return provider.Secret(path, key)
+}
+
+// ResolveCredentials returns a copy of the environment with the secrets its credentials reference looked up.
+// The environment itself is left untouched so that the secrets are looked up again on the next deployment.
+func (r Resolver) ResolveCredentials(environment S.Environment) (S.Environment, error) {
+	credentials, err := r.resolve(environment.Credentials)
+	if err != nil {
+		return S.Environment{}, err
+	}
+	environment.Credentials = credentials
+
+	if environment.FoundationCredentials != nil {
+		foundationCredentials := make(map[string]S.Credentials, len(environment.FoundationCredentials))
+		for foundation, credentials := range environment.FoundationCredentials {
+			foundationCredentials[foundation], err = r.resolve(credentials)
+			if err != nil {
+				return S.Environment{}, err
+			}
+		}
+		environment.FoundationCredentials = foundationCredentials
+	}
+
+	return environment, nil
+}
+
+func (r Resolver) resolve(credentials S.Credentials) (S.Credentials, error) {
+	var err error
+	for _, value := range []*string{&credentials.Username, &credentials.Password, &credentials.ClientID, &credentials.ClientSecret} {
+		*value, err = r.Resolve(*value)
+		if err != nil {
+			return S.Credentials{}, err
+		}
+	}
+	return credentials, nil
+}
@@ -0,0 +1,134 @@
+package secrets_test
+
+import (
+	"errors"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	. "github.com/compozed/deployadactyl/secrets"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type provider map[string]string
+
+func (p provider) Secret(path, key string) (string, error) {
+	if path == "broken" {
+		return "", errors.New("provider is down")
+	}
+	value, ok := p[path+"#"+key]
+	if !ok {
+		return "", SecretNotFoundError{"test", path, key}
+	}
+	return value, nil
+}
+
+var _ = Describe("Secrets", func() {
+	Describe("ParseReference", func() {
+		It("splits the reference into its provider, path and key", func() {
+			name, path, key, err := ParseReference("secret://vault/secret/data/production#password")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal("vault"))
+			Expect(path).To(Equal("secret/data/production"))
+			Expect(key).To(Equal("password"))
+		})
+
+		It("does not need a key", func() {
+			name, path, key, err := ParseReference("secret://env/PASSWORD")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal("env"))
+			Expect(path).To(Equal("PASSWORD"))
+			Expect(key).To(BeEmpty())
+		})
+
+		It("rejects malformed references", func() {
+			for _, reference := range []string{"password", "secret://", "secret://vault", "secret://vault/", "secret:///path", "secret://vault/path#"} {
+				_, _, _, err := ParseReference(reference)
+
+				Expect(err).To(MatchError(InvalidReferenceError{reference}), reference)
+			}
+		})
+	})
+
+	Describe("Resolver", func() {
+		var resolver Resolver
+
+		BeforeEach(func() {
+			resolver = NewResolver(map[string]I.SecretProvider{
+				"test": provider{
+					"production#password": "production-password",
+					"api2#secret":         "api2-secret",
+				},
+			})
+		})
+
+		It("returns values that are not references as they are", func() {
+			value, err := resolver.Resolve("password")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal("password"))
+		})
+
+		It("looks up references with the provider they name", func() {
+			value, err := resolver.Resolve("secret://test/production#password")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal("production-password"))
+		})
+
+		It("returns an error for an unknown provider", func() {
+			_, err := resolver.Resolve("secret://vault/production#password")
+
+			Expect(err).To(MatchError(UnknownProviderError{"vault"}))
+		})
+
+		It("returns the errors of the provider", func() {
+			_, err := resolver.Resolve("secret://test/broken#password")
+
+			Expect(err).To(MatchError("provider is down"))
+		})
+
+		Describe("ResolveCredentials", func() {
+			var environment S.Environment
+
+			BeforeEach(func() {
+				environment = S.Environment{
+					Name:        "production",
+					Credentials: S.Credentials{Username: "deployadactyl", Password: "secret://test/production#password"},
+					FoundationCredentials: map[string]S.Credentials{
+						"api2.example.com": {ClientID: "deployadactyl", ClientSecret: "secret://test/api2#secret"},
+					},
+				}
+			})
+
+			It("resolves the credentials of the environment and its foundations", func() {
+				resolved, err := resolver.ResolveCredentials(environment)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resolved.Name).To(Equal("production"))
+				Expect(resolved.Credentials).To(Equal(S.Credentials{Username: "deployadactyl", Password: "production-password"}))
+				Expect(resolved.FoundationCredentials).To(Equal(map[string]S.Credentials{
+					"api2.example.com": {ClientID: "deployadactyl", ClientSecret: "api2-secret"},
+				}))
+			})
+
+			It("leaves the references in the environment to look them up again", func() {
+				_, err := resolver.ResolveCredentials(environment)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(environment.Credentials.Password).To(Equal("secret://test/production#password"))
+				Expect(environment.FoundationCredentials["api2.example.com"].ClientSecret).To(Equal("secret://test/api2#secret"))
+			})
+
+			It("returns an error when a secret is not found", func() {
+				environment.FoundationCredentials["api2.example.com"] = S.Credentials{ClientID: "deployadactyl", ClientSecret: "secret://test/api3#secret"}
+
+				_, err := resolver.ResolveCredentials(environment)
+
+				Expect(err).To(MatchError(SecretNotFoundError{"test", "api3", "secret"}))
+			})
+		})
+	})
+})
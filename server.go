@@ -7,7 +7,11 @@ import (
 	"os"
 
 	"github.com/compozed/deployadactyl/creator"
+	"github.com/compozed/deployadactyl/state/command"
 	"github.com/compozed/deployadactyl/state/push"
+	"github.com/compozed/deployadactyl/state/revert"
+	"github.com/compozed/deployadactyl/state/start"
+	"github.com/compozed/deployadactyl/state/stop"
 	"github.com/op/go-logging"
 	"github.com/compozed/deployadactyl/interfaces"
 )
@@ -63,6 +67,30 @@ func main() {
 		em.AddBinding(push.NewPushFinishedEventBinding(routeMapper.PushFinishedEventHandler))
 	}
 
+	recorder := c.CreateHistoryRecorder()
+	log.Infof("registering deployment history handlers")
+	em.AddBinding(push.NewDeployStartEventBinding(recorder.DeployStartedEventHandler))
+	em.AddBinding(push.NewArtifactRetrievalSuccessEventBinding(recorder.ArtifactRetrievalSuccessEventHandler))
+	em.AddBinding(push.NewDeploySuccessEventBinding(recorder.DeploySuccessEventHandler))
+	em.AddBinding(push.NewDeployFailureEventBinding(recorder.DeployFailureEventHandler))
+	em.AddBinding(push.NewDeployFinishedEventBinding(recorder.DeployFinishedEventHandler))
+	em.AddBinding(start.NewStartStartedEventBinding(recorder.StartStartedEventHandler))
+	em.AddBinding(start.NewStartSuccessEventBinding(recorder.StartSuccessEventHandler))
+	em.AddBinding(start.NewStartFailureEventBinding(recorder.StartFailureEventHandler))
+	em.AddBinding(start.NewStartFinishedEventBinding(recorder.StartFinishedEventHandler))
+	em.AddBinding(stop.NewStopStartedEventBinding(recorder.StopStartedEventHandler))
+	em.AddBinding(stop.NewStopSuccessEventBinding(recorder.StopSuccessEventHandler))
+	em.AddBinding(stop.NewStopFailureEventBinding(recorder.StopFailureEventHandler))
+	em.AddBinding(stop.NewStopFinishedEventBinding(recorder.StopFinishedEventHandler))
+	em.AddBinding(revert.NewRevertStartedEventBinding(recorder.RevertStartedEventHandler))
+	em.AddBinding(revert.NewRevertSuccessEventBinding(recorder.RevertSuccessEventHandler))
+	em.AddBinding(revert.NewRevertFailureEventBinding(recorder.RevertFailureEventHandler))
+	em.AddBinding(revert.NewRevertFinishedEventBinding(recorder.RevertFinishedEventHandler))
+	em.AddBinding(command.NewStartedEventBinding(recorder.CommandStartedEventHandler))
+	em.AddBinding(command.NewSuccessEventBinding(recorder.CommandSuccessEventHandler))
+	em.AddBinding(command.NewFailureEventBinding(recorder.CommandFailureEventHandler))
+	em.AddBinding(command.NewFinishedEventBinding(recorder.CommandFinishedEventHandler))
+
 	l := c.CreateListener()
 	controller := c.CreateController()
 
@@ -0,0 +1,510 @@
+package fakecf
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/candiedyaml"
+)
+
+// flagsWithValues are the flags of the cf commands the Courier runs that take a value.
+var flagsWithValues = map[string]bool{
+	"-a": true, "-u": true, "-p": true, "-o": true, "-s": true,
+	"-i": true, "-n": true, "-m": true, "--path": true,
+}
+
+type arguments struct {
+	positional []string
+	flags      map[string]string
+}
+
+func parse(args []string) arguments {
+	a := arguments{flags: map[string]string{}}
+
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "":
+		case flagsWithValues[args[i]] && i+1 < len(args):
+			a.flags[args[i]] = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "-"):
+			a.flags[args[i]] = "true"
+		default:
+			a.positional = append(a.positional, args[i])
+		}
+	}
+
+	return a
+}
+
+// arg returns the positional argument, or an empty string when it is missing.
+func (a arguments) arg(i int) string {
+	if i < len(a.positional) {
+		return a.positional[i]
+	}
+	return ""
+}
+
+func failed(format string, args ...interface{}) Result {
+	return Result{Output: "FAILED\n" + fmt.Sprintf(format, args...) + "\n", ExitCode: 1}
+}
+
+func ok(format string, args ...interface{}) Result {
+	return Result{Output: fmt.Sprintf(format, args...) + "\nOK\n"}
+}
+
+// Run runs the cf command against the foundation the home of the command has logged in to.
+func (s *Server) Run(command Command) Result {
+	if len(command.Args) == 0 {
+		return failed("no command given")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	name, args := command.Args[0], parse(command.Args[1:])
+
+	switch name {
+	case "login":
+		return s.login(command.Home, args)
+	case "api":
+		return s.api(command.Home, args)
+	case "auth":
+		return s.auth(command.Home, args)
+	}
+
+	current, loggedIn := s.session(command.Home)
+	if !loggedIn {
+		return failed("Not logged in. Use 'cf login' to log in.")
+	}
+
+	f := s.foundation(current.foundation)
+	f.commands = append(f.commands, name)
+	if f.failures[name] {
+		return failed("cf %s failed on %s", name, current.foundation)
+	}
+
+	switch name {
+	case "target":
+		return s.target(command.Home, current, args)
+	case "push":
+		return s.push(f, command.Directory, args)
+	case "app":
+		return s.app(f, current, args.arg(0))
+	case "rename":
+		return rename(f, args.arg(0), args.arg(1))
+	case "delete":
+		return deleteApp(f, args.arg(0))
+	case "start":
+		return setState(f, args.arg(0), "started", "Starting")
+	case "restart":
+		return setState(f, args.arg(0), "started", "Restarting")
+	case "restage":
+		return setState(f, args.arg(0), "started", "Restaging")
+	case "stop":
+		return setState(f, args.arg(0), "stopped", "Stopping")
+	case "scale":
+		return scale(f, args)
+	case "map-route":
+		return s.mapRoute(f, args)
+	case "unmap-route":
+		return unmapRoute(f, args)
+	case "delete-route":
+		return deleteRoute(f, args)
+	case "domains":
+		return s.domains(current)
+	case "env":
+		return env(f, args.arg(0))
+	case "logs":
+		return logs(f, args.arg(0))
+	case "services":
+		return services(f, current)
+	case "create-service":
+		return createService(f, args.arg(0), args.arg(1), args.arg(2))
+	case "bind-service":
+		return bindService(f, args.arg(0), args.arg(1))
+	case "unbind-service":
+		return unbindService(f, args.arg(0), args.arg(1))
+	case "delete-service":
+		return deleteService(f, args.arg(0))
+	case "cups":
+		return cups(f, args.arg(0), args.flags["-p"])
+	case "uups":
+		return uups(f, args.arg(0), args.flags["-p"])
+	}
+
+	return failed("'%s' is not a registered command. See 'cf help -a'", name)
+}
+
+type manifest struct {
+	Applications []struct {
+		Instances *uint16           `yaml:"instances"`
+		Memory    string            `yaml:"memory"`
+		Buildpack string            `yaml:"buildpack"`
+		Stack     string            `yaml:"stack"`
+		Env       map[string]string `yaml:"env"`
+	} `yaml:"applications"`
+}
+
+func (s *Server) push(f *foundation, directory string, args arguments) Result {
+	name := args.arg(0)
+	if name == "" {
+		return failed("Incorrect Usage: the required argument `APP_NAME` was not provided")
+	}
+
+	app, exists := f.apps[name]
+	if !exists {
+		app = &App{Name: name, Instances: 1, Memory: "1G", Stack: "cflinuxfs3", Env: map[string]string{}}
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(directory, "manifest.yml"))
+	if err == nil {
+		var m manifest
+		err = candiedyaml.Unmarshal(contents, &m)
+		if err != nil {
+			return failed("Error reading manifest file: %s", err)
+		}
+		if len(m.Applications) > 0 {
+			a := m.Applications[0]
+			if a.Instances != nil {
+				app.Instances = *a.Instances
+			}
+			if a.Memory != "" {
+				app.Memory = a.Memory
+			}
+			if a.Buildpack != "" {
+				app.Buildpack = a.Buildpack
+			}
+			if a.Stack != "" {
+				app.Stack = a.Stack
+			}
+			for k, v := range a.Env {
+				app.Env[k] = v
+			}
+		}
+	}
+
+	if instances, ok := args.flags["-i"]; ok {
+		n, err := strconv.Atoi(instances)
+		if err != nil || n < 0 {
+			return failed("Incorrect Usage: invalid instances %s", instances)
+		}
+		app.Instances = uint16(n)
+	}
+
+	hostname := args.flags["-n"]
+	if hostname == "" {
+		hostname = name
+	}
+	if len(s.Domains) > 0 {
+		route := hostname + "." + s.Domains[0]
+		f.routes[route] = true
+		app.Routes = appendMissing(app.Routes, route)
+	}
+
+	app.State = "started"
+	f.apps[name] = app
+
+	return ok("Pushing app %s\nUploading files...\nStaging app and tracing logs...\nWaiting for app to start...", name)
+}
+
+func (s *Server) app(f *foundation, current session, name string) Result {
+	app, exists := f.apps[name]
+	if !exists {
+		return failed("App %s not found", name)
+	}
+
+	running := uint16(0)
+	if app.State == "started" {
+		running = app.Instances
+	}
+
+	routes := make([]string, 0, len(app.Routes))
+	for _, route := range app.Routes {
+		routes = append(routes, s.routeURL(current.foundation, route))
+	}
+
+	output := &bytes.Buffer{}
+	fmt.Fprintf(output, "Showing health and status for app %s in org %s / space %s as %s...\n\n", name, current.org, current.space, current.user)
+	fmt.Fprintf(output, "name:              %s\n", name)
+	fmt.Fprintf(output, "requested state:   %s\n", app.State)
+	fmt.Fprintf(output, "routes:            %s\n", strings.Join(routes, ", "))
+	fmt.Fprintf(output, "stack:             %s\n", app.Stack)
+	fmt.Fprintf(output, "buildpacks:        %s\n\n", app.Buildpack)
+	fmt.Fprintf(output, "type:           web\n")
+	fmt.Fprintf(output, "instances:      %d/%d\n", running, app.Instances)
+	fmt.Fprintf(output, "memory usage:   %s\n", app.Memory)
+	fmt.Fprintf(output, "     state     since                  cpu    memory   disk     details\n")
+	for i := uint16(0); i < app.Instances; i++ {
+		state := "down"
+		if app.State == "started" {
+			state = "running"
+		}
+		fmt.Fprintf(output, "#%d   %s   2018-01-01T00:00:00Z   0.0%%   0 of %s   0 of 1G\n", i, state, app.Memory)
+	}
+
+	return Result{Output: output.String()}
+}
+
+func rename(f *foundation, oldName, newName string) Result {
+	app, exists := f.apps[oldName]
+	if !exists {
+		return failed("App %s not found", oldName)
+	}
+	if _, taken := f.apps[newName]; taken {
+		return failed("The app name is taken: %s", newName)
+	}
+
+	delete(f.apps, oldName)
+	app.Name = newName
+	f.apps[newName] = app
+
+	return ok("Renaming app %s to %s...", oldName, newName)
+}
+
+func deleteApp(f *foundation, name string) Result {
+	if _, exists := f.apps[name]; !exists {
+		return ok("Deleting app %s...\nApp %s does not exist.", name, name)
+	}
+
+	delete(f.apps, name)
+	for _, s := range f.services {
+		s.boundApps = remove(s.boundApps, name)
+	}
+
+	return ok("Deleting app %s...", name)
+}
+
+func setState(f *foundation, name, state, action string) Result {
+	app, exists := f.apps[name]
+	if !exists {
+		return failed("App %s not found", name)
+	}
+
+	app.State = state
+	return ok("%s app %s...", action, name)
+}
+
+func scale(f *foundation, args arguments) Result {
+	app, exists := f.apps[args.arg(0)]
+	if !exists {
+		return failed("App %s not found", args.arg(0))
+	}
+
+	if instances, ok := args.flags["-i"]; ok {
+		n, err := strconv.Atoi(instances)
+		if err != nil || n < 0 {
+			return failed("Incorrect Usage: invalid instances %s", instances)
+		}
+		app.Instances = uint16(n)
+	}
+	if memory, ok := args.flags["-m"]; ok {
+		app.Memory = memory
+	}
+
+	return ok("Scaling app %s...", app.Name)
+}
+
+// route returns the route of the arguments of map-route, unmap-route or delete-route.
+func route(domain string, args arguments) string {
+	route := args.flags["-n"] + "." + domain
+	if path := strings.TrimPrefix(args.flags["--path"], "/"); path != "" {
+		route += "/" + path
+	}
+	return route
+}
+
+func (s *Server) mapRoute(f *foundation, args arguments) Result {
+	app, exists := f.apps[args.arg(0)]
+	if !exists {
+		return failed("App %s not found", args.arg(0))
+	}
+	if !contains(s.Domains, args.arg(1)) {
+		return failed("Domain %s not found", args.arg(1))
+	}
+
+	r := route(args.arg(1), args)
+	f.routes[r] = true
+	app.Routes = appendMissing(app.Routes, r)
+
+	return ok("Adding route %s to app %s...", r, app.Name)
+}
+
+func unmapRoute(f *foundation, args arguments) Result {
+	app, exists := f.apps[args.arg(0)]
+	if !exists {
+		return failed("App %s not found", args.arg(0))
+	}
+
+	r := route(args.arg(1), args)
+	if !f.routes[r] {
+		return ok("Removing route %s from app %s...\nRoute %s does not exist.", r, app.Name, r)
+	}
+	app.Routes = remove(app.Routes, r)
+
+	return ok("Removing route %s from app %s...", r, app.Name)
+}
+
+func deleteRoute(f *foundation, args arguments) Result {
+	r := route(args.arg(0), args)
+	if !f.routes[r] {
+		return ok("Deleting route %s...\nUnable to delete, route '%s' does not exist.", r, r)
+	}
+
+	delete(f.routes, r)
+	for _, app := range f.apps {
+		app.Routes = remove(app.Routes, r)
+	}
+
+	return ok("Deleting route %s...", r)
+}
+
+func (s *Server) domains(current session) Result {
+	output := &bytes.Buffer{}
+	fmt.Fprintf(output, "Getting domains in org %s as %s...\n", current.org, current.user)
+	fmt.Fprintf(output, "name   status   type\n")
+	for _, domain := range s.Domains {
+		fmt.Fprintf(output, "%s   shared\n", domain)
+	}
+
+	return Result{Output: output.String()}
+}
+
+func env(f *foundation, name string) Result {
+	app, exists := f.apps[name]
+	if !exists {
+		return failed("App %s not found", name)
+	}
+
+	names := make([]string, 0, len(app.Env))
+	for name := range app.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	output := &bytes.Buffer{}
+	fmt.Fprintf(output, "Getting env variables for app %s...\nOK\n\nSystem-Provided:\n{}\n\nUser-Provided:\n", name)
+	for _, name := range names {
+		fmt.Fprintf(output, "%s: %s\n", name, app.Env[name])
+	}
+	fmt.Fprintf(output, "\nNo running env variables have been set\n")
+
+	return Result{Output: output.String()}
+}
+
+func logs(f *foundation, name string) Result {
+	if _, exists := f.apps[name]; !exists {
+		return failed("App %s not found", name)
+	}
+
+	return Result{Output: fmt.Sprintf("Retrieving logs for app %s...\n\n   2018-01-01T00:00:00.00+0000 [APP/PROC/WEB/0] OUT %s started\n", name, name)}
+}
+
+func services(f *foundation, current session) Result {
+	names := make([]string, 0, len(f.services))
+	for name := range f.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	output := &bytes.Buffer{}
+	fmt.Fprintf(output, "Getting services in org %s / space %s as %s...\n\n", current.org, current.space, current.user)
+	fmt.Fprintf(output, "name   service   plan   bound apps   last operation\n")
+	for _, name := range names {
+		s := f.services[name]
+		fmt.Fprintf(output, "%s   %s   %s   %s\n", name, s.offering, s.plan, strings.Join(s.boundApps, ", "))
+	}
+
+	return Result{Output: output.String()}
+}
+
+func createService(f *foundation, offering, plan, name string) Result {
+	if _, exists := f.services[name]; exists {
+		return ok("Creating service instance %s...\nService %s already exists", name, name)
+	}
+
+	f.services[name] = &service{offering: offering, plan: plan}
+	return ok("Creating service instance %s...", name)
+}
+
+func bindService(f *foundation, appName, serviceName string) Result {
+	if _, exists := f.apps[appName]; !exists {
+		return failed("App %s not found", appName)
+	}
+	s, exists := f.services[serviceName]
+	if !exists {
+		return failed("Service instance %s not found", serviceName)
+	}
+
+	s.boundApps = appendMissing(s.boundApps, appName)
+	return ok("Binding service %s to app %s...", serviceName, appName)
+}
+
+func unbindService(f *foundation, appName, serviceName string) Result {
+	s, exists := f.services[serviceName]
+	if !exists {
+		return failed("Service instance %s not found", serviceName)
+	}
+
+	s.boundApps = remove(s.boundApps, appName)
+	return ok("Unbinding app %s from service %s...", appName, serviceName)
+}
+
+func deleteService(f *foundation, name string) Result {
+	if _, exists := f.services[name]; !exists {
+		return ok("Deleting service %s...\nService %s does not exist.", name, name)
+	}
+
+	delete(f.services, name)
+	return ok("Deleting service %s...", name)
+}
+
+func cups(f *foundation, name, credentials string) Result {
+	if _, exists := f.services[name]; exists {
+		return failed("Service instance %s already exists", name)
+	}
+
+	f.services[name] = &service{offering: "user-provided", credentials: credentials}
+	return ok("Creating user provided service %s...", name)
+}
+
+func uups(f *foundation, name, credentials string) Result {
+	s, exists := f.services[name]
+	if !exists || s.offering != "user-provided" {
+		return failed("Service instance %s not found", name)
+	}
+
+	s.credentials = credentials
+	return ok("Updating user provided service %s...", name)
+}
+
+func appendMissing(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+func remove(values []string, value string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
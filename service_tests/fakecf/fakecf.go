@@ -0,0 +1,316 @@
+// Package fakecf is a fake Cloud Foundry for end to end tests. It keeps the apps, routes and
+// services of every foundation in memory and runs the cf commands of a shim binary, built by
+// BuildCLI, against them. Failures of single commands on single foundations can be injected.
+package fakecf
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// URLVariable is the environment variable that tells the cf shim where the Server is.
+const URLVariable = "FAKECF_URL"
+
+// App is an application on a foundation of the Server.
+type App struct {
+	Name      string
+	State     string
+	Instances uint16
+	Memory    string
+	Buildpack string
+	Stack     string
+	// Routes are the routes mapped to the application, such as app.example.com or app.example.com/path.
+	Routes []string
+	Env    map[string]string
+}
+
+// Command is a cf command as the shim sends it to the Server.
+type Command struct {
+	Args      []string `json:"args"`
+	Directory string   `json:"directory"`
+	Home      string   `json:"home"`
+}
+
+// Result is the output and exit code of a cf command.
+type Result struct {
+	Output   string `json:"output"`
+	ExitCode int    `json:"exit_code"`
+}
+
+type service struct {
+	offering    string
+	plan        string
+	credentials string
+	boundApps   []string
+}
+
+type foundation struct {
+	apps     map[string]*App
+	routes   map[string]bool
+	services map[string]*service
+	failures map[string]bool
+	commands []string
+}
+
+// session is who a cf home is logged in as and what it targets.
+type session struct {
+	foundation string
+	org        string
+	space      string
+	user       string
+}
+
+// Server is a fake Cloud Foundry with any number of foundations. A foundation is created by
+// the first cf command that logs in to it, or by AddApp.
+type Server struct {
+	// Domains are the domains of every foundation. The first one is where cf push puts routes.
+	Domains []string
+
+	mutex       sync.Mutex
+	control     *httptest.Server
+	router      *httptest.Server
+	foundations map[string]*foundation
+	// tokens are the foundation and user of every access token the Server handed out.
+	tokens    map[string]session
+	lastToken int
+}
+
+// New starts a Server with the example.com domain.
+func New() *Server {
+	s := &Server{
+		Domains:     []string{"example.com"},
+		foundations: map[string]*foundation{},
+		tokens:      map[string]session{},
+	}
+	s.control = httptest.NewServer(http.HandlerFunc(s.serveCommand))
+	s.router = httptest.NewTLSServer(http.HandlerFunc(s.serveRoute))
+
+	return s
+}
+
+// URL is where the cf shim sends its commands. It has to be exported as URLVariable.
+func (s *Server) URL() string {
+	return s.control.URL
+}
+
+// Close stops the Server.
+func (s *Server) Close() {
+	s.control.Close()
+	s.router.Close()
+}
+
+// Fail makes every run of the cf command, such as login, push or rename, fail on the foundation.
+// Failing health makes the routes of the applications on the foundation respond with 500.
+func (s *Server) Fail(foundationURL, command string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.foundation(foundationURL).failures[command] = true
+}
+
+// Expire revokes the tokens of every session on the foundation, so that the cf commands of
+// homes which logged in to it have to log in again.
+func (s *Server) Expire(foundationURL string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for token, current := range s.tokens {
+		if current.foundation == foundationURL {
+			delete(s.tokens, token)
+		}
+	}
+}
+
+// AddApp adds a started application with its routes to the foundation.
+func (s *Server) AddApp(foundationURL string, app App) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f := s.foundation(foundationURL)
+	if app.State == "" {
+		app.State = "started"
+	}
+	if app.Instances == 0 {
+		app.Instances = 1
+	}
+	if app.Memory == "" {
+		app.Memory = "1G"
+	}
+	if app.Env == nil {
+		app.Env = map[string]string{}
+	}
+	for _, route := range app.Routes {
+		f.routes[route] = true
+	}
+	f.apps[app.Name] = &app
+}
+
+// App returns a copy of the application on the foundation.
+func (s *Server) App(foundationURL, name string) (App, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	app, ok := s.foundation(foundationURL).apps[name]
+	if !ok {
+		return App{}, false
+	}
+	return *app, true
+}
+
+// Apps returns the names of the applications on the foundation, sorted by name.
+func (s *Server) Apps(foundationURL string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var names []string
+	for name := range s.foundation(foundationURL).apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Commands returns the cf commands that ran against the foundation, without the arguments,
+// in the order they ran.
+func (s *Server) Commands(foundationURL string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string{}, s.foundation(foundationURL).commands...)
+}
+
+// foundation returns the state of the foundation, creating it if it does not exist yet.
+// The mutex must be held.
+func (s *Server) foundation(foundationURL string) *foundation {
+	f, ok := s.foundations[foundationURL]
+	if !ok {
+		f = &foundation{
+			apps:     map[string]*App{},
+			routes:   map[string]bool{},
+			services: map[string]*service{},
+			failures: map[string]bool{},
+		}
+		s.foundations[foundationURL] = f
+	}
+	return f
+}
+
+func (s *Server) serveCommand(w http.ResponseWriter, r *http.Request) {
+	var command Command
+	err := json.NewDecoder(r.Body).Decode(&command)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(s.Run(command))
+}
+
+// serveRoute answers a request to a route of an application with 200 when a started application is
+// mapped to the route. The routes reported by cf app name the foundation in their path. A request made
+// with Client to the host of a route goes to every foundation the route is mapped on, like a load
+// balancer in front of all of them, and fails when the application fails on any of them.
+func (s *Server) serveRoute(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var (
+		requested   = r.Host + r.URL.Path
+		foundations = s.foundations
+	)
+
+	if r.Host == strings.TrimPrefix(s.router.URL, "https://") {
+		parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+		if len(parts) < 2 {
+			http.NotFound(w, r)
+			return
+		}
+
+		foundationURL, err := url.PathUnescape(parts[0])
+		f, ok := s.foundations[foundationURL]
+		if err != nil || !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		requested = parts[1]
+		foundations = map[string]*foundation{foundationURL: f}
+	}
+
+	healthy := ""
+	for _, f := range foundations {
+		for _, app := range f.apps {
+			for _, route := range app.Routes {
+				if app.State != "started" || (requested != route && !strings.HasPrefix(requested, route+"/")) {
+					continue
+				}
+
+				if f.failures["health"] {
+					http.Error(w, "injected health failure", http.StatusInternalServerError)
+					return
+				}
+				healthy = app.Name
+			}
+		}
+	}
+
+	if healthy == "" {
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Fprintf(w, "%s is healthy", healthy)
+}
+
+// routeURL is the route as cf app reports it. It points at the router of the Server so that
+// requests to the route reach the application.
+func (s *Server) routeURL(foundationURL, route string) string {
+	return strings.TrimPrefix(s.router.URL, "https://") + "/" + url.PathEscape(foundationURL) + "/" + route
+}
+
+// Client returns an http client that sends the requests to the host of every route to the router of the Server.
+func (s *Server) Client() *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, s.router.Listener.Addr().String())
+		},
+	}}
+}
+
+// BuildCLI builds the cf shim into the directory. Putting the directory first on the PATH makes
+// the Executor run it instead of the Cloud Foundry CLI.
+//
+// Returns the path of the shim.
+func BuildCLI(directory string) (string, error) {
+	_, source, _, _ := runtime.Caller(0)
+
+	name := "cf"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	binary := filepath.Join(directory, name)
+
+	build := exec.Command("go", "build", "-o", binary, "./shim")
+	build.Dir = filepath.Dir(source)
+	build.Env = os.Environ()
+
+	output, err := build.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("cannot build the cf shim: %s: %s", err, output)
+	}
+
+	return binary, nil
+}
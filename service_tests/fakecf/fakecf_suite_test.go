@@ -0,0 +1,39 @@
+package fakecf_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/compozed/deployadactyl/service_tests/fakecf"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+var (
+	cliDirectory string
+	ospath       string
+)
+
+func TestFakeCF(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fake CF Suite")
+}
+
+var _ = BeforeSuite(func() {
+	var err error
+	cliDirectory, err = ioutil.TempDir("", "fakecf-")
+	Expect(err).ToNot(HaveOccurred())
+
+	_, err = fakecf.BuildCLI(cliDirectory)
+	Expect(err).ToNot(HaveOccurred())
+
+	ospath = os.Getenv("PATH")
+	os.Setenv("PATH", cliDirectory+string(os.PathListSeparator)+ospath)
+})
+
+var _ = AfterSuite(func() {
+	os.Setenv("PATH", ospath)
+	os.RemoveAll(cliDirectory)
+})
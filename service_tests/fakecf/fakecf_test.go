@@ -0,0 +1,260 @@
+package fakecf_test
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/executor"
+	"github.com/compozed/deployadactyl/interfaces"
+	. "github.com/compozed/deployadactyl/service_tests/fakecf"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Fake CF", func() {
+	const foundationURL = "api1.example.com"
+
+	var (
+		server      *Server
+		cf          interfaces.Courier
+		appLocation string
+	)
+
+	BeforeEach(func() {
+		server = New()
+		os.Setenv(URLVariable, server.URL())
+
+		ex, err := executor.New(&afero.Afero{Fs: afero.NewOsFs()})
+		Expect(err).ToNot(HaveOccurred())
+		cf = courier.NewCourier(ex)
+
+		appLocation, err = ioutil.TempDir("", "fakecf-app-")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(appLocation, "manifest.yml"), []byte("---\napplications:\n- name: app\n  memory: 512M\n  env:\n    GREETING: hello\n"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		cf.CleanUp()
+		os.RemoveAll(appLocation)
+		os.Unsetenv(URLVariable)
+		server.Close()
+	})
+
+	login := func() {
+		_, err := cf.Login(foundationURL, "user", "password", "org", "space", false, false)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("runs commands against the foundation the courier logged in to", func() {
+		login()
+
+		_, err := cf.Push("app", appLocation, "app", 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(server.Apps(foundationURL)).To(Equal([]string{"app"}))
+		Expect(server.Apps("api2.example.com")).To(BeEmpty())
+		Expect(server.Commands(foundationURL)).To(Equal([]string{"login", "push"}))
+	})
+
+	It("needs a login first", func() {
+		_, err := cf.Push("app", appLocation, "app", 2)
+
+		Expect(err).To(HaveOccurred())
+		Expect(server.Apps(foundationURL)).To(BeEmpty())
+	})
+
+	It("reports the status of an application the way the courier reads it", func() {
+		login()
+		_, err := cf.Push("app", appLocation, "app", 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		status, err := cf.AppStatus("app")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(status.State).To(Equal("started"))
+		Expect(status.Instances).To(Equal(uint16(2)))
+		Expect(status.Running).To(Equal(uint16(2)))
+		Expect(status.Memory).To(Equal("512M"))
+		Expect(status.InstanceStates).To(Equal([]string{"running", "running"}))
+		Expect(status.Routes).To(HaveLen(1))
+		Expect(status.Routes[0]).To(HaveSuffix("/app.example.com"))
+	})
+
+	It("answers requests to the routes of started applications", func() {
+		login()
+		_, err := cf.Push("app", appLocation, "app", 1)
+		Expect(err).ToNot(HaveOccurred())
+		status, err := cf.AppStatus("app")
+		Expect(err).ToNot(HaveOccurred())
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+
+		response, err := client.Get("https://" + status.Routes[0] + "/health")
+		Expect(err).ToNot(HaveOccurred())
+		response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+		_, err = cf.Stop("app")
+		Expect(err).ToNot(HaveOccurred())
+
+		response, err = client.Get("https://" + status.Routes[0] + "/health")
+		Expect(err).ToNot(HaveOccurred())
+		response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("answers requests made with Client to the host of a route", func() {
+		login()
+		_, err := cf.Push("app", appLocation, "app", 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		response, err := server.Client().Get("https://app.example.com/health")
+		Expect(err).ToNot(HaveOccurred())
+		response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+		server.Fail(foundationURL, "health")
+
+		response, err = server.Client().Get("https://app.example.com/health")
+		Expect(err).ToNot(HaveOccurred())
+		response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		response, err = server.Client().Get("https://other.example.com/health")
+		Expect(err).ToNot(HaveOccurred())
+		response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("keeps routes, environment variables, domains and services", func() {
+		server.Domains = []string{"example.com", "apps.example.com"}
+		login()
+		_, err := cf.Push("app", appLocation, "app", 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = cf.MapRouteWithPath("app", "apps.example.com", "app", "path")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = cf.Cups("credentials", `{"user":"name"}`)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = cf.BindService("app", "credentials")
+		Expect(err).ToNot(HaveOccurred())
+
+		app, _ := server.App(foundationURL, "app")
+		Expect(app.Routes).To(Equal([]string{"app.example.com", "app.apps.example.com/path"}))
+
+		variables, err := cf.EnvironmentVariables("app")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(variables).To(Equal(map[string]string{"GREETING": "hello"}))
+
+		domains, err := cf.Domains()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(domains).To(Equal([]string{"example.com", "apps.example.com"}))
+
+		services, err := cf.UserProvidedServices()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(services).To(Equal(map[string][]string{"credentials": {"app"}}))
+	})
+
+	It("renames and deletes applications", func() {
+		server.AddApp(foundationURL, App{Name: "app", Routes: []string{"app.example.com"}})
+		login()
+
+		Expect(cf.Exists("app")).To(BeTrue())
+		_, err := cf.Rename("app", "app-venerable")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cf.Exists("app")).To(BeFalse())
+
+		_, err = cf.Delete("app-venerable")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(server.Apps(foundationURL)).To(BeEmpty())
+	})
+
+	Describe("sessions", func() {
+		var sessions *executor.Sessions
+
+		BeforeEach(func() {
+			sessions = executor.NewSessions()
+		})
+
+		newCourier := func() interfaces.Courier {
+			ex, err := executor.New(&afero.Afero{Fs: afero.NewOsFs()})
+			Expect(err).ToNot(HaveOccurred())
+			return courier.NewCourier(ex.WithSessions(sessions))
+		}
+
+		It("reuses the session of an earlier login on the foundation", func() {
+			first := newCourier()
+			defer first.CleanUp()
+			_, err := first.Login(foundationURL, "user", "password", "org", "space", false, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			second := newCourier()
+			defer second.CleanUp()
+			_, err = second.Login(foundationURL, "user", "password", "org", "space", false, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(second.Exists("app")).To(BeFalse())
+			Expect(server.Commands(foundationURL)).To(Equal([]string{"login", "target", "app"}))
+		})
+
+		It("logs in again when the session has expired", func() {
+			first := newCourier()
+			defer first.CleanUp()
+			_, err := first.Login(foundationURL, "user", "password", "org", "space", false, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			server.Expire(foundationURL)
+
+			second := newCourier()
+			defer second.CleanUp()
+			_, err = second.Login(foundationURL, "user", "password", "org", "space", false, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(server.Commands(foundationURL)).To(Equal([]string{"login", "login"}))
+		})
+
+		It("authenticates with client credentials", func() {
+			first := newCourier()
+			defer first.CleanUp()
+			_, err := first.Login(foundationURL, "client", "secret", "org", "space", false, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			second := newCourier()
+			defer second.CleanUp()
+			_, err = second.Login(foundationURL, "client", "secret", "org", "space", false, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(server.Commands(foundationURL)).To(Equal([]string{"api", "auth", "target", "target"}))
+		})
+	})
+
+	Describe("injected failures", func() {
+		It("fails the login to the foundation", func() {
+			server.Fail(foundationURL, "login")
+
+			_, err := cf.Login(foundationURL, "user", "password", "org", "space", false, false)
+			Expect(err).To(HaveOccurred())
+
+			_, err = cf.Login("api2.example.com", "user", "password", "org", "space", false, false)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fails the command on the foundation and keeps its state", func() {
+			server.AddApp(foundationURL, App{Name: "app"})
+			server.Fail(foundationURL, "rename")
+			login()
+
+			out, err := cf.Rename("app", "app-venerable")
+
+			Expect(err).To(HaveOccurred())
+			Expect(strings.HasPrefix(string(out), "FAILED")).To(BeTrue())
+			Expect(server.Apps(foundationURL)).To(Equal([]string{"app"}))
+		})
+	})
+})
@@ -0,0 +1,152 @@
+package fakecf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// config is the part of the configuration of the Cloud Foundry CLI, CF_HOME/.cf/config.json,
+// that the Server keeps its sessions in. Copying it into another home copies the session.
+type config struct {
+	Target             string
+	AccessToken        string
+	OrganizationFields struct {
+		Name string
+	}
+	SpaceFields struct {
+		Name string
+	}
+}
+
+func configPath(home string) string {
+	return filepath.Join(home, ".cf", "config.json")
+}
+
+func readConfig(home string) config {
+	var c config
+
+	contents, err := ioutil.ReadFile(configPath(home))
+	if err == nil {
+		json.Unmarshal(contents, &c)
+	}
+
+	return c
+}
+
+func writeConfig(home string, c config) error {
+	err := os.MkdirAll(filepath.Dir(configPath(home)), 0700)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(configPath(home), contents, 0600)
+}
+
+// session returns the session of the home, if its token has not been revoked. The mutex must be held.
+func (s *Server) session(home string) (session, bool) {
+	c := readConfig(home)
+
+	current, ok := s.tokens[c.AccessToken]
+	if !ok {
+		return session{}, false
+	}
+	current.org = c.OrganizationFields.Name
+	current.space = c.SpaceFields.Name
+
+	return current, true
+}
+
+// authenticate hands out a new token to the user of the home. The mutex must be held.
+func (s *Server) authenticate(home, foundationURL, user string) (config, error) {
+	s.lastToken++
+	token := fmt.Sprintf("bearer token-%d", s.lastToken)
+	s.tokens[token] = session{foundation: foundationURL, user: user}
+
+	c := config{Target: foundationURL, AccessToken: token}
+	return c, writeConfig(home, c)
+}
+
+// login runs cf login, which authenticates with a password and targets the org and space.
+func (s *Server) login(home string, args arguments) Result {
+	foundationURL := args.flags["-a"]
+	f := s.foundation(foundationURL)
+	f.commands = append(f.commands, "login")
+
+	if f.failures["login"] || args.flags["-u"] == "" || args.flags["-p"] == "" {
+		os.Remove(configPath(home))
+		return failed("API endpoint: %s\nAuthenticating...\nCredentials were rejected, please try again.", foundationURL)
+	}
+
+	c, err := s.authenticate(home, foundationURL, args.flags["-u"])
+	if err != nil {
+		return failed("%s", err)
+	}
+	c.OrganizationFields.Name = args.flags["-o"]
+	c.SpaceFields.Name = args.flags["-s"]
+	err = writeConfig(home, c)
+	if err != nil {
+		return failed("%s", err)
+	}
+
+	return ok("API endpoint: %s\nAuthenticating...\nTargeted org %s\nTargeted space %s", foundationURL, args.flags["-o"], args.flags["-s"])
+}
+
+// api runs cf api, which sets the foundation the home logs in to next.
+func (s *Server) api(home string, args arguments) Result {
+	foundationURL := args.arg(0)
+	f := s.foundation(foundationURL)
+	f.commands = append(f.commands, "api")
+
+	err := writeConfig(home, config{Target: foundationURL})
+	if err != nil {
+		return failed("%s", err)
+	}
+
+	return ok("Setting api endpoint to %s...", foundationURL)
+}
+
+// auth runs cf auth, which authenticates with a password or, with --client-credentials,
+// as a UAA client, without targeting an org or space.
+func (s *Server) auth(home string, args arguments) Result {
+	foundationURL := readConfig(home).Target
+	if foundationURL == "" {
+		return failed("No API endpoint set. Use 'cf api' to set an endpoint")
+	}
+
+	f := s.foundation(foundationURL)
+	f.commands = append(f.commands, "auth")
+
+	if f.failures["login"] || f.failures["auth"] || args.arg(0) == "" || args.arg(1) == "" {
+		os.Remove(configPath(home))
+		return failed("API endpoint: %s\nAuthenticating...\nCredentials were rejected, please try again.", foundationURL)
+	}
+
+	_, err := s.authenticate(home, foundationURL, args.arg(0))
+	if err != nil {
+		return failed("%s", err)
+	}
+
+	return ok("API endpoint: %s\nAuthenticating...", foundationURL)
+}
+
+// target runs cf target, which targets the org and space with the session of the home.
+func (s *Server) target(home string, current session, args arguments) Result {
+	c := readConfig(home)
+	c.OrganizationFields.Name = args.flags["-o"]
+	c.SpaceFields.Name = args.flags["-s"]
+
+	err := writeConfig(home, c)
+	if err != nil {
+		return failed("%s", err)
+	}
+
+	return ok("API endpoint: %s\nuser: %s\norg: %s\nspace: %s", current.foundation, current.user, c.OrganizationFields.Name, c.SpaceFields.Name)
+}
@@ -0,0 +1,45 @@
+// Command shim stands in for the Cloud Foundry CLI in end to end tests. It sends its arguments
+// to the fake Cloud Foundry at FAKECF_URL and prints the output of the command.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/compozed/deployadactyl/service_tests/fakecf"
+)
+
+func main() {
+	serverURL := os.Getenv(fakecf.URLVariable)
+	if serverURL == "" {
+		fmt.Printf("FAILED\n%s is not set\n", fakecf.URLVariable)
+		os.Exit(1)
+	}
+
+	directory, _ := os.Getwd()
+	command, err := json.Marshal(fakecf.Command{Args: os.Args[1:], Directory: directory, Home: os.Getenv("CF_HOME")})
+	if err != nil {
+		fmt.Printf("FAILED\n%s\n", err)
+		os.Exit(1)
+	}
+
+	response, err := http.Post(serverURL, "application/json", bytes.NewReader(command))
+	if err != nil {
+		fmt.Printf("FAILED\n%s\n", err)
+		os.Exit(1)
+	}
+	defer response.Body.Close()
+
+	var result fakecf.Result
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		fmt.Printf("FAILED\n%s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(result.Output)
+	os.Exit(result.ExitCode)
+}
@@ -114,7 +114,7 @@ environments:
 	})
 
 	It("returns correct status code", func() {
-		Expect(response.StatusCode).To(Equal(http.StatusInternalServerError), string(responseBody))
+		Expect(response.StatusCode).To(Equal(http.StatusUnprocessableEntity), string(responseBody))
 	})
 	It("calls prechecker with all foundation urls", func() {
 		fs := prechecker.AssertAllFoundationsUpCall.Received.Environment.Foundations
@@ -146,7 +146,7 @@ applications:
 	})
 
 	It("returns correct status code", func() {
-		Expect(response.StatusCode).To(Equal(http.StatusOK), string(responseBody))
+		Expect(response.StatusCode).To(Equal(http.StatusUnprocessableEntity), string(responseBody))
 	})
 	It("calls prechecker with all foundation urls", func() {
 		fs := prechecker.AssertAllFoundationsUpCall.Received.Environment.Foundations
@@ -0,0 +1,225 @@
+package push
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/compozed/deployadactyl/creator"
+	"github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/compozed/deployadactyl/service_tests/fakecf"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var cliDirectory string
+
+var _ = Describe("Service against a fake Cloud Foundry", func() {
+
+	const (
+		CONFIGPATH      = "./fake_cf_config.yml"
+		ENVIRONMENTNAME = "test"
+		TESTCONFIG      = `---
+environments:
+- name: Test
+  domain: example.com
+  rollback_enabled: true
+  foundations:
+  - api1.example.com
+  - api2.example.com
+`
+		foundation1 = "api1.example.com"
+		foundation2 = "api2.example.com"
+	)
+
+	var (
+		cf           *fakecf.Server
+		path         string
+		appLocation  string
+		response     *http.Response
+		responseBody []byte
+		appName      string
+	)
+
+	BeforeEach(func() {
+		if cliDirectory == "" {
+			directory, err := ioutil.TempDir("", "fakecf-")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = fakecf.BuildCLI(directory)
+			Expect(err).ToNot(HaveOccurred())
+			cliDirectory = directory
+		}
+		path = os.Getenv("PATH")
+		os.Setenv("PATH", cliDirectory+string(os.PathListSeparator)+path)
+
+		cf = fakecf.New()
+		os.Setenv(fakecf.URLVariable, cf.URL())
+
+		os.Setenv("CF_USERNAME", randomizer.StringRunes(10))
+		os.Setenv("CF_PASSWORD", randomizer.StringRunes(10))
+		Expect(ioutil.WriteFile(CONFIGPATH, []byte(TESTCONFIG), 0644)).To(Succeed())
+
+		appName = randomizer.StringRunes(10)
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		appLocation, err = ioutil.TempDir("", "fakecf-push-")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(appLocation, "manifest.yml"), []byte("---\napplications:\n- name: "+appName+"\n  memory: 64M\n"), 0644)).To(Succeed())
+
+		fetcher := &mocks.Fetcher{}
+		fetcher.FetchCall.Returns.AppPath = appLocation
+
+		provider := creator.CreatorModuleProvider{
+			NewPrechecker: func(eventManager interfaces.EventManager) interfaces.Prechecker {
+				return &mocks.Prechecker{}
+			},
+			NewFetcher: func(fs *afero.Afero, ex interfaces.Extractor, log interfaces.DeploymentLogger) interfaces.Fetcher {
+				return fetcher
+			},
+			NewEventManager: func(log interfaces.Logger) interfaces.EventManager {
+				return &mocks.EventManager{}
+			},
+			HTTPClient: cf.Client(),
+		}
+
+		c, err := creator.Custom("DEBUG", CONFIGPATH, provider)
+		Expect(err).ToNot(HaveOccurred())
+
+		deployadactylServer := httptest.NewServer(c.CreateControllerHandler(c.CreateController()))
+		defer deployadactylServer.Close()
+
+		j, err := json.Marshal(gin.H{
+			"artifact_url":          "the artifact url",
+			"health_check_endpoint": "/health",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		requestURL := fmt.Sprintf("%s/v3/apps/%s/%s/%s/%s", deployadactylServer.URL, ENVIRONMENTNAME, "org", "space", appName)
+		req, err := http.NewRequest("POST", requestURL, bytes.NewBuffer(j))
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Add("Content-Type", "application/json")
+
+		response, err = http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+
+		responseBody, err = ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		cf.Close()
+		os.Unsetenv(fakecf.URLVariable)
+		os.Setenv("PATH", path)
+		os.Remove(CONFIGPATH)
+		os.RemoveAll(appLocation)
+	})
+
+	Context("when the application is new", func() {
+		It("pushes it to every foundation", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusOK), string(responseBody))
+
+			for _, foundation := range []string{foundation1, foundation2} {
+				Expect(cf.Apps(foundation)).To(Equal([]string{appName}))
+
+				app, _ := cf.App(foundation, appName)
+				Expect(app.State).To(Equal("started"))
+				Expect(app.Memory).To(Equal("64M"))
+				Expect(app.Routes).To(Equal([]string{appName + ".example.com"}))
+			}
+		})
+	})
+
+	Context("when the application exists", func() {
+		BeforeEach(func() {
+			for _, foundation := range []string{foundation1, foundation2} {
+				cf.AddApp(foundation, fakecf.App{Name: appName, Memory: "2G", Routes: []string{appName + ".example.com"}})
+			}
+		})
+
+		It("replaces it on every foundation", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusOK), string(responseBody))
+
+			for _, foundation := range []string{foundation1, foundation2} {
+				Expect(cf.Apps(foundation)).To(Equal([]string{appName}))
+
+				app, _ := cf.App(foundation, appName)
+				Expect(app.Memory).To(Equal("64M"))
+				Expect(cf.Commands(foundation)).To(ContainElement("delete"))
+			}
+		})
+
+		Context("and the push fails on the second foundation", func() {
+			BeforeEach(func() {
+				cf.Fail(foundation2, "push")
+			})
+
+			It("rolls the push back on every foundation", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusBadGateway), string(responseBody))
+
+				for _, foundation := range []string{foundation1, foundation2} {
+					Expect(cf.Apps(foundation)).To(Equal([]string{appName}))
+
+					app, _ := cf.App(foundation, appName)
+					Expect(app.Memory).To(Equal("2G"))
+				}
+			})
+		})
+
+		Context("and the health check fails on the second foundation", func() {
+			BeforeEach(func() {
+				cf.Fail(foundation2, "health")
+			})
+
+			It("rolls the push back on every foundation", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusUnprocessableEntity), string(responseBody))
+
+				for _, foundation := range []string{foundation1, foundation2} {
+					Expect(cf.Apps(foundation)).To(Equal([]string{appName}))
+
+					app, _ := cf.App(foundation, appName)
+					Expect(app.Memory).To(Equal("2G"))
+				}
+			})
+		})
+
+		Context("and the rename fails on the second foundation", func() {
+			BeforeEach(func() {
+				cf.Fail(foundation2, "rename")
+			})
+
+			It("fails after replacing the application on the first foundation", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusBadGateway), string(responseBody))
+
+				app, _ := cf.App(foundation1, appName)
+				Expect(app.Memory).To(Equal("64M"))
+				Expect(cf.Commands(foundation2)).To(ContainElement("rename"))
+			})
+		})
+	})
+
+	Context("when the login fails on the second foundation", func() {
+		BeforeEach(func() {
+			cf.Fail(foundation2, "login")
+		})
+
+		It("does not push to any foundation", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusBadRequest), string(responseBody))
+
+			Expect(cf.Commands(foundation1)).ToNot(ContainElement("push"))
+			Expect(cf.Apps(foundation1)).To(BeEmpty())
+			Expect(cf.Apps(foundation2)).To(BeEmpty())
+		})
+	})
+})
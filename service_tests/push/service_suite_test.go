@@ -39,4 +39,5 @@ var _ = AfterSuite(func() {
 	os.Setenv("CF_USERNAME", username)
 	os.Setenv("CF_PASSWORD", password)
 	os.Setenv("PATH", ospath)
+	os.RemoveAll(cliDirectory)
 })
@@ -12,6 +12,7 @@ import (
 	"encoding/base64"
 	"github.com/compozed/deployadactyl/creator"
 	"github.com/compozed/deployadactyl/interfaces"
+	S "github.com/compozed/deployadactyl/structs"
 	"github.com/compozed/deployadactyl/mocks"
 	"github.com/compozed/deployadactyl/randomizer"
 	"github.com/compozed/deployadactyl/state/push"
@@ -74,6 +75,7 @@ applications:
 		couriers = make([]*mocks.Courier, 0)
 
 		provider = creator.CreatorModuleProvider{
+			HTTPClient: &http.Client{Transport: healthyRoutes{}},
 			NewPrechecker: func(eventManager interfaces.EventManager) interfaces.Prechecker {
 				return prechecker
 			},
@@ -81,6 +83,7 @@ applications:
 				courier := &mocks.Courier{}
 				couriers = append(couriers, courier)
 				courier.ExistsCall.Returns.Bool = true
+				courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 1, Running: 1, InstanceStates: []string{"running"}}
 
 				return courier
 			},
@@ -184,10 +187,11 @@ applications:
 	})
 	It("maps the new application routes", func() {
 		for _, c := range couriers {
-			Expect(len(c.MapRouteCall.Received.AppName)).To(Equal(1))
+			Expect(len(c.MapRouteCall.Received.AppName)).To(Equal(2))
 			Expect(c.MapRouteCall.Received.AppName[0]).To(ContainSubstring(appName + "-new-build-"))
 			Expect(c.MapRouteCall.Received.Domain[0]).To(Equal("example.com"))
 			Expect(c.MapRouteCall.Received.Hostname[0]).To(Equal(appName))
+			Expect(c.MapRouteCall.Received.Hostname[1]).To(Equal(c.MapRouteCall.Received.AppName[1]))
 		}
 	})
 	It("unmaps the old application routes", func() {
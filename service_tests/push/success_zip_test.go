@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 
 	"github.com/compozed/deployadactyl/mocks"
 	"github.com/compozed/deployadactyl/randomizer"
@@ -13,6 +14,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/compozed/deployadactyl/creator"
 	"github.com/compozed/deployadactyl/interfaces"
+	S "github.com/compozed/deployadactyl/structs"
 	"reflect"
 	"github.com/compozed/deployadactyl/state/push"
 	"io"
@@ -41,6 +43,13 @@ applications:
   instances: 1`
 )
 
+// healthyRoutes answers the route check of every new build with 200, without going to the network.
+type healthyRoutes struct{}
+
+func (healthyRoutes) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("")), Request: r}, nil
+}
+
 var _ = Describe("Service", func() {
 
 	var (
@@ -69,6 +78,7 @@ var _ = Describe("Service", func() {
 		couriers = make([]*mocks.Courier, 0)
 
 		provider = creator.CreatorModuleProvider{
+			HTTPClient: &http.Client{Transport: healthyRoutes{}},
 			NewPrechecker: func(eventManager interfaces.EventManager) interfaces.Prechecker {
 				return prechecker
 			},
@@ -76,6 +86,7 @@ var _ = Describe("Service", func() {
 				courier := &mocks.Courier{}
 				couriers = append(couriers, courier)
 				courier.ExistsCall.Returns.Bool = true
+				courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 1, Running: 1, InstanceStates: []string{"running"}}
 
 				return courier
 			},
@@ -156,10 +167,11 @@ var _ = Describe("Service", func() {
 	})
 	It("maps the new application routes", func() {
 		for _, c := range couriers {
-			Expect(len(c.MapRouteCall.Received.AppName)).To(Equal(1))
+			Expect(len(c.MapRouteCall.Received.AppName)).To(Equal(2))
 			Expect(c.MapRouteCall.Received.AppName[0]).To(ContainSubstring(appName+"-new-build-"))
 			Expect(c.MapRouteCall.Received.Domain[0]).To(Equal("example.com"))
 			Expect(c.MapRouteCall.Received.Hostname[0]).To(Equal(appName))
+			Expect(c.MapRouteCall.Received.Hostname[1]).To(Equal(c.MapRouteCall.Received.AppName[1]))
 		}
 	})
 	It("unmaps the old application routes", func() {
@@ -0,0 +1,167 @@
+package start
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/compozed/deployadactyl/creator"
+	"github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/compozed/deployadactyl/service_tests/fakecf"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var cliDirectory string
+
+var _ = Describe("Service against a fake Cloud Foundry", func() {
+
+	const (
+		CONFIGPATH      = "./fake_cf_config.yml"
+		ENVIRONMENTNAME = "test"
+		TESTCONFIG      = `---
+environments:
+- name: Test
+  domain: example.com
+  rollback_enabled: true
+  foundations:
+  - api1.example.com
+  - api2.example.com
+`
+		foundation1 = "api1.example.com"
+		foundation2 = "api2.example.com"
+	)
+
+	var (
+		cf           *fakecf.Server
+		path         string
+		response     *http.Response
+		responseBody []byte
+		appName      string
+	)
+
+	BeforeEach(func() {
+		if cliDirectory == "" {
+			directory, err := ioutil.TempDir("", "fakecf-")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = fakecf.BuildCLI(directory)
+			Expect(err).ToNot(HaveOccurred())
+			cliDirectory = directory
+		}
+		path = os.Getenv("PATH")
+		os.Setenv("PATH", cliDirectory+string(os.PathListSeparator)+path)
+
+		cf = fakecf.New()
+		os.Setenv(fakecf.URLVariable, cf.URL())
+
+		os.Setenv("CF_USERNAME", randomizer.StringRunes(10))
+		os.Setenv("CF_PASSWORD", randomizer.StringRunes(10))
+		Expect(ioutil.WriteFile(CONFIGPATH, []byte(TESTCONFIG), 0644)).To(Succeed())
+
+		appName = randomizer.StringRunes(10)
+		for _, foundation := range []string{foundation1, foundation2} {
+			cf.AddApp(foundation, fakecf.App{Name: appName, State: "stopped", Instances: 2})
+		}
+	})
+
+	JustBeforeEach(func() {
+		provider := creator.CreatorModuleProvider{
+			NewPrechecker: func(eventManager interfaces.EventManager) interfaces.Prechecker {
+				return &mocks.Prechecker{}
+			},
+			NewEventManager: func(log interfaces.Logger) interfaces.EventManager {
+				return &mocks.EventManager{}
+			},
+		}
+
+		c, err := creator.Custom("DEBUG", CONFIGPATH, provider)
+		Expect(err).ToNot(HaveOccurred())
+
+		deployadactylServer := httptest.NewServer(c.CreateControllerHandler(c.CreateController()))
+		defer deployadactylServer.Close()
+
+		j, err := json.Marshal(gin.H{
+			"state": "started",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		requestURL := fmt.Sprintf("%s/v3/apps/%s/%s/%s/%s", deployadactylServer.URL, ENVIRONMENTNAME, "org", "space", appName)
+		req, err := http.NewRequest("PUT", requestURL, bytes.NewBuffer(j))
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Add("Content-Type", "application/json")
+
+		response, err = http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+
+		responseBody, err = ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		cf.Close()
+		os.Unsetenv(fakecf.URLVariable)
+		os.Setenv("PATH", path)
+		os.Remove(CONFIGPATH)
+	})
+
+	It("starts the application on every foundation", func() {
+		Expect(response.StatusCode).To(Equal(http.StatusOK), string(responseBody))
+
+		for _, foundation := range []string{foundation1, foundation2} {
+			app, _ := cf.App(foundation, appName)
+			Expect(app.State).To(Equal("started"))
+		}
+	})
+
+	Context("when the application does not exist", func() {
+		BeforeEach(func() {
+			appName = randomizer.StringRunes(10)
+		})
+
+		It("does not start anything", func() {
+			Expect(response.StatusCode).ToNot(Equal(http.StatusOK), string(responseBody))
+
+			for _, foundation := range []string{foundation1, foundation2} {
+				Expect(cf.Commands(foundation)).ToNot(ContainElement("start"))
+			}
+		})
+	})
+
+	Context("when the start fails on the second foundation", func() {
+		BeforeEach(func() {
+			cf.Fail(foundation2, "start")
+		})
+
+		It("stops the application again on the first foundation", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusBadGateway), string(responseBody))
+
+			for _, foundation := range []string{foundation1, foundation2} {
+				app, _ := cf.App(foundation, appName)
+				Expect(app.State).To(Equal("stopped"))
+			}
+			Expect(cf.Commands(foundation1)).To(ContainElement("stop"))
+		})
+	})
+
+	Context("when the login fails on the second foundation", func() {
+		BeforeEach(func() {
+			cf.Fail(foundation2, "login")
+		})
+
+		It("does not start the application on any foundation", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusBadRequest), string(responseBody))
+
+			Expect(cf.Commands(foundation1)).ToNot(ContainElement("start"))
+			app, _ := cf.App(foundation1, appName)
+			Expect(app.State).To(Equal("stopped"))
+		})
+	})
+})
@@ -118,7 +118,7 @@ environments:
 	})
 
 	It("returns correct status code", func() {
-		Expect(response.StatusCode).To(Equal(http.StatusInternalServerError), string(responseBody))
+		Expect(response.StatusCode).To(Equal(http.StatusBadGateway), string(responseBody))
 	})
 	It("calls prechecker with all foundation urls", func() {
 		fs := prechecker.AssertAllFoundationsUpCall.Received.Environment.Foundations
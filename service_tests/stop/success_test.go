@@ -16,6 +16,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/compozed/deployadactyl/creator"
 	"github.com/compozed/deployadactyl/interfaces"
+	S "github.com/compozed/deployadactyl/structs"
 	"reflect"
 	"github.com/compozed/deployadactyl/state/stop"
 )
@@ -70,6 +71,7 @@ environments:
 				courier := &mocks.Courier{}
 				couriers = append(couriers, courier)
 				courier.ExistsCall.Returns.Bool = true
+				courier.AppStatusCall.Returns.Status = S.AppStatus{State: "stopped", Instances: 1}
 
 				return courier
 			},
@@ -0,0 +1,78 @@
+// Package command runs a single cf command, such as restart or restage, against an application on every foundation.
+// Every command shares the same controller, manager and events. A Command only says which action runs on a
+// foundation and how its errors are reported.
+package command
+
+import (
+	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+// Command describes a cf command that is run against an application on every foundation.
+type Command struct {
+	// Type is the deployment type the command is recorded as, such as restart. The names of its events start with it.
+	Type string
+	// Past is how messages say that the command has run, such as restarted.
+	Past string
+	// Validate checks the data of a request before the command runs on any foundation. It is optional.
+	Validate func(data map[string]interface{}) error
+	// NewAction returns the action that runs the command on a single foundation.
+	NewAction func(foundation Foundation, deploymentInfo S.DeploymentInfo) (I.Action, error)
+
+	ExecuteError func(executeErrors []error) error
+	UndoError    func(executeErrors, undoErrors []error) error
+	SuccessError func(successErrors []error) error
+}
+
+// Restart restarts an application.
+var Restart = Command{
+	Type: C.DeploymentTypeRestart,
+	Past: "restarted",
+	NewAction: func(foundation Foundation, deploymentInfo S.DeploymentInfo) (I.Action, error) {
+		return &Runner{
+			Foundation: foundation,
+			Past:       "restarted",
+			Run:        foundation.Courier.Restart,
+			Error: func(appName string, out []byte) error {
+				return state.RestartError{ApplicationName: appName, Out: out}
+			},
+		}, nil
+	},
+	ExecuteError: func(executeErrors []error) error {
+		return bluegreen.RestartError{Errors: executeErrors}
+	},
+	UndoError: func(executeErrors, undoErrors []error) error {
+		return bluegreen.RollbackRestartError{RestartErrors: executeErrors, RollbackErrors: undoErrors}
+	},
+	SuccessError: func(successErrors []error) error {
+		return bluegreen.FinishRestartError{FinishRestartErrors: successErrors}
+	},
+}
+
+// Restage restages an application, so that it is built again with the latest buildpack and environment.
+var Restage = Command{
+	Type: C.DeploymentTypeRestage,
+	Past: "restaged",
+	NewAction: func(foundation Foundation, deploymentInfo S.DeploymentInfo) (I.Action, error) {
+		return &Runner{
+			Foundation: foundation,
+			Past:       "restaged",
+			Run:        foundation.Courier.Restage,
+			Error: func(appName string, out []byte) error {
+				return state.RestageError{ApplicationName: appName, Out: out}
+			},
+		}, nil
+	},
+	ExecuteError: func(executeErrors []error) error {
+		return bluegreen.RestageError{Errors: executeErrors}
+	},
+	UndoError: func(executeErrors, undoErrors []error) error {
+		return bluegreen.RollbackRestageError{RestageErrors: executeErrors, RollbackErrors: undoErrors}
+	},
+	SuccessError: func(successErrors []error) error {
+		return bluegreen.FinishRestageError{FinishRestageErrors: successErrors}
+	},
+}
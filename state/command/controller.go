@@ -0,0 +1,196 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+
+	"io"
+
+	"github.com/compozed/deployadactyl/config"
+	"github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
+	"github.com/compozed/deployadactyl/structs"
+)
+
+type ControllerConstructor func(log I.DeploymentLogger, command Command, deployer I.Deployer, conf config.Config, eventManager I.EventManager, errorFinder I.ErrorFinder, managerFactory I.CommandManagerFactory, locker I.Locker) I.CommandController
+
+func NewController(l I.DeploymentLogger, cmd Command, d I.Deployer, c config.Config, em I.EventManager, ef I.ErrorFinder, mf I.CommandManagerFactory, lk I.Locker) I.CommandController {
+	return &Controller{
+		Command:        cmd,
+		Deployer:       d,
+		Config:         c,
+		EventManager:   em,
+		ErrorFinder:    ef,
+		ManagerFactory: mf,
+		Log:            l,
+		Locker:         lk,
+	}
+}
+
+// Controller runs its Command against an application on every foundation.
+type Controller struct {
+	Log            I.DeploymentLogger
+	Command        Command
+	ManagerFactory I.CommandManagerFactory
+	Deployer       I.Deployer
+	Config         config.Config
+	EventManager   I.EventManager
+	ErrorFinder    I.ErrorFinder
+	Locker         I.Locker
+}
+
+func (c *Controller) RunCommand(deployment *I.Deployment, data map[string]interface{}, response *bytes.Buffer) (deployResponse I.DeployResponse) {
+	cf := deployment.CFContext
+	c.Log.Debugf("Preparing to %s %s with UUID %s", c.Command.Type, cf.Application, c.Log.UUID)
+
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	environment, err := c.resolveEnvironment(cf.Environment)
+	if err != nil {
+		fmt.Fprintln(response, err.Error())
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+	auth, err := state.ResolveAuthorization(deployment.Authorization, environment, c.Config, c.Log)
+	if err != nil {
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+
+	if c.Command.Validate != nil {
+		err = c.Command.Validate(data)
+		if err != nil {
+			fmt.Fprintln(response, err.Error())
+			return I.DeployResponse{
+				StatusCode: deployer.StatusCode(err),
+				Error:      err,
+			}
+		}
+	}
+
+	deploymentInfo := &structs.DeploymentInfo{
+		Org:               cf.Organization,
+		Space:             cf.Space,
+		AppName:           cf.Application,
+		Environment:       cf.Environment,
+		UUID:              c.Log.UUID,
+		Domain:            environment.Domain,
+		SkipSSL:           environment.SkipSSL,
+		ClientCredentials: environment.ClientCredentials,
+		ServiceAccount:    deployment.Authorization.Username == "" && deployment.Authorization.Password == "",
+		CustomParams:      environment.CustomParams,
+		Username:          auth.Username,
+		Password:          auth.Password,
+		Data:              data,
+	}
+
+	unlock, err := c.Locker.Lock(deployment.Context, cf, I.LockHolder{UUID: c.Log.UUID, Type: c.Command.Type, User: auth.Username})
+	if err != nil {
+		fmt.Fprintln(response, err.Error())
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+	defer unlock()
+
+	defer c.emitFinish(response, c.Log, cf, &auth, &environment, data)
+	defer c.emitSuccessOrFailure(response, c.Log, cf, &auth, &environment, data, &deployResponse)
+
+	started := StartedEvent{
+		Type:          c.Command.Type,
+		CFContext:     cf,
+		Authorization: auth,
+		Environment:   environment,
+		Data:          data,
+		Response:      response,
+		Log:           c.Log,
+	}
+	err = c.EventManager.EmitEvent(started)
+	if err != nil {
+		c.Log.Error(err)
+		err = bluegreen.InitializationError{Err: err}
+		err = deployer.EventError{Type: started.Name(), Err: err}
+		return I.DeployResponse{
+			StatusCode:     deployer.StatusCode(err),
+			Error:          err,
+			DeploymentInfo: deploymentInfo,
+		}
+	}
+
+	deployEventData := structs.DeployEventData{Response: response, DeploymentInfo: deploymentInfo}
+
+	manager := c.ManagerFactory.CommandManager(c.Log, deployEventData, c.Command.Type)
+	deployResponse = *c.Deployer.Deploy(deployment.Context, deploymentInfo, environment, manager, response)
+	return deployResponse
+}
+
+func (c *Controller) resolveEnvironment(env string) (structs.Environment, error) {
+	config := c.Config
+	environment, ok := config.Environments[env]
+	if !ok {
+		return structs.Environment{}, deployer.EnvironmentNotFoundError{Environment: env}
+	}
+	return environment, nil
+}
+
+func (c Controller) emitFinish(response io.ReadWriter, deploymentLogger I.DeploymentLogger, cfContext I.CFContext, auth *I.Authorization, environment *structs.Environment, data map[string]interface{}) {
+	var event I.IEvent
+	event = FinishedEvent{
+		Type:          c.Command.Type,
+		CFContext:     cfContext,
+		Authorization: *auth,
+		Data:          data,
+		Environment:   *environment,
+		Log:           deploymentLogger,
+	}
+	deploymentLogger.Debugf("emitting a %s event", event.Name())
+	eventErr := c.EventManager.EmitEvent(event)
+	if eventErr != nil {
+		deploymentLogger.Errorf("an error occurred when emitting a %s event: %s", event.Name(), eventErr)
+		fmt.Fprintln(response, eventErr)
+	}
+}
+
+func (c Controller) emitSuccessOrFailure(response io.ReadWriter, deploymentLogger I.DeploymentLogger, cfContext I.CFContext, auth *I.Authorization, environment *structs.Environment, data map[string]interface{}, deployResponse *I.DeployResponse) {
+	var event I.IEvent
+
+	if deployResponse.Error != nil {
+		state.PrintErrors(response, c.ErrorFinder)
+		event = FailureEvent{
+			Type:          c.Command.Type,
+			CFContext:     cfContext,
+			Authorization: *auth,
+			Environment:   *environment,
+			Data:          data,
+			Response:      response,
+			Error:         deployResponse.Error,
+			Log:           deploymentLogger,
+		}
+
+	} else {
+		event = SuccessEvent{
+			Type:          c.Command.Type,
+			CFContext:     cfContext,
+			Authorization: *auth,
+			Environment:   *environment,
+			Data:          data,
+			Response:      response,
+			Log:           deploymentLogger,
+		}
+	}
+	deploymentLogger.Debugf("emitting a %s event", event.Name())
+	eventErr := c.EventManager.EmitEvent(event)
+	if eventErr != nil {
+		deploymentLogger.Errorf("an error occurred when emitting a %s event: %s", event.Name(), eventErr)
+		fmt.Fprintln(response, eventErr)
+	}
+}
@@ -0,0 +1,202 @@
+package command_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/compozed/deployadactyl/config"
+	C "github.com/compozed/deployadactyl/constants"
+	D "github.com/compozed/deployadactyl/controller/deployer"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/locker"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	. "github.com/compozed/deployadactyl/state/command"
+	"github.com/compozed/deployadactyl/state/deletion"
+	"github.com/compozed/deployadactyl/state/scale"
+	"github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+var _ = Describe("Controller", func() {
+	var (
+		managerFactory *mocks.CommandManagerFactory
+		eventManager   *mocks.EventManager
+		deployer       *mocks.Deployer
+		appLocker      *mocks.Locker
+		controller     *Controller
+		deployment     *I.Deployment
+		response       *bytes.Buffer
+		logBuffer      *Buffer
+
+		environment string
+		uuid        string
+	)
+
+	BeforeEach(func() {
+		logBuffer = NewBuffer()
+		response = &bytes.Buffer{}
+		environment = "environment-" + randomizer.StringRunes(10)
+		uuid = "uuid-" + randomizer.StringRunes(10)
+
+		managerFactory = &mocks.CommandManagerFactory{}
+		eventManager = &mocks.EventManager{}
+		deployer = &mocks.Deployer{}
+		appLocker = &mocks.Locker{}
+
+		controller = &Controller{
+			Log:            I.DeploymentLogger{Log: I.DefaultLogger(logBuffer, logging.DEBUG, "controller_test"), UUID: uuid},
+			Command:        Restart,
+			Deployer:       deployer,
+			ManagerFactory: managerFactory,
+			EventManager:   eventManager,
+			ErrorFinder:    &mocks.ErrorFinder{},
+			Locker:         appLocker,
+			Config: config.Config{
+				Environments: map[string]structs.Environment{
+					environment: {Name: environment, Domain: "example.com"},
+				},
+			},
+		}
+
+		deployment = &I.Deployment{
+			CFContext: I.CFContext{
+				Environment:  environment,
+				Organization: "myOrg",
+				Space:        "mySpace",
+				Application:  "myApp",
+			},
+		}
+	})
+
+	commands := []struct {
+		command Command
+		data    map[string]interface{}
+		event   string
+	}{
+		{command: Restart, event: "Restart"},
+		{command: Restage, event: "Restage"},
+		{command: scale.Command, data: map[string]interface{}{"instances": float64(4)}, event: "Scale"},
+		{command: deletion.Command, data: map[string]interface{}{"delete_services": true}, event: "Delete"},
+	}
+
+	for _, entry := range commands {
+		entry := entry
+
+		Context("when it runs "+entry.command.Type, func() {
+			BeforeEach(func() {
+				controller.Command = entry.command
+			})
+
+			It("runs the command on every foundation", func() {
+				deployer.DeployCall.Returns.StatusCode = http.StatusOK
+
+				deployResponse := controller.RunCommand(deployment, entry.data, response)
+
+				Expect(deployResponse.StatusCode).To(Equal(http.StatusOK))
+				Expect(deployer.DeployCall.Called).To(Equal(1))
+				Expect(managerFactory.CommandManagerCall.Received.Command).To(Equal(entry.command.Type))
+
+				deploymentInfo := managerFactory.CommandManagerCall.Received.DeployEventData.DeploymentInfo
+				Expect(deploymentInfo.AppName).To(Equal("myApp"))
+				Expect(deploymentInfo.Domain).To(Equal("example.com"))
+				Expect(deploymentInfo.UUID).To(Equal(uuid))
+				for key, value := range entry.data {
+					Expect(deploymentInfo.Data).To(HaveKeyWithValue(key, value))
+				}
+				Expect(logBuffer).To(Say("Preparing to %s myApp with UUID %s", entry.command.Type, uuid))
+			})
+
+			It("emits a started, a success and a finished event", func() {
+				controller.RunCommand(deployment, entry.data, response)
+
+				events := eventManager.EmitEventCall.Received.Events
+				Expect(events).To(HaveLen(3))
+				Expect(events[0].(StartedEvent).Type).To(Equal(entry.command.Type))
+				Expect(events[0].(StartedEvent).CFContext).To(Equal(deployment.CFContext))
+				Expect(events[0].Name()).To(Equal(entry.event + "StartedEvent"))
+				Expect(events[1].Name()).To(Equal(entry.event + "SuccessEvent"))
+				Expect(events[2].Name()).To(Equal(entry.event + "FinishedEvent"))
+			})
+
+			It("emits a failure event with the error when it fails", func() {
+				deployer.DeployCall.Returns.StatusCode = http.StatusInternalServerError
+				deployer.DeployCall.Returns.Error = errors.New("command failed")
+
+				deployResponse := controller.RunCommand(deployment, entry.data, response)
+
+				Expect(deployResponse.Error).To(MatchError("command failed"))
+				failure, ok := eventManager.EmitEventCall.Received.Events[1].(FailureEvent)
+				Expect(ok).To(BeTrue())
+				Expect(failure.Name()).To(Equal(entry.event + "FailureEvent"))
+				Expect(failure.Error).To(MatchError("command failed"))
+			})
+		})
+	}
+
+	Context("when the finished event fails", func() {
+		It("logs the error and writes it to the response", func() {
+			eventManager.EmitEventCall.Returns.Error = []error{nil, nil, errors.New("finished event failed")}
+
+			controller.RunCommand(deployment, nil, response)
+
+			Expect(logBuffer).To(Say("an error occurred when emitting a RestartFinishedEvent event: finished event failed"))
+			Expect(response.String()).To(ContainSubstring("finished event failed"))
+		})
+	})
+
+	Context("when the application is not locked", func() {
+		It("locks the application while it runs", func() {
+			deployment.Authorization = I.Authorization{Username: "username", Password: "password"}
+
+			controller.RunCommand(deployment, nil, response)
+
+			Expect(appLocker.LockCall.Received.CFContext).To(Equal(deployment.CFContext))
+			Expect(appLocker.LockCall.Received.Holder.UUID).To(Equal(uuid))
+			Expect(appLocker.LockCall.Received.Holder.Type).To(Equal(C.DeploymentTypeRestart))
+			Expect(appLocker.LockCall.Received.Holder.User).To(Equal("username"))
+			Expect(appLocker.LockCall.Unlocked).To(BeTrue())
+		})
+	})
+
+	Context("when the application is locked", func() {
+		It("returns http.StatusConflict without running", func() {
+			appLocker.LockCall.Returns.Error = locker.LockedError{Holder: I.LockHolder{UUID: "another uuid"}}
+
+			deployResponse := controller.RunCommand(deployment, nil, response)
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusConflict))
+			Expect(deployResponse.Error).To(MatchError(appLocker.LockCall.Returns.Error))
+			Expect(deployer.DeployCall.Called).To(Equal(0))
+			Expect(eventManager.EmitEventCall.Received.Events).To(BeEmpty())
+		})
+	})
+
+	Context("when the environment is unknown", func() {
+		It("returns an error", func() {
+			deployment.CFContext.Environment = "unknown"
+
+			deployResponse := controller.RunCommand(deployment, nil, response)
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(deployResponse.Error).To(MatchError(D.EnvironmentNotFoundError{Environment: "unknown"}))
+		})
+	})
+
+	Context("when the data is not valid for the command", func() {
+		It("returns http.StatusBadRequest without running it", func() {
+			controller.Command = scale.Command
+
+			deployResponse := controller.RunCommand(deployment, map[string]interface{}{"memory": "lots"}, response)
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(deployResponse.Error).To(MatchError(scale.InvalidScaleDataError{Data: map[string]interface{}{"memory": "lots"}}))
+			Expect(deployer.DeployCall.Called).To(Equal(0))
+			Expect(eventManager.EmitEventCall.Received.Events).To(BeEmpty())
+		})
+	})
+})
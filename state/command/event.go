@@ -0,0 +1,150 @@
+package command
+
+import (
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/compozed/deployadactyl/eventmanager"
+	"github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/structs"
+	"github.com/go-errors/errors"
+)
+
+type eventBinding struct {
+	etype   reflect.Type
+	handler func(event interface{}) error
+}
+
+func (s eventBinding) Accepts(event interface{}) bool {
+	return reflect.TypeOf(event) == s.etype
+}
+
+func (b eventBinding) Emit(event interface{}) error {
+	return b.handler(event)
+}
+
+// eventName prefixes the kind of event with the type of the command, such as RestartStartedEvent.
+func eventName(commandType, kind string) string {
+	if commandType == "" {
+		return kind
+	}
+	return strings.ToUpper(commandType[:1]) + commandType[1:] + kind
+}
+
+// FailureEvent is emitted when a command failed on any foundation. Type is the type of the command.
+type FailureEvent struct {
+	Type          string
+	CFContext     interfaces.CFContext
+	Data          map[string]interface{}
+	Environment   structs.Environment
+	Authorization interfaces.Authorization
+	Response      io.ReadWriter
+	Error         error
+	Log           interfaces.DeploymentLogger
+}
+
+func (e FailureEvent) Name() string {
+	return eventName(e.Type, "FailureEvent")
+}
+
+func NewFailureEventBinding(handler func(event FailureEvent) error) interfaces.Binding {
+	return eventBinding{
+		etype: reflect.TypeOf(FailureEvent{}),
+		handler: func(gevent interface{}) error {
+			event, ok := gevent.(FailureEvent)
+			if ok {
+				return handler(event)
+			} else {
+				return eventmanager.InvalidEventType{Err: errors.New("invalid event type")}
+			}
+		},
+	}
+}
+
+// SuccessEvent is emitted when a command succeeded on every foundation. Type is the type of the command.
+type SuccessEvent struct {
+	Type          string
+	CFContext     interfaces.CFContext
+	Data          map[string]interface{}
+	Environment   structs.Environment
+	Authorization interfaces.Authorization
+	Response      io.ReadWriter
+	Log           interfaces.DeploymentLogger
+}
+
+func (e SuccessEvent) Name() string {
+	return eventName(e.Type, "SuccessEvent")
+}
+
+func NewSuccessEventBinding(handler func(event SuccessEvent) error) interfaces.Binding {
+	return eventBinding{
+		etype: reflect.TypeOf(SuccessEvent{}),
+		handler: func(gevent interface{}) error {
+			event, ok := gevent.(SuccessEvent)
+			if ok {
+				return handler(event)
+			} else {
+				return eventmanager.InvalidEventType{Err: errors.New("invalid event type")}
+			}
+		},
+	}
+}
+
+// StartedEvent is emitted before a command runs on any foundation. Type is the type of the command.
+type StartedEvent struct {
+	Type          string
+	CFContext     interfaces.CFContext
+	Data          map[string]interface{}
+	Environment   structs.Environment
+	Authorization interfaces.Authorization
+	Response      io.ReadWriter
+	Log           interfaces.DeploymentLogger
+}
+
+func (e StartedEvent) Name() string {
+	return eventName(e.Type, "StartedEvent")
+}
+
+func NewStartedEventBinding(handler func(event StartedEvent) error) interfaces.Binding {
+	return eventBinding{
+		etype: reflect.TypeOf(StartedEvent{}),
+		handler: func(gevent interface{}) error {
+			event, ok := gevent.(StartedEvent)
+			if ok {
+				return handler(event)
+			} else {
+				return eventmanager.InvalidEventType{Err: errors.New("invalid event type")}
+			}
+		},
+	}
+}
+
+// FinishedEvent is emitted once a command has finished, whether it succeeded or not. Type is the type of the command.
+type FinishedEvent struct {
+	Type          string
+	CFContext     interfaces.CFContext
+	Data          map[string]interface{}
+	Authorization interfaces.Authorization
+	Response      io.ReadWriter
+	Environment   structs.Environment
+	Log           interfaces.DeploymentLogger
+}
+
+func (e FinishedEvent) Name() string {
+	return eventName(e.Type, "FinishedEvent")
+}
+
+func NewFinishedEventBinding(handler func(event FinishedEvent) error) interfaces.Binding {
+	return eventBinding{
+		etype: reflect.TypeOf(FinishedEvent{}),
+		handler: func(gevent interface{}) error {
+			event, ok := gevent.(FinishedEvent)
+			if ok {
+				return handler(event)
+			} else {
+				return eventmanager.InvalidEventType{Err: errors.New("invalid event type")}
+			}
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"context"
+	"io"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
+)
+
+// Foundation holds what every command needs to run on a single foundation. The actions of the commands
+// embed it for the login in Initially and the clean up in Finally. Context is the context of the deployment
+// and Verification is how long the commands wait for the application to come up.
+type Foundation struct {
+	Courier       I.Courier
+	CFContext     I.CFContext
+	Authorization I.Authorization
+	EventManager  I.EventManager
+	Response      io.ReadWriter
+	Log           I.DeploymentLogger
+	FoundationURL string
+	AppName       string
+	Data          map[string]interface{}
+	Context       context.Context
+	Verification  state.Verification
+}
+
+// Login will login to a Cloud Foundry instance.
+func (f Foundation) Initially() error {
+	f.Log.Debugf(
+		`logging into cloud foundry with parameters:
+		foundation URL: %+v
+		username: %+v
+		org: %+v
+		space: %+v`,
+		f.FoundationURL, f.Authorization.Username, f.CFContext.Organization, f.CFContext.Space,
+	)
+
+	output, err := f.Courier.Login(
+		f.FoundationURL,
+		f.Authorization.Username,
+		f.Authorization.Password,
+		f.CFContext.Organization,
+		f.CFContext.Space,
+		f.CFContext.SkipSSL,
+		f.CFContext.ClientCredentials,
+	)
+	f.Response.Write(output)
+	if err != nil {
+		f.Log.Errorf("could not login to %s", f.FoundationURL)
+		return state.LoginError{FoundationURL: f.FoundationURL, Out: output}
+	}
+
+	f.Log.Infof("logged into cloud foundry %s", f.FoundationURL)
+
+	return nil
+}
+
+// CleanUp removes the temporary directory created by the Executor.
+func (f Foundation) Finally() error {
+	return f.Courier.CleanUp()
+}
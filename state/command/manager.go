@@ -0,0 +1,116 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+const successfulCommand = `Your %s was successful! (^_^)b
+
+`
+
+type courierCreator interface {
+	CreateCourier(ctx context.Context) (I.Courier, error)
+}
+
+// Manager creates the action of its Command for every foundation.
+type Manager struct {
+	Command         Command
+	CourierCreator  courierCreator
+	EventManager    I.EventManager
+	Logger          I.DeploymentLogger
+	DeployEventData S.DeployEventData
+}
+
+func (a Manager) SetUp() error {
+	return nil
+}
+
+func (a Manager) OnStart() error {
+	return nil
+}
+
+func (a Manager) OnFinish(env S.Environment, response io.ReadWriter, err error) I.DeployResponse {
+	if err != nil {
+		fmt.Fprintf(response, "\nYour application was not successfully %s on all foundations: %s\n\n", a.Command.Past, err.Error())
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+
+	a.Logger.Infof("successfully %s application %s", a.Command.Past, a.DeployEventData.DeploymentInfo.AppName)
+	fmt.Fprintf(response, "\n"+successfulCommand, a.Command.Type)
+
+	return I.DeployResponse{StatusCode: http.StatusOK}
+}
+
+func (a Manager) CleanUp() {}
+
+func (a Manager) Create(ctx context.Context, environment S.Environment, response io.ReadWriter, foundationURL string) (I.Action, error) {
+	deploymentInfo := *a.DeployEventData.DeploymentInfo
+
+	if a.Command.Validate != nil {
+		if err := a.Command.Validate(deploymentInfo.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	courier, err := a.CourierCreator.CreateCourier(ctx)
+	if err != nil {
+		a.Logger.Error(err)
+		return nil, state.CourierCreationError{Err: err}
+	}
+
+	username, password, clientCredentials := state.Login(deploymentInfo, environment, foundationURL)
+
+	foundation := Foundation{
+		Courier: courier,
+		CFContext: I.CFContext{
+			Environment:       environment.Name,
+			Organization:      deploymentInfo.Org,
+			Space:             deploymentInfo.Space,
+			Application:       deploymentInfo.AppName,
+			SkipSSL:           deploymentInfo.SkipSSL,
+			ClientCredentials: clientCredentials,
+		},
+		Authorization: I.Authorization{
+			Username: username,
+			Password: password,
+		},
+		EventManager:  a.EventManager,
+		Response:      response,
+		Log:           a.Logger,
+		FoundationURL: foundationURL,
+		AppName:       deploymentInfo.AppName,
+		Data:          deploymentInfo.Data,
+		Context:       ctx,
+		Verification:  state.DefaultVerification,
+	}
+
+	return a.Command.NewAction(foundation, deploymentInfo)
+}
+
+func (a Manager) InitiallyError(initiallyErrors []error) error {
+	return bluegreen.LoginError{LoginErrors: initiallyErrors}
+}
+
+func (a Manager) ExecuteError(executeErrors []error) error {
+	return a.Command.ExecuteError(executeErrors)
+}
+
+func (a Manager) UndoError(executeErrors, undoErrors []error) error {
+	return a.Command.UndoError(executeErrors, undoErrors)
+}
+
+func (a Manager) SuccessError(successErrors []error) error {
+	return a.Command.SuccessError(successErrors)
+}
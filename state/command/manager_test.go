@@ -0,0 +1,193 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/state"
+	. "github.com/compozed/deployadactyl/state/command"
+	"github.com/compozed/deployadactyl/state/deletion"
+	"github.com/compozed/deployadactyl/state/scale"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+type courierCreator struct {
+	courier I.Courier
+	err     error
+	called  *bool
+}
+
+func (c courierCreator) CreateCourier(ctx context.Context) (I.Courier, error) {
+	if c.called != nil {
+		*c.called = true
+	}
+	return c.courier, c.err
+}
+
+var _ = Describe("Manager", func() {
+	var (
+		courier  *mocks.Courier
+		manager  Manager
+		response *Buffer
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+		response = NewBuffer()
+
+		manager = Manager{
+			Command:        Restart,
+			CourierCreator: courierCreator{courier: courier},
+			Logger:         I.DeploymentLogger{Log: I.DefaultLogger(NewBuffer(), logging.DEBUG, "manager_test"), UUID: "uuid"},
+			DeployEventData: S.DeployEventData{
+				DeploymentInfo: &S.DeploymentInfo{
+					Org:     "org",
+					Space:   "space",
+					AppName: "appName",
+					Domain:  "example.com",
+					UUID:    "uuid",
+				},
+			},
+		}
+	})
+
+	commands := []struct {
+		command      Command
+		data         map[string]interface{}
+		action       I.Action
+		past         string
+		executeError error
+		undoError    error
+		successError error
+	}{
+		{
+			command:      Restart,
+			action:       &Runner{},
+			past:         "restarted",
+			executeError: bluegreen.RestartError{},
+			undoError:    bluegreen.RollbackRestartError{},
+			successError: bluegreen.FinishRestartError{},
+		},
+		{
+			command:      Restage,
+			action:       &Runner{},
+			past:         "restaged",
+			executeError: bluegreen.RestageError{},
+			undoError:    bluegreen.RollbackRestageError{},
+			successError: bluegreen.FinishRestageError{},
+		},
+		{
+			command:      scale.Command,
+			data:         map[string]interface{}{"instances": float64(4), "memory": "1G"},
+			action:       &scale.Scaler{},
+			past:         "scaled",
+			executeError: bluegreen.ScaleError{},
+			undoError:    bluegreen.RollbackScaleError{},
+			successError: bluegreen.FinishScaleError{},
+		},
+		{
+			command:      deletion.Command,
+			action:       &deletion.Deleter{},
+			past:         "deleted",
+			executeError: bluegreen.DeleteError{},
+			undoError:    bluegreen.RollbackDeleteError{},
+			successError: bluegreen.FinishDeleteError{},
+		},
+	}
+
+	for _, entry := range commands {
+		entry := entry
+
+		Context("when it manages "+entry.command.Type, func() {
+			BeforeEach(func() {
+				manager.Command = entry.command
+				manager.DeployEventData.DeploymentInfo.Data = entry.data
+			})
+
+			It("creates the action of the command for the foundation", func() {
+				action, err := manager.Create(context.Background(), S.Environment{Name: "environment"}, response, "foundationURL")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(action).To(BeAssignableToTypeOf(entry.action))
+
+				foundation := reflect.ValueOf(action).Elem().FieldByName("Foundation").Interface().(Foundation)
+				Expect(foundation.Courier).To(Equal(courier))
+				Expect(foundation.FoundationURL).To(Equal("foundationURL"))
+				Expect(foundation.AppName).To(Equal("appName"))
+				Expect(foundation.CFContext.Environment).To(Equal("environment"))
+				Expect(foundation.CFContext.Organization).To(Equal("org"))
+			})
+
+			It("returns http.StatusOK when the command succeeded", func() {
+				deployResponse := manager.OnFinish(S.Environment{}, response, nil)
+
+				Expect(deployResponse.StatusCode).To(Equal(http.StatusOK))
+				Expect(response).To(Say("Your %s was successful!", entry.command.Type))
+			})
+
+			It("returns http.StatusInternalServerError when the command failed", func() {
+				deployResponse := manager.OnFinish(S.Environment{}, response, errors.New("command failed"))
+
+				Expect(deployResponse.StatusCode).To(Equal(http.StatusInternalServerError))
+				Expect(response).To(Say("not successfully %s", entry.past))
+			})
+
+			It("returns the errors of the command", func() {
+				Expect(manager.InitiallyError(nil)).To(BeAssignableToTypeOf(bluegreen.LoginError{}))
+				Expect(manager.ExecuteError(nil)).To(BeAssignableToTypeOf(entry.executeError))
+				Expect(manager.UndoError(nil, nil)).To(BeAssignableToTypeOf(entry.undoError))
+				Expect(manager.SuccessError(nil)).To(BeAssignableToTypeOf(entry.successError))
+			})
+		})
+	}
+
+	Describe("Create", func() {
+		It("returns an error when the courier cannot be created", func() {
+			manager.CourierCreator = courierCreator{err: errors.New("courier error")}
+
+			_, err := manager.Create(context.Background(), S.Environment{}, response, "foundationURL")
+			Expect(err).To(MatchError(state.CourierCreationError{Err: errors.New("courier error")}))
+		})
+
+		It("returns an error without creating a courier when the data is not valid for the command", func() {
+			called := false
+			manager.Command = scale.Command
+			manager.CourierCreator = courierCreator{courier: courier, called: &called}
+			manager.DeployEventData.DeploymentInfo.Data = map[string]interface{}{"instances": "four"}
+
+			_, err := manager.Create(context.Background(), S.Environment{}, response, "foundationURL")
+			Expect(err).To(MatchError(scale.InvalidScaleDataError{Data: map[string]interface{}{"instances": "four"}}))
+			Expect(called).To(BeFalse())
+		})
+
+		It("gives the scaler the instances and the memory to scale to", func() {
+			manager.Command = scale.Command
+			manager.DeployEventData.DeploymentInfo.Data = map[string]interface{}{"instances": float64(4), "memory": "1G"}
+
+			action, err := manager.Create(context.Background(), S.Environment{}, response, "foundationURL")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(action.(*scale.Scaler).Instances).To(Equal(uint16(4)))
+			Expect(action.(*scale.Scaler).Memory).To(Equal("1G"))
+		})
+
+		It("gives the deleter the domain and whether to delete the services", func() {
+			manager.Command = deletion.Command
+			manager.DeployEventData.DeploymentInfo.Data = map[string]interface{}{"delete_services": true}
+
+			action, err := manager.Create(context.Background(), S.Environment{}, response, "foundationURL")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(action.(*deletion.Deleter).Domain).To(Equal("example.com"))
+			Expect(action.(*deletion.Deleter).DeleteServices).To(BeTrue())
+		})
+	})
+})
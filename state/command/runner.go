@@ -0,0 +1,76 @@
+package command
+
+import (
+	"github.com/compozed/deployadactyl/state"
+)
+
+// Runner runs a cf command that cannot be taken back, such as restart or restage, on a single foundation.
+type Runner struct {
+	Foundation
+
+	// Past is how the log says that the command has run, such as restarted.
+	Past string
+	// Run runs the cf command against the application.
+	Run func(appName string) ([]byte, error)
+	// Error returns the error for a failed run with its output.
+	Error func(appName string, out []byte) error
+}
+
+func (r Runner) Execute() error {
+	if !r.Courier.Exists(r.AppName) {
+		r.Log.Errorf("failed to run command on foundation %s: application doesn't exist", r.FoundationURL)
+		return state.ExistsError{ApplicationName: r.AppName}
+	}
+
+	r.Log.Infof("running command on app %s", r.AppName)
+
+	output, err := r.Run(r.AppName)
+	r.Response.Write(output)
+	if err != nil {
+		r.Log.Errorf("failed to run command on foundation %s: %s", r.FoundationURL, err.Error())
+		return r.Error(r.AppName, output)
+	}
+
+	r.Log.Infof("successfully %s app %s", r.Past, r.AppName)
+
+	return nil
+}
+
+// Verify checks that all instances of the application are running again.
+func (r Runner) Verify() error {
+	r.Log.Debugf("verifying that %s is started", r.AppName)
+
+	_, err := r.Verification.WaitForAppState(r.Context, r.Courier, r.AppName, "started")
+	if err != nil {
+		r.Log.Errorf("failed to verify app on foundation %s: %s", r.FoundationURL, err)
+		return err
+	}
+
+	r.Log.Infof("verified that %s is started", r.AppName)
+
+	return nil
+}
+
+// Undo cannot take the command back. It starts the application, so that it does not stay stopped
+// when it failed to come back up on this foundation.
+func (r Runner) Undo() error {
+	if !r.Courier.Exists(r.AppName) {
+		return nil
+	}
+
+	r.Log.Infof("starting app %s", r.AppName)
+
+	output, err := r.Courier.Start(r.AppName)
+	r.Response.Write(output)
+	if err != nil {
+		return state.StartError{ApplicationName: r.AppName, Out: output}
+	}
+
+	r.Log.Infof("successfully started app %s", r.AppName)
+
+	return nil
+}
+
+func (r Runner) Success() error {
+	return nil
+}
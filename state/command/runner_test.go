@@ -0,0 +1,217 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/compozed/deployadactyl/state"
+	. "github.com/compozed/deployadactyl/state/command"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+var _ = Describe("Foundation", func() {
+	var (
+		foundation Foundation
+		courier    *mocks.Courier
+		response   *Buffer
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+		response = NewBuffer()
+
+		foundation = Foundation{
+			Courier:       courier,
+			CFContext:     I.CFContext{Organization: "org", Space: "space"},
+			Authorization: I.Authorization{Username: "username", Password: "password"},
+			Response:      response,
+			Log:           I.DeploymentLogger{Log: I.DefaultLogger(NewBuffer(), logging.DEBUG, "foundation_test")},
+			FoundationURL: "foundationURL",
+		}
+	})
+
+	Describe("Initially", func() {
+		It("logs into the foundation", func() {
+			courier.LoginCall.Returns.Output = []byte("logged in")
+
+			Expect(foundation.Initially()).To(Succeed())
+
+			Expect(courier.LoginCall.Received.FoundationURL).To(Equal("foundationURL"))
+			Expect(courier.LoginCall.Received.Username).To(Equal("username"))
+			Expect(response).To(Say("logged in"))
+		})
+
+		It("returns an error when login fails", func() {
+			courier.LoginCall.Returns.Output = []byte("login output")
+			courier.LoginCall.Returns.Error = errors.New("login error")
+
+			Expect(foundation.Initially()).To(MatchError(state.LoginError{FoundationURL: "foundationURL", Out: []byte("login output")}))
+		})
+	})
+
+	Describe("Finally", func() {
+		It("cleans up the courier", func() {
+			courier.CleanUpCall.Returns.Error = errors.New("clean up error")
+
+			Expect(foundation.Finally()).To(MatchError("clean up error"))
+		})
+	})
+})
+
+var _ = Describe("Runner", func() {
+	var (
+		courier    *mocks.Courier
+		logBuffer  *Buffer
+		response   *Buffer
+		foundation Foundation
+
+		appName string
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+		logBuffer = NewBuffer()
+		response = NewBuffer()
+
+		appName = "appName-" + randomizer.StringRunes(10)
+
+		courier.ExistsCall.Returns.Bool = true
+
+		foundation = Foundation{
+			Courier:       courier,
+			Response:      response,
+			Log:           I.DeploymentLogger{Log: I.DefaultLogger(logBuffer, logging.DEBUG, "runner_test")},
+			FoundationURL: "foundationURL",
+			AppName:       appName,
+		}
+	})
+
+	commands := []struct {
+		command  Command
+		past     string
+		run      func(courier *mocks.Courier) string
+		fail     func(courier *mocks.Courier, out []byte)
+		runError func(appName string, out []byte) error
+	}{
+		{
+			command: Restart,
+			past:    "restarted",
+			run:     func(courier *mocks.Courier) string { return courier.RestartCall.Received.AppName },
+			fail: func(courier *mocks.Courier, out []byte) {
+				courier.RestartCall.Returns.Output = out
+				courier.RestartCall.Returns.Error = errors.New("restart error")
+			},
+			runError: func(appName string, out []byte) error {
+				return state.RestartError{ApplicationName: appName, Out: out}
+			},
+		},
+		{
+			command: Restage,
+			past:    "restaged",
+			run:     func(courier *mocks.Courier) string { return courier.RestageCall.Received.AppName },
+			fail: func(courier *mocks.Courier, out []byte) {
+				courier.RestageCall.Returns.Output = out
+				courier.RestageCall.Returns.Error = errors.New("restage error")
+			},
+			runError: func(appName string, out []byte) error {
+				return state.RestageError{ApplicationName: appName, Out: out}
+			},
+		},
+	}
+
+	for _, entry := range commands {
+		entry := entry
+
+		Describe(entry.command.Type, func() {
+			var runner I.Action
+
+			BeforeEach(func() {
+				var err error
+				runner, err = entry.command.NewAction(foundation, S.DeploymentInfo{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("runs the command against the app", func() {
+				Expect(runner.Execute()).To(Succeed())
+
+				Expect(entry.run(courier)).To(Equal(appName))
+				Expect(logBuffer).To(Say("successfully %s app %s", entry.past, appName))
+			})
+
+			It("returns an error when the command fails", func() {
+				entry.fail(courier, []byte("command output"))
+
+				Expect(runner.Execute()).To(MatchError(entry.runError(appName, []byte("command output"))))
+				Expect(response).To(Say("command output"))
+			})
+
+			It("returns an error when the app does not exist", func() {
+				courier.ExistsCall.Returns.Bool = false
+
+				Expect(runner.Execute()).To(MatchError(state.ExistsError{ApplicationName: appName}))
+				Expect(entry.run(courier)).To(BeEmpty())
+			})
+		})
+	}
+
+	Describe("Verify", func() {
+		BeforeEach(func() {
+			foundation.Verification = state.Verification{Attempts: 10}
+		})
+
+		It("succeeds when all instances are running", func() {
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 2, Running: 2, InstanceStates: []string{"running", "running"}}
+
+			Expect(Runner{Foundation: foundation}.Verify()).To(Succeed())
+			Expect(courier.AppStatusCall.Received.AppName).To(Equal(appName))
+		})
+
+		It("returns an error when an instance crashed", func() {
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 2, Running: 1, InstanceStates: []string{"running", "crashed"}}
+
+			Expect(Runner{Foundation: foundation}.Verify()).To(MatchError(state.InstancesNotRunningError{ApplicationName: appName, Running: 1, Instances: 2}))
+		})
+
+		It("stops waiting when the deployment is cancelled", func() {
+			foundation.Verification.Interval = time.Minute
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "starting", Instances: 2}
+			ctx, cancel := context.WithCancel(context.Background())
+			foundation.Context = ctx
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			start := time.Now()
+			Expect(Runner{Foundation: foundation}.Verify()).To(Equal(context.Canceled))
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		})
+	})
+
+	Describe("Undo", func() {
+		It("starts the app", func() {
+			Expect(Runner{Foundation: foundation}.Undo()).To(Succeed())
+
+			Expect(courier.StartCall.Received.AppName).To(Equal(appName))
+		})
+
+		It("returns an error when the app cannot be started", func() {
+			courier.StartCall.Returns.Output = []byte("start output")
+			courier.StartCall.Returns.Error = errors.New("start error")
+
+			Expect(Runner{Foundation: foundation}.Undo()).To(MatchError(state.StartError{ApplicationName: appName, Out: []byte("start output")}))
+		})
+
+		It("does nothing when the app does not exist", func() {
+			courier.ExistsCall.Returns.Bool = false
+
+			Expect(Runner{Foundation: foundation}.Undo()).To(Succeed())
+			Expect(courier.StartCall.Received.AppName).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,50 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/compozed/deployadactyl/config"
+	"github.com/compozed/deployadactyl/controller/deployer"
+	I "github.com/compozed/deployadactyl/interfaces"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+// ResolveAuthorization returns the credentials a deployment logs in with. Deployments without basic auth
+// use the username and password of the configuration, unless the environment authenticates its users.
+func ResolveAuthorization(auth I.Authorization, environment S.Environment, conf config.Config, log I.DeploymentLogger) (I.Authorization, error) {
+	log.Debug("checking for basic auth")
+	if auth.Username == "" && auth.Password == "" {
+		if environment.Authenticate {
+			return I.Authorization{}, deployer.BasicAuthError{}
+		}
+		auth.Username = conf.Username
+		auth.Password = conf.Password
+	}
+
+	return auth, nil
+}
+
+// PrintErrors appends the errors the ErrorFinder finds in the output of a failed deployment to the response,
+// along with their potential solutions. The error of the deployment itself is left as it is, so that its code
+// and category are still reported.
+func PrintErrors(response io.ReadWriter, errorFinder I.ErrorFinder) {
+	tempBuffer := bytes.Buffer{}
+	tempBuffer.ReadFrom(response)
+	fmt.Fprint(response, tempBuffer.String())
+
+	errors := errorFinder.FindErrors(tempBuffer.String())
+	for _, error := range errors {
+		fmt.Fprintln(response)
+		fmt.Fprintln(response, "*******************")
+		fmt.Fprintln(response)
+		fmt.Fprintln(response, "The following error was found in the above logs: "+error.Error())
+		fmt.Fprintln(response)
+		fmt.Fprintln(response, "Error: "+error.Details()[0])
+		fmt.Fprintln(response)
+		fmt.Fprintln(response, "Potential solution: "+error.Solution())
+		fmt.Fprintln(response)
+		fmt.Fprintln(response, "*******************")
+	}
+}
@@ -0,0 +1,28 @@
+package state
+
+import (
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+// Login returns the username and password to log in to the foundation with, and whether they are the
+// client credentials of a UAA client. Deployments that were not given credentials of their own use the
+// service account configured for the foundation, or else the one configured for the environment.
+func Login(deploymentInfo S.DeploymentInfo, environment S.Environment, foundationURL string) (username, password string, clientCredentials bool) {
+	if deploymentInfo.ServiceAccount {
+		if credentials, ok := environment.FoundationCredentials[foundationURL]; ok {
+			return login(credentials)
+		}
+		if credentials := environment.Credentials; credentials != (S.Credentials{}) {
+			return login(credentials)
+		}
+	}
+
+	return deploymentInfo.Username, deploymentInfo.Password, deploymentInfo.ClientCredentials
+}
+
+func login(credentials S.Credentials) (string, string, bool) {
+	if credentials.ClientID != "" {
+		return credentials.ClientID, credentials.ClientSecret, true
+	}
+	return credentials.Username, credentials.Password, false
+}
@@ -0,0 +1,195 @@
+// Package deletion tears an application down on every foundation of an environment.
+package deletion
+
+import (
+	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
+	"github.com/compozed/deployadactyl/state/command"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+// Command deletes an application. The user provided services bound only to it are deleted as well
+// when the data of the request has delete_services set.
+var Command = command.Command{
+	Type: C.DeploymentTypeDelete,
+	Past: "deleted",
+	NewAction: func(foundation command.Foundation, deploymentInfo S.DeploymentInfo) (I.Action, error) {
+		return &Deleter{
+			Foundation:     foundation,
+			Domain:         deploymentInfo.Domain,
+			DeleteServices: deploymentInfo.Data["delete_services"] == true,
+		}, nil
+	},
+	ExecuteError: func(executeErrors []error) error {
+		return bluegreen.DeleteError{Errors: executeErrors}
+	},
+	UndoError: func(executeErrors, undoErrors []error) error {
+		return bluegreen.RollbackDeleteError{DeleteErrors: executeErrors, RollbackErrors: undoErrors}
+	},
+	SuccessError: func(successErrors []error) error {
+		return bluegreen.FinishDeleteError{FinishDeleteErrors: successErrors}
+	},
+}
+
+// Deleter deletes an application on a single foundation. Execute only takes the application out of
+// the load balancer and stops it, so that it can be brought back when any foundation fails.
+// The application is deleted in Success, once every foundation has been stopped.
+type Deleter struct {
+	command.Foundation
+
+	Domain         string
+	DeleteServices bool
+
+	exists  bool
+	stopped bool
+}
+
+// Execute unmaps the load balanced route from the application and stops it.
+// An application that does not exist on the foundation is already deleted.
+func (d *Deleter) Execute() error {
+	d.exists = d.Courier.Exists(d.AppName)
+	if !d.exists {
+		d.Log.Infof("app %s doesn't exist on foundation %s: nothing to delete", d.AppName, d.FoundationURL)
+		return nil
+	}
+
+	if d.Domain != "" {
+		output, err := d.Courier.UnmapRoute(d.AppName, d.Domain, d.AppName)
+		if err != nil {
+			d.Log.Errorf("could not unmap %s", d.AppName)
+			return state.UnmapRouteError{ApplicationName: d.AppName, Out: output}
+		}
+		d.Log.Infof("unmapped route %s.%s from %s", d.AppName, d.Domain, d.AppName)
+	}
+
+	status, err := d.Courier.AppStatus(d.AppName)
+	if err != nil {
+		d.Log.Errorf("failed to delete app on foundation %s: %s", d.FoundationURL, err)
+		return err
+	}
+
+	if status.State == "started" {
+		d.Log.Infof("stopping app %s", d.AppName)
+
+		output, err := d.Courier.Stop(d.AppName)
+		d.Response.Write(output)
+		if err != nil {
+			d.Log.Errorf("failed to stop app on foundation %s: %s", d.FoundationURL, err)
+			return state.StopError{ApplicationName: d.AppName, Out: output}
+		}
+		d.stopped = true
+	}
+
+	return nil
+}
+
+// Verify checks that the application is stopped.
+func (d *Deleter) Verify() error {
+	if !d.exists {
+		return nil
+	}
+
+	_, err := d.Verification.WaitForAppState(d.Context, d.Courier, d.AppName, "stopped")
+	if err != nil {
+		d.Log.Errorf("failed to verify app on foundation %s: %s", d.FoundationURL, err)
+		return err
+	}
+
+	return nil
+}
+
+// Undo starts the application again if it was stopped and maps the load balanced route back.
+func (d *Deleter) Undo() error {
+	if !d.exists {
+		return nil
+	}
+
+	if d.stopped {
+		d.Log.Infof("starting app %s", d.AppName)
+
+		output, err := d.Courier.Start(d.AppName)
+		d.Response.Write(output)
+		if err != nil {
+			return state.StartError{ApplicationName: d.AppName, Out: output}
+		}
+	}
+
+	if d.Domain != "" {
+		output, err := d.Courier.MapRoute(d.AppName, d.Domain, d.AppName)
+		if err != nil {
+			d.Log.Errorf("could not map %s to %s", d.AppName, d.Domain)
+			return state.MapRouteError{Out: output}
+		}
+	}
+
+	return nil
+}
+
+// Success deletes the application and its load balanced route. When asked to, it also deletes
+// the user provided services that were bound to the application and to no other application.
+func (d *Deleter) Success() error {
+	if !d.exists {
+		return nil
+	}
+
+	var services map[string][]string
+	if d.DeleteServices {
+		var err error
+		services, err = d.Courier.UserProvidedServices()
+		if err != nil {
+			d.Log.Errorf("could not list the services of %s: %s", d.AppName, err)
+			return err
+		}
+	}
+
+	d.Log.Infof("deleting app %s", d.AppName)
+
+	output, err := d.Courier.Delete(d.AppName)
+	d.Response.Write(output)
+	if err != nil {
+		d.Log.Errorf("failed to delete app on foundation %s", d.FoundationURL)
+		return state.DeleteApplicationError{ApplicationName: d.AppName, Out: output}
+	}
+
+	d.Log.Infof("successfully deleted app %s", d.AppName)
+
+	if d.Domain != "" {
+		_, err = d.Courier.DeleteRoute(d.Domain, d.AppName)
+		if err != nil {
+			d.Log.Errorf("could not delete route %s.%s: %s", d.AppName, d.Domain, err)
+		}
+	}
+
+	for service, boundApps := range services {
+		if !boundTo(boundApps, d.AppName) {
+			continue
+		}
+		if len(boundApps) > 1 {
+			d.Log.Infof("not deleting service %s: it is also bound to %v", service, boundApps)
+			continue
+		}
+
+		d.Log.Infof("deleting service %s", service)
+
+		output, err = d.Courier.DeleteService(service)
+		d.Response.Write(output)
+		if err != nil {
+			d.Log.Errorf("failed to delete service %s on foundation %s", service, d.FoundationURL)
+			return state.DeleteServiceError{ServiceName: service, Out: output}
+		}
+	}
+
+	return nil
+}
+
+func boundTo(boundApps []string, appName string) bool {
+	for _, app := range boundApps {
+		if app == appName {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,149 @@
+package deletion_test
+
+import (
+	"errors"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/compozed/deployadactyl/state"
+	"github.com/compozed/deployadactyl/state/command"
+	. "github.com/compozed/deployadactyl/state/deletion"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+var _ = Describe("Deleter", func() {
+	var (
+		deleter   *Deleter
+		courier   *mocks.Courier
+		logBuffer *Buffer
+		response  *Buffer
+
+		appName string
+		domain  string
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+		logBuffer = NewBuffer()
+		response = NewBuffer()
+
+		appName = "appName-" + randomizer.StringRunes(10)
+		domain = "domain-" + randomizer.StringRunes(10)
+
+		courier.ExistsCall.Returns.Bool = true
+		courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 1, Running: 1}
+
+		deleter = &Deleter{
+			Foundation: command.Foundation{
+				Courier:       courier,
+				CFContext:     I.CFContext{Organization: "org", Space: "space"},
+				Authorization: I.Authorization{Username: "username", Password: "password"},
+				Response:      response,
+				Log:           I.DeploymentLogger{Log: I.DefaultLogger(logBuffer, logging.DEBUG, "deleter_test")},
+				FoundationURL: "foundationURL",
+				AppName:       appName,
+			},
+			Domain: domain,
+		}
+	})
+
+	Describe("Execute", func() {
+		It("unmaps the load balanced route and stops the app without deleting it", func() {
+			Expect(deleter.Execute()).To(Succeed())
+
+			Expect(courier.UnmapRouteCall.Received.AppName).To(Equal(appName))
+			Expect(courier.UnmapRouteCall.Received.Domain).To(Equal(domain))
+			Expect(courier.StopCall.Received.AppName).To(Equal(appName))
+			Expect(courier.DeleteCall.Received.AppNames).To(BeEmpty())
+		})
+
+		It("does nothing when the app does not exist", func() {
+			courier.ExistsCall.Returns.Bool = false
+
+			Expect(deleter.Execute()).To(Succeed())
+			Expect(deleter.Success()).To(Succeed())
+
+			Expect(courier.StopCall.Received.AppName).To(BeEmpty())
+			Expect(courier.DeleteCall.Received.AppNames).To(BeEmpty())
+			Expect(logBuffer).To(Say("nothing to delete"))
+		})
+
+		It("returns an error when the route cannot be unmapped", func() {
+			courier.UnmapRouteCall.Returns.Output = []byte("unmap output")
+			courier.UnmapRouteCall.Returns.Error = errors.New("unmap error")
+
+			Expect(deleter.Execute()).To(MatchError(state.UnmapRouteError{ApplicationName: appName, Out: []byte("unmap output")}))
+		})
+	})
+
+	Describe("Undo", func() {
+		It("starts the app again and maps the route back", func() {
+			Expect(deleter.Execute()).To(Succeed())
+			Expect(deleter.Undo()).To(Succeed())
+
+			Expect(courier.StartCall.Received.AppName).To(Equal(appName))
+			Expect(courier.MapRouteCall.Received.AppName).To(Equal([]string{appName}))
+			Expect(courier.MapRouteCall.Received.Domain).To(Equal([]string{domain}))
+		})
+
+		It("does not start an app that was already stopped", func() {
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "stopped"}
+
+			Expect(deleter.Execute()).To(Succeed())
+			Expect(deleter.Undo()).To(Succeed())
+
+			Expect(courier.StartCall.Received.AppName).To(BeEmpty())
+		})
+	})
+
+	Describe("Success", func() {
+		BeforeEach(func() {
+			Expect(deleter.Execute()).To(Succeed())
+		})
+
+		It("deletes the app and its load balanced route", func() {
+			Expect(deleter.Success()).To(Succeed())
+
+			Expect(courier.DeleteCall.Received.AppNames).To(Equal([]string{appName}))
+			Expect(courier.DeleteRouteCall.Received.Domain).To(Equal(domain))
+			Expect(courier.DeleteRouteCall.Received.Hostname).To(Equal(appName))
+			Expect(courier.DeleteServiceCall.Received.ServiceNames).To(BeEmpty())
+		})
+
+		It("returns an error when the app cannot be deleted", func() {
+			courier.DeleteCall.Returns.Output = []byte("delete output")
+			courier.DeleteCall.Returns.Error = errors.New("delete error")
+
+			Expect(deleter.Success()).To(MatchError(state.DeleteApplicationError{ApplicationName: appName, Out: []byte("delete output")}))
+		})
+
+		Context("when the bound services are deleted too", func() {
+			BeforeEach(func() {
+				deleter.DeleteServices = true
+				courier.UserProvidedServicesCall.Returns.Services = map[string][]string{
+					"own-ups":    {appName},
+					"shared-ups": {appName, "other-app"},
+					"other-ups":  {"other-app"},
+				}
+			})
+
+			It("only deletes the user-provided services bound to nothing but the app", func() {
+				Expect(deleter.Success()).To(Succeed())
+
+				Expect(courier.DeleteServiceCall.Received.ServiceNames).To(Equal([]string{"own-ups"}))
+			})
+
+			It("returns an error when a service cannot be deleted", func() {
+				courier.DeleteServiceCall.Returns.Output = []byte("delete service output")
+				courier.DeleteServiceCall.Returns.Error = errors.New("delete service error")
+
+				Expect(deleter.Success()).To(MatchError(state.DeleteServiceError{ServiceName: "own-ups", Out: []byte("delete service output")}))
+			})
+		})
+	})
+})
@@ -1,6 +1,11 @@
 package state
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
 
 type CloudFoundryGetLogsError struct {
 	CfTaskErr error
@@ -11,6 +16,18 @@ func (e CloudFoundryGetLogsError) Error() string {
 	return fmt.Sprintf("%s: cannot get Cloud Foundry logs: %s", e.CfTaskErr, e.CfLogErr)
 }
 
+func (e CloudFoundryGetLogsError) Code() string {
+	return "CloudFoundryGetLogsError"
+}
+
+func (e CloudFoundryGetLogsError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e CloudFoundryGetLogsError) Retryable() bool {
+	return true
+}
+
 type DeleteApplicationError struct {
 	ApplicationName string
 	Out             []byte
@@ -20,6 +37,39 @@ func (e DeleteApplicationError) Error() string {
 	return fmt.Sprintf("cannot delete %s: %s", e.ApplicationName, string(e.Out))
 }
 
+func (e DeleteApplicationError) Code() string {
+	return "DeleteApplicationError"
+}
+
+func (e DeleteApplicationError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e DeleteApplicationError) Retryable() bool {
+	return true
+}
+
+type DeleteServiceError struct {
+	ServiceName string
+	Out         []byte
+}
+
+func (e DeleteServiceError) Error() string {
+	return fmt.Sprintf("cannot delete service %s: %s", e.ServiceName, string(e.Out))
+}
+
+func (e DeleteServiceError) Code() string {
+	return "DeleteServiceError"
+}
+
+func (e DeleteServiceError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e DeleteServiceError) Retryable() bool {
+	return true
+}
+
 type LoginError struct {
 	FoundationURL string
 	Out           []byte
@@ -29,6 +79,18 @@ func (e LoginError) Error() string {
 	return fmt.Sprintf("cannot login to %s: %s", e.FoundationURL, string(e.Out))
 }
 
+func (e LoginError) Code() string {
+	return "FoundationLoginError"
+}
+
+func (e LoginError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e LoginError) Retryable() bool {
+	return false
+}
+
 type RenameError struct {
 	ApplicationName string
 	Out             []byte
@@ -38,12 +100,36 @@ func (e RenameError) Error() string {
 	return fmt.Sprintf("cannot rename %s: %s", e.ApplicationName, string(e.Out))
 }
 
+func (e RenameError) Code() string {
+	return "RenameError"
+}
+
+func (e RenameError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e RenameError) Retryable() bool {
+	return true
+}
+
 type PushError struct{}
 
 func (e PushError) Error() string {
 	return "check the Cloud Foundry output above for more information"
 }
 
+func (e PushError) Code() string {
+	return "AppPushError"
+}
+
+func (e PushError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e PushError) Retryable() bool {
+	return false
+}
+
 type MapRouteError struct {
 	Out []byte
 }
@@ -52,6 +138,18 @@ func (e MapRouteError) Error() string {
 	return fmt.Sprintf("map route failed: %s", string(e.Out))
 }
 
+func (e MapRouteError) Code() string {
+	return "MapRouteError"
+}
+
+func (e MapRouteError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e MapRouteError) Retryable() bool {
+	return true
+}
+
 type UnmapRouteError struct {
 	ApplicationName string
 	Out             []byte
@@ -61,12 +159,36 @@ func (e UnmapRouteError) Error() string {
 	return fmt.Sprintf("failed to unmap route for %s: %s", e.ApplicationName, string(e.Out))
 }
 
+func (e UnmapRouteError) Code() string {
+	return "UnmapRouteError"
+}
+
+func (e UnmapRouteError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e UnmapRouteError) Retryable() bool {
+	return true
+}
+
 type InvalidContentTypeError struct{}
 
 func (e InvalidContentTypeError) Error() string {
 	return "must be application/json or application/zip"
 }
 
+func (e InvalidContentTypeError) Code() string {
+	return "InvalidContentTypeError"
+}
+
+func (e InvalidContentTypeError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e InvalidContentTypeError) Retryable() bool {
+	return false
+}
+
 type AppPathError struct {
 	Err error
 }
@@ -75,12 +197,36 @@ func (e AppPathError) Error() string {
 	return fmt.Sprintf("unzipped app path failed: %s", e.Err)
 }
 
+func (e AppPathError) Code() string {
+	return "AppPathError"
+}
+
+func (e AppPathError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e AppPathError) Retryable() bool {
+	return false
+}
+
 type ManifestError struct{}
 
 func (e ManifestError) Error() string {
 	return "manifest decoding error"
 }
 
+func (e ManifestError) Code() string {
+	return "ManifestDecodingError"
+}
+
+func (e ManifestError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e ManifestError) Retryable() bool {
+	return false
+}
+
 type UnzippingError struct {
 	Err error
 }
@@ -89,6 +235,18 @@ func (e UnzippingError) Error() string {
 	return fmt.Sprintf("unzipping request body error: %s", e.Err)
 }
 
+func (e UnzippingError) Code() string {
+	return "UnzippingError"
+}
+
+func (e UnzippingError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e UnzippingError) Retryable() bool {
+	return false
+}
+
 type CourierCreationError struct {
 	Err error
 }
@@ -97,6 +255,18 @@ func (e CourierCreationError) Error() string {
 	return fmt.Sprintf("failed to create Courier: %s", e.Err.Error())
 }
 
+func (e CourierCreationError) Code() string {
+	return "CourierCreationError"
+}
+
+func (e CourierCreationError) Category() string {
+	return C.ErrorCategoryInternal
+}
+
+func (e CourierCreationError) Retryable() bool {
+	return false
+}
+
 type StartError struct {
 	ApplicationName string
 	Out             []byte
@@ -106,6 +276,60 @@ func (e StartError) Error() string {
 	return fmt.Sprintf("cannot start %s: %s", e.ApplicationName, string(e.Out))
 }
 
+func (e StartError) Code() string {
+	return "AppStartError"
+}
+
+func (e StartError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e StartError) Retryable() bool {
+	return true
+}
+
+type RestartError struct {
+	ApplicationName string
+	Out             []byte
+}
+
+func (e RestartError) Error() string {
+	return fmt.Sprintf("cannot restart %s: %s", e.ApplicationName, string(e.Out))
+}
+
+func (e RestartError) Code() string {
+	return "AppRestartError"
+}
+
+func (e RestartError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e RestartError) Retryable() bool {
+	return true
+}
+
+type RestageError struct {
+	ApplicationName string
+	Out             []byte
+}
+
+func (e RestageError) Error() string {
+	return fmt.Sprintf("cannot restage %s: %s", e.ApplicationName, string(e.Out))
+}
+
+func (e RestageError) Code() string {
+	return "AppRestageError"
+}
+
+func (e RestageError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e RestageError) Retryable() bool {
+	return true
+}
+
 type StopError struct {
 	ApplicationName string
 	Out             []byte
@@ -115,6 +339,18 @@ func (e StopError) Error() string {
 	return fmt.Sprintf("cannot stop %s: %s", e.ApplicationName, string(e.Out))
 }
 
+func (e StopError) Code() string {
+	return "AppStopError"
+}
+
+func (e StopError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e StopError) Retryable() bool {
+	return true
+}
+
 type ExistsError struct {
 	ApplicationName string
 }
@@ -122,3 +358,151 @@ type ExistsError struct {
 func (e ExistsError) Error() string {
 	return fmt.Sprintf("app %s doesn't exist", e.ApplicationName)
 }
+
+func (e ExistsError) Code() string {
+	return "ExistsError"
+}
+
+func (e ExistsError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e ExistsError) Retryable() bool {
+	return false
+}
+
+type ScaleError struct {
+	ApplicationName string
+	Out             []byte
+}
+
+func (e ScaleError) Error() string {
+	return fmt.Sprintf("cannot scale %s: %s", e.ApplicationName, string(e.Out))
+}
+
+func (e ScaleError) Code() string {
+	return "AppScaleError"
+}
+
+func (e ScaleError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e ScaleError) Retryable() bool {
+	return true
+}
+
+type CanaryHealthCheckError struct {
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+func (e CanaryHealthCheckError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("canary health check of %s failed: %s", e.URL, e.Err)
+	}
+	return fmt.Sprintf("canary health check of %s returned %d", e.URL, e.StatusCode)
+}
+
+func (e CanaryHealthCheckError) Code() string {
+	return "CanaryHealthCheckError"
+}
+
+func (e CanaryHealthCheckError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e CanaryHealthCheckError) Retryable() bool {
+	return false
+}
+
+type AppStateError struct {
+	ApplicationName string
+	RequestedState  string
+	State           string
+}
+
+func (e AppStateError) Error() string {
+	return fmt.Sprintf("%s is %s instead of %s", e.ApplicationName, e.State, e.RequestedState)
+}
+
+func (e AppStateError) Code() string {
+	return "AppStateError"
+}
+
+func (e AppStateError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e AppStateError) Retryable() bool {
+	return false
+}
+
+type InstancesNotRunningError struct {
+	ApplicationName string
+	Running         uint16
+	Instances       uint16
+}
+
+func (e InstancesNotRunningError) Error() string {
+	return fmt.Sprintf("only %d of %d instances of %s are running", e.Running, e.Instances, e.ApplicationName)
+}
+
+func (e InstancesNotRunningError) Code() string {
+	return "InstancesNotRunningError"
+}
+
+func (e InstancesNotRunningError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e InstancesNotRunningError) Retryable() bool {
+	return true
+}
+
+type HealthCheckTimeoutError struct {
+	URL     string
+	Timeout time.Duration
+}
+
+func (e HealthCheckTimeoutError) Error() string {
+	return fmt.Sprintf("health check of %s did not answer within %s", e.URL, e.Timeout)
+}
+
+func (e HealthCheckTimeoutError) Code() string {
+	return "HealthCheckTimeoutError"
+}
+
+func (e HealthCheckTimeoutError) Category() string {
+	return C.ErrorCategoryFoundation
+}
+
+func (e HealthCheckTimeoutError) Retryable() bool {
+	return true
+}
+
+type RouteCheckError struct {
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+func (e RouteCheckError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("route %s does not respond: %s", e.URL, e.Err)
+	}
+	return fmt.Sprintf("route %s responded with %d", e.URL, e.StatusCode)
+}
+
+func (e RouteCheckError) Code() string {
+	return "RouteCheckError"
+}
+
+func (e RouteCheckError) Category() string {
+	return C.ErrorCategoryArtifact
+}
+
+func (e RouteCheckError) Retryable() bool {
+	return false
+}
@@ -0,0 +1,26 @@
+package state
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+// HealthCheck gets the url with the client. When a timeout is given and the client is an http.Client,
+// the request is given up after the timeout and a HealthCheckTimeoutError is returned.
+func HealthCheck(client I.Client, url string, timeout time.Duration) (*http.Response, error) {
+	if httpClient, ok := client.(*http.Client); ok && timeout > 0 {
+		limited := *httpClient
+		limited.Timeout = timeout
+		client = &limited
+	}
+
+	resp, err := client.Get(url)
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return nil, HealthCheckTimeoutError{URL: url, Timeout: timeout}
+	}
+
+	return resp, err
+}
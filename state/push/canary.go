@@ -0,0 +1,194 @@
+package push
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/compozed/deployadactyl/state"
+)
+
+// isCanary returns true when the new build has to take over the traffic of an existing application in steps.
+func (p *Pusher) isCanary() bool {
+	return p.Environment.Canary.Enabled && len(p.Environment.Canary.Steps) > 0 && p.Courier.Exists(p.DeploymentInfo.AppName)
+}
+
+// canaryInstances returns how many of the instances run the new build at a step, which is at least one.
+func canaryInstances(instances, percent uint16) uint16 {
+	canary := (uint32(instances)*uint32(percent) + 99) / 100
+	if canary < 1 {
+		canary = 1
+	}
+	if canary > uint32(instances) {
+		canary = uint32(instances)
+	}
+
+	return uint16(canary)
+}
+
+// shiftTraffic runs the steps of a canary push. On each step the new build is scaled up and the
+// original application is scaled down by as many instances, after which the health check endpoint
+// of the new build is called Checks times. Any failed check stops the push so that it is rolled back.
+func (p *Pusher) shiftTraffic(tempAppWithUUID string) error {
+	var (
+		canary    = p.Environment.Canary
+		instances = p.DeploymentInfo.Instances
+		checkURL  string
+	)
+
+	if p.DeploymentInfo.HealthCheckEndpoint != "" && p.DeploymentInfo.Domain != "" && p.Client != nil {
+		out, err := p.Courier.MapRoute(tempAppWithUUID, p.DeploymentInfo.Domain, tempAppWithUUID)
+		if err != nil {
+			p.Log.Errorf("could not map %s to %s", tempAppWithUUID, p.DeploymentInfo.Domain)
+			return state.MapRouteError{Out: out}
+		}
+		defer p.Courier.DeleteRoute(p.DeploymentInfo.Domain, tempAppWithUUID)
+		defer p.Courier.UnmapRoute(tempAppWithUUID, p.DeploymentInfo.Domain, tempAppWithUUID)
+
+		checkURL = fmt.Sprintf("https://%s.%s/%s", tempAppWithUUID, p.DeploymentInfo.Domain, strings.TrimPrefix(p.DeploymentInfo.HealthCheckEndpoint, "/"))
+	} else {
+		p.Log.Infof("no health check endpoint and domain for the canary of %s: only waiting between steps", p.DeploymentInfo.AppName)
+	}
+
+	status, err := p.Courier.AppStatus(p.DeploymentInfo.AppName)
+	if err != nil {
+		p.Log.Errorf("could not look up the instances of %s before the canary: %s", p.DeploymentInfo.AppName, err)
+		return err
+	}
+	p.original = &status
+
+	for i, step := range canary.Steps {
+		newInstances := canaryInstances(instances, step)
+
+		if i > 0 {
+			err := p.scaleApplication(tempAppWithUUID, newInstances)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := p.scaleApplication(p.DeploymentInfo.AppName, instances-newInstances)
+		if err != nil {
+			return err
+		}
+
+		p.Log.Infof("canary step %d%%: %d of %d instances run %s", step, newInstances, instances, tempAppWithUUID)
+		fmt.Fprintf(p.Response, "canary step %d%%: %d of %d instances run the new build\n", step, newInstances, instances)
+
+		for check := 0; check < canary.Checks; check++ {
+			err = state.Sleep(p.Context, time.Duration(canary.Interval)*time.Second)
+			if err != nil {
+				p.Log.Errorf("canary of %s stopped on step %d%%: %s", tempAppWithUUID, step, err)
+				return err
+			}
+
+			if checkURL == "" {
+				continue
+			}
+
+			err = p.checkCanary(checkURL)
+			if err != nil {
+				fmt.Fprintf(p.Response, "canary health check failed on step %d%%: %s\n", step, err)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *Pusher) checkCanary(url string) error {
+	p.Log.Debugf("checking canary %s", url)
+
+	resp, err := state.HealthCheck(p.Client, url, p.healthCheckTimeout())
+	if timeoutErr, ok := err.(state.HealthCheckTimeoutError); ok {
+		p.Log.Errorf("canary health check of %s timed out", url)
+		return timeoutErr
+	}
+	if err != nil {
+		p.Log.Errorf("canary health check of %s failed: %s", url, err)
+		return state.CanaryHealthCheckError{URL: url, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		p.Log.Errorf("canary health check of %s returned %d: %s", url, resp.StatusCode, body)
+		return state.CanaryHealthCheckError{URL: url, StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// checkOwnRoute maps a route of its own on the domain to the new build for as long as it takes to check it.
+func (p *Pusher) checkOwnRoute(tempAppWithUUID string) error {
+	out, err := p.Courier.MapRoute(tempAppWithUUID, p.DeploymentInfo.Domain, tempAppWithUUID)
+	if err != nil {
+		p.Log.Errorf("could not map %s to %s", tempAppWithUUID, p.DeploymentInfo.Domain)
+		return state.MapRouteError{Out: out}
+	}
+	defer p.Courier.DeleteRoute(p.DeploymentInfo.Domain, tempAppWithUUID)
+	defer p.Courier.UnmapRoute(tempAppWithUUID, p.DeploymentInfo.Domain, tempAppWithUUID)
+
+	return p.checkRoute(tempAppWithUUID + "." + p.DeploymentInfo.Domain)
+}
+
+// checkRoute calls the health check endpoint, or else the path of the route check of the environment,
+// on a route of the new build. It has to return the status code of the route check when one is set.
+// Otherwise it has to return 200 when a path is called, or only answer without a server error.
+func (p *Pusher) checkRoute(route string) error {
+	var (
+		path   = p.DeploymentInfo.HealthCheckEndpoint
+		status = p.Environment.RouteCheck.StatusCode
+	)
+	if path == "" {
+		path = p.Environment.RouteCheck.Path
+	}
+
+	url := fmt.Sprintf("https://%s/%s", strings.TrimSuffix(route, "/"), strings.TrimPrefix(path, "/"))
+
+	p.Log.Debugf("checking route %s", url)
+
+	resp, err := state.HealthCheck(p.Client, url, p.healthCheckTimeout())
+	if timeoutErr, ok := err.(state.HealthCheckTimeoutError); ok {
+		p.Log.Errorf("route check of %s timed out", url)
+		return timeoutErr
+	}
+	if err != nil {
+		p.Log.Errorf("route check of %s failed: %s", url, err)
+		return state.RouteCheckError{URL: url, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if status == 0 && path != "" {
+		status = http.StatusOK
+	}
+
+	if (status != 0 && resp.StatusCode != status) || (status == 0 && resp.StatusCode >= http.StatusInternalServerError) {
+		p.Log.Errorf("route check of %s returned %d", url, resp.StatusCode)
+		return state.RouteCheckError{URL: url, StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// healthCheckTimeout is how long a health check of the environment may take, or zero when there is no limit.
+func (p *Pusher) healthCheckTimeout() time.Duration {
+	return time.Duration(p.Environment.Timeouts.HealthCheck) * time.Second
+}
+
+func (p *Pusher) scaleApplication(appName string, instances uint16) error {
+	p.Log.Debugf("scaling %s to %d instances", appName, instances)
+
+	out, err := p.Courier.Scale(appName, instances)
+	if err != nil {
+		p.Log.Errorf("could not scale %s to %d instances", appName, instances)
+		return state.ScaleError{ApplicationName: appName, Out: out}
+	}
+
+	p.Log.Infof("scaled %s to %d instances", appName, instances)
+
+	return nil
+}
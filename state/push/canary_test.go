@@ -0,0 +1,189 @@
+package push_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/compozed/deployadactyl/state"
+	. "github.com/compozed/deployadactyl/state/push"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+var _ = Describe("Canary", func() {
+	var (
+		pusher          Pusher
+		courier         *mocks.Courier
+		client          *mocks.Client
+		response        *Buffer
+		appName         string
+		tempAppWithUUID string
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+		client = &mocks.Client{}
+		response = NewBuffer()
+
+		appName = "appName-" + randomizer.StringRunes(10)
+		uuid := randomizer.StringRunes(10)
+		tempAppWithUUID = appName + TemporaryNameSuffix + uuid
+
+		courier.ExistsCall.Returns.Bool = true
+		client.GetCall.Returns.Response = http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(""))}
+
+		pusher = Pusher{
+			Courier: courier,
+			DeploymentInfo: S.DeploymentInfo{
+				AppName:             appName,
+				UUID:                uuid,
+				Instances:           4,
+				Domain:              "example.com",
+				HealthCheckEndpoint: "/health",
+			},
+			EventManager: &mocks.EventManager{},
+			Response:     response,
+			Log:          interfaces.DeploymentLogger{Log: interfaces.DefaultLogger(NewBuffer(), logging.DEBUG, "canary_test")},
+			Environment: S.Environment{
+				EnableRollback: true,
+				Canary:         S.Canary{Enabled: true, Steps: []uint16{25, 50, 100}, Checks: 2},
+			},
+			Client: client,
+		}
+	})
+
+	Describe("Execute", func() {
+		It("pushes the new build with the instances of the first step", func() {
+			Expect(pusher.Execute()).To(Succeed())
+
+			Expect(courier.PushCall.Received.AppName).To(Equal(tempAppWithUUID))
+			Expect(courier.PushCall.Received.Instances).To(Equal(uint16(1)))
+		})
+
+		It("shifts the instances onto the new build step by step", func() {
+			Expect(pusher.Execute()).To(Succeed())
+
+			Expect(courier.ScaleCall.Received.Scales).To(Equal([]string{
+				appName + "=3",
+				tempAppWithUUID + "=2",
+				appName + "=2",
+				tempAppWithUUID + "=4",
+				appName + "=0",
+			}))
+			Expect(response).To(Say("canary step 25%: 1 of 4 instances run the new build"))
+			Expect(response).To(Say("canary step 100%: 4 of 4 instances run the new build"))
+		})
+
+		It("checks the health of the new build on every step", func() {
+			Expect(pusher.Execute()).To(Succeed())
+
+			Expect(client.GetCall.Received.URL).To(Equal("https://" + tempAppWithUUID + ".example.com/health"))
+			Expect(courier.MapRouteCall.Received.AppName).To(ContainElement(tempAppWithUUID))
+			Expect(courier.MapRouteCall.Received.Hostname).To(ContainElement(tempAppWithUUID))
+			Expect(courier.UnmapRouteCall.Received.Hostname).To(Equal(tempAppWithUUID))
+			Expect(courier.DeleteRouteCall.Received.Hostname).To(Equal(tempAppWithUUID))
+		})
+
+		Context("when a health check fails", func() {
+			It("stops shifting and returns an error", func() {
+				client.GetCall.Returns.Response = http.Response{StatusCode: http.StatusInternalServerError, Body: ioutil.NopCloser(bytes.NewBufferString("down"))}
+
+				err := pusher.Execute()
+
+				Expect(err).To(MatchError(state.CanaryHealthCheckError{URL: "https://" + tempAppWithUUID + ".example.com/health", StatusCode: http.StatusInternalServerError}))
+				Expect(courier.ScaleCall.Received.Scales).To(Equal([]string{appName + "=3"}))
+			})
+		})
+
+		Context("when the instances of the original application cannot be looked up", func() {
+			It("returns an error before shifting", func() {
+				courier.AppStatusCall.Returns.Error = errors.New("app status error")
+
+				Expect(pusher.Execute()).To(MatchError("app status error"))
+				Expect(courier.ScaleCall.Received.Scales).To(BeEmpty())
+			})
+		})
+
+		Context("when scaling fails", func() {
+			It("returns an error", func() {
+				courier.ScaleCall.Returns.Output = []byte("scale output")
+				courier.ScaleCall.Returns.Error = errors.New("scale error")
+
+				Expect(pusher.Execute()).To(MatchError(state.ScaleError{ApplicationName: appName, Out: []byte("scale output")}))
+			})
+		})
+
+		Context("when the deployment is cancelled while it waits between checks", func() {
+			It("stops shifting and returns the error of the context", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				pusher.Context = ctx
+				pusher.Environment.Canary.Interval = 60
+				time.AfterFunc(50*time.Millisecond, cancel)
+
+				start := time.Now()
+				err := pusher.Execute()
+
+				Expect(err).To(Equal(context.Canceled))
+				Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+				Expect(courier.ScaleCall.Received.Scales).To(Equal([]string{appName + "=3"}))
+				Expect(client.GetCall.Received.URL).To(BeEmpty())
+			})
+		})
+
+		Context("when the application does not exist yet", func() {
+			It("pushes all of the instances at once", func() {
+				courier.ExistsCall.Returns.Bool = false
+
+				Expect(pusher.Execute()).To(Succeed())
+
+				Expect(courier.PushCall.Received.Instances).To(Equal(uint16(4)))
+				Expect(courier.ScaleCall.Received.Scales).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Success", func() {
+		It("keeps the venerable application with the instances it had before the canary", func() {
+			pusher.Environment.KeepVenerable = 1
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 6, Running: 6}
+			Expect(pusher.Execute()).To(Succeed())
+
+			Expect(pusher.Success()).To(Succeed())
+
+			scales := courier.ScaleCall.Received.Scales
+			Expect(scales[len(scales)-1]).To(Equal(appName + "=6"))
+			Expect(courier.RenameCall.Received.NewNames).To(ContainElement(appName + "-venerable"))
+		})
+	})
+
+	Describe("Undo", func() {
+		It("scales the original application back to the instances it had before the canary", func() {
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 6, Running: 6}
+			client.GetCall.Returns.Response = http.Response{StatusCode: http.StatusInternalServerError, Body: ioutil.NopCloser(bytes.NewBufferString("down"))}
+			Expect(pusher.Execute()).ToNot(Succeed())
+
+			Expect(pusher.Undo()).To(Succeed())
+
+			Expect(courier.AppStatusCall.Received.AppName).To(Equal(appName))
+			Expect(courier.ScaleCall.Received.Scales).To(Equal([]string{appName + "=3", appName + "=6"}))
+			Expect(courier.DeleteCall.Received.AppName).To(Equal(tempAppWithUUID))
+		})
+
+		It("does not scale the original application when no traffic was shifted off it", func() {
+			Expect(pusher.Undo()).To(Succeed())
+
+			Expect(courier.ScaleCall.Received.Scales).To(BeEmpty())
+			Expect(courier.DeleteCall.Received.AppName).To(Equal(tempAppWithUUID))
+		})
+	})
+})
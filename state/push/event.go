@@ -7,6 +7,7 @@ import (
 	"github.com/compozed/deployadactyl/structs"
 	"io"
 	"reflect"
+	"time"
 )
 
 type eventBinding struct {
@@ -185,6 +186,7 @@ type PushFinishedEvent struct {
 	Data                map[string]interface{}
 	Courier             interfaces.Courier
 	HealthCheckEndpoint string
+	HealthCheckTimeout  time.Duration
 	Log                 interfaces.DeploymentLogger
 }
 
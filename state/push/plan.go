@@ -0,0 +1,54 @@
+package push
+
+import (
+	"fmt"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+// Plan returns the steps Execute, Verify and Success would take on the foundation, depending on
+// whether the application already exists there. Nothing is changed on the foundation.
+func (p *Pusher) Plan() (I.FoundationPlan, error) {
+	var (
+		appName         = p.DeploymentInfo.AppName
+		tempAppWithUUID = appName + TemporaryNameSuffix + p.DeploymentInfo.UUID
+		instances       = p.DeploymentInfo.Instances
+		plan            = I.FoundationPlan{AppExists: p.Courier.Exists(appName)}
+		canary          = p.Environment.Canary.Enabled && len(p.Environment.Canary.Steps) > 0 && plan.AppExists
+	)
+
+	pushed := instances
+	if canary {
+		pushed = canaryInstances(instances, p.Environment.Canary.Steps[0])
+	}
+	plan.Steps = append(plan.Steps, fmt.Sprintf("push %s with %d instances", tempAppWithUUID, pushed))
+
+	if p.DeploymentInfo.Domain != "" {
+		plan.Steps = append(plan.Steps, fmt.Sprintf("map route %s.%s to %s", appName, p.DeploymentInfo.Domain, tempAppWithUUID))
+	}
+
+	if canary {
+		for _, step := range p.Environment.Canary.Steps {
+			newInstances := canaryInstances(instances, step)
+			plan.Steps = append(plan.Steps, fmt.Sprintf("scale %s to %d and %s to %d instances", tempAppWithUUID, newInstances, appName, instances-newInstances))
+		}
+	}
+
+	plan.Steps = append(plan.Steps, fmt.Sprintf("verify %s", tempAppWithUUID))
+
+	if plan.AppExists {
+		if p.DeploymentInfo.Domain != "" {
+			plan.Steps = append(plan.Steps, fmt.Sprintf("unmap route %s.%s from %s", appName, p.DeploymentInfo.Domain, appName))
+		}
+
+		if p.Environment.KeepVenerable > 0 {
+			plan.Steps = append(plan.Steps, fmt.Sprintf("stop %s and rename it to %s", appName, VenerableName(appName, 1)))
+		} else {
+			plan.Steps = append(plan.Steps, fmt.Sprintf("delete %s", appName))
+		}
+	}
+
+	plan.Steps = append(plan.Steps, fmt.Sprintf("rename %s to %s", tempAppWithUUID, appName))
+
+	return plan, nil
+}
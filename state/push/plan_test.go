@@ -0,0 +1,106 @@
+package push_test
+
+import (
+	"github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	. "github.com/compozed/deployadactyl/state/push"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+var _ = Describe("Plan", func() {
+	var (
+		pusher          Pusher
+		courier         *mocks.Courier
+		appName         string
+		tempAppWithUUID string
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+
+		appName = "appName-" + randomizer.StringRunes(10)
+		uuid := randomizer.StringRunes(10)
+		tempAppWithUUID = appName + TemporaryNameSuffix + uuid
+
+		pusher = Pusher{
+			Courier: courier,
+			DeploymentInfo: S.DeploymentInfo{
+				AppName:   appName,
+				UUID:      uuid,
+				Instances: 4,
+				Domain:    "example.com",
+			},
+			EventManager: &mocks.EventManager{},
+			Response:     NewBuffer(),
+			Log:          interfaces.DeploymentLogger{Log: interfaces.DefaultLogger(NewBuffer(), logging.DEBUG, "plan_test")},
+		}
+	})
+
+	Context("when the application does not exist", func() {
+		It("plans to push the new build and give it the name of the application", func() {
+			plan, err := pusher.Plan()
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.AppExists).To(BeFalse())
+			Expect(plan.Steps).To(Equal([]string{
+				"push " + tempAppWithUUID + " with 4 instances",
+				"map route " + appName + ".example.com to " + tempAppWithUUID,
+				"verify " + tempAppWithUUID,
+				"rename " + tempAppWithUUID + " to " + appName,
+			}))
+		})
+	})
+
+	Context("when the application exists", func() {
+		BeforeEach(func() {
+			courier.ExistsCall.Returns.Bool = true
+		})
+
+		It("plans to replace the application", func() {
+			plan, err := pusher.Plan()
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.AppExists).To(BeTrue())
+			Expect(plan.Steps).To(Equal([]string{
+				"push " + tempAppWithUUID + " with 4 instances",
+				"map route " + appName + ".example.com to " + tempAppWithUUID,
+				"verify " + tempAppWithUUID,
+				"unmap route " + appName + ".example.com from " + appName,
+				"delete " + appName,
+				"rename " + tempAppWithUUID + " to " + appName,
+			}))
+		})
+
+		It("plans to keep the application when the environment keeps venerable applications", func() {
+			pusher.Environment.KeepVenerable = 2
+
+			plan, _ := pusher.Plan()
+
+			Expect(plan.Steps).To(ContainElement("stop " + appName + " and rename it to " + appName + "-venerable"))
+			Expect(plan.Steps).ToNot(ContainElement("delete " + appName))
+		})
+
+		It("plans the steps of a canary push", func() {
+			pusher.Environment.Canary = S.Canary{Enabled: true, Steps: []uint16{25, 100}}
+
+			plan, _ := pusher.Plan()
+
+			Expect(plan.Steps[0]).To(Equal("push " + tempAppWithUUID + " with 1 instances"))
+			Expect(plan.Steps).To(ContainElement("scale " + tempAppWithUUID + " to 1 and " + appName + " to 3 instances"))
+			Expect(plan.Steps).To(ContainElement("scale " + tempAppWithUUID + " to 4 and " + appName + " to 0 instances"))
+		})
+	})
+
+	It("does not change anything on the foundation", func() {
+		pusher.Plan()
+
+		Expect(courier.PushCall.Received.AppName).To(BeEmpty())
+		Expect(courier.MapRouteCall.TimesCalled).To(BeZero())
+		Expect(courier.RenameCall.Received.AppName).To(BeEmpty())
+	})
+})
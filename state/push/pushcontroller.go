@@ -10,16 +10,16 @@ import (
 	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
 	"github.com/compozed/deployadactyl/geterrors"
 	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
 	"github.com/compozed/deployadactyl/structs"
 	"io"
 	"io/ioutil"
-	"net/http"
 	"os"
 )
 
-type PushControllerConstructor func(log I.DeploymentLogger, deployer, silentDeployer I.Deployer, conf config.Config, eventManager I.EventManager, errorFinder I.ErrorFinder, pushManagerFactory I.PushManagerFactory) I.PushController
+type PushControllerConstructor func(log I.DeploymentLogger, deployer, silentDeployer I.Deployer, conf config.Config, eventManager I.EventManager, errorFinder I.ErrorFinder, pushManagerFactory I.PushManagerFactory, locker I.Locker) I.PushController
 
-func NewPushController(l I.DeploymentLogger, d, sd I.Deployer, c config.Config, em I.EventManager, ef I.ErrorFinder, pmf I.PushManagerFactory) I.PushController {
+func NewPushController(l I.DeploymentLogger, d, sd I.Deployer, c config.Config, em I.EventManager, ef I.ErrorFinder, pmf I.PushManagerFactory, lk I.Locker) I.PushController {
 	return &PushController{
 		Deployer:           d,
 		SilentDeployer:     sd,
@@ -28,6 +28,7 @@ func NewPushController(l I.DeploymentLogger, d, sd I.Deployer, c config.Config,
 		ErrorFinder:        ef,
 		PushManagerFactory: pmf,
 		Log:                l,
+		Locker:             lk,
 	}
 }
 
@@ -39,6 +40,7 @@ type PushController struct {
 	EventManager       I.EventManager
 	ErrorFinder        I.ErrorFinder
 	PushManagerFactory I.PushManagerFactory
+	Locker             I.Locker
 }
 
 // PUSH specific
@@ -65,23 +67,24 @@ func (c *PushController) RunDeployment(deployment *I.Deployment, response *bytes
 		deploymentInfo.Body = body
 		deploymentInfo.ContentType = "ZIP"
 	} else {
+		contentTypeErr := deployer.InvalidContentTypeError{}
 		return I.DeployResponse{
-			StatusCode: http.StatusBadRequest,
-			Error:      deployer.InvalidContentTypeError{},
+			StatusCode: deployer.StatusCode(contentTypeErr),
+			Error:      contentTypeErr,
 		}
 	}
 	environment, err := c.resolveEnvironment(cf.Environment)
 	if err != nil {
 		fmt.Fprintln(response, err.Error())
 		return I.DeployResponse{
-			StatusCode: http.StatusInternalServerError,
+			StatusCode: deployer.StatusCode(err),
 			Error:      err,
 		}
 	}
-	auth, err := c.resolveAuthorization(deployment.Authorization, environment, c.Log)
+	auth, err := state.ResolveAuthorization(deployment.Authorization, environment, c.Config, c.Log)
 	if err != nil {
 		return I.DeployResponse{
-			StatusCode: http.StatusUnauthorized,
+			StatusCode: deployer.StatusCode(err),
 			Error:      err,
 		}
 	}
@@ -90,6 +93,8 @@ func (c *PushController) RunDeployment(deployment *I.Deployment, response *bytes
 	deploymentInfo.Password = auth.Password
 	deploymentInfo.Domain = environment.Domain
 	deploymentInfo.SkipSSL = environment.SkipSSL
+	deploymentInfo.ClientCredentials = environment.ClientCredentials
+	deploymentInfo.ServiceAccount = deployment.Authorization.Username == "" && deployment.Authorization.Password == ""
 	deploymentInfo.CustomParams = environment.CustomParams
 
 	if deployment.Type.JSON {
@@ -97,7 +102,7 @@ func (c *PushController) RunDeployment(deployment *I.Deployment, response *bytes
 		if err != nil {
 			c.Log.Error(err)
 			return I.DeployResponse{
-				StatusCode:     http.StatusInternalServerError,
+				StatusCode:     deployer.StatusCode(err),
 				Error:          err,
 				DeploymentInfo: deploymentInfo,
 			}
@@ -105,6 +110,24 @@ func (c *PushController) RunDeployment(deployment *I.Deployment, response *bytes
 	}
 
 	deployEventData := structs.DeployEventData{Response: response, DeploymentInfo: deploymentInfo, RequestBody: body}
+
+	if deployment.DryRun {
+		c.Log.Debugf("planning the deploy of %s", cf.Application)
+		deploymentInfo.DryRun = true
+		pusherCreator := c.PushManagerFactory.PushManager(c.Log, deployEventData, cf, auth, environment, deploymentInfo.EnvironmentVariables)
+		return *c.Deployer.Deploy(deployment.Context, deploymentInfo, environment, pusherCreator, response)
+	}
+
+	unlock, err := c.Locker.Lock(deployment.Context, cf, I.LockHolder{UUID: c.Log.UUID, Type: constants.DeploymentTypePush, User: auth.Username})
+	if err != nil {
+		fmt.Fprintln(response, err.Error())
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+	defer unlock()
+
 	defer c.emitDeployFinish(&deployEventData, response, cf, auth, environment, &deployResponse, c.Log)
 	defer c.emitDeploySuccessOrFailure(&deployEventData, response, cf, auth, environment, &deployResponse, c.Log)
 
@@ -113,10 +136,11 @@ func (c *PushController) RunDeployment(deployment *I.Deployment, response *bytes
 	err = c.EventManager.Emit(I.Event{Type: constants.DeployStartEvent, Data: &deployEventData})
 	if err != nil {
 		c.Log.Error(err)
-		err = &bluegreen.InitializationError{err}
+		err = bluegreen.InitializationError{Err: err}
+		err = deployer.EventError{Type: constants.DeployStartEvent, Err: err}
 		return I.DeployResponse{
-			StatusCode:     http.StatusInternalServerError,
-			Error:          deployer.EventError{Type: constants.DeployStartEvent, Err: err},
+			StatusCode:     deployer.StatusCode(err),
+			Error:          err,
 			DeploymentInfo: deploymentInfo,
 		}
 	}
@@ -134,10 +158,11 @@ func (c *PushController) RunDeployment(deployment *I.Deployment, response *bytes
 	})
 	if err != nil {
 		c.Log.Error(err)
-		err = &bluegreen.InitializationError{err}
+		err = bluegreen.InitializationError{Err: err}
+		err = deployer.EventError{Type: constants.DeployStartEvent, Err: err}
 		return I.DeployResponse{
-			StatusCode:     http.StatusInternalServerError,
-			Error:          deployer.EventError{Type: constants.DeployStartEvent, Err: err},
+			StatusCode:     deployer.StatusCode(err),
+			Error:          err,
 			DeploymentInfo: deploymentInfo,
 		}
 	}
@@ -150,13 +175,13 @@ func (c *PushController) RunDeployment(deployment *I.Deployment, response *bytes
 	defer close(reqChannel2)
 
 	go func() {
-		reqChannel1 <- c.Deployer.Deploy(deploymentInfo, environment, pusherCreator, response)
+		reqChannel1 <- c.Deployer.Deploy(deployment.Context, deploymentInfo, environment, pusherCreator, response)
 	}()
 
 	silentResponse := &bytes.Buffer{}
 	if cf.Environment == os.Getenv("SILENT_DEPLOY_ENVIRONMENT") {
 		go func() {
-			reqChannel2 <- c.SilentDeployer.Deploy(deploymentInfo, environment, pusherCreator, silentResponse)
+			reqChannel2 <- c.SilentDeployer.Deploy(deployment.Context, deploymentInfo, environment, pusherCreator, silentResponse)
 		}()
 		<-reqChannel2
 	}
@@ -170,7 +195,7 @@ func (c *PushController) getDeploymentInfo(body *[]byte, deploymentInfo *structs
 	reader := ioutil.NopCloser(bytes.NewBuffer(*body))
 	err := json.NewDecoder(reader).Decode(deploymentInfo)
 	if err != nil {
-		return deploymentInfo, err
+		return deploymentInfo, deployer.InvalidRequestBodyError{Err: err}
 	}
 
 	getter := geterrors.WrapFunc(func(key string) string {
@@ -184,26 +209,11 @@ func (c *PushController) getDeploymentInfo(body *[]byte, deploymentInfo *structs
 
 	err = getter.Err("The following properties are missing")
 	if err != nil {
-		return &structs.DeploymentInfo{}, err
+		return &structs.DeploymentInfo{}, deployer.InvalidRequestBodyError{Err: err}
 	}
 	return deploymentInfo, nil
 }
 
-func (c *PushController) resolveAuthorization(auth I.Authorization, envs structs.Environment, deploymentLogger I.DeploymentLogger) (I.Authorization, error) {
-	config := c.Config
-	deploymentLogger.Debug("checking for basic auth")
-	if auth.Username == "" && auth.Password == "" {
-		if envs.Authenticate {
-			return I.Authorization{}, deployer.BasicAuthError{}
-
-		}
-		auth.Username = config.Username
-		auth.Password = config.Password
-	}
-
-	return auth, nil
-}
-
 func (c *PushController) resolveEnvironment(env string) (structs.Environment, error) {
 	config := c.Config
 	environment, ok := config.Environments[env]
@@ -220,7 +230,7 @@ func (c *PushController) emitDeployFinish(deployEventData *structs.DeployEventDa
 		fmt.Fprintln(response, finishErr)
 		err := bluegreen.FinishDeployError{Err: fmt.Errorf("%s: %s", deployResponse.Error, deployer.EventError{constants.DeployFinishEvent, finishErr})}
 		deployResponse.Error = err
-		deployResponse.StatusCode = http.StatusInternalServerError
+		deployResponse.StatusCode = deployer.StatusCode(err)
 	}
 
 	finishErr = c.EventManager.EmitEvent(DeployFinishedEvent{
@@ -235,19 +245,16 @@ func (c *PushController) emitDeployFinish(deployEventData *structs.DeployEventDa
 	})
 	if finishErr != nil {
 		fmt.Fprintln(response, finishErr)
-		if finishErr != nil {
-			fmt.Fprintln(response, finishErr)
-			err := bluegreen.FinishDeployError{Err: fmt.Errorf("%s: %s", deployResponse.Error, deployer.EventError{constants.DeployFinishEvent, finishErr})}
-			deployResponse.Error = err
-			deployResponse.StatusCode = http.StatusInternalServerError
-		}
+		err := bluegreen.FinishDeployError{Err: fmt.Errorf("%s: %s", deployResponse.Error, deployer.EventError{constants.DeployFinishEvent, finishErr})}
+		deployResponse.Error = err
+		deployResponse.StatusCode = deployer.StatusCode(err)
 	}
 }
 
 func (c PushController) emitDeploySuccessOrFailure(deployEventData *structs.DeployEventData, response io.ReadWriter, cf I.CFContext, auth I.Authorization, environment structs.Environment, deployResponse *I.DeployResponse, deploymentLogger I.DeploymentLogger) {
 	deployEvent := I.Event{Type: constants.DeploySuccessEvent, Data: deployEventData}
 	if deployResponse.Error != nil {
-		c.printErrors(response, &deployResponse.Error)
+		state.PrintErrors(response, c.ErrorFinder)
 
 		deployEvent.Type = constants.DeployFailureEvent
 		deployEvent.Error = deployResponse.Error
@@ -295,26 +302,3 @@ func (c PushController) emitDeploySuccessOrFailure(deployEventData *structs.Depl
 	}
 
 }
-
-func (c PushController) printErrors(response io.ReadWriter, err *error) {
-	tempBuffer := bytes.Buffer{}
-	tempBuffer.ReadFrom(response)
-	fmt.Fprint(response, tempBuffer.String())
-
-	errors := c.ErrorFinder.FindErrors(tempBuffer.String())
-	if len(errors) > 0 {
-		*err = errors[0]
-		for _, error := range errors {
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "*******************")
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "The following error was found in the above logs: "+error.Error())
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "Error: "+error.Details()[0])
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "Potential solution: "+error.Solution())
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "*******************")
-		}
-	}
-}
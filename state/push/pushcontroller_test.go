@@ -9,6 +9,7 @@ import (
 	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
 	"github.com/compozed/deployadactyl/controller/deployer/error_finder"
 	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/locker"
 	"github.com/compozed/deployadactyl/mocks"
 	"github.com/compozed/deployadactyl/randomizer"
 	"github.com/compozed/deployadactyl/state/push"
@@ -32,6 +33,7 @@ var _ = Describe("RunDeployment", func() {
 		pushManagerFactory *mocks.PushManagerFactory
 		eventManager       *mocks.EventManager
 		errorFinder        *mocks.ErrorFinder
+		appLocker          *mocks.Locker
 		controller         *push.PushController
 		deployment         I.Deployment
 		logBuffer          *Buffer
@@ -59,6 +61,7 @@ var _ = Describe("RunDeployment", func() {
 		pushManagerFactory = &mocks.PushManagerFactory{}
 
 		errorFinder = &mocks.ErrorFinder{}
+		appLocker = &mocks.Locker{}
 		controller = &push.PushController{
 			Deployer:           deployer,
 			SilentDeployer:     silentDeployer,
@@ -67,6 +70,7 @@ var _ = Describe("RunDeployment", func() {
 			EventManager:       eventManager,
 			Config:             config.Config{},
 			ErrorFinder:        errorFinder,
+			Locker:             appLocker,
 		}
 
 		environments := map[string]structs.Environment{}
@@ -83,6 +87,72 @@ var _ = Describe("RunDeployment", func() {
 		}
 
 	})
+	Context("when the application is not locked", func() {
+		It("locks the application while it runs", func() {
+			deployment := &I.Deployment{
+				Body: &[]byte{},
+				Type: I.DeploymentType{ZIP: true},
+				CFContext: I.CFContext{
+					Environment:  environment,
+					Organization: org,
+					Space:        space,
+					Application:  appName,
+				},
+				Authorization: I.Authorization{Username: "username", Password: "password"},
+			}
+
+			controller.RunDeployment(deployment, response)
+
+			Expect(appLocker.LockCall.Received.CFContext).To(Equal(deployment.CFContext))
+			Expect(appLocker.LockCall.Received.Holder.UUID).To(Equal(uuid))
+			Expect(appLocker.LockCall.Received.Holder.Type).To(Equal(constants.DeploymentTypePush))
+			Expect(appLocker.LockCall.Received.Holder.User).To(Equal("username"))
+			Expect(appLocker.LockCall.Unlocked).To(BeTrue())
+		})
+	})
+
+	Context("when it is a dry run", func() {
+		It("plans the deploy without locking the application or emitting events", func() {
+			deployment := &I.Deployment{
+				Body: &[]byte{},
+				Type: I.DeploymentType{ZIP: true},
+				CFContext: I.CFContext{
+					Environment: environment,
+					Application: appName,
+				},
+				DryRun: true,
+			}
+
+			controller.RunDeployment(deployment, response)
+
+			Expect(deployer.DeployCall.Called).To(Equal(1))
+			Expect(deployer.DeployCall.Received.DeploymentInfo.DryRun).To(BeTrue())
+			Expect(appLocker.LockCall.Received.Holder.UUID).To(BeEmpty())
+			Expect(eventManager.EmitCall.TimesCalled).To(BeZero())
+			Expect(eventManager.EmitEventCall.TimesCalled).To(BeZero())
+		})
+	})
+
+	Context("when the application is locked", func() {
+		It("returns http.StatusConflict without deploying", func() {
+			appLocker.LockCall.Returns.Error = locker.LockedError{Holder: I.LockHolder{UUID: "another uuid"}}
+			deployment := &I.Deployment{
+				Body: &[]byte{},
+				Type: I.DeploymentType{ZIP: true},
+				CFContext: I.CFContext{
+					Environment: environment,
+					Application: appName,
+				},
+			}
+
+			deployResponse := controller.RunDeployment(deployment, response)
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusConflict))
+			Expect(deployResponse.Error).To(MatchError(appLocker.LockCall.Returns.Error))
+			Expect(deployer.DeployCall.Called).To(Equal(0))
+		})
+	})
+
 	Context("when verbose deployer is called", func() {
 		It("deployer is provided correct authorization", func() {
 
@@ -593,6 +663,7 @@ var _ = Describe("RunDeployment", func() {
 
 						Eventually(deploymentResponse.Error).ShouldNot(BeNil())
 						Eventually(deploymentResponse.Error.Error()).Should(ContainSubstring("The following properties are missing: artifact_url"))
+						Expect(deploymentResponse.StatusCode).To(Equal(http.StatusBadRequest))
 					})
 				})
 				Context("if body is invalid", func() {
@@ -607,6 +678,7 @@ var _ = Describe("RunDeployment", func() {
 
 						Eventually(deploymentResponse.Error).ShouldNot(BeNil())
 						Eventually(deploymentResponse.Error.Error()).Should(ContainSubstring("EOF"))
+						Expect(deploymentResponse.StatusCode).To(Equal(http.StatusBadRequest))
 					})
 				})
 				Context("deploy.start event", func() {
@@ -657,6 +729,17 @@ var _ = Describe("RunDeployment", func() {
 
 							Expect(reflect.TypeOf(deploymentResponse.Error)).Should(Equal(reflect.TypeOf(D.EventError{})))
 						})
+
+						It("wraps the error in an initialization error value", func() {
+							deployment.CFContext.Environment = environment
+							deployment.Type.ZIP = true
+
+							eventManager.EmitEventCall.Returns.Error = []error{errors.New("a test error")}
+
+							deploymentResponse := controller.RunDeployment(&deployment, response)
+
+							Expect(deploymentResponse.Error.(D.EventError).Err).Should(BeAssignableToTypeOf(bluegreen.InitializationError{}))
+						})
 					})
 					It("passes populated deploymentInfo to DeployStartEvent event", func() {
 						deployment.CFContext.Environment = environment
@@ -825,6 +908,16 @@ var _ = Describe("RunDeployment", func() {
 
 							Expect(reflect.TypeOf(deploymentResponse.Error)).Should(Equal(reflect.TypeOf(bluegreen.FinishDeployError{})))
 						})
+						It("prints the error once", func() {
+							deployment.CFContext.Environment = environment
+							deployment.Type.ZIP = true
+
+							eventManager.EmitEventCall.Returns.Error = []error{nil, nil, errors.New("a test error")}
+
+							controller.RunDeployment(&deployment, response)
+
+							Expect(bytes.Count(response.Bytes(), []byte("a test error"))).To(Equal(1))
+						})
 					})
 				})
 				Context("deploy.success event", func() {
@@ -1065,11 +1158,16 @@ var _ = Describe("RunDeployment", func() {
 					retError := error_finder.CreateLogMatchedError("a description", []string{"some details"}, "a solution", "a code")
 					errorFinder.FindErrorsCall.Returns.Errors = []I.LogMatchedError{retError}
 
-					controller.RunDeployment(&deployment, response)
+					deployResponse := controller.RunDeployment(&deployment, response)
 					responseBytes, _ := ioutil.ReadAll(response)
 					Eventually(string(responseBytes)).Should(ContainSubstring("The following error was found in the above logs: a description"))
 					Eventually(string(responseBytes)).Should(ContainSubstring("Error: some details"))
 					Eventually(string(responseBytes)).Should(ContainSubstring("Potential solution: a solution"))
+
+					Expect(deployResponse.Error).ToNot(Equal(retError))
+					deploymentError, ok := deployResponse.Error.(I.DeploymentError)
+					Expect(ok).To(BeTrue())
+					Expect(deploymentError.Code()).To(Equal("EventError"))
 				})
 			})
 		})
@@ -2,10 +2,12 @@
 package push
 
 import (
+	"context"
 	"fmt"
 	"io"
 
 	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/executor"
 	I "github.com/compozed/deployadactyl/interfaces"
 	"github.com/compozed/deployadactyl/state"
 	S "github.com/compozed/deployadactyl/structs"
@@ -15,8 +17,21 @@ import (
 // not overide the existing application name.
 const TemporaryNameSuffix = "-new-build-"
 
+// VenerableNameSuffix is used when keeping the previous application as a stopped standby.
+const VenerableNameSuffix = "-venerable"
+
+// VenerableName returns the name of a kept application, where generation 1 is the most recent one.
+func VenerableName(appName string, generation uint16) string {
+	if generation <= 1 {
+		return appName + VenerableNameSuffix
+	}
+
+	return fmt.Sprintf("%s%s-%d", appName, VenerableNameSuffix, generation)
+}
+
 // Pusher has a courier used to push applications to Cloud Foundry.
-// It represents logging into a single foundation to perform operations.
+// It represents logging into a single foundation to perform operations. A canary push keeps the status
+// the original application had before traffic was shifted off it, so that Undo can scale it back.
 type Pusher struct {
 	Courier        I.Courier
 	DeploymentInfo S.DeploymentInfo
@@ -29,10 +44,15 @@ type Pusher struct {
 	Fetcher        I.Fetcher
 	CFContext      I.CFContext
 	Auth           I.Authorization
+	Client         I.Client
+	Context        context.Context
+	Verification   state.Verification
+
+	original *S.AppStatus
 }
 
 // Login will login to a Cloud Foundry instance.
-func (p Pusher) Initially() error {
+func (p *Pusher) Initially() error {
 	p.Log.Debugf(
 		`logging into cloud foundry with parameters:
 		foundation URL: %+v
@@ -49,8 +69,13 @@ func (p Pusher) Initially() error {
 		p.DeploymentInfo.Org,
 		p.DeploymentInfo.Space,
 		p.DeploymentInfo.SkipSSL,
+		p.DeploymentInfo.ClientCredentials,
 	)
 	p.Response.Write(output)
+	if timeoutErr, ok := err.(executor.TimeoutError); ok {
+		p.Log.Errorf("login to %s timed out", p.FoundationURL)
+		return timeoutErr
+	}
 	if err != nil {
 		p.Log.Errorf("could not login to %s", p.FoundationURL)
 		return state.LoginError{p.FoundationURL, output}
@@ -67,19 +92,20 @@ func (p Pusher) Initially() error {
 // It will map a load balanced domain if provided in the config.yml.
 //
 // Returns Cloud Foundry logs if there is an error.
-
-func (p Pusher) Verify() error {
-	return nil
-}
-
-func (p Pusher) Execute() error {
+func (p *Pusher) Execute() error {
 
 	var (
 		tempAppWithUUID = p.DeploymentInfo.AppName + TemporaryNameSuffix + p.DeploymentInfo.UUID
+		instances       = p.DeploymentInfo.Instances
+		canary          = p.isCanary()
 		err             error
 	)
 
-	err = p.pushApplication(tempAppWithUUID, p.AppPath)
+	if canary {
+		instances = canaryInstances(p.DeploymentInfo.Instances, p.Environment.Canary.Steps[0])
+	}
+
+	err = p.pushApplication(tempAppWithUUID, p.AppPath, instances)
 	if err != nil {
 		return err
 	}
@@ -118,6 +144,7 @@ func (p Pusher) Execute() error {
 		Courier:             p.Courier,
 		Manifest:            p.DeploymentInfo.Manifest,
 		HealthCheckEndpoint: p.DeploymentInfo.HealthCheckEndpoint,
+		HealthCheckTimeout:  p.healthCheckTimeout(),
 	}
 	err = p.EventManager.EmitEvent(event)
 	if err != nil {
@@ -125,19 +152,59 @@ func (p Pusher) Execute() error {
 	}
 	p.Log.Infof("emitted a %s event", event.Name())
 
+	if canary {
+		return p.shiftTraffic(tempAppWithUUID)
+	}
+
 	return nil
 }
 
-// FinishPush will delete the original application if it existed. It will always
-// rename the the newly pushed application to the appName.
-func (p Pusher) Success() error {
+// Verify checks that all instances of the newly pushed application are running and that its own
+// route on the domain responds, as the route check of the environment says. The route it was pushed
+// with is not checked, because it is shared with the original application.
+func (p *Pusher) Verify() error {
+	tempAppWithUUID := p.DeploymentInfo.AppName + TemporaryNameSuffix + p.DeploymentInfo.UUID
+
+	p.Log.Debugf("verifying %s", tempAppWithUUID)
+
+	_, err := p.Verification.WaitForAppState(p.Context, p.Courier, tempAppWithUUID, "started")
+	if err != nil {
+		p.Log.Errorf("could not verify %s: %s", tempAppWithUUID, err)
+		fmt.Fprintf(p.Response, "verify failed: %s\n", err)
+		return err
+	}
+
+	if p.Client != nil && !p.Environment.RouteCheck.Skip {
+		if p.DeploymentInfo.Domain == "" {
+			p.Log.Infof("no domain to check a route of %s on", tempAppWithUUID)
+		} else {
+			err = p.checkOwnRoute(tempAppWithUUID)
+			if err != nil {
+				fmt.Fprintf(p.Response, "verify failed: %s\n", err)
+				return err
+			}
+		}
+	}
+
+	p.Log.Infof("verified %s", tempAppWithUUID)
+
+	return nil
+}
+
+// FinishPush will delete the original application if it existed, or keep it stopped as appName-venerable
+// when the environment keeps venerable applications. It will always rename the the newly pushed application to the appName.
+func (p *Pusher) Success() error {
 	if p.Courier.Exists(p.DeploymentInfo.AppName) {
 		err := p.unMapLoadBalancedRoute()
 		if err != nil {
 			return err
 		}
 
-		err = p.deleteApplication(p.DeploymentInfo.AppName)
+		if p.Environment.KeepVenerable > 0 {
+			err = p.keepVenerableApplication()
+		} else {
+			err = p.deleteApplication(p.DeploymentInfo.AppName)
+		}
 		if err != nil {
 			return err
 		}
@@ -154,7 +221,7 @@ func (p Pusher) Success() error {
 // UndoPush is only called when a Push fails. If it is not the first deployment, UndoPush will
 // delete the temporary application that was pushed.
 // If is the first deployment, UndoPush will rename the failed push to have the appName.
-func (p Pusher) Undo() error {
+func (p *Pusher) Undo() error {
 
 	tempAppWithUUID := p.DeploymentInfo.AppName + TemporaryNameSuffix + p.DeploymentInfo.UUID
 	if !p.Environment.EnableRollback {
@@ -166,6 +233,13 @@ func (p Pusher) Undo() error {
 		if p.Courier.Exists(p.DeploymentInfo.AppName) {
 			p.Log.Errorf("rolling back deploy of %s", tempAppWithUUID)
 
+			if p.original != nil {
+				err := p.scaleApplication(p.DeploymentInfo.AppName, p.original.Instances)
+				if err != nil {
+					return err
+				}
+			}
+
 			err := p.deleteApplication(tempAppWithUUID)
 			if err != nil {
 				return err
@@ -184,11 +258,11 @@ func (p Pusher) Undo() error {
 }
 
 // CleanUp removes the temporary directory created by the Executor.
-func (p Pusher) Finally() error {
+func (p *Pusher) Finally() error {
 	return p.Courier.CleanUp()
 }
 
-func (p Pusher) pushApplication(appName, appPath string) error {
+func (p *Pusher) pushApplication(appName, appPath string, instances uint16) error {
 	p.Log.Debugf("pushing app %s to %s", appName, p.DeploymentInfo.Domain)
 	p.Log.Debugf("tempdir for app %s: %s", appName, appPath)
 
@@ -202,7 +276,7 @@ func (p Pusher) pushApplication(appName, appPath string) error {
 	defer func() { p.Response.Write(cloudFoundryLogs) }()
 	defer func() { p.Response.Write(pushOutput) }()
 
-	pushOutput, err = p.Courier.Push(appName, appPath, p.DeploymentInfo.AppName, p.DeploymentInfo.Instances)
+	pushOutput, err = p.Courier.Push(appName, appPath, p.DeploymentInfo.AppName, instances)
 	p.Log.Infof("output from Cloud Foundry: \n%s", pushOutput)
 	if err != nil {
 		defer func() { p.Log.Errorf("logs from %s: \n%s", appName, cloudFoundryLogs) }()
@@ -212,6 +286,10 @@ func (p Pusher) pushApplication(appName, appPath string) error {
 			return state.CloudFoundryGetLogsError{err, cloudFoundryLogsErr}
 		}
 
+		if timeoutErr, ok := err.(executor.TimeoutError); ok {
+			return timeoutErr
+		}
+
 		return state.PushError{}
 	}
 
@@ -220,7 +298,7 @@ func (p Pusher) pushApplication(appName, appPath string) error {
 	return nil
 }
 
-func (p Pusher) mapTempAppToLoadBalancedDomain(appName string) error {
+func (p *Pusher) mapTempAppToLoadBalancedDomain(appName string) error {
 	p.Log.Debugf("mapping route for %s to %s", p.DeploymentInfo.AppName, p.DeploymentInfo.Domain)
 
 	out, err := p.Courier.MapRoute(appName, p.DeploymentInfo.Domain, p.DeploymentInfo.AppName)
@@ -236,7 +314,7 @@ func (p Pusher) mapTempAppToLoadBalancedDomain(appName string) error {
 	return nil
 }
 
-func (p Pusher) unMapLoadBalancedRoute() error {
+func (p *Pusher) unMapLoadBalancedRoute() error {
 	if p.DeploymentInfo.Domain != "" {
 		p.Log.Debugf("unmapping route %s", p.DeploymentInfo.AppName)
 
@@ -252,7 +330,7 @@ func (p Pusher) unMapLoadBalancedRoute() error {
 	return nil
 }
 
-func (p Pusher) deleteApplication(appName string) error {
+func (p *Pusher) deleteApplication(appName string) error {
 	p.Log.Debugf("deleting %s", appName)
 
 	out, err := p.Courier.Delete(appName)
@@ -268,7 +346,62 @@ func (p Pusher) deleteApplication(appName string) error {
 	return nil
 }
 
-func (p Pusher) renameNewBuildToOriginalAppName() error {
+// keepVenerableApplication moves every kept generation one place down, deleting the oldest one,
+// and then stops the original application and renames it to appName-venerable.
+func (p *Pusher) keepVenerableApplication() error {
+	appName := p.DeploymentInfo.AppName
+	keep := p.Environment.KeepVenerable
+
+	oldest := VenerableName(appName, keep)
+	if p.Courier.Exists(oldest) {
+		err := p.deleteApplication(oldest)
+		if err != nil {
+			return err
+		}
+	}
+
+	for generation := keep - 1; generation >= 1; generation-- {
+		if p.Courier.Exists(VenerableName(appName, generation)) {
+			err := p.renameApplication(VenerableName(appName, generation), VenerableName(appName, generation+1))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	p.Log.Debugf("stopping %s", appName)
+
+	out, err := p.Courier.Stop(appName)
+	if err != nil {
+		p.Log.Errorf("could not stop %s", appName)
+		return state.StopError{ApplicationName: appName, Out: out}
+	}
+
+	if p.Environment.Canary.Enabled && p.original != nil {
+		err = p.scaleApplication(appName, p.original.Instances)
+		if err != nil {
+			return err
+		}
+	}
+
+	return p.renameApplication(appName, VenerableName(appName, 1))
+}
+
+func (p *Pusher) renameApplication(oldName, newName string) error {
+	p.Log.Debugf("renaming %s to %s", oldName, newName)
+
+	out, err := p.Courier.Rename(oldName, newName)
+	if err != nil {
+		p.Log.Errorf("could not rename %s to %s", oldName, newName)
+		return state.RenameError{ApplicationName: oldName, Out: out}
+	}
+
+	p.Log.Infof("renamed %s to %s", oldName, newName)
+
+	return nil
+}
+
+func (p *Pusher) renameNewBuildToOriginalAppName() error {
 	p.Log.Debugf("renaming %s to %s", p.DeploymentInfo.AppName+TemporaryNameSuffix+p.DeploymentInfo.UUID, p.DeploymentInfo.AppName)
 
 	out, err := p.Courier.Rename(p.DeploymentInfo.AppName+TemporaryNameSuffix+p.DeploymentInfo.UUID, p.DeploymentInfo.AppName)
@@ -1,11 +1,14 @@
 package push_test
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"math/rand"
 
 	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen/courier/executor"
 	"github.com/compozed/deployadactyl/mocks"
 	"github.com/compozed/deployadactyl/randomizer"
 	. "github.com/compozed/deployadactyl/state/push"
@@ -19,7 +22,13 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gbytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
+	"time"
 )
 
 var _ = Describe("Pusher", func() {
@@ -82,6 +91,7 @@ var _ = Describe("Pusher", func() {
 			Space:               randomSpace,
 			AppName:             randomAppName,
 			SkipSSL:             skipSSL,
+			ClientCredentials:   true,
 			Instances:           randomInstances,
 			Domain:              randomDomain,
 			UUID:                randomUUID,
@@ -118,6 +128,7 @@ var _ = Describe("Pusher", func() {
 				Expect(courier.LoginCall.Received.Org).To(Equal(randomOrg))
 				Expect(courier.LoginCall.Received.Space).To(Equal(randomSpace))
 				Expect(courier.LoginCall.Received.SkipSSL).To(Equal(skipSSL))
+				Expect(courier.LoginCall.Received.ClientCredentials).To(BeTrue())
 			})
 
 			It("writes the output of the courier to the response", func() {
@@ -157,6 +168,16 @@ var _ = Describe("Pusher", func() {
 				Eventually(logBuffer).Should(Say(fmt.Sprintf("could not login to %s", randomFoundationURL)))
 			})
 		})
+
+		Context("when login times out", func() {
+			It("returns the timeout error", func() {
+				timeoutErr := executor.TimeoutError{Command: "login", Timeout: time.Second}
+				courier.LoginCall.Returns.Error = timeoutErr
+
+				Expect(pusher.Initially()).To(MatchError(timeoutErr))
+				Eventually(logBuffer).Should(Say(fmt.Sprintf("login to %s timed out", randomFoundationURL)))
+			})
+		})
 	})
 
 	Describe("Execute", func() {
@@ -205,6 +226,14 @@ var _ = Describe("Pusher", func() {
 					Eventually(logBuffer).Should(Say("logs from"))
 				})
 
+				It("returns the timeout error when the push times out", func() {
+					fetcher.FetchCall.Returns.AppPath = randomAppPath
+					timeoutErr := executor.TimeoutError{Command: "push", Timeout: time.Minute}
+					courier.PushCall.Returns.Error = timeoutErr
+
+					Expect(pusher.Execute()).To(MatchError(timeoutErr))
+				})
+
 				Context("when the courier log call fails", func() {
 					It("returns an error", func() {
 						fetcher.FetchCall.Returns.AppPath = randomAppPath
@@ -392,6 +421,7 @@ var _ = Describe("Pusher", func() {
 				pusher.Response = response
 				pusher.AppPath = randomAppName
 				pusher.FoundationURL = randomFoundationURL
+				pusher.Environment.Timeouts.HealthCheck = 5
 
 				pusher.Execute()
 
@@ -401,6 +431,7 @@ var _ = Describe("Pusher", func() {
 				Expect(event.AppPath).To(Equal(pusher.AppPath))
 				Expect(event.FoundationURL).To(Equal(pusher.FoundationURL))
 				Expect(event.TempAppWithUUID).ToNot(BeNil())
+				Expect(event.HealthCheckTimeout).To(Equal(5 * time.Second))
 			})
 			Context("when Emit fails", func() {
 				It("returns an error", func() {
@@ -514,6 +545,64 @@ var _ = Describe("Pusher", func() {
 			})
 		})
 
+		Context("when the environment keeps venerable applications", func() {
+			BeforeEach(func() {
+				pusher.Environment.KeepVenerable = 3
+				courier.ExistsCall.Returns.Apps = map[string]bool{
+					randomAppName:                  true,
+					randomAppName + "-venerable":   true,
+					randomAppName + "-venerable-2": true,
+					randomAppName + "-venerable-3": true,
+				}
+			})
+
+			It("stops the original application instead of deleting it", func() {
+				Expect(pusher.Success()).To(Succeed())
+
+				Expect(courier.StopCall.Received.AppNames).To(Equal([]string{randomAppName}))
+				Expect(courier.DeleteCall.Received.AppNames).ToNot(ContainElement(randomAppName))
+			})
+
+			It("moves every generation down and deletes the oldest one", func() {
+				Expect(pusher.Success()).To(Succeed())
+
+				Expect(courier.DeleteCall.Received.AppNames).To(Equal([]string{randomAppName + "-venerable-3"}))
+				Expect(courier.RenameCall.Received.OldNames).To(Equal([]string{
+					randomAppName + "-venerable-2",
+					randomAppName + "-venerable",
+					randomAppName,
+					tempAppWithUUID,
+				}))
+				Expect(courier.RenameCall.Received.NewNames).To(Equal([]string{
+					randomAppName + "-venerable-3",
+					randomAppName + "-venerable-2",
+					randomAppName + "-venerable",
+					randomAppName,
+				}))
+			})
+
+			It("only renames the generations that exist", func() {
+				courier.ExistsCall.Returns.Apps = map[string]bool{randomAppName: true}
+
+				Expect(pusher.Success()).To(Succeed())
+
+				Expect(courier.DeleteCall.Received.AppNames).To(BeEmpty())
+				Expect(courier.RenameCall.Received.OldNames).To(Equal([]string{randomAppName, tempAppWithUUID}))
+			})
+
+			Context("when stopping the original application fails", func() {
+				It("returns an error", func() {
+					courier.StopCall.Returns.Output = []byte("stop output")
+					courier.StopCall.Returns.Error = errors.New("stop error")
+
+					err := pusher.Success()
+					Expect(err).To(MatchError(state.StopError{ApplicationName: randomAppName, Out: []byte("stop output")}))
+
+					Expect(courier.RenameCall.Received.NewNames).ToNot(ContainElement(randomAppName + "-venerable"))
+				})
+			})
+		})
+
 		Context("when the application does not exist", func() {
 			It("does not delete the non-existant original application", func() {
 				courier.ExistsCall.Returns.Bool = false
@@ -595,8 +684,166 @@ var _ = Describe("Pusher", func() {
 	})
 
 	Describe("Verify", func() {
-		It("returns nil", func() {
-			Expect(pusher.Verify()).To(BeNil())
+		var client *mocks.Client
+
+		BeforeEach(func() {
+			pusher.Verification = state.Verification{Attempts: 10}
+
+			client = &mocks.Client{}
+			client.GetCall.Returns.Response = http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(""))}
+			pusher.Client = client
+
+			courier.AppStatusCall.Returns.Status = S.AppStatus{
+				State:          "started",
+				Instances:      2,
+				Running:        2,
+				InstanceStates: []string{"running", "running"},
+				Routes:         []string{randomAppName + "." + randomDomain},
+			}
+		})
+
+		It("checks the instances and the own route of the new build", func() {
+			Expect(pusher.Verify()).To(Succeed())
+
+			Expect(courier.AppStatusCall.Received.AppName).To(Equal(tempAppWithUUID))
+			Expect(client.GetCall.Received.URL).To(Equal(fmt.Sprintf("https://%s.%s/%s", tempAppWithUUID, randomDomain, randomEndpoint)))
+
+			Eventually(logBuffer).Should(Say(fmt.Sprintf("verified %s", tempAppWithUUID)))
+		})
+
+		It("maps the own route of the new build only for as long as it is checked", func() {
+			Expect(pusher.Verify()).To(Succeed())
+
+			Expect(courier.MapRouteCall.Received.AppName).To(Equal([]string{tempAppWithUUID}))
+			Expect(courier.MapRouteCall.Received.Hostname).To(Equal([]string{tempAppWithUUID}))
+			Expect(courier.UnmapRouteCall.Received.Hostname).To(Equal(tempAppWithUUID))
+			Expect(courier.DeleteRouteCall.Received.Hostname).To(Equal(tempAppWithUUID))
+		})
+
+		It("returns an error when the own route cannot be mapped", func() {
+			courier.MapRouteCall.Returns.Output = [][]byte{[]byte("map route output")}
+			courier.MapRouteCall.Returns.Error = []error{errors.New("map route error")}
+
+			Expect(pusher.Verify()).To(MatchError(state.MapRouteError{Out: []byte("map route output")}))
+			Expect(client.GetCall.Received.URL).To(BeEmpty())
+		})
+
+		It("does not check a route when there is no domain", func() {
+			pusher.DeploymentInfo.Domain = ""
+
+			Expect(pusher.Verify()).To(Succeed())
+
+			Expect(courier.MapRouteCall.Received.AppName).To(BeEmpty())
+			Expect(client.GetCall.Received.URL).To(BeEmpty())
+		})
+
+		It("does not check the route when the route check of the environment is skipped", func() {
+			pusher.Environment.RouteCheck.Skip = true
+			client.GetCall.Returns.Response.StatusCode = http.StatusBadGateway
+
+			Expect(pusher.Verify()).To(Succeed())
+			Expect(client.GetCall.Received.URL).To(BeEmpty())
+		})
+
+		It("calls the path of the route check of the environment when there is no health check endpoint", func() {
+			pusher.DeploymentInfo.HealthCheckEndpoint = ""
+			pusher.Environment.RouteCheck.Path = "/ready"
+			client.GetCall.Returns.Response.StatusCode = http.StatusNotFound
+
+			err := pusher.Verify()
+
+			Expect(err).To(MatchError(state.RouteCheckError{
+				URL:        fmt.Sprintf("https://%s.%s/ready", tempAppWithUUID, randomDomain),
+				StatusCode: http.StatusNotFound,
+			}))
+		})
+
+		It("needs the status code of the route check of the environment when it is set", func() {
+			pusher.Environment.RouteCheck.StatusCode = http.StatusNoContent
+
+			Expect(pusher.Verify()).To(MatchError(state.RouteCheckError{
+				URL:        fmt.Sprintf("https://%s.%s/%s", tempAppWithUUID, randomDomain, randomEndpoint),
+				StatusCode: http.StatusOK,
+			}))
+
+			client.GetCall.Returns.Response.StatusCode = http.StatusNoContent
+
+			Expect(pusher.Verify()).To(Succeed())
+		})
+
+		It("returns an error when not all instances are running", func() {
+			courier.AppStatusCall.Returns.Status.Running = 1
+			courier.AppStatusCall.Returns.Status.InstanceStates = []string{"running", "crashed"}
+
+			err := pusher.Verify()
+
+			Expect(err).To(MatchError(state.InstancesNotRunningError{ApplicationName: tempAppWithUUID, Running: 1, Instances: 2}))
+			Expect(client.GetCall.Received.URL).To(BeEmpty())
+			Eventually(response).Should(Say("verify failed"))
+		})
+
+		It("returns an error when the health check endpoint does not return 200", func() {
+			client.GetCall.Returns.Response.StatusCode = http.StatusNotFound
+
+			err := pusher.Verify()
+
+			Expect(err).To(MatchError(state.RouteCheckError{
+				URL:        fmt.Sprintf("https://%s.%s/%s", tempAppWithUUID, randomDomain, randomEndpoint),
+				StatusCode: http.StatusNotFound,
+			}))
+		})
+
+		It("only needs the route to respond without a server error when there is no health check endpoint", func() {
+			pusher.DeploymentInfo.HealthCheckEndpoint = ""
+			client.GetCall.Returns.Response.StatusCode = http.StatusNotFound
+
+			Expect(pusher.Verify()).To(Succeed())
+
+			client.GetCall.Returns.Response.StatusCode = http.StatusBadGateway
+
+			Expect(pusher.Verify()).To(HaveOccurred())
+		})
+
+		It("returns an error when the route cannot be reached", func() {
+			client.GetCall.Returns.Error = errors.New("connection refused")
+
+			Expect(pusher.Verify()).To(MatchError(ContainSubstring("connection refused")))
+		})
+
+		Context("when the health check endpoint does not answer in time", func() {
+			var (
+				server  *httptest.Server
+				release chan struct{}
+			)
+
+			BeforeEach(func() {
+				release = make(chan struct{})
+				server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					<-release
+				}))
+
+				pusher.Client = &http.Client{Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+					DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+						return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+					},
+				}}
+				pusher.Environment.Timeouts.HealthCheck = 1
+			})
+
+			AfterEach(func() {
+				close(release)
+				server.Close()
+			})
+
+			It("returns a HealthCheckTimeoutError", func() {
+				err := pusher.Verify()
+
+				Expect(err).To(MatchError(state.HealthCheckTimeoutError{
+					URL:     fmt.Sprintf("https://%s.%s/%s", tempAppWithUUID, randomDomain, randomEndpoint),
+					Timeout: time.Second,
+				}))
+			})
 		})
 	})
 })
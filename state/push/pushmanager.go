@@ -1,6 +1,7 @@
 package push
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"github.com/compozed/deployadactyl/constants"
@@ -12,7 +13,6 @@ import (
 	S "github.com/compozed/deployadactyl/structs"
 	"io"
 	"net/http"
-	"regexp"
 )
 
 const deploymentOutput = `Deployment Parameters:
@@ -31,7 +31,7 @@ Thanks for using Deployadactyl! Please push down pull up on your lap bar and exi
 `
 
 type courierCreator interface {
-	CreateCourier() (I.Courier, error)
+	CreateCourier(ctx context.Context) (I.Courier, error)
 }
 
 type fileSystemCleaner interface {
@@ -49,6 +49,7 @@ type PushManager struct {
 	Auth                 I.Authorization
 	Environment          S.Environment
 	EnvironmentVariables map[string]string
+	Client               I.Client
 }
 
 func (a *PushManager) SetUp() error {
@@ -107,7 +108,7 @@ func (a *PushManager) SetUp() error {
 	err = a.EventManager.EmitEvent(event)
 	if err != nil {
 		a.Logger.Error(err)
-		err = &bluegreen.InitializationError{err}
+		err = bluegreen.InitializationError{Err: err}
 		return deployer.EventError{Type: event.Name(), Err: err}
 	}
 
@@ -150,7 +151,7 @@ func (a *PushManager) SetUp() error {
 	err = a.EventManager.EmitEvent(event)
 	if err != nil {
 		a.Logger.Error(err)
-		err = &bluegreen.InitializationError{err}
+		err = bluegreen.InitializationError{Err: err}
 		return deployer.EventError{Type: event.Name(), Err: err}
 	}
 
@@ -171,7 +172,7 @@ func (a PushManager) OnStart() error {
 	err := a.EventManager.Emit(I.Event{Type: constants.PushStartedEvent, Data: &a.DeployEventData})
 	if err != nil {
 		a.Logger.Error(err)
-		err = &bluegreen.InitializationError{err}
+		err = bluegreen.InitializationError{Err: err}
 		return deployer.EventError{Type: constants.PushStartedEvent, Err: err}
 	}
 
@@ -189,7 +190,7 @@ func (a PushManager) OnStart() error {
 	err = a.EventManager.EmitEvent(event)
 	if err != nil {
 		a.Logger.Error(err)
-		err = &bluegreen.InitializationError{err}
+		err = bluegreen.InitializationError{Err: err}
 		return deployer.EventError{Type: event.Name(), Err: err}
 	}
 	return nil
@@ -197,23 +198,8 @@ func (a PushManager) OnStart() error {
 
 func (a PushManager) OnFinish(env S.Environment, response io.ReadWriter, err error) I.DeployResponse {
 	if err != nil {
-		if !env.EnableRollback {
-			a.Logger.Errorf("EnableRollback %t, returning status %d and err %s", env.EnableRollback, http.StatusOK, err)
-			return I.DeployResponse{
-				StatusCode: http.StatusOK,
-				Error:      err,
-			}
-		}
-
-		if matched, _ := regexp.MatchString("login failed", err.Error()); matched {
-			return I.DeployResponse{
-				StatusCode: http.StatusBadRequest,
-				Error:      err,
-			}
-		}
-
 		return I.DeployResponse{
-			StatusCode: http.StatusInternalServerError,
+			StatusCode: deployer.StatusCode(err),
 			Error:      err,
 		}
 	}
@@ -227,17 +213,20 @@ func (a PushManager) CleanUp() {
 	a.FileSystemCleaner.RemoveAll(a.DeployEventData.DeploymentInfo.AppPath)
 }
 
-func (a PushManager) Create(environment S.Environment, response io.ReadWriter, foundationURL string) (I.Action, error) {
+func (a PushManager) Create(ctx context.Context, environment S.Environment, response io.ReadWriter, foundationURL string) (I.Action, error) {
 
-	courier, err := a.CourierCreator.CreateCourier()
+	courier, err := a.CourierCreator.CreateCourier(ctx)
 	if err != nil {
 		a.Logger.Error(err)
 		return &Pusher{}, state.CourierCreationError{Err: err}
 	}
 
+	deploymentInfo := *a.DeployEventData.DeploymentInfo
+	deploymentInfo.Username, deploymentInfo.Password, deploymentInfo.ClientCredentials = state.Login(deploymentInfo, environment, foundationURL)
+
 	p := &Pusher{
 		Courier:        courier,
-		DeploymentInfo: *a.DeployEventData.DeploymentInfo,
+		DeploymentInfo: deploymentInfo,
 		EventManager:   a.EventManager,
 		Response:       response,
 		Log:            a.Logger,
@@ -247,6 +236,9 @@ func (a PushManager) Create(environment S.Environment, response io.ReadWriter, f
 		Fetcher:        a.Fetcher,
 		CFContext:      a.CFContext,
 		Auth:           a.Auth,
+		Client:         a.Client,
+		Context:        ctx,
+		Verification:   state.DefaultVerification,
 	}
 
 	return p, nil
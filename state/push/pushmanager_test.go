@@ -5,9 +5,11 @@ import (
 	"encoding/base64"
 	"github.com/compozed/deployadactyl/constants"
 	"github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
 	"github.com/compozed/deployadactyl/interfaces"
 	"github.com/compozed/deployadactyl/mocks"
 	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/compozed/deployadactyl/state"
 	. "github.com/compozed/deployadactyl/state/push"
 	"github.com/compozed/deployadactyl/structs"
 	"github.com/go-errors/errors"
@@ -626,35 +628,36 @@ applications:
 	Describe("OnFinish", func() {
 		Context("when error occurs", func() {
 			Context("and EnableRollback is false", func() {
-				It("returns StatusOK", func() {
+				It("returns the status code of the error", func() {
 					env := structs.Environment{EnableRollback: false}
-					err := errors.New("a test error")
+					err := bluegreen.PushError{PushErrors: []error{state.PushError{}}}
 
 					resp := pusherCreator.OnFinish(env, response, err)
 
-					Expect(resp.StatusCode).To(Equal(http.StatusOK))
-				})
-				It("logs the failure", func() {
-					env := structs.Environment{EnableRollback: false}
-					err := errors.New("a test error")
-
-					pusherCreator.OnFinish(env, response, err)
-
-					logBytes, _ := ioutil.ReadAll(logBuffer)
-					Eventually(string(logBytes)).Should(ContainSubstring("EnableRollback false, returning status"))
+					Expect(resp.StatusCode).To(Equal(http.StatusUnprocessableEntity))
 				})
 			})
 			Context("and EnableRollback is true", func() {
 				Context("and error is a login failure", func() {
 					It("returns StatusBadRequest", func() {
 						env := structs.Environment{EnableRollback: true}
-						err := errors.New("the login failed")
+						err := bluegreen.LoginError{LoginErrors: []error{errors.New("bad credentials")}}
 
 						resp := pusherCreator.OnFinish(env, response, err)
 
 						Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
 					})
 				})
+				Context("and error is a foundation failure", func() {
+					It("returns StatusBadGateway", func() {
+						env := structs.Environment{EnableRollback: true}
+						err := bluegreen.PushError{PushErrors: []error{state.MapRouteError{Out: []byte("route is taken")}}}
+
+						resp := pusherCreator.OnFinish(env, response, err)
+
+						Expect(resp.StatusCode).To(Equal(http.StatusBadGateway))
+					})
+				})
 				It("returns StatusInternalServerError", func() {
 					env := structs.Environment{EnableRollback: true}
 					err := errors.New("a test error")
@@ -0,0 +1,27 @@
+package revert
+
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
+
+type VenerableNotKeptError struct {
+	Environment string
+}
+
+func (e VenerableNotKeptError) Error() string {
+	return fmt.Sprintf("cannot revert: environment %s does not keep venerable applications", e.Environment)
+}
+
+func (e VenerableNotKeptError) Code() string {
+	return "VenerableNotKeptError"
+}
+
+func (e VenerableNotKeptError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e VenerableNotKeptError) Retryable() bool {
+	return false
+}
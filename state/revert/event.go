@@ -0,0 +1,133 @@
+package revert
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/compozed/deployadactyl/eventmanager"
+	"github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/structs"
+	"github.com/go-errors/errors"
+)
+
+type eventBinding struct {
+	etype   reflect.Type
+	handler func(event interface{}) error
+}
+
+func (s eventBinding) Accepts(event interface{}) bool {
+	return reflect.TypeOf(event) == s.etype
+}
+
+func (b eventBinding) Emit(event interface{}) error {
+	return b.handler(event)
+}
+
+type RevertFailureEvent struct {
+	CFContext     interfaces.CFContext
+	Data          map[string]interface{}
+	Environment   structs.Environment
+	Authorization interfaces.Authorization
+	Response      io.ReadWriter
+	Error         error
+	Log           interfaces.DeploymentLogger
+}
+
+func (e RevertFailureEvent) Name() string {
+	return "RevertFailureEvent"
+}
+
+func NewRevertFailureEventBinding(handler func(event RevertFailureEvent) error) interfaces.Binding {
+	return eventBinding{
+		etype: reflect.TypeOf(RevertFailureEvent{}),
+		handler: func(gevent interface{}) error {
+			event, ok := gevent.(RevertFailureEvent)
+			if ok {
+				return handler(event)
+			} else {
+				return eventmanager.InvalidEventType{Err: errors.New("invalid event type")}
+			}
+		},
+	}
+}
+
+type RevertSuccessEvent struct {
+	CFContext     interfaces.CFContext
+	Data          map[string]interface{}
+	Environment   structs.Environment
+	Authorization interfaces.Authorization
+	Response      io.ReadWriter
+	Log           interfaces.DeploymentLogger
+}
+
+func (e RevertSuccessEvent) Name() string {
+	return "RevertSuccessEvent"
+}
+
+func NewRevertSuccessEventBinding(handler func(event RevertSuccessEvent) error) interfaces.Binding {
+	return eventBinding{
+		etype: reflect.TypeOf(RevertSuccessEvent{}),
+		handler: func(gevent interface{}) error {
+			event, ok := gevent.(RevertSuccessEvent)
+			if ok {
+				return handler(event)
+			} else {
+				return eventmanager.InvalidEventType{Err: errors.New("invalid event type")}
+			}
+		},
+	}
+}
+
+type RevertStartedEvent struct {
+	CFContext     interfaces.CFContext
+	Data          map[string]interface{}
+	Environment   structs.Environment
+	Authorization interfaces.Authorization
+	Response      io.ReadWriter
+	Log           interfaces.DeploymentLogger
+}
+
+func (e RevertStartedEvent) Name() string {
+	return "RevertStartedEvent"
+}
+
+func NewRevertStartedEventBinding(handler func(event RevertStartedEvent) error) interfaces.Binding {
+	return eventBinding{
+		etype: reflect.TypeOf(RevertStartedEvent{}),
+		handler: func(gevent interface{}) error {
+			event, ok := gevent.(RevertStartedEvent)
+			if ok {
+				return handler(event)
+			} else {
+				return eventmanager.InvalidEventType{Err: errors.New("invalid event type")}
+			}
+		},
+	}
+}
+
+type RevertFinishedEvent struct {
+	CFContext     interfaces.CFContext
+	Data          map[string]interface{}
+	Authorization interfaces.Authorization
+	Response      io.ReadWriter
+	Environment   structs.Environment
+	Log           interfaces.DeploymentLogger
+}
+
+func (e RevertFinishedEvent) Name() string {
+	return "RevertFinishedEvent"
+}
+
+func NewRevertFinishedEventBinding(handler func(event RevertFinishedEvent) error) interfaces.Binding {
+	return eventBinding{
+		etype: reflect.TypeOf(RevertFinishedEvent{}),
+		handler: func(gevent interface{}) error {
+			event, ok := gevent.(RevertFinishedEvent)
+			if ok {
+				return handler(event)
+			} else {
+				return eventmanager.InvalidEventType{Err: errors.New("invalid event type")}
+			}
+		},
+	}
+}
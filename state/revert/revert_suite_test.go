@@ -0,0 +1,13 @@
+package revert_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestRevert(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Revert Suite")
+}
@@ -0,0 +1,184 @@
+package revert
+
+import (
+	"bytes"
+	"fmt"
+
+	"io"
+
+	"github.com/compozed/deployadactyl/config"
+	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
+	"github.com/compozed/deployadactyl/structs"
+)
+
+type RevertControllerConstructor func(log I.DeploymentLogger, deployer I.Deployer, conf config.Config, eventManager I.EventManager, errorFinder I.ErrorFinder, revertManagerFactory I.RevertManagerFactory, locker I.Locker) I.RevertController
+
+func NewRevertController(l I.DeploymentLogger, d I.Deployer, c config.Config, em I.EventManager, ef I.ErrorFinder, rmf I.RevertManagerFactory, lk I.Locker) I.RevertController {
+	return &RevertController{
+		Deployer:             d,
+		Config:               c,
+		EventManager:         em,
+		ErrorFinder:          ef,
+		RevertManagerFactory: rmf,
+		Log:                  l,
+		Locker:               lk,
+	}
+}
+
+// RevertController swaps an application back to the venerable application kept by its last push on every foundation.
+type RevertController struct {
+	Log                  I.DeploymentLogger
+	RevertManagerFactory I.RevertManagerFactory
+	Deployer             I.Deployer
+	Config               config.Config
+	EventManager         I.EventManager
+	ErrorFinder          I.ErrorFinder
+	Locker               I.Locker
+}
+
+func (c *RevertController) RevertDeployment(deployment *I.Deployment, data map[string]interface{}, response *bytes.Buffer) (deployResponse I.DeployResponse) {
+	cf := deployment.CFContext
+	c.Log.Debugf("Preparing to revert %s with UUID %s", cf.Application, c.Log.UUID)
+
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	environment, err := c.resolveEnvironment(cf.Environment)
+	if err != nil {
+		fmt.Fprintln(response, err.Error())
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+	if environment.KeepVenerable == 0 {
+		err = VenerableNotKeptError{Environment: environment.Name}
+		fmt.Fprintln(response, err.Error())
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+
+	auth, err := state.ResolveAuthorization(deployment.Authorization, environment, c.Config, c.Log)
+	if err != nil {
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+
+	deploymentInfo := &structs.DeploymentInfo{
+		Org:               cf.Organization,
+		Space:             cf.Space,
+		AppName:           cf.Application,
+		Environment:       cf.Environment,
+		UUID:              c.Log.UUID,
+		Domain:            environment.Domain,
+		SkipSSL:           environment.SkipSSL,
+		ClientCredentials: environment.ClientCredentials,
+		ServiceAccount:    deployment.Authorization.Username == "" && deployment.Authorization.Password == "",
+		CustomParams:      environment.CustomParams,
+		Username:          auth.Username,
+		Password:          auth.Password,
+		Data:              data,
+	}
+
+	unlock, err := c.Locker.Lock(deployment.Context, cf, I.LockHolder{UUID: c.Log.UUID, Type: C.DeploymentTypeRevert, User: auth.Username})
+	if err != nil {
+		fmt.Fprintln(response, err.Error())
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+	defer unlock()
+
+	defer c.emitRevertFinish(response, c.Log, cf, &auth, &environment, data, &deployResponse)
+	defer c.emitRevertSuccessOrFailure(response, c.Log, cf, &auth, &environment, data, &deployResponse)
+
+	err = c.EventManager.EmitEvent(RevertStartedEvent{
+		CFContext:     cf,
+		Authorization: auth,
+		Environment:   environment,
+		Data:          data,
+		Response:      response,
+		Log:           c.Log,
+	})
+	if err != nil {
+		c.Log.Error(err)
+		err = bluegreen.InitializationError{Err: err}
+		err = deployer.EventError{Type: "RevertStartedEvent", Err: err}
+		return I.DeployResponse{
+			StatusCode:     deployer.StatusCode(err),
+			Error:          err,
+			DeploymentInfo: deploymentInfo,
+		}
+	}
+
+	deployEventData := structs.DeployEventData{Response: response, DeploymentInfo: deploymentInfo}
+
+	manager := c.RevertManagerFactory.RevertManager(c.Log, deployEventData)
+	deployResponse = *c.Deployer.Deploy(deployment.Context, deploymentInfo, environment, manager, response)
+	return deployResponse
+}
+
+func (c *RevertController) resolveEnvironment(env string) (structs.Environment, error) {
+	config := c.Config
+	environment, ok := config.Environments[env]
+	if !ok {
+		return structs.Environment{}, deployer.EnvironmentNotFoundError{Environment: env}
+	}
+	return environment, nil
+}
+
+func (c RevertController) emitRevertFinish(response io.ReadWriter, deploymentLogger I.DeploymentLogger, cfContext I.CFContext, auth *I.Authorization, environment *structs.Environment, data map[string]interface{}, deployResponse *I.DeployResponse) {
+	var event I.IEvent
+	event = RevertFinishedEvent{
+		CFContext:     cfContext,
+		Authorization: *auth,
+		Data:          data,
+		Environment:   *environment,
+		Log:           deploymentLogger,
+	}
+	deploymentLogger.Debugf("emitting a %s event", event.Name())
+	c.EventManager.EmitEvent(event)
+}
+
+func (c RevertController) emitRevertSuccessOrFailure(response io.ReadWriter, deploymentLogger I.DeploymentLogger, cfContext I.CFContext, auth *I.Authorization, environment *structs.Environment, data map[string]interface{}, deployResponse *I.DeployResponse) {
+	var event I.IEvent
+
+	if deployResponse.Error != nil {
+		state.PrintErrors(response, c.ErrorFinder)
+		event = RevertFailureEvent{
+			CFContext:     cfContext,
+			Authorization: *auth,
+			Environment:   *environment,
+			Data:          data,
+			Response:      response,
+			Error:         deployResponse.Error,
+			Log:           deploymentLogger,
+		}
+
+	} else {
+		event = RevertSuccessEvent{
+			CFContext:     cfContext,
+			Authorization: *auth,
+			Environment:   *environment,
+			Data:          data,
+			Response:      response,
+			Log:           deploymentLogger,
+		}
+	}
+	deploymentLogger.Debugf("emitting a %s event", event.Name())
+	eventErr := c.EventManager.EmitEvent(event)
+	if eventErr != nil {
+		deploymentLogger.Errorf("an error occurred when emitting a %s event: %s", event.Name(), eventErr)
+		fmt.Fprintln(response, eventErr)
+	}
+}
@@ -0,0 +1,160 @@
+package revert_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/compozed/deployadactyl/config"
+	C "github.com/compozed/deployadactyl/constants"
+	D "github.com/compozed/deployadactyl/controller/deployer"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/locker"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	. "github.com/compozed/deployadactyl/state/revert"
+	"github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+var _ = Describe("RevertDeployment", func() {
+	var (
+		revertManagerFactory *mocks.RevertManagerFactory
+		eventManager         *mocks.EventManager
+		deployer             *mocks.Deployer
+		appLocker            *mocks.Locker
+		controller           *RevertController
+		deployment           *I.Deployment
+		response             *bytes.Buffer
+		logBuffer            *Buffer
+
+		environment string
+		uuid        string
+	)
+
+	BeforeEach(func() {
+		logBuffer = NewBuffer()
+		response = &bytes.Buffer{}
+		environment = "environment-" + randomizer.StringRunes(10)
+		uuid = "uuid-" + randomizer.StringRunes(10)
+
+		revertManagerFactory = &mocks.RevertManagerFactory{}
+		eventManager = &mocks.EventManager{}
+		deployer = &mocks.Deployer{}
+		appLocker = &mocks.Locker{}
+
+		controller = &RevertController{
+			Log:                  I.DeploymentLogger{Log: I.DefaultLogger(logBuffer, logging.DEBUG, "revertcontroller_test"), UUID: uuid},
+			Deployer:             deployer,
+			RevertManagerFactory: revertManagerFactory,
+			EventManager:         eventManager,
+			ErrorFinder:          &mocks.ErrorFinder{},
+			Locker:               appLocker,
+			Config: config.Config{
+				Environments: map[string]structs.Environment{
+					environment: {Name: environment, Domain: "example.com", KeepVenerable: 2},
+				},
+			},
+		}
+
+		deployment = &I.Deployment{
+			CFContext: I.CFContext{
+				Environment:  environment,
+				Organization: "myOrg",
+				Space:        "mySpace",
+				Application:  "myApp",
+			},
+		}
+	})
+
+	It("reverts the application on every foundation", func() {
+		deployer.DeployCall.Returns.StatusCode = http.StatusOK
+
+		deployResponse := controller.RevertDeployment(deployment, nil, response)
+
+		Expect(deployResponse.StatusCode).To(Equal(http.StatusOK))
+		Expect(deployer.DeployCall.Called).To(Equal(1))
+
+		deploymentInfo := revertManagerFactory.RevertManagerCall.Received.DeployEventData.DeploymentInfo
+		Expect(deploymentInfo.AppName).To(Equal("myApp"))
+		Expect(deploymentInfo.Domain).To(Equal("example.com"))
+		Expect(deploymentInfo.UUID).To(Equal(uuid))
+		Expect(logBuffer).To(Say("Preparing to revert myApp with UUID %s", uuid))
+	})
+
+	It("emits a started, a success and a finished event", func() {
+		controller.RevertDeployment(deployment, nil, response)
+
+		Expect(eventManager.EmitEventCall.Received.Events).To(HaveLen(3))
+		Expect(eventManager.EmitEventCall.Received.Events[0]).To(BeAssignableToTypeOf(RevertStartedEvent{}))
+		Expect(eventManager.EmitEventCall.Received.Events[1]).To(BeAssignableToTypeOf(RevertSuccessEvent{}))
+		Expect(eventManager.EmitEventCall.Received.Events[2]).To(BeAssignableToTypeOf(RevertFinishedEvent{}))
+	})
+
+	Context("when the revert fails", func() {
+		It("emits a failure event with the error", func() {
+			deployer.DeployCall.Returns.StatusCode = http.StatusInternalServerError
+			deployer.DeployCall.Returns.Error = errors.New("revert failed")
+
+			deployResponse := controller.RevertDeployment(deployment, nil, response)
+
+			Expect(deployResponse.Error).To(MatchError("revert failed"))
+			failure, ok := eventManager.EmitEventCall.Received.Events[1].(RevertFailureEvent)
+			Expect(ok).To(BeTrue())
+			Expect(failure.Error).To(MatchError("revert failed"))
+		})
+	})
+
+	Context("when the environment does not keep venerable applications", func() {
+		It("returns http.StatusBadRequest without reverting", func() {
+			controller.Config.Environments[environment] = structs.Environment{Name: environment}
+
+			deployResponse := controller.RevertDeployment(deployment, nil, response)
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(deployResponse.Error).To(MatchError(VenerableNotKeptError{Environment: environment}))
+			Expect(deployer.DeployCall.Called).To(Equal(0))
+		})
+	})
+
+	Context("when the application is not locked", func() {
+		It("locks the application while it runs", func() {
+			deployment.Authorization = I.Authorization{Username: "username", Password: "password"}
+
+			controller.RevertDeployment(deployment, nil, response)
+
+			Expect(appLocker.LockCall.Received.CFContext).To(Equal(deployment.CFContext))
+			Expect(appLocker.LockCall.Received.Holder.UUID).To(Equal(uuid))
+			Expect(appLocker.LockCall.Received.Holder.Type).To(Equal(C.DeploymentTypeRevert))
+			Expect(appLocker.LockCall.Received.Holder.User).To(Equal("username"))
+			Expect(appLocker.LockCall.Unlocked).To(BeTrue())
+		})
+	})
+
+	Context("when the application is locked", func() {
+		It("returns http.StatusConflict without running", func() {
+			appLocker.LockCall.Returns.Error = locker.LockedError{Holder: I.LockHolder{UUID: "another uuid"}}
+
+			deployResponse := controller.RevertDeployment(deployment, nil, response)
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusConflict))
+			Expect(deployResponse.Error).To(MatchError(appLocker.LockCall.Returns.Error))
+			Expect(deployer.DeployCall.Called).To(Equal(0))
+			Expect(eventManager.EmitEventCall.Received.Events).To(BeEmpty())
+		})
+	})
+
+	Context("when the environment is unknown", func() {
+		It("returns an error", func() {
+			deployment.CFContext.Environment = "unknown"
+
+			deployResponse := controller.RevertDeployment(deployment, nil, response)
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(deployResponse.Error).To(MatchError(D.EnvironmentNotFoundError{Environment: "unknown"}))
+		})
+	})
+})
@@ -0,0 +1,175 @@
+// Package revert swaps an application back to the venerable application that was kept by its last push.
+package revert
+
+import (
+	"io"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
+	"github.com/compozed/deployadactyl/state/push"
+)
+
+// Reverter moves the traffic of a single foundation from the application to appName-venerable.
+// Routes are swapped in Execute and the applications are only renamed in Success, once every foundation has been swapped.
+type Reverter struct {
+	Courier       I.Courier
+	CFContext     I.CFContext
+	Authorization I.Authorization
+	EventManager  I.EventManager
+	Response      io.ReadWriter
+	Log           I.DeploymentLogger
+	FoundationURL string
+	AppName       string
+	Domain        string
+	UUID          string
+	Data          map[string]interface{}
+}
+
+func (r Reverter) Verify() error {
+	return nil
+}
+
+// Login will login to a Cloud Foundry instance.
+func (r Reverter) Initially() error {
+	r.Log.Debugf(
+		`logging into cloud foundry with parameters:
+		foundation URL: %+v
+		username: %+v
+		org: %+v
+		space: %+v`,
+		r.FoundationURL, r.Authorization.Username, r.CFContext.Organization, r.CFContext.Space,
+	)
+
+	output, err := r.Courier.Login(
+		r.FoundationURL,
+		r.Authorization.Username,
+		r.Authorization.Password,
+		r.CFContext.Organization,
+		r.CFContext.Space,
+		r.CFContext.SkipSSL,
+		r.CFContext.ClientCredentials,
+	)
+	r.Response.Write(output)
+	if err != nil {
+		r.Log.Errorf("could not login to %s", r.FoundationURL)
+		return state.LoginError{FoundationURL: r.FoundationURL, Out: output}
+	}
+
+	r.Log.Infof("logged into cloud foundry %s", r.FoundationURL)
+
+	return nil
+}
+
+// Execute starts appName-venerable and moves the load balanced route onto it before stopping the application.
+func (r Reverter) Execute() error {
+	venerable := push.VenerableName(r.AppName, 1)
+
+	if !r.Courier.Exists(venerable) {
+		r.Log.Errorf("failed to revert app on foundation %s: %s doesn't exist", r.FoundationURL, venerable)
+		return state.ExistsError{ApplicationName: venerable}
+	}
+
+	return r.swap(r.AppName, venerable)
+}
+
+// Undo moves the traffic back onto the application when the revert failed on any foundation.
+func (r Reverter) Undo() error {
+	venerable := push.VenerableName(r.AppName, 1)
+
+	if !r.Courier.Exists(r.AppName) {
+		return state.ExistsError{ApplicationName: r.AppName}
+	}
+
+	return r.swap(venerable, r.AppName)
+}
+
+// Success renames appName-venerable to appName. The application that was reverted from becomes the new appName-venerable,
+// so that a revert can itself be reverted.
+func (r Reverter) Success() error {
+	var (
+		venerable = push.VenerableName(r.AppName, 1)
+		temporary = r.AppName + push.TemporaryNameSuffix + r.UUID
+		exists    = r.Courier.Exists(r.AppName)
+	)
+
+	if exists {
+		err := r.rename(r.AppName, temporary)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := r.rename(venerable, r.AppName)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return r.rename(temporary, venerable)
+	}
+
+	return nil
+}
+
+// CleanUp removes the temporary directory created by the Executor.
+func (r Reverter) Finally() error {
+	return r.Courier.CleanUp()
+}
+
+func (r Reverter) swap(from, to string) error {
+	r.Log.Infof("starting app %s", to)
+
+	output, err := r.Courier.Start(to)
+	r.Response.Write(output)
+	if err != nil {
+		r.Log.Errorf("could not start %s on foundation %s", to, r.FoundationURL)
+		return state.StartError{ApplicationName: to, Out: output}
+	}
+
+	if r.Domain != "" {
+		output, err = r.Courier.MapRoute(to, r.Domain, r.AppName)
+		if err != nil {
+			r.Log.Errorf("could not map %s to %s", r.AppName, to)
+			return state.MapRouteError{Out: output}
+		}
+		r.Log.Infof("mapped route %s.%s to %s", r.AppName, r.Domain, to)
+	}
+
+	if !r.Courier.Exists(from) {
+		return nil
+	}
+
+	if r.Domain != "" {
+		output, err = r.Courier.UnmapRoute(from, r.Domain, r.AppName)
+		if err != nil {
+			r.Log.Errorf("could not unmap %s", from)
+			return state.UnmapRouteError{ApplicationName: from, Out: output}
+		}
+		r.Log.Infof("unmapped route %s.%s from %s", r.AppName, r.Domain, from)
+	}
+
+	r.Log.Infof("stopping app %s", from)
+
+	output, err = r.Courier.Stop(from)
+	r.Response.Write(output)
+	if err != nil {
+		r.Log.Errorf("could not stop %s on foundation %s", from, r.FoundationURL)
+		return state.StopError{ApplicationName: from, Out: output}
+	}
+
+	return nil
+}
+
+func (r Reverter) rename(oldName, newName string) error {
+	r.Log.Debugf("renaming %s to %s", oldName, newName)
+
+	output, err := r.Courier.Rename(oldName, newName)
+	if err != nil {
+		r.Log.Errorf("could not rename %s to %s", oldName, newName)
+		return state.RenameError{ApplicationName: oldName, Out: output}
+	}
+
+	r.Log.Infof("renamed %s to %s", oldName, newName)
+
+	return nil
+}
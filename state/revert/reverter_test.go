@@ -0,0 +1,145 @@
+package revert_test
+
+import (
+	"errors"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/compozed/deployadactyl/state"
+	. "github.com/compozed/deployadactyl/state/revert"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+var _ = Describe("Reverter", func() {
+	var (
+		reverter  Reverter
+		courier   *mocks.Courier
+		logBuffer *Buffer
+		response  *Buffer
+
+		appName   string
+		venerable string
+		domain    string
+		uuid      string
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+		logBuffer = NewBuffer()
+		response = NewBuffer()
+
+		appName = "appName-" + randomizer.StringRunes(10)
+		venerable = appName + "-venerable"
+		domain = "domain-" + randomizer.StringRunes(10)
+		uuid = "uuid-" + randomizer.StringRunes(10)
+
+		courier.ExistsCall.Returns.Apps = map[string]bool{appName: true, venerable: true}
+
+		reverter = Reverter{
+			Courier:       courier,
+			CFContext:     I.CFContext{Organization: "org", Space: "space"},
+			Authorization: I.Authorization{Username: "username", Password: "password"},
+			Response:      response,
+			Log:           I.DeploymentLogger{Log: I.DefaultLogger(logBuffer, logging.DEBUG, "reverter_test"), UUID: uuid},
+			FoundationURL: "foundationURL",
+			AppName:       appName,
+			Domain:        domain,
+			UUID:          uuid,
+		}
+	})
+
+	Describe("Initially", func() {
+		It("logs into the foundation", func() {
+			courier.LoginCall.Returns.Output = []byte("logged in")
+
+			Expect(reverter.Initially()).To(Succeed())
+
+			Expect(courier.LoginCall.Received.FoundationURL).To(Equal("foundationURL"))
+			Expect(courier.LoginCall.Received.Username).To(Equal("username"))
+			Expect(courier.LoginCall.Received.Org).To(Equal("org"))
+			Expect(response).To(Say("logged in"))
+		})
+
+		It("returns an error when login fails", func() {
+			courier.LoginCall.Returns.Output = []byte("login output")
+			courier.LoginCall.Returns.Error = errors.New("login error")
+
+			Expect(reverter.Initially()).To(MatchError(state.LoginError{FoundationURL: "foundationURL", Out: []byte("login output")}))
+		})
+	})
+
+	Describe("Execute", func() {
+		It("starts the venerable app and moves the route onto it", func() {
+			Expect(reverter.Execute()).To(Succeed())
+
+			Expect(courier.StartCall.Received.AppNames).To(Equal([]string{venerable}))
+			Expect(courier.MapRouteCall.Received.AppName).To(Equal([]string{venerable}))
+			Expect(courier.MapRouteCall.Received.Domain).To(Equal([]string{domain}))
+			Expect(courier.MapRouteCall.Received.Hostname).To(Equal([]string{appName}))
+			Expect(courier.UnmapRouteCall.Received.AppName).To(Equal(appName))
+			Expect(courier.StopCall.Received.AppNames).To(Equal([]string{appName}))
+		})
+
+		It("does not map any route when there is no domain", func() {
+			reverter.Domain = ""
+
+			Expect(reverter.Execute()).To(Succeed())
+
+			Expect(courier.MapRouteCall.Received.AppName).To(BeEmpty())
+			Expect(courier.UnmapRouteCall.Received.AppName).To(BeEmpty())
+			Expect(courier.StopCall.Received.AppNames).To(Equal([]string{appName}))
+		})
+
+		Context("when there is no venerable app", func() {
+			It("returns an error", func() {
+				courier.ExistsCall.Returns.Apps = map[string]bool{appName: true}
+
+				Expect(reverter.Execute()).To(MatchError(state.ExistsError{ApplicationName: venerable}))
+				Expect(courier.StartCall.Received.AppNames).To(BeEmpty())
+			})
+		})
+
+		Context("when starting the venerable app fails", func() {
+			It("returns an error and leaves the app running", func() {
+				courier.StartCall.Returns.Output = []byte("start output")
+				courier.StartCall.Returns.Error = errors.New("start error")
+
+				Expect(reverter.Execute()).To(MatchError(state.StartError{ApplicationName: venerable, Out: []byte("start output")}))
+				Expect(courier.StopCall.Received.AppNames).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Undo", func() {
+		It("moves the route back onto the app and stops the venerable app", func() {
+			Expect(reverter.Undo()).To(Succeed())
+
+			Expect(courier.StartCall.Received.AppNames).To(Equal([]string{appName}))
+			Expect(courier.MapRouteCall.Received.AppName).To(Equal([]string{appName}))
+			Expect(courier.UnmapRouteCall.Received.AppName).To(Equal(venerable))
+			Expect(courier.StopCall.Received.AppNames).To(Equal([]string{venerable}))
+		})
+	})
+
+	Describe("Success", func() {
+		It("swaps the names of the app and the venerable app", func() {
+			Expect(reverter.Success()).To(Succeed())
+
+			temporary := appName + "-new-build-" + uuid
+			Expect(courier.RenameCall.Received.OldNames).To(Equal([]string{appName, venerable, temporary}))
+			Expect(courier.RenameCall.Received.NewNames).To(Equal([]string{temporary, appName, venerable}))
+		})
+
+		It("returns an error when renaming fails", func() {
+			courier.RenameCall.Returns.Output = []byte("rename output")
+			courier.RenameCall.Returns.Error = errors.New("rename error")
+
+			Expect(reverter.Success()).To(MatchError(state.RenameError{ApplicationName: appName, Out: []byte("rename output")}))
+			Expect(logBuffer).To(Say("could not rename"))
+		})
+	})
+})
@@ -0,0 +1,106 @@
+package revert
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+const successfulRevert = `Your revert was successful! (^_^)b
+
+`
+
+type courierCreator interface {
+	CreateCourier(ctx context.Context) (I.Courier, error)
+}
+
+type RevertManager struct {
+	CourierCreator  courierCreator
+	EventManager    I.EventManager
+	Logger          I.DeploymentLogger
+	DeployEventData S.DeployEventData
+}
+
+func (a RevertManager) SetUp() error {
+	return nil
+}
+
+func (a RevertManager) OnStart() error {
+	return nil
+}
+
+func (a RevertManager) OnFinish(env S.Environment, response io.ReadWriter, err error) I.DeployResponse {
+	if err != nil {
+		fmt.Fprintf(response, "\nYour application was not successfully reverted on all foundations: %s\n\n", err.Error())
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+
+	a.Logger.Infof("successfully reverted application %s", a.DeployEventData.DeploymentInfo.AppName)
+	fmt.Fprintf(response, "\n%s", successfulRevert)
+
+	return I.DeployResponse{StatusCode: http.StatusOK}
+}
+
+func (a RevertManager) CleanUp() {}
+
+func (a RevertManager) Create(ctx context.Context, environment S.Environment, response io.ReadWriter, foundationURL string) (I.Action, error) {
+	courier, err := a.CourierCreator.CreateCourier(ctx)
+	if err != nil {
+		a.Logger.Error(err)
+		return &Reverter{}, state.CourierCreationError{Err: err}
+	}
+
+	username, password, clientCredentials := state.Login(*a.DeployEventData.DeploymentInfo, environment, foundationURL)
+
+	r := &Reverter{
+		Courier: courier,
+		CFContext: I.CFContext{
+			Environment:       environment.Name,
+			Organization:      a.DeployEventData.DeploymentInfo.Org,
+			Space:             a.DeployEventData.DeploymentInfo.Space,
+			Application:       a.DeployEventData.DeploymentInfo.AppName,
+			SkipSSL:           a.DeployEventData.DeploymentInfo.SkipSSL,
+			ClientCredentials: clientCredentials,
+		},
+		Authorization: I.Authorization{
+			Username: username,
+			Password: password,
+		},
+		EventManager:  a.EventManager,
+		Response:      response,
+		Log:           a.Logger,
+		FoundationURL: foundationURL,
+		AppName:       a.DeployEventData.DeploymentInfo.AppName,
+		Domain:        a.DeployEventData.DeploymentInfo.Domain,
+		UUID:          a.DeployEventData.DeploymentInfo.UUID,
+		Data:          a.DeployEventData.DeploymentInfo.Data,
+	}
+
+	return r, nil
+}
+
+func (a RevertManager) InitiallyError(initiallyErrors []error) error {
+	return bluegreen.LoginError{LoginErrors: initiallyErrors}
+}
+
+func (a RevertManager) ExecuteError(executeErrors []error) error {
+	return bluegreen.RevertError{Errors: executeErrors}
+}
+
+func (a RevertManager) UndoError(executeErrors, undoErrors []error) error {
+	return bluegreen.RollbackRevertError{RevertErrors: executeErrors, RollbackErrors: undoErrors}
+}
+
+func (a RevertManager) SuccessError(successErrors []error) error {
+	return bluegreen.FinishRevertError{FinishRevertErrors: successErrors}
+}
@@ -0,0 +1,91 @@
+package revert_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/state"
+	. "github.com/compozed/deployadactyl/state/revert"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+type courierCreator struct {
+	courier I.Courier
+	err     error
+}
+
+func (c courierCreator) CreateCourier(ctx context.Context) (I.Courier, error) {
+	return c.courier, c.err
+}
+
+var _ = Describe("RevertManager", func() {
+	var (
+		courier  *mocks.Courier
+		manager  RevertManager
+		response *Buffer
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+		response = NewBuffer()
+
+		manager = RevertManager{
+			CourierCreator: courierCreator{courier: courier},
+			Logger:         I.DeploymentLogger{Log: I.DefaultLogger(NewBuffer(), logging.DEBUG, "revertmanager_test"), UUID: "uuid"},
+			DeployEventData: S.DeployEventData{
+				DeploymentInfo: &S.DeploymentInfo{
+					Org:     "org",
+					Space:   "space",
+					AppName: "appName",
+					Domain:  "example.com",
+					UUID:    "uuid",
+				},
+			},
+		}
+	})
+
+	Describe("Create", func() {
+		It("creates a Reverter for the foundation", func() {
+			action, err := manager.Create(context.Background(), S.Environment{Name: "environment"}, response, "foundationURL")
+			Expect(err).ToNot(HaveOccurred())
+
+			reverter := action.(*Reverter)
+			Expect(reverter.Courier).To(Equal(courier))
+			Expect(reverter.FoundationURL).To(Equal("foundationURL"))
+			Expect(reverter.AppName).To(Equal("appName"))
+			Expect(reverter.Domain).To(Equal("example.com"))
+			Expect(reverter.UUID).To(Equal("uuid"))
+			Expect(reverter.CFContext.Environment).To(Equal("environment"))
+		})
+
+		It("returns an error when the courier cannot be created", func() {
+			manager.CourierCreator = courierCreator{err: errors.New("courier error")}
+
+			_, err := manager.Create(context.Background(), S.Environment{}, response, "foundationURL")
+			Expect(err).To(MatchError(state.CourierCreationError{Err: errors.New("courier error")}))
+		})
+	})
+
+	Describe("OnFinish", func() {
+		It("returns http.StatusOK when the revert succeeded", func() {
+			deployResponse := manager.OnFinish(S.Environment{}, response, nil)
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusOK))
+			Expect(response).To(Say("Your revert was successful!"))
+		})
+
+		It("returns http.StatusInternalServerError when the revert failed", func() {
+			deployResponse := manager.OnFinish(S.Environment{}, response, errors.New("revert failed"))
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(response).To(Say("not successfully reverted"))
+		})
+	})
+})
@@ -0,0 +1,27 @@
+package scale
+
+import (
+	"fmt"
+
+	C "github.com/compozed/deployadactyl/constants"
+)
+
+type InvalidScaleDataError struct {
+	Data map[string]interface{}
+}
+
+func (e InvalidScaleDataError) Error() string {
+	return fmt.Sprintf("cannot scale: data needs a positive whole number of instances or a memory limit such as 512M or 1G: %v", e.Data)
+}
+
+func (e InvalidScaleDataError) Code() string {
+	return "InvalidScaleDataError"
+}
+
+func (e InvalidScaleDataError) Category() string {
+	return C.ErrorCategoryUser
+}
+
+func (e InvalidScaleDataError) Retryable() bool {
+	return false
+}
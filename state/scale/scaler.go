@@ -0,0 +1,178 @@
+// Package scale changes the number of instances or the memory limit of an application on every foundation.
+package scale
+
+import (
+	"math"
+	"regexp"
+
+	C "github.com/compozed/deployadactyl/constants"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
+	"github.com/compozed/deployadactyl/state/command"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+var memoryPattern = regexp.MustCompile(`^(?i)\d+(M|G|MB|GB)$`)
+
+// Command scales an application to the instances or the memory given in the data of the request.
+var Command = command.Command{
+	Type: C.DeploymentTypeScale,
+	Past: "scaled",
+	Validate: func(data map[string]interface{}) error {
+		_, _, err := ParseScaleData(data)
+		return err
+	},
+	NewAction: func(foundation command.Foundation, deploymentInfo S.DeploymentInfo) (I.Action, error) {
+		instances, memory, err := ParseScaleData(deploymentInfo.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Scaler{Foundation: foundation, Instances: instances, Memory: memory}, nil
+	},
+	ExecuteError: func(executeErrors []error) error {
+		return bluegreen.ScaleError{Errors: executeErrors}
+	},
+	UndoError: func(executeErrors, undoErrors []error) error {
+		return bluegreen.RollbackScaleError{ScaleErrors: executeErrors, RollbackErrors: undoErrors}
+	},
+	SuccessError: func(successErrors []error) error {
+		return bluegreen.FinishScaleError{FinishScaleErrors: successErrors}
+	},
+}
+
+// Scaler scales an application on a single foundation. It keeps the status the application had
+// before it was scaled, so that Undo can scale it back.
+type Scaler struct {
+	command.Foundation
+
+	Instances uint16
+	Memory    string
+
+	previous *S.AppStatus
+}
+
+// Execute scales the instances and then the memory of the application, when they are given.
+func (s *Scaler) Execute() error {
+	if !s.Courier.Exists(s.AppName) {
+		s.Log.Errorf("failed to scale app on foundation %s: application doesn't exist", s.FoundationURL)
+		return state.ExistsError{ApplicationName: s.AppName}
+	}
+
+	status, err := s.Courier.AppStatus(s.AppName)
+	if err != nil {
+		s.Log.Errorf("failed to scale app on foundation %s: %s", s.FoundationURL, err)
+		return err
+	}
+	s.previous = &status
+
+	return s.scale(s.Instances, s.Memory)
+}
+
+// Verify checks that a started application runs all of the requested instances.
+func (s *Scaler) Verify() error {
+	if s.previous == nil || s.previous.State != "started" {
+		return nil
+	}
+
+	s.Log.Debugf("verifying that %s is scaled", s.AppName)
+
+	status, err := s.Verification.WaitForAppState(s.Context, s.Courier, s.AppName, "started")
+	if err != nil {
+		s.Log.Errorf("failed to verify app on foundation %s: %s", s.FoundationURL, err)
+		return err
+	}
+
+	if s.Instances > 0 && status.Instances != s.Instances {
+		s.Log.Errorf("failed to verify app on foundation %s: %d instances instead of %d", s.FoundationURL, status.Instances, s.Instances)
+		return state.InstancesNotRunningError{ApplicationName: s.AppName, Running: status.Running, Instances: s.Instances}
+	}
+
+	s.Log.Infof("verified that %s is scaled", s.AppName)
+
+	return nil
+}
+
+// Undo scales the application back to the instances and memory it had before.
+func (s *Scaler) Undo() error {
+	if s.previous == nil {
+		return nil
+	}
+
+	var (
+		instances uint16
+		memory    string
+	)
+
+	if s.Instances > 0 && s.Instances != s.previous.Instances {
+		instances = s.previous.Instances
+	}
+	if s.Memory != "" && s.Memory != s.previous.Memory {
+		memory = s.previous.Memory
+	}
+
+	return s.scale(instances, memory)
+}
+
+func (s *Scaler) Success() error {
+	return nil
+}
+
+func (s *Scaler) scale(instances uint16, memory string) error {
+	if instances > 0 {
+		s.Log.Infof("scaling app %s to %d instances", s.AppName, instances)
+
+		output, err := s.Courier.Scale(s.AppName, instances)
+		s.Response.Write(output)
+		if err != nil {
+			s.Log.Errorf("failed to scale app on foundation %s: %s", s.FoundationURL, err)
+			return state.ScaleError{ApplicationName: s.AppName, Out: output}
+		}
+	}
+
+	if memory != "" {
+		s.Log.Infof("scaling app %s to %s of memory", s.AppName, memory)
+
+		output, err := s.Courier.ScaleMemory(s.AppName, memory)
+		s.Response.Write(output)
+		if err != nil {
+			s.Log.Errorf("failed to scale app on foundation %s: %s", s.FoundationURL, err)
+			return state.ScaleError{ApplicationName: s.AppName, Out: output}
+		}
+	}
+
+	s.Log.Infof("successfully scaled app %s", s.AppName)
+
+	return nil
+}
+
+// ParseScaleData reads the instances and the memory out of the data of a scale request.
+// At least one of them has to be given.
+func ParseScaleData(data map[string]interface{}) (uint16, string, error) {
+	var (
+		instances uint16
+		memory    string
+	)
+
+	if value, ok := data["instances"]; ok {
+		number, ok := value.(float64)
+		if !ok || number < 1 || number > math.MaxUint16 || number != math.Trunc(number) {
+			return 0, "", InvalidScaleDataError{Data: data}
+		}
+		instances = uint16(number)
+	}
+
+	if value, ok := data["memory"]; ok {
+		memory, ok = value.(string)
+		if !ok || !memoryPattern.MatchString(memory) {
+			return 0, "", InvalidScaleDataError{Data: data}
+		}
+	}
+
+	if instances == 0 && memory == "" {
+		return 0, "", InvalidScaleDataError{Data: data}
+	}
+
+	return instances, memory, nil
+}
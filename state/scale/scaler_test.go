@@ -0,0 +1,155 @@
+package scale_test
+
+import (
+	"errors"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/compozed/deployadactyl/state"
+	"github.com/compozed/deployadactyl/state/command"
+	. "github.com/compozed/deployadactyl/state/scale"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+var _ = Describe("Scaler", func() {
+	var (
+		scaler    *Scaler
+		courier   *mocks.Courier
+		logBuffer *Buffer
+		response  *Buffer
+
+		appName string
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+		logBuffer = NewBuffer()
+		response = NewBuffer()
+
+		appName = "appName-" + randomizer.StringRunes(10)
+
+		courier.ExistsCall.Returns.Bool = true
+		courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 2, Running: 2, Memory: "512M", InstanceStates: []string{"running", "running"}}
+
+		scaler = &Scaler{
+			Foundation: command.Foundation{
+				Courier:       courier,
+				CFContext:     I.CFContext{Organization: "org", Space: "space"},
+				Authorization: I.Authorization{Username: "username", Password: "password"},
+				Response:      response,
+				Log:           I.DeploymentLogger{Log: I.DefaultLogger(logBuffer, logging.DEBUG, "scaler_test")},
+				FoundationURL: "foundationURL",
+				AppName:       appName,
+			},
+			Instances: 4,
+			Memory:    "1G",
+		}
+	})
+
+	Describe("Execute", func() {
+		It("scales the instances and the memory of the app", func() {
+			courier.ScaleCall.Returns.Output = []byte("scale succeeded")
+
+			Expect(scaler.Execute()).To(Succeed())
+
+			Expect(courier.ScaleCall.Received.Scales).To(Equal([]string{appName + "=4"}))
+			Expect(courier.ScaleMemoryCall.Received.Scales).To(Equal([]string{appName + "=1G"}))
+			Expect(response).To(Say("scale succeeded"))
+		})
+
+		It("only scales what is given", func() {
+			scaler.Instances = 0
+
+			Expect(scaler.Execute()).To(Succeed())
+
+			Expect(courier.ScaleCall.Received.Scales).To(BeEmpty())
+			Expect(courier.ScaleMemoryCall.Received.Scales).To(Equal([]string{appName + "=1G"}))
+		})
+
+		It("returns an error when the app does not exist", func() {
+			courier.ExistsCall.Returns.Bool = false
+
+			Expect(scaler.Execute()).To(MatchError(state.ExistsError{ApplicationName: appName}))
+		})
+
+		It("returns an error when scaling fails", func() {
+			courier.ScaleCall.Returns.Output = []byte("scale output")
+			courier.ScaleCall.Returns.Error = errors.New("scale error")
+
+			Expect(scaler.Execute()).To(MatchError(state.ScaleError{ApplicationName: appName, Out: []byte("scale output")}))
+			Expect(courier.ScaleMemoryCall.Received.Scales).To(BeEmpty())
+		})
+	})
+
+	Describe("Verify", func() {
+		BeforeEach(func() {
+			scaler.Verification = state.Verification{Attempts: 10}
+			Expect(scaler.Execute()).To(Succeed())
+		})
+
+		It("succeeds when all requested instances are running", func() {
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 4, Running: 4}
+
+			Expect(scaler.Verify()).To(Succeed())
+		})
+
+		It("returns an error when the app does not have the requested instances", func() {
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 2, Running: 2}
+
+			Expect(scaler.Verify()).To(MatchError(state.InstancesNotRunningError{ApplicationName: appName, Running: 2, Instances: 4}))
+		})
+	})
+
+	Describe("Undo", func() {
+		It("scales the app back to what it was", func() {
+			Expect(scaler.Execute()).To(Succeed())
+			Expect(scaler.Undo()).To(Succeed())
+
+			Expect(courier.ScaleCall.Received.Scales).To(Equal([]string{appName + "=4", appName + "=2"}))
+			Expect(courier.ScaleMemoryCall.Received.Scales).To(Equal([]string{appName + "=1G", appName + "=512M"}))
+		})
+
+		It("does nothing when the app was never scaled", func() {
+			courier.ExistsCall.Returns.Bool = false
+			scaler.Execute()
+
+			Expect(scaler.Undo()).To(Succeed())
+			Expect(courier.ScaleCall.Received.Scales).To(BeEmpty())
+		})
+	})
+
+	Describe("ParseScaleData", func() {
+		It("reads the instances and the memory", func() {
+			instances, memory, err := ParseScaleData(map[string]interface{}{"instances": float64(4), "memory": "1G"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instances).To(Equal(uint16(4)))
+			Expect(memory).To(Equal("1G"))
+		})
+
+		It("returns an error when neither is given", func() {
+			_, _, err := ParseScaleData(map[string]interface{}{})
+
+			Expect(err).To(MatchError(InvalidScaleDataError{Data: map[string]interface{}{}}))
+		})
+
+		It("returns an error for a number of instances that is not a positive whole number", func() {
+			for _, instances := range []interface{}{float64(0), float64(2.5), "4"} {
+				_, _, err := ParseScaleData(map[string]interface{}{"instances": instances})
+
+				Expect(err).To(HaveOccurred())
+			}
+		})
+
+		It("returns an error for a memory limit without a unit", func() {
+			_, _, err := ParseScaleData(map[string]interface{}{"memory": "1024"})
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -3,20 +3,21 @@ package start
 import (
 	"bytes"
 	"fmt"
-	"net/http"
 
 	"io"
 
 	"github.com/compozed/deployadactyl/config"
+	C "github.com/compozed/deployadactyl/constants"
 	"github.com/compozed/deployadactyl/controller/deployer"
 	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
 	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
 	"github.com/compozed/deployadactyl/structs"
 )
 
-type StartControllerConstructor func(log I.DeploymentLogger, deployer I.Deployer, conf config.Config, eventManager I.EventManager, errorFinder I.ErrorFinder, startManagerFactory I.StartManagerFactory) I.StartController
+type StartControllerConstructor func(log I.DeploymentLogger, deployer I.Deployer, conf config.Config, eventManager I.EventManager, errorFinder I.ErrorFinder, startManagerFactory I.StartManagerFactory, locker I.Locker) I.StartController
 
-func NewStartController(l I.DeploymentLogger, d I.Deployer, c config.Config, em I.EventManager, ef I.ErrorFinder, smf I.StartManagerFactory) I.StartController {
+func NewStartController(l I.DeploymentLogger, d I.Deployer, c config.Config, em I.EventManager, ef I.ErrorFinder, smf I.StartManagerFactory, lk I.Locker) I.StartController {
 	return &StartController{
 		Deployer:            d,
 		Config:              c,
@@ -24,6 +25,7 @@ func NewStartController(l I.DeploymentLogger, d I.Deployer, c config.Config, em
 		ErrorFinder:         ef,
 		StartManagerFactory: smf,
 		Log:                 l,
+		Locker:              lk,
 	}
 }
 
@@ -35,6 +37,7 @@ type StartController struct {
 	Config              config.Config
 	EventManager        I.EventManager
 	ErrorFinder         I.ErrorFinder
+	Locker              I.Locker
 }
 
 func (c *StartController) StartDeployment(deployment *I.Deployment, data map[string]interface{}, response *bytes.Buffer) (deployResponse I.DeployResponse) {
@@ -49,32 +52,51 @@ func (c *StartController) StartDeployment(deployment *I.Deployment, data map[str
 	if err != nil {
 		fmt.Fprintln(response, err.Error())
 		return I.DeployResponse{
-			StatusCode: http.StatusInternalServerError,
+			StatusCode: deployer.StatusCode(err),
 			Error:      err,
 		}
 	}
-	auth, err := c.resolveAuthorization(deployment.Authorization, environment, c.Log)
+	auth, err := state.ResolveAuthorization(deployment.Authorization, environment, c.Config, c.Log)
 	if err != nil {
 		return I.DeployResponse{
-			StatusCode: http.StatusUnauthorized,
+			StatusCode: deployer.StatusCode(err),
 			Error:      err,
 		}
 	}
 
 	deploymentInfo := &structs.DeploymentInfo{
-		Org:          cf.Organization,
-		Space:        cf.Space,
-		AppName:      cf.Application,
-		Environment:  cf.Environment,
-		UUID:         c.Log.UUID,
-		Domain:       environment.Domain,
-		SkipSSL:      environment.SkipSSL,
-		CustomParams: environment.CustomParams,
-		Username:     auth.Username,
-		Password:     auth.Password,
-		Data:         data,
+		Org:               cf.Organization,
+		Space:             cf.Space,
+		AppName:           cf.Application,
+		Environment:       cf.Environment,
+		UUID:              c.Log.UUID,
+		Domain:            environment.Domain,
+		SkipSSL:           environment.SkipSSL,
+		ClientCredentials: environment.ClientCredentials,
+		ServiceAccount:    deployment.Authorization.Username == "" && deployment.Authorization.Password == "",
+		CustomParams:      environment.CustomParams,
+		Username:          auth.Username,
+		Password:          auth.Password,
+		Data:              data,
 	}
 
+	if deployment.DryRun {
+		c.Log.Debugf("planning the start of %s", cf.Application)
+		deploymentInfo.DryRun = true
+		manager := c.StartManagerFactory.StartManager(c.Log, structs.DeployEventData{Response: response, DeploymentInfo: deploymentInfo})
+		return *c.Deployer.Deploy(deployment.Context, deploymentInfo, environment, manager, response)
+	}
+
+	unlock, err := c.Locker.Lock(deployment.Context, cf, I.LockHolder{UUID: c.Log.UUID, Type: C.DeploymentTypeStart, User: auth.Username})
+	if err != nil {
+		fmt.Fprintln(response, err.Error())
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+	defer unlock()
+
 	defer c.emitStartFinish(response, c.Log, cf, &auth, &environment, data, &deployResponse)
 	defer c.emitStartSuccessOrFailure(response, c.Log, cf, &auth, &environment, data, &deployResponse)
 
@@ -88,10 +110,11 @@ func (c *StartController) StartDeployment(deployment *I.Deployment, data map[str
 	})
 	if err != nil {
 		c.Log.Error(err)
-		err = &bluegreen.InitializationError{err}
+		err = bluegreen.InitializationError{Err: err}
+		err = deployer.EventError{Type: "StartStartedEvent", Err: err}
 		return I.DeployResponse{
-			StatusCode:     http.StatusInternalServerError,
-			Error:          deployer.EventError{Type: "StartStartedEvent", Err: err},
+			StatusCode:     deployer.StatusCode(err),
+			Error:          err,
 			DeploymentInfo: deploymentInfo,
 		}
 	}
@@ -99,25 +122,10 @@ func (c *StartController) StartDeployment(deployment *I.Deployment, data map[str
 	deployEventData := structs.DeployEventData{Response: response, DeploymentInfo: deploymentInfo}
 
 	manager := c.StartManagerFactory.StartManager(c.Log, deployEventData)
-	deployResponse = *c.Deployer.Deploy(deploymentInfo, environment, manager, response)
+	deployResponse = *c.Deployer.Deploy(deployment.Context, deploymentInfo, environment, manager, response)
 	return deployResponse
 }
 
-func (c *StartController) resolveAuthorization(auth I.Authorization, envs structs.Environment, deploymentLogger I.DeploymentLogger) (I.Authorization, error) {
-	config := c.Config
-	deploymentLogger.Debug("checking for basic auth")
-	if auth.Username == "" && auth.Password == "" {
-		if envs.Authenticate {
-			return I.Authorization{}, deployer.BasicAuthError{}
-
-		}
-		auth.Username = config.Username
-		auth.Password = config.Password
-	}
-
-	return auth, nil
-}
-
 func (c *StartController) resolveEnvironment(env string) (structs.Environment, error) {
 	config := c.Config
 	environment, ok := config.Environments[env]
@@ -144,7 +152,7 @@ func (c StartController) emitStartSuccessOrFailure(response io.ReadWriter, deplo
 	var event I.IEvent
 
 	if deployResponse.Error != nil {
-		c.printErrors(response, &deployResponse.Error)
+		state.PrintErrors(response, c.ErrorFinder)
 		event = StartFailureEvent{
 			CFContext:     cfContext,
 			Authorization: *auth,
@@ -172,26 +180,3 @@ func (c StartController) emitStartSuccessOrFailure(response io.ReadWriter, deplo
 		fmt.Fprintln(response, eventErr)
 	}
 }
-
-func (c StartController) printErrors(response io.ReadWriter, err *error) {
-	tempBuffer := bytes.Buffer{}
-	tempBuffer.ReadFrom(response)
-	fmt.Fprint(response, tempBuffer.String())
-
-	errors := c.ErrorFinder.FindErrors(tempBuffer.String())
-	if len(errors) > 0 {
-		*err = errors[0]
-		for _, error := range errors {
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "*******************")
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "The following error was found in the above logs: "+error.Error())
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "Error: "+error.Details()[0])
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "Potential solution: "+error.Solution())
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "*******************")
-		}
-	}
-}
@@ -9,9 +9,11 @@ import (
 	"reflect"
 
 	"github.com/compozed/deployadactyl/config"
+	C "github.com/compozed/deployadactyl/constants"
 	D "github.com/compozed/deployadactyl/controller/deployer"
 	"github.com/compozed/deployadactyl/controller/deployer/error_finder"
 	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/locker"
 	"github.com/compozed/deployadactyl/mocks"
 	"github.com/compozed/deployadactyl/randomizer"
 	. "github.com/compozed/deployadactyl/state/start"
@@ -27,6 +29,7 @@ var _ = Describe("StartDeployment", func() {
 		startManagerFactory *mocks.StartManagerFactory
 		eventManager        *mocks.EventManager
 		errorFinder         *mocks.ErrorFinder
+		appLocker           *mocks.Locker
 		controller          *StartController
 		deployment          I.Deployment
 		logBuffer           *Buffer
@@ -53,6 +56,7 @@ var _ = Describe("StartDeployment", func() {
 
 		startManagerFactory = &mocks.StartManagerFactory{}
 		errorFinder = &mocks.ErrorFinder{}
+		appLocker = &mocks.Locker{}
 
 		controller = &StartController{
 			Log:                 I.DeploymentLogger{Log: I.DefaultLogger(logBuffer, logging.DEBUG, "api_test"), UUID: uuid},
@@ -61,6 +65,7 @@ var _ = Describe("StartDeployment", func() {
 			EventManager:        eventManager,
 			Config:              config.Config{},
 			ErrorFinder:         errorFinder,
+			Locker:              appLocker,
 		}
 		environments := map[string]structs.Environment{}
 		environments[environment] = structs.Environment{}
@@ -127,6 +132,69 @@ var _ = Describe("StartDeployment", func() {
 
 	})
 
+	Context("when the application is not locked", func() {
+		It("locks the application while it runs", func() {
+			deployment := &I.Deployment{
+				Body: &[]byte{},
+				CFContext: I.CFContext{
+					Environment:  environment,
+					Organization: org,
+					Space:        space,
+					Application:  appName,
+				},
+				Authorization: I.Authorization{Username: "username", Password: "password"},
+			}
+
+			controller.StartDeployment(deployment, nil, response)
+
+			Expect(appLocker.LockCall.Received.CFContext).To(Equal(deployment.CFContext))
+			Expect(appLocker.LockCall.Received.Holder.UUID).To(Equal(uuid))
+			Expect(appLocker.LockCall.Received.Holder.Type).To(Equal(C.DeploymentTypeStart))
+			Expect(appLocker.LockCall.Received.Holder.User).To(Equal("username"))
+			Expect(appLocker.LockCall.Unlocked).To(BeTrue())
+		})
+	})
+
+	Context("when it is a dry run", func() {
+		It("plans the start without locking the application or emitting events", func() {
+			deployment := &I.Deployment{
+				Body: &[]byte{},
+				CFContext: I.CFContext{
+					Environment: environment,
+					Application: appName,
+				},
+				DryRun: true,
+			}
+
+			controller.StartDeployment(deployment, nil, response)
+
+			Expect(deployer.DeployCall.Called).To(Equal(1))
+			Expect(deployer.DeployCall.Received.DeploymentInfo.DryRun).To(BeTrue())
+			Expect(appLocker.LockCall.Received.Holder.UUID).To(BeEmpty())
+			Expect(eventManager.EmitCall.TimesCalled).To(BeZero())
+			Expect(eventManager.EmitEventCall.TimesCalled).To(BeZero())
+		})
+	})
+
+	Context("when the application is locked", func() {
+		It("returns http.StatusConflict without deploying", func() {
+			appLocker.LockCall.Returns.Error = locker.LockedError{Holder: I.LockHolder{UUID: "another uuid"}}
+			deployment := &I.Deployment{
+				Body: &[]byte{},
+				CFContext: I.CFContext{
+					Environment: environment,
+					Application: appName,
+				},
+			}
+
+			deployResponse := controller.StartDeployment(deployment, nil, response)
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusConflict))
+			Expect(deployResponse.Error).To(MatchError(appLocker.LockCall.Returns.Error))
+			Expect(deployer.DeployCall.Called).To(Equal(0))
+		})
+	})
+
 	Context("When StartStartEvent succeeds", func() {
 		It("should emit a StartStartedEvent", func() {
 			deployment := &I.Deployment{
@@ -204,6 +272,21 @@ var _ = Describe("StartDeployment", func() {
 			Expect(deploymentResponse.DeploymentInfo.SkipSSL).Should(Equal(true))
 			Expect(deploymentResponse.DeploymentInfo.CustomParams["customName"]).Should(Equal("customParams"))
 		})
+
+		It("Should return ClientCredentials", func() {
+			controller.Config.Environments[environment] = structs.Environment{
+				ClientCredentials: true,
+			}
+
+			deployment := &I.Deployment{
+				CFContext: I.CFContext{
+					Environment: environment,
+				}}
+
+			response := bytes.NewBuffer([]byte{})
+			deploymentResponse := controller.StartDeployment(deployment, nil, response)
+			Expect(deploymentResponse.DeploymentInfo.ClientCredentials).Should(BeTrue())
+		})
 	})
 
 	Context("When auth does not exist", func() {
@@ -1,6 +1,7 @@
 package start
 
 import (
+	"context"
 	"io"
 
 	I "github.com/compozed/deployadactyl/interfaces"
@@ -17,9 +18,22 @@ type Starter struct {
 	FoundationURL string
 	AppName       string
 	Data          map[string]interface{}
+	Context       context.Context
+	Verification  state.Verification
 }
 
+// Verify checks that the application is started and that all of its instances are running.
 func (s Starter) Verify() error {
+	s.Log.Debugf("verifying that %s is started", s.AppName)
+
+	_, err := s.Verification.WaitForAppState(s.Context, s.Courier, s.AppName, "started")
+	if err != nil {
+		s.Log.Errorf("failed to verify app on foundation %s: %s", s.FoundationURL, err)
+		return err
+	}
+
+	s.Log.Infof("verified that %s is started", s.AppName)
+
 	return nil
 }
 
@@ -49,6 +63,7 @@ func (s Starter) Initially() error {
 		s.CFContext.Organization,
 		s.CFContext.Space,
 		s.CFContext.SkipSSL,
+		s.CFContext.ClientCredentials,
 	)
 	s.Response.Write(output)
 	if err != nil {
@@ -82,6 +97,18 @@ func (s Starter) Execute() error {
 	return nil
 }
 
+// Plan returns the steps Execute and Verify would take on the foundation. The application has to exist.
+func (s Starter) Plan() (I.FoundationPlan, error) {
+	if !s.Courier.Exists(s.AppName) {
+		return I.FoundationPlan{}, state.ExistsError{ApplicationName: s.AppName}
+	}
+
+	return I.FoundationPlan{
+		AppExists: true,
+		Steps:     []string{"start " + s.AppName, "verify " + s.AppName + " is started"},
+	}, nil
+}
+
 func (s Starter) Undo() error {
 
 	if s.Courier.Exists(s.AppName) != true {
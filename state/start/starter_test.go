@@ -85,9 +85,10 @@ var _ = Describe("Starter", func() {
 		}
 
 		cfContext = interfaces.CFContext{
-			Organization: randomOrg,
-			Space:        randomSpace,
-			Application:  randomAppName,
+			Organization:      randomOrg,
+			Space:             randomSpace,
+			Application:       randomAppName,
+			ClientCredentials: true,
 		}
 
 		auth = interfaces.Authorization{
@@ -119,6 +120,7 @@ var _ = Describe("Starter", func() {
 				Expect(courier.LoginCall.Received.Org).To(Equal(randomOrg))
 				Expect(courier.LoginCall.Received.Space).To(Equal(randomSpace))
 				Expect(courier.LoginCall.Received.SkipSSL).To(Equal(skipSSL))
+				Expect(courier.LoginCall.Received.ClientCredentials).To(BeTrue())
 			})
 
 			It("writes the output of the courier to the response", func() {
@@ -200,6 +202,31 @@ var _ = Describe("Starter", func() {
 		})
 	})
 
+	Describe("Plan", func() {
+		It("plans to start the application", func() {
+			courier.ExistsCall.Returns.Bool = true
+
+			plan, err := starter.Plan()
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan).To(Equal(interfaces.FoundationPlan{
+				AppExists: true,
+				Steps:     []string{"start " + randomAppName, "verify " + randomAppName + " is started"},
+			}))
+			Expect(courier.StartCall.Received.AppName).To(BeEmpty())
+		})
+
+		Context("when the app does not exist", func() {
+			It("returns an error", func() {
+				courier.ExistsCall.Returns.Bool = false
+
+				_, err := starter.Plan()
+
+				Expect(err).To(MatchError(state.ExistsError{ApplicationName: randomAppName}))
+			})
+		})
+	})
+
 	Describe("Undo", func() {
 		Context("when the app does not exist", func() {
 			It("returns an error", func() {
@@ -238,8 +265,39 @@ var _ = Describe("Starter", func() {
 	})
 
 	Describe("Verify", func() {
-		It("returns nil", func() {
-			Expect(starter.Verify()).To(BeNil())
+		BeforeEach(func() {
+			starter.Verification = state.Verification{Attempts: 10}
+		})
+
+		It("succeeds when the app is started and all instances are running", func() {
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 2, Running: 2, InstanceStates: []string{"running", "running"}}
+
+			Expect(starter.Verify()).To(Succeed())
+			Expect(courier.AppStatusCall.Received.AppName).To(Equal(randomAppName))
+
+			Eventually(logBuffer).Should(Say(fmt.Sprintf("verified that %s is started", randomAppName)))
+		})
+
+		It("returns an error when the app is not started", func() {
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "stopped", Instances: 2}
+
+			err := starter.Verify()
+
+			Expect(err).To(MatchError(state.AppStateError{ApplicationName: randomAppName, RequestedState: "started", State: "stopped"}))
+		})
+
+		It("returns an error when an instance crashed", func() {
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 2, Running: 1, InstanceStates: []string{"running", "crashed"}}
+
+			err := starter.Verify()
+
+			Expect(err).To(MatchError(state.InstancesNotRunningError{ApplicationName: randomAppName, Running: 1, Instances: 2}))
+		})
+
+		It("returns an error when the status cannot be found", func() {
+			courier.AppStatusCall.Returns.Error = errors.New("app status error")
+
+			Expect(starter.Verify()).To(MatchError("app status error"))
 		})
 	})
 
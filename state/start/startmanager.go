@@ -1,15 +1,16 @@
 package start
 
 import (
+	"context"
 	"io"
 
 	"fmt"
+	"github.com/compozed/deployadactyl/controller/deployer"
 	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
 	I "github.com/compozed/deployadactyl/interfaces"
 	"github.com/compozed/deployadactyl/state"
 	S "github.com/compozed/deployadactyl/structs"
 	"net/http"
-	"regexp"
 )
 
 const successfulStart = `Your start was successful! (^_^)b
@@ -17,7 +18,7 @@ const successfulStart = `Your start was successful! (^_^)b
 `
 
 type courierCreator interface {
-	CreateCourier() (I.Courier, error)
+	CreateCourier(ctx context.Context) (I.Courier, error)
 }
 
 type StartManager struct {
@@ -38,15 +39,9 @@ func (a StartManager) OnStart() error {
 func (a StartManager) OnFinish(env S.Environment, response io.ReadWriter, err error) I.DeployResponse {
 	if err != nil {
 		fmt.Fprintf(response, "\nYour application was not successfully started on all foundations: %s\n\n", err.Error())
-		if matched, _ := regexp.MatchString("login failed", err.Error()); matched {
-			return I.DeployResponse{
-				StatusCode: http.StatusBadRequest,
-				Error:      err,
-			}
-		}
 		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
 			Error:      err,
-			StatusCode: http.StatusInternalServerError,
 		}
 	}
 
@@ -58,24 +53,28 @@ func (a StartManager) OnFinish(env S.Environment, response io.ReadWriter, err er
 
 func (a StartManager) CleanUp() {}
 
-func (a StartManager) Create(environment S.Environment, response io.ReadWriter, foundationURL string) (I.Action, error) {
-	courier, err := a.CourierCreator.CreateCourier()
+func (a StartManager) Create(ctx context.Context, environment S.Environment, response io.ReadWriter, foundationURL string) (I.Action, error) {
+	courier, err := a.CourierCreator.CreateCourier(ctx)
 	if err != nil {
 		a.Logger.Error(err)
 		return &Starter{}, state.CourierCreationError{Err: err}
 	}
+
+	username, password, clientCredentials := state.Login(*a.DeployEventData.DeploymentInfo, environment, foundationURL)
+
 	p := &Starter{
 		Courier: courier,
 		CFContext: I.CFContext{
-			Environment:  environment.Name,
-			Organization: a.DeployEventData.DeploymentInfo.Org,
-			Space:        a.DeployEventData.DeploymentInfo.Space,
-			Application:  a.DeployEventData.DeploymentInfo.AppName,
-			SkipSSL:      a.DeployEventData.DeploymentInfo.SkipSSL,
+			Environment:       environment.Name,
+			Organization:      a.DeployEventData.DeploymentInfo.Org,
+			Space:             a.DeployEventData.DeploymentInfo.Space,
+			Application:       a.DeployEventData.DeploymentInfo.AppName,
+			SkipSSL:           a.DeployEventData.DeploymentInfo.SkipSSL,
+			ClientCredentials: clientCredentials,
 		},
 		Authorization: I.Authorization{
-			Username: a.DeployEventData.DeploymentInfo.Username,
-			Password: a.DeployEventData.DeploymentInfo.Password,
+			Username: username,
+			Password: password,
 		},
 		EventManager:  a.EventManager,
 		Response:      response,
@@ -83,6 +82,8 @@ func (a StartManager) Create(environment S.Environment, response io.ReadWriter,
 		FoundationURL: foundationURL,
 		AppName:       a.DeployEventData.DeploymentInfo.AppName,
 		Data:          a.DeployEventData.DeploymentInfo.Data,
+		Context:       ctx,
+		Verification:  state.DefaultVerification,
 	}
 
 	return p, nil
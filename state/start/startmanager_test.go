@@ -1,6 +1,7 @@
 package start_test
 
 import (
+	"context"
 	"github.com/compozed/deployadactyl/state/start"
 	"github.com/compozed/deployadactyl/structs"
 	. "github.com/onsi/ginkgo"
@@ -23,7 +24,7 @@ import (
 type courierCreator struct {
 	CourierCreatorFn func() (interfaces.Courier, error)
 }
-func (c courierCreator) CreateCourier() (interfaces.Courier, error) {
+func (c courierCreator) CreateCourier(ctx context.Context) (interfaces.Courier, error) {
 	if c.CourierCreatorFn != nil {
 		return c.CourierCreatorFn()
 	}
@@ -68,7 +69,7 @@ var _ = Describe("Startmanager", func() {
 			It("should return a Starter object", func() {
 				env := structs.Environment{}
 				foundationURL := "foundation url"
-				starter, _ := startManager.Create(env, response, foundationURL)
+				starter, _ := startManager.Create(context.Background(), env, response, foundationURL)
 
 				Expect(reflect.TypeOf(starter)).Should(Equal(reflect.TypeOf(&start.Starter{})))
 
@@ -85,7 +86,7 @@ var _ = Describe("Startmanager", func() {
 					Password: "password",
 				}
 				*startManager.(start.StartManager).DeployEventData.DeploymentInfo = deploymentInfo
-				starter, _ := startManager.Create(env, response, foundationURL)
+				starter, _ := startManager.Create(context.Background(), env, response, foundationURL)
 
 				starterData := starter.(*start.Starter)
 				Expect(starterData.CFContext.Application).Should(Equal("myApp"))
@@ -105,7 +106,7 @@ var _ = Describe("Startmanager", func() {
 
 				env := structs.Environment{}
 				foundationURL := "foundation url"
-				_, err := startManager.Create(env, response, foundationURL)
+				_, err := startManager.Create(context.Background(), env, response, foundationURL)
 				Expect(err).ShouldNot(BeNil())
 				Expect(err.Error()).Should(ContainSubstring("a test error"))
 
@@ -187,7 +188,7 @@ var _ = Describe("Startmanager", func() {
 		Context("when an error occurs", func() {
 			Context("and it is a log in error", func() {
 				It("returns a http status bad request", func() {
-					deployResponse := startManager.OnFinish(structs.Environment{}, response, errors.New("login failed"))
+					deployResponse := startManager.OnFinish(structs.Environment{}, response, bluegreen.LoginError{LoginErrors: []error{errors.New("bad credentials")}})
 
 					Expect(deployResponse.StatusCode).To(Equal(http.StatusBadRequest))
 				})
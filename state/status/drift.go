@@ -0,0 +1,92 @@
+package status
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+const unset = "(unset)"
+
+// Differences compares an application across the foundations that could be read and returns the properties
+// that do not have the same value everywhere. Apart from its existence, the application is only compared
+// between the foundations it exists on. Every foundation has routes of its own, so only the routes on the
+// load balanced domain are compared. Environment variables are compared by fingerprint to keep their values out of the report.
+func Differences(domain string, foundations []S.FoundationReport) []S.Difference {
+	var read, existing []S.FoundationReport
+	for _, foundation := range foundations {
+		if foundation.Error != "" {
+			continue
+		}
+		read = append(read, foundation)
+		if foundation.Exists {
+			existing = append(existing, foundation)
+		}
+	}
+
+	differences := []S.Difference{}
+	compare := func(property string, foundations []S.FoundationReport, value func(S.FoundationReport) string) {
+		values := map[string]string{}
+		distinct := map[string]bool{}
+		for _, foundation := range foundations {
+			v := value(foundation)
+			values[foundation.FoundationURL] = v
+			distinct[v] = true
+		}
+		if len(distinct) > 1 {
+			differences = append(differences, S.Difference{Property: property, Values: values})
+		}
+	}
+
+	compare("exists", read, func(f S.FoundationReport) string { return strconv.FormatBool(f.Exists) })
+	compare("state", existing, func(f S.FoundationReport) string { return f.State })
+	compare("instances", existing, func(f S.FoundationReport) string { return strconv.Itoa(int(f.Instances)) })
+	compare("routes", existing, func(f S.FoundationReport) string { return strings.Join(loadBalancedRoutes(domain, f.Routes), ", ") })
+	compare("buildpack", existing, func(f S.FoundationReport) string { return f.Buildpack })
+	compare("stack", existing, func(f S.FoundationReport) string { return f.Stack })
+
+	for _, name := range variableNames(existing) {
+		compare("env:"+name, existing, func(f S.FoundationReport) string {
+			value, ok := f.EnvironmentVariables[name]
+			if !ok {
+				return unset
+			}
+			return fmt.Sprintf("%x", sha256.Sum256([]byte(value)))[:12]
+		})
+	}
+
+	return differences
+}
+
+func loadBalancedRoutes(domain string, routes []string) []string {
+	found := []string{}
+	for _, route := range routes {
+		host := strings.SplitN(route, "/", 2)[0]
+		if domain == "" || host == domain || strings.HasSuffix(host, "."+domain) {
+			found = append(found, route)
+		}
+	}
+	sort.Strings(found)
+
+	return found
+}
+
+func variableNames(foundations []S.FoundationReport) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, foundation := range foundations {
+		for name := range foundation.EnvironmentVariables {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,86 @@
+package status_test
+
+import (
+	. "github.com/compozed/deployadactyl/state/status"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Differences", func() {
+	var foundations []S.FoundationReport
+
+	BeforeEach(func() {
+		foundations = []S.FoundationReport{
+			{
+				FoundationURL:        "foundation-1",
+				Exists:               true,
+				State:                "started",
+				Instances:            2,
+				Routes:               []string{"myApp.example.com", "myApp.apps.foundation-1.com"},
+				Buildpack:            "java_buildpack",
+				Stack:                "cflinuxfs2",
+				EnvironmentVariables: map[string]string{"FOO": "bar"},
+			},
+			{
+				FoundationURL:        "foundation-2",
+				Exists:               true,
+				State:                "started",
+				Instances:            2,
+				Routes:               []string{"myApp.apps.foundation-2.com", "myApp.example.com"},
+				Buildpack:            "java_buildpack",
+				Stack:                "cflinuxfs2",
+				EnvironmentVariables: map[string]string{"FOO": "bar"},
+			},
+		}
+	})
+
+	It("finds nothing when the app is the same everywhere", func() {
+		Expect(Differences("example.com", foundations)).To(BeEmpty())
+	})
+
+	It("only compares the routes on the load balanced domain", func() {
+		foundations[1].Routes = []string{"myApp.apps.foundation-2.com"}
+
+		differences := Differences("example.com", foundations)
+
+		Expect(differences).To(Equal([]S.Difference{
+			{Property: "routes", Values: map[string]string{"foundation-1": "myApp.example.com", "foundation-2": ""}},
+		}))
+	})
+
+	It("compares the buildpack and the stack", func() {
+		foundations[0].Buildpack = "go_buildpack"
+		foundations[1].Stack = "cflinuxfs3"
+
+		differences := Differences("example.com", foundations)
+
+		Expect(differences).To(HaveLen(2))
+		Expect(differences[0].Property).To(Equal("buildpack"))
+		Expect(differences[1].Property).To(Equal("stack"))
+	})
+
+	It("compares the environment variables without exposing their values", func() {
+		foundations[1].EnvironmentVariables = map[string]string{"FOO": "baz", "BAR": "qux"}
+
+		differences := Differences("example.com", foundations)
+
+		Expect(differences).To(HaveLen(2))
+		Expect(differences[0].Property).To(Equal("env:BAR"))
+		Expect(differences[0].Values["foundation-1"]).To(Equal("(unset)"))
+		Expect(differences[0].Values["foundation-2"]).ToNot(ContainSubstring("qux"))
+		Expect(differences[1].Property).To(Equal("env:FOO"))
+		Expect(differences[1].Values["foundation-1"]).ToNot(Equal(differences[1].Values["foundation-2"]))
+		Expect(differences[1].Values["foundation-1"]).ToNot(ContainSubstring("bar"))
+	})
+
+	It("only compares the existence of the app with the foundations it is missing from", func() {
+		foundations = append(foundations, S.FoundationReport{FoundationURL: "foundation-3"})
+
+		differences := Differences("example.com", foundations)
+
+		Expect(differences).To(Equal([]S.Difference{
+			{Property: "exists", Values: map[string]string{"foundation-1": "true", "foundation-2": "true", "foundation-3": "false"}},
+		}))
+	})
+})
@@ -0,0 +1,49 @@
+package status
+
+import (
+	"reflect"
+
+	"github.com/compozed/deployadactyl/eventmanager"
+	"github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/structs"
+	"github.com/go-errors/errors"
+)
+
+type eventBinding struct {
+	etype   reflect.Type
+	handler func(event interface{}) error
+}
+
+func (s eventBinding) Accepts(event interface{}) bool {
+	return reflect.TypeOf(event) == s.etype
+}
+
+func (b eventBinding) Emit(event interface{}) error {
+	return b.handler(event)
+}
+
+// DriftDetectedEvent is emitted when an application is not the same on every foundation of an environment.
+type DriftDetectedEvent struct {
+	CFContext   interfaces.CFContext
+	Environment structs.Environment
+	Report      structs.DriftReport
+	Log         interfaces.DeploymentLogger
+}
+
+func (e DriftDetectedEvent) Name() string {
+	return "DriftDetectedEvent"
+}
+
+func NewDriftDetectedEventBinding(handler func(event DriftDetectedEvent) error) interfaces.Binding {
+	return eventBinding{
+		etype: reflect.TypeOf(DriftDetectedEvent{}),
+		handler: func(gevent interface{}) error {
+			event, ok := gevent.(DriftDetectedEvent)
+			if ok {
+				return handler(event)
+			} else {
+				return eventmanager.InvalidEventType{Err: errors.New("invalid event type")}
+			}
+		},
+	}
+}
@@ -0,0 +1,111 @@
+// Package status reports the state of an application on every foundation of an environment.
+package status
+
+import (
+	"io"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+// Inspector reads the status of an application on a single foundation. It never changes the application,
+// so there is nothing to undo and a foundation that cannot be read does not fail the others.
+type Inspector struct {
+	Courier       I.Courier
+	CFContext     I.CFContext
+	Authorization I.Authorization
+	Response      io.ReadWriter
+	Log           I.DeploymentLogger
+	FoundationURL string
+	AppName       string
+	Report        *S.FoundationReport
+	// ReadEnvironment also reads the user provided environment variables of the application.
+	ReadEnvironment bool
+}
+
+// Login will login to a Cloud Foundry instance.
+func (i *Inspector) Initially() error {
+	i.Log.Debugf(
+		`logging into cloud foundry with parameters:
+		foundation URL: %+v
+		username: %+v
+		org: %+v
+		space: %+v`,
+		i.FoundationURL, i.Authorization.Username, i.CFContext.Organization, i.CFContext.Space,
+	)
+
+	output, err := i.Courier.Login(
+		i.FoundationURL,
+		i.Authorization.Username,
+		i.Authorization.Password,
+		i.CFContext.Organization,
+		i.CFContext.Space,
+		i.CFContext.SkipSSL,
+		i.CFContext.ClientCredentials,
+	)
+	i.Response.Write(output)
+	if err != nil {
+		i.Log.Errorf("could not login to %s", i.FoundationURL)
+		return state.LoginError{FoundationURL: i.FoundationURL, Out: output}
+	}
+
+	i.Log.Infof("logged into cloud foundry %s", i.FoundationURL)
+
+	return nil
+}
+
+// Execute fills the report with the status of the application.
+// An error is kept in the report instead of being returned.
+func (i *Inspector) Execute() error {
+	i.Report.FoundationURL = i.FoundationURL
+
+	i.Report.Exists = i.Courier.Exists(i.AppName)
+	if !i.Report.Exists {
+		i.Log.Infof("app %s doesn't exist on foundation %s", i.AppName, i.FoundationURL)
+		return nil
+	}
+
+	status, err := i.Courier.AppStatus(i.AppName)
+	if err != nil {
+		i.Log.Errorf("could not read the status of %s on foundation %s: %s", i.AppName, i.FoundationURL, err)
+		i.Report.Error = err.Error()
+		return nil
+	}
+
+	i.Report.State = status.State
+	i.Report.Instances = status.Instances
+	i.Report.Running = status.Running
+	i.Report.Routes = status.Routes
+	i.Report.Buildpack = status.Buildpack
+	i.Report.Stack = status.Stack
+
+	if i.ReadEnvironment {
+		variables, err := i.Courier.EnvironmentVariables(i.AppName)
+		if err != nil {
+			i.Log.Errorf("could not read the environment variables of %s on foundation %s: %s", i.AppName, i.FoundationURL, err)
+			i.Report.Error = err.Error()
+			return nil
+		}
+		i.Report.EnvironmentVariables = variables
+	}
+
+	return nil
+}
+
+func (i *Inspector) Verify() error {
+	return nil
+}
+
+func (i *Inspector) Undo() error {
+	return nil
+}
+
+func (i *Inspector) Success() error {
+	return nil
+}
+
+// CleanUp removes the temporary directory created by the Executor.
+func (i *Inspector) Finally() error {
+	return i.Courier.CleanUp()
+}
@@ -0,0 +1,107 @@
+package status_test
+
+import (
+	"errors"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	"github.com/compozed/deployadactyl/state"
+	. "github.com/compozed/deployadactyl/state/status"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+var _ = Describe("Inspector", func() {
+	var (
+		inspector *Inspector
+		courier   *mocks.Courier
+		logBuffer *Buffer
+		response  *Buffer
+
+		appName string
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+		logBuffer = NewBuffer()
+		response = NewBuffer()
+
+		appName = "appName-" + randomizer.StringRunes(10)
+
+		courier.ExistsCall.Returns.Bool = true
+		courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 2, Running: 1, Routes: []string{appName + ".example.com"}}
+
+		inspector = &Inspector{
+			Courier:       courier,
+			CFContext:     I.CFContext{Organization: "org", Space: "space"},
+			Authorization: I.Authorization{Username: "username", Password: "password"},
+			Response:      response,
+			Log:           I.DeploymentLogger{Log: I.DefaultLogger(logBuffer, logging.DEBUG, "inspector_test")},
+			FoundationURL: "foundationURL",
+			AppName:       appName,
+			Report:        &S.FoundationReport{},
+		}
+	})
+
+	Describe("Initially", func() {
+		It("returns an error when login fails", func() {
+			courier.LoginCall.Returns.Output = []byte("login output")
+			courier.LoginCall.Returns.Error = errors.New("login error")
+
+			Expect(inspector.Initially()).To(MatchError(state.LoginError{FoundationURL: "foundationURL", Out: []byte("login output")}))
+		})
+	})
+
+	Describe("Execute", func() {
+		It("reports the state, the instances and the routes of the app", func() {
+			Expect(inspector.Execute()).To(Succeed())
+
+			Expect(courier.AppStatusCall.Received.AppName).To(Equal(appName))
+			Expect(*inspector.Report).To(Equal(S.FoundationReport{
+				FoundationURL: "foundationURL",
+				Exists:        true,
+				State:         "started",
+				Instances:     2,
+				Running:       1,
+				Routes:        []string{appName + ".example.com"},
+			}))
+		})
+
+		It("reads the environment variables when asked to", func() {
+			courier.EnvironmentVariablesCall.Returns.Variables = map[string]string{"FOO": "bar"}
+			inspector.ReadEnvironment = true
+
+			Expect(inspector.Execute()).To(Succeed())
+
+			Expect(courier.EnvironmentVariablesCall.Received.AppName).To(Equal(appName))
+			Expect(inspector.Report.EnvironmentVariables).To(Equal(map[string]string{"FOO": "bar"}))
+		})
+
+		It("does not read the environment variables otherwise", func() {
+			Expect(inspector.Execute()).To(Succeed())
+
+			Expect(courier.EnvironmentVariablesCall.Received.AppName).To(BeEmpty())
+		})
+
+		It("reports an app that does not exist", func() {
+			courier.ExistsCall.Returns.Bool = false
+
+			Expect(inspector.Execute()).To(Succeed())
+
+			Expect(inspector.Report.Exists).To(BeFalse())
+			Expect(courier.AppStatusCall.Received.AppName).To(BeEmpty())
+		})
+
+		It("keeps the error in the report instead of failing", func() {
+			courier.AppStatusCall.Returns.Error = errors.New("app status error")
+
+			Expect(inspector.Execute()).To(Succeed())
+
+			Expect(inspector.Report.Error).To(Equal("app status error"))
+		})
+	})
+})
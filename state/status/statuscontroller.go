@@ -0,0 +1,166 @@
+package status
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/compozed/deployadactyl/config"
+	"github.com/compozed/deployadactyl/controller/deployer"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/structs"
+)
+
+type StatusControllerConstructor func(log I.DeploymentLogger, deployer I.Deployer, conf config.Config, eventManager I.EventManager, statusManagerFactory I.StatusManagerFactory) I.StatusController
+
+func NewStatusController(l I.DeploymentLogger, d I.Deployer, c config.Config, em I.EventManager, smf I.StatusManagerFactory) I.StatusController {
+	return &StatusController{
+		Deployer:             d,
+		Config:               c,
+		EventManager:         em,
+		StatusManagerFactory: smf,
+		Log:                  l,
+	}
+}
+
+// StatusController reads the status of an application on every foundation of an environment.
+// Nothing is changed on the foundations, so no deployment event is emitted.
+type StatusController struct {
+	Deployer             I.Deployer
+	Log                  I.DeploymentLogger
+	StatusManagerFactory I.StatusManagerFactory
+	Config               config.Config
+	EventManager         I.EventManager
+}
+
+func (c *StatusController) AppStatus(deployment *I.Deployment, response *bytes.Buffer) (structs.StatusReport, I.DeployResponse) {
+	c.Log.Debugf("Preparing to read the status of %s with UUID %s", deployment.CFContext.Application, c.Log.UUID)
+
+	_, report, deployResponse := c.inspect(deployment, map[string]interface{}{}, response)
+
+	return report, deployResponse
+}
+
+// Drift compares the application across the foundations of its environment and emits a DriftDetectedEvent
+// when it is not the same everywhere.
+func (c *StatusController) Drift(deployment *I.Deployment, response *bytes.Buffer) (structs.DriftReport, I.DeployResponse) {
+	cf := deployment.CFContext
+	c.Log.Debugf("Preparing to look for drift of %s with UUID %s", cf.Application, c.Log.UUID)
+
+	drift := structs.DriftReport{
+		Environment:  cf.Environment,
+		Organization: cf.Organization,
+		Space:        cf.Space,
+		Application:  cf.Application,
+		Differences:  []structs.Difference{},
+	}
+
+	environment, report, deployResponse := c.inspect(deployment, map[string]interface{}{"environment_variables": true}, response)
+	if deployResponse.Error != nil {
+		return drift, deployResponse
+	}
+
+	for _, foundation := range report.Foundations {
+		if foundation.Error != "" {
+			if drift.Unread == nil {
+				drift.Unread = map[string]string{}
+			}
+			drift.Unread[foundation.FoundationURL] = foundation.Error
+		}
+	}
+
+	drift.Differences = Differences(environment.Domain, report.Foundations)
+	if len(drift.Differences) == 0 {
+		return drift, deployResponse
+	}
+
+	drift.Drift = true
+	c.Log.Infof("%s has drifted on %d properties", cf.Application, len(drift.Differences))
+
+	event := DriftDetectedEvent{
+		CFContext:   cf,
+		Environment: environment,
+		Report:      drift,
+		Log:         c.Log,
+	}
+	c.Log.Debugf("emitting a %s event", event.Name())
+	err := c.EventManager.EmitEvent(event)
+	if err != nil {
+		c.Log.Errorf("an error occurred when emitting a %s event: %s", event.Name(), err)
+	}
+
+	return drift, deployResponse
+}
+
+func (c *StatusController) inspect(deployment *I.Deployment, data map[string]interface{}, response *bytes.Buffer) (structs.Environment, structs.StatusReport, I.DeployResponse) {
+	cf := deployment.CFContext
+
+	report := structs.StatusReport{
+		Environment:  cf.Environment,
+		Organization: cf.Organization,
+		Space:        cf.Space,
+		Application:  cf.Application,
+	}
+
+	environment, err := c.resolveEnvironment(cf.Environment)
+	if err != nil {
+		fmt.Fprintln(response, err.Error())
+		return environment, report, I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+	auth, err := c.resolveAuthorization(deployment.Authorization, environment, c.Log)
+	if err != nil {
+		fmt.Fprintln(response, err.Error())
+		return environment, report, I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+
+	deploymentInfo := &structs.DeploymentInfo{
+		Org:               cf.Organization,
+		Space:             cf.Space,
+		AppName:           cf.Application,
+		Environment:       cf.Environment,
+		UUID:              c.Log.UUID,
+		Domain:            environment.Domain,
+		SkipSSL:           environment.SkipSSL,
+		ClientCredentials: environment.ClientCredentials,
+		ServiceAccount:    deployment.Authorization.Username == "" && deployment.Authorization.Password == "",
+		CustomParams:      environment.CustomParams,
+		Username:          auth.Username,
+		Password:          auth.Password,
+		Data:              data,
+	}
+
+	deployEventData := structs.DeployEventData{Response: response, DeploymentInfo: deploymentInfo}
+
+	manager := c.StatusManagerFactory.StatusManager(c.Log, deployEventData, &report)
+	deployResponse := c.Deployer.Deploy(deployment.Context, deploymentInfo, environment, manager, response)
+
+	return environment, report, *deployResponse
+}
+
+func (c *StatusController) resolveAuthorization(auth I.Authorization, envs structs.Environment, deploymentLogger I.DeploymentLogger) (I.Authorization, error) {
+	config := c.Config
+	deploymentLogger.Debug("checking for basic auth")
+	if auth.Username == "" && auth.Password == "" {
+		if envs.Authenticate {
+			return I.Authorization{}, deployer.BasicAuthError{}
+		}
+		auth.Username = config.Username
+		auth.Password = config.Password
+	}
+
+	return auth, nil
+}
+
+func (c *StatusController) resolveEnvironment(env string) (structs.Environment, error) {
+	config := c.Config
+	environment, ok := config.Environments[env]
+	if !ok {
+		return structs.Environment{}, deployer.EnvironmentNotFoundError{Environment: env}
+	}
+	return environment, nil
+}
@@ -0,0 +1,170 @@
+package status_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/compozed/deployadactyl/config"
+	D "github.com/compozed/deployadactyl/controller/deployer"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	"github.com/compozed/deployadactyl/randomizer"
+	. "github.com/compozed/deployadactyl/state/status"
+	"github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+// reportingDeployer fills the report the manager was created with, as the inspectors would.
+type reportingDeployer struct {
+	factory     *mocks.StatusManagerFactory
+	foundations []structs.FoundationReport
+}
+
+func (d reportingDeployer) Deploy(ctx context.Context, deploymentInfo *structs.DeploymentInfo, env structs.Environment, actionCreator I.ActionCreator, response io.ReadWriter) *I.DeployResponse {
+	d.factory.StatusManagerCall.Received.Report.Foundations = d.foundations
+
+	return &I.DeployResponse{StatusCode: http.StatusOK, DeploymentInfo: deploymentInfo}
+}
+
+var _ = Describe("StatusController", func() {
+	var (
+		statusManagerFactory *mocks.StatusManagerFactory
+		deployer             *mocks.Deployer
+		eventManager         *mocks.EventManager
+		controller           *StatusController
+		deployment           *I.Deployment
+		response             *bytes.Buffer
+
+		environment string
+		uuid        string
+	)
+
+	BeforeEach(func() {
+		response = &bytes.Buffer{}
+		environment = "environment-" + randomizer.StringRunes(10)
+		uuid = "uuid-" + randomizer.StringRunes(10)
+
+		statusManagerFactory = &mocks.StatusManagerFactory{}
+		deployer = &mocks.Deployer{}
+		eventManager = &mocks.EventManager{}
+
+		controller = &StatusController{
+			Log:                  I.DeploymentLogger{Log: I.DefaultLogger(NewBuffer(), logging.DEBUG, "statuscontroller_test"), UUID: uuid},
+			Deployer:             deployer,
+			StatusManagerFactory: statusManagerFactory,
+			EventManager:         eventManager,
+			Config: config.Config{
+				Environments: map[string]structs.Environment{
+					environment: {Name: environment, Domain: "example.com", Foundations: []string{"foundation-1"}},
+				},
+			},
+		}
+
+		deployment = &I.Deployment{
+			CFContext: I.CFContext{
+				Environment:  environment,
+				Organization: "myOrg",
+				Space:        "mySpace",
+				Application:  "myApp",
+			},
+		}
+	})
+
+	It("reads the status of the application on every foundation", func() {
+		deployer.DeployCall.Returns.StatusCode = http.StatusOK
+
+		report, deployResponse := controller.AppStatus(deployment, response)
+
+		Expect(deployResponse.StatusCode).To(Equal(http.StatusOK))
+		Expect(deployer.DeployCall.Called).To(Equal(1))
+		Expect(deployer.DeployCall.Received.Env.Foundations).To(Equal([]string{"foundation-1"}))
+
+		deploymentInfo := statusManagerFactory.StatusManagerCall.Received.DeployEventData.DeploymentInfo
+		Expect(deploymentInfo.AppName).To(Equal("myApp"))
+		Expect(deploymentInfo.UUID).To(Equal(uuid))
+		Expect(statusManagerFactory.StatusManagerCall.Received.Report).ToNot(BeNil())
+
+		Expect(report.Environment).To(Equal(environment))
+		Expect(report.Organization).To(Equal("myOrg"))
+		Expect(report.Space).To(Equal("mySpace"))
+		Expect(report.Application).To(Equal("myApp"))
+	})
+
+	It("returns an error when the environment is not found", func() {
+		deployment.CFContext.Environment = "unknown"
+
+		_, deployResponse := controller.AppStatus(deployment, response)
+
+		Expect(deployResponse.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(deployResponse.Error).To(MatchError(D.EnvironmentNotFoundError{Environment: "unknown"}))
+		Expect(deployer.DeployCall.Called).To(Equal(0))
+	})
+
+	It("returns unauthorized when the environment requires credentials", func() {
+		env := controller.Config.Environments[environment]
+		env.Authenticate = true
+		controller.Config.Environments[environment] = env
+
+		_, deployResponse := controller.AppStatus(deployment, response)
+
+		Expect(deployResponse.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(deployResponse.Error).To(MatchError(D.BasicAuthError{}))
+	})
+
+	Describe("Drift", func() {
+		It("reads the environment variables too", func() {
+			controller.Drift(deployment, response)
+
+			Expect(deployer.DeployCall.Called).To(Equal(1))
+			deploymentInfo := statusManagerFactory.StatusManagerCall.Received.DeployEventData.DeploymentInfo
+			Expect(deploymentInfo.Data).To(HaveKeyWithValue("environment_variables", true))
+		})
+
+		It("emits a DriftDetectedEvent when the foundations differ", func() {
+			controller.Deployer = reportingDeployer{
+				factory: statusManagerFactory,
+				foundations: []structs.FoundationReport{
+					{FoundationURL: "foundation-1", Exists: true, State: "started", Instances: 2},
+					{FoundationURL: "foundation-2", Exists: true, State: "started", Instances: 3},
+					{FoundationURL: "foundation-3", Error: "bork"},
+				},
+			}
+
+			report, deployResponse := controller.Drift(deployment, response)
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusOK))
+			Expect(report.Drift).To(BeTrue())
+			Expect(report.Application).To(Equal("myApp"))
+			Expect(report.Unread).To(Equal(map[string]string{"foundation-3": "bork"}))
+			Expect(report.Differences).To(Equal([]structs.Difference{
+				{Property: "instances", Values: map[string]string{"foundation-1": "2", "foundation-2": "3"}},
+			}))
+
+			Expect(eventManager.EmitEventCall.Received.Events).To(HaveLen(1))
+			event := eventManager.EmitEventCall.Received.Events[0].(DriftDetectedEvent)
+			Expect(event.Report).To(Equal(report))
+			Expect(event.CFContext.Application).To(Equal("myApp"))
+		})
+
+		It("does not emit an event when the foundations are the same", func() {
+			controller.Deployer = reportingDeployer{
+				factory: statusManagerFactory,
+				foundations: []structs.FoundationReport{
+					{FoundationURL: "foundation-1", Exists: true, State: "started", Instances: 2},
+					{FoundationURL: "foundation-2", Exists: true, State: "started", Instances: 2},
+				},
+			}
+
+			report, _ := controller.Drift(deployment, response)
+
+			Expect(report.Drift).To(BeFalse())
+			Expect(report.Differences).To(BeEmpty())
+			Expect(eventManager.EmitEventCall.Received.Events).To(BeEmpty())
+		})
+	})
+})
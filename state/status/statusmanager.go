@@ -0,0 +1,148 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/compozed/deployadactyl/controller/deployer"
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+type courierCreator interface {
+	CreateCourier(ctx context.Context) (I.Courier, error)
+}
+
+// StatusManager creates an Inspector for every foundation and gathers what they read into Report.
+type StatusManager struct {
+	CourierCreator  courierCreator
+	Logger          I.DeploymentLogger
+	DeployEventData S.DeployEventData
+	Report          *S.StatusReport
+
+	foundations []*S.FoundationReport
+}
+
+func (a *StatusManager) SetUp() error {
+	return nil
+}
+
+func (a *StatusManager) OnStart() error {
+	return nil
+}
+
+func (a *StatusManager) OnFinish(env S.Environment, response io.ReadWriter, err error) I.DeployResponse {
+	if err != nil {
+		fmt.Fprintf(response, "\nThe status of your application could not be read on all foundations: %s\n\n", err.Error())
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+
+	a.Report.Foundations = make([]S.FoundationReport, 0, len(a.foundations))
+	for _, foundation := range a.foundations {
+		a.Report.Foundations = append(a.Report.Foundations, *foundation)
+	}
+	a.Report.Drift = FlagDrift(a.Report.Foundations)
+
+	return I.DeployResponse{StatusCode: http.StatusOK}
+}
+
+func (a *StatusManager) CleanUp() {}
+
+func (a *StatusManager) Create(ctx context.Context, environment S.Environment, response io.ReadWriter, foundationURL string) (I.Action, error) {
+	courier, err := a.CourierCreator.CreateCourier(ctx)
+	if err != nil {
+		a.Logger.Error(err)
+		return &Inspector{}, state.CourierCreationError{Err: err}
+	}
+
+	report := &S.FoundationReport{FoundationURL: foundationURL}
+	a.foundations = append(a.foundations, report)
+
+	username, password, clientCredentials := state.Login(*a.DeployEventData.DeploymentInfo, environment, foundationURL)
+
+	r := &Inspector{
+		Courier: courier,
+		CFContext: I.CFContext{
+			Environment:       environment.Name,
+			Organization:      a.DeployEventData.DeploymentInfo.Org,
+			Space:             a.DeployEventData.DeploymentInfo.Space,
+			Application:       a.DeployEventData.DeploymentInfo.AppName,
+			SkipSSL:           a.DeployEventData.DeploymentInfo.SkipSSL,
+			ClientCredentials: clientCredentials,
+		},
+		Authorization: I.Authorization{
+			Username: username,
+			Password: password,
+		},
+		Response:      response,
+		Log:           a.Logger,
+		FoundationURL: foundationURL,
+		AppName:       a.DeployEventData.DeploymentInfo.AppName,
+		Report:        report,
+
+		ReadEnvironment: a.DeployEventData.DeploymentInfo.Data["environment_variables"] == true,
+	}
+
+	return r, nil
+}
+
+func (a *StatusManager) InitiallyError(initiallyErrors []error) error {
+	return bluegreen.LoginError{LoginErrors: initiallyErrors}
+}
+
+func (a *StatusManager) ExecuteError(executeErrors []error) error {
+	return bluegreen.StatusError{Errors: executeErrors}
+}
+
+func (a *StatusManager) UndoError(executeErrors, undoErrors []error) error {
+	return bluegreen.StatusError{Errors: executeErrors}
+}
+
+func (a *StatusManager) SuccessError(successErrors []error) error {
+	return bluegreen.StatusError{Errors: successErrors}
+}
+
+type appState struct {
+	exists    bool
+	state     string
+	instances uint16
+}
+
+// FlagDrift flags every foundation where the application is not in the state most foundations agree on,
+// and returns whether any foundation was flagged. Foundations that could not be read are left out.
+func FlagDrift(foundations []S.FoundationReport) bool {
+	counts := map[appState]int{}
+	var common appState
+	for _, foundation := range foundations {
+		if foundation.Error != "" {
+			continue
+		}
+
+		s := appState{foundation.Exists, foundation.State, foundation.Instances}
+		counts[s]++
+		if counts[s] > counts[common] {
+			common = s
+		}
+	}
+
+	drift := false
+	for i, foundation := range foundations {
+		if foundation.Error != "" {
+			continue
+		}
+
+		if (appState{foundation.Exists, foundation.State, foundation.Instances}) != common {
+			foundations[i].Drifted = true
+			drift = true
+		}
+	}
+
+	return drift
+}
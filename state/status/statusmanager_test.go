@@ -0,0 +1,107 @@
+package status_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/mocks"
+	. "github.com/compozed/deployadactyl/state/status"
+	S "github.com/compozed/deployadactyl/structs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/op/go-logging"
+)
+
+type courierCreator struct {
+	courier I.Courier
+	err     error
+}
+
+func (c courierCreator) CreateCourier(ctx context.Context) (I.Courier, error) {
+	return c.courier, c.err
+}
+
+var _ = Describe("StatusManager", func() {
+	var (
+		manager  *StatusManager
+		courier  *mocks.Courier
+		report   *S.StatusReport
+		response *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+		report = &S.StatusReport{}
+		response = &bytes.Buffer{}
+
+		manager = &StatusManager{
+			CourierCreator: courierCreator{courier: courier},
+			Logger:         I.DeploymentLogger{Log: I.DefaultLogger(NewBuffer(), logging.DEBUG, "statusmanager_test")},
+			DeployEventData: S.DeployEventData{
+				DeploymentInfo: &S.DeploymentInfo{Org: "org", Space: "space", AppName: "myApp"},
+			},
+			Report: report,
+		}
+	})
+
+	It("gathers the report of every foundation", func() {
+		courier.ExistsCall.Returns.Bool = true
+		courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 2, Running: 2}
+
+		for _, foundationURL := range []string{"foundation-1", "foundation-2"} {
+			action, err := manager.Create(context.Background(), S.Environment{}, response, foundationURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(action.(*Inspector).AppName).To(Equal("myApp"))
+			Expect(action.Execute()).To(Succeed())
+		}
+
+		deployResponse := manager.OnFinish(S.Environment{}, response, nil)
+
+		Expect(deployResponse.StatusCode).To(Equal(http.StatusOK))
+		Expect(report.Drift).To(BeFalse())
+		Expect(report.Foundations).To(HaveLen(2))
+		Expect(report.Foundations[0].FoundationURL).To(Equal("foundation-1"))
+		Expect(report.Foundations[1].FoundationURL).To(Equal("foundation-2"))
+		Expect(report.Foundations[1].State).To(Equal("started"))
+	})
+
+	It("returns a bad request when login fails", func() {
+		deployResponse := manager.OnFinish(S.Environment{}, response, bluegreen.LoginError{LoginErrors: []error{errors.New("bork")}})
+
+		Expect(deployResponse.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(response.String()).To(ContainSubstring("could not be read on all foundations"))
+	})
+
+	Describe("FlagDrift", func() {
+		It("flags the foundations that differ from most of the others", func() {
+			foundations := []S.FoundationReport{
+				{FoundationURL: "foundation-1", Exists: true, State: "started", Instances: 2},
+				{FoundationURL: "foundation-2", Exists: true, State: "stopped", Instances: 2},
+				{FoundationURL: "foundation-3", Exists: true, State: "started", Instances: 2},
+				{FoundationURL: "foundation-4", Exists: false},
+			}
+
+			Expect(FlagDrift(foundations)).To(BeTrue())
+
+			Expect(foundations[0].Drifted).To(BeFalse())
+			Expect(foundations[1].Drifted).To(BeTrue())
+			Expect(foundations[2].Drifted).To(BeFalse())
+			Expect(foundations[3].Drifted).To(BeTrue())
+		})
+
+		It("does not compare the foundations that could not be read", func() {
+			foundations := []S.FoundationReport{
+				{FoundationURL: "foundation-1", Exists: true, State: "started", Instances: 2},
+				{FoundationURL: "foundation-2", Error: "bork"},
+			}
+
+			Expect(FlagDrift(foundations)).To(BeFalse())
+			Expect(foundations[1].Drifted).To(BeFalse())
+		})
+	})
+})
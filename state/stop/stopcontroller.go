@@ -4,17 +4,18 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/compozed/deployadactyl/config"
+	C "github.com/compozed/deployadactyl/constants"
 	"github.com/compozed/deployadactyl/controller/deployer"
 	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
 	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/state"
 	"github.com/compozed/deployadactyl/structs"
 	"io"
-	"net/http"
 )
 
-type StopControllerConstructor func(log I.DeploymentLogger, deployer I.Deployer, conf config.Config, eventManager I.EventManager, errorFinder I.ErrorFinder, startManagerFactory I.StartManagerFactory) I.StopController
+type StopControllerConstructor func(log I.DeploymentLogger, deployer I.Deployer, conf config.Config, eventManager I.EventManager, errorFinder I.ErrorFinder, startManagerFactory I.StartManagerFactory, locker I.Locker) I.StopController
 
-func NewStopController(l I.DeploymentLogger, d I.Deployer, c config.Config, em I.EventManager, ef I.ErrorFinder, smf I.StopManagerFactory) I.StopController {
+func NewStopController(l I.DeploymentLogger, d I.Deployer, c config.Config, em I.EventManager, ef I.ErrorFinder, smf I.StopManagerFactory, lk I.Locker) I.StopController {
 	return &StopController{
 		Deployer:           d,
 		Config:             c,
@@ -22,6 +23,7 @@ func NewStopController(l I.DeploymentLogger, d I.Deployer, c config.Config, em I
 		ErrorFinder:        ef,
 		StopManagerFactory: smf,
 		Log:                l,
+		Locker:             lk,
 	}
 }
 
@@ -32,6 +34,7 @@ type StopController struct {
 	Config             config.Config
 	EventManager       I.EventManager
 	ErrorFinder        I.ErrorFinder
+	Locker             I.Locker
 }
 
 func (c *StopController) StopDeployment(deployment *I.Deployment, data map[string]interface{}, response *bytes.Buffer) (deployResponse I.DeployResponse) {
@@ -46,32 +49,51 @@ func (c *StopController) StopDeployment(deployment *I.Deployment, data map[strin
 	if err != nil {
 		fmt.Fprintln(response, err.Error())
 		return I.DeployResponse{
-			StatusCode: http.StatusInternalServerError,
+			StatusCode: deployer.StatusCode(err),
 			Error:      err,
 		}
 	}
-	auth, err := c.resolveAuthorization(deployment.Authorization, environment, c.Log)
+	auth, err := state.ResolveAuthorization(deployment.Authorization, environment, c.Config, c.Log)
 	if err != nil {
 		return I.DeployResponse{
-			StatusCode: http.StatusUnauthorized,
+			StatusCode: deployer.StatusCode(err),
 			Error:      err,
 		}
 	}
 
 	deploymentInfo := &structs.DeploymentInfo{
-		Org:          cf.Organization,
-		Space:        cf.Space,
-		AppName:      cf.Application,
-		Environment:  cf.Environment,
-		UUID:         c.Log.UUID,
-		Domain:       environment.Domain,
-		SkipSSL:      environment.SkipSSL,
-		CustomParams: environment.CustomParams,
-		Username:     auth.Username,
-		Password:     auth.Password,
-		Data:         data,
+		Org:               cf.Organization,
+		Space:             cf.Space,
+		AppName:           cf.Application,
+		Environment:       cf.Environment,
+		UUID:              c.Log.UUID,
+		Domain:            environment.Domain,
+		SkipSSL:           environment.SkipSSL,
+		ClientCredentials: environment.ClientCredentials,
+		ServiceAccount:    deployment.Authorization.Username == "" && deployment.Authorization.Password == "",
+		CustomParams:      environment.CustomParams,
+		Username:          auth.Username,
+		Password:          auth.Password,
+		Data:              data,
 	}
 
+	if deployment.DryRun {
+		c.Log.Debugf("planning the stop of %s", cf.Application)
+		deploymentInfo.DryRun = true
+		manager := c.StopManagerFactory.StopManager(c.Log, structs.DeployEventData{Response: response, DeploymentInfo: deploymentInfo})
+		return *c.Deployer.Deploy(deployment.Context, deploymentInfo, environment, manager, response)
+	}
+
+	unlock, err := c.Locker.Lock(deployment.Context, cf, I.LockHolder{UUID: c.Log.UUID, Type: C.DeploymentTypeStop, User: auth.Username})
+	if err != nil {
+		fmt.Fprintln(response, err.Error())
+		return I.DeployResponse{
+			StatusCode: deployer.StatusCode(err),
+			Error:      err,
+		}
+	}
+	defer unlock()
+
 	defer c.emitStopFinish(response, c.Log, cf, &auth, &environment, data, &deployResponse)
 	defer c.emitStopSuccessOrFailure(response, c.Log, cf, &auth, &environment, data, &deployResponse)
 
@@ -85,10 +107,11 @@ func (c *StopController) StopDeployment(deployment *I.Deployment, data map[strin
 	})
 	if err != nil {
 		c.Log.Error(err)
-		err = &bluegreen.InitializationError{err}
+		err = bluegreen.InitializationError{Err: err}
+		err = deployer.EventError{Type: "StopStartedEvent", Err: err}
 		return I.DeployResponse{
-			StatusCode:     http.StatusInternalServerError,
-			Error:          deployer.EventError{Type: "StopStartedEvent", Err: err},
+			StatusCode:     deployer.StatusCode(err),
+			Error:          err,
 			DeploymentInfo: deploymentInfo,
 		}
 	}
@@ -96,7 +119,7 @@ func (c *StopController) StopDeployment(deployment *I.Deployment, data map[strin
 	deployEventData := structs.DeployEventData{Response: response, DeploymentInfo: deploymentInfo}
 
 	manager := c.StopManagerFactory.StopManager(c.Log, deployEventData)
-	return *c.Deployer.Deploy(deploymentInfo, environment, manager, response)
+	return *c.Deployer.Deploy(deployment.Context, deploymentInfo, environment, manager, response)
 }
 
 func (c StopController) emitStopFinish(response io.ReadWriter, deploymentLogger I.DeploymentLogger, cfContext I.CFContext, auth *I.Authorization, environment *structs.Environment, data map[string]interface{}, deployResponse *I.DeployResponse) {
@@ -117,7 +140,7 @@ func (c StopController) emitStopSuccessOrFailure(response io.ReadWriter, deploym
 	var event I.IEvent
 
 	if deployResponse.Error != nil {
-		c.printErrors(response, &deployResponse.Error)
+		state.PrintErrors(response, c.ErrorFinder)
 		event = StopFailureEvent{
 			CFContext:     cfContext,
 			Authorization: *auth,
@@ -146,43 +169,6 @@ func (c StopController) emitStopSuccessOrFailure(response io.ReadWriter, deploym
 	}
 }
 
-func (c StopController) printErrors(response io.ReadWriter, err *error) {
-	tempBuffer := bytes.Buffer{}
-	tempBuffer.ReadFrom(response)
-	fmt.Fprint(response, tempBuffer.String())
-
-	errors := c.ErrorFinder.FindErrors(tempBuffer.String())
-	if len(errors) > 0 {
-		*err = errors[0]
-		for _, error := range errors {
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "*******************")
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "The following error was found in the above logs: "+error.Error())
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "Error: "+error.Details()[0])
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "Potential solution: "+error.Solution())
-			fmt.Fprintln(response)
-			fmt.Fprintln(response, "*******************")
-		}
-	}
-}
-
-func (c *StopController) resolveAuthorization(auth I.Authorization, envs structs.Environment, deploymentLogger I.DeploymentLogger) (I.Authorization, error) {
-	config := c.Config
-	deploymentLogger.Debug("checking for basic auth")
-	if auth.Username == "" && auth.Password == "" {
-		if envs.Authenticate {
-			return I.Authorization{}, deployer.BasicAuthError{}
-		}
-		auth.Username = config.Username
-		auth.Password = config.Password
-	}
-
-	return auth, nil
-}
-
 func (c *StopController) resolveEnvironment(env string) (structs.Environment, error) {
 	config := c.Config
 	environment, ok := config.Environments[env]
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"github.com/compozed/deployadactyl/config"
+	C "github.com/compozed/deployadactyl/constants"
 	D "github.com/compozed/deployadactyl/controller/deployer"
 	"github.com/compozed/deployadactyl/controller/deployer/error_finder"
 	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/locker"
 	"github.com/compozed/deployadactyl/mocks"
 	"github.com/compozed/deployadactyl/randomizer"
 	. "github.com/compozed/deployadactyl/state/stop"
@@ -27,6 +29,7 @@ var _ = Describe("StopDeployment", func() {
 		stopManagerFactory *mocks.StopManagerFactory
 		eventManager       *mocks.EventManager
 		errorFinder        *mocks.ErrorFinder
+		appLocker          *mocks.Locker
 		controller         *StopController
 		deployment         I.Deployment
 		logBuffer          *Buffer
@@ -51,6 +54,7 @@ var _ = Describe("StopDeployment", func() {
 
 		stopManagerFactory = &mocks.StopManagerFactory{}
 		errorFinder = &mocks.ErrorFinder{}
+		appLocker = &mocks.Locker{}
 		controller = &StopController{
 			Deployer:           deployer,
 			Log:                I.DeploymentLogger{Log: I.DefaultLogger(logBuffer, logging.DEBUG, "api_test"), UUID: randomizer.StringRunes(10)},
@@ -58,6 +62,7 @@ var _ = Describe("StopDeployment", func() {
 			EventManager:       eventManager,
 			Config:             config.Config{},
 			ErrorFinder:        errorFinder,
+			Locker:             appLocker,
 		}
 		environments := map[string]structs.Environment{}
 		environments[environment] = structs.Environment{}
@@ -122,6 +127,69 @@ var _ = Describe("StopDeployment", func() {
 		Expect(logBuffer).Should(Say(fmt.Sprintf("Preparing to stop %s with UUID %s", "myApp", deploymentResponse.DeploymentInfo.UUID)))
 
 	})
+	Context("when the application is not locked", func() {
+		It("locks the application while it runs", func() {
+			deployment := &I.Deployment{
+				Body: &[]byte{},
+				CFContext: I.CFContext{
+					Environment:  environment,
+					Organization: org,
+					Space:        space,
+					Application:  appName,
+				},
+				Authorization: I.Authorization{Username: "username", Password: "password"},
+			}
+
+			controller.StopDeployment(deployment, nil, response)
+
+			Expect(appLocker.LockCall.Received.CFContext).To(Equal(deployment.CFContext))
+			Expect(appLocker.LockCall.Received.Holder.UUID).To(Equal(controller.Log.UUID))
+			Expect(appLocker.LockCall.Received.Holder.Type).To(Equal(C.DeploymentTypeStop))
+			Expect(appLocker.LockCall.Received.Holder.User).To(Equal("username"))
+			Expect(appLocker.LockCall.Unlocked).To(BeTrue())
+		})
+	})
+
+	Context("when it is a dry run", func() {
+		It("plans the stop without locking the application or emitting events", func() {
+			deployment := &I.Deployment{
+				Body: &[]byte{},
+				CFContext: I.CFContext{
+					Environment: environment,
+					Application: appName,
+				},
+				DryRun: true,
+			}
+
+			controller.StopDeployment(deployment, nil, response)
+
+			Expect(deployer.DeployCall.Called).To(Equal(1))
+			Expect(deployer.DeployCall.Received.DeploymentInfo.DryRun).To(BeTrue())
+			Expect(appLocker.LockCall.Received.Holder.UUID).To(BeEmpty())
+			Expect(eventManager.EmitCall.TimesCalled).To(BeZero())
+			Expect(eventManager.EmitEventCall.TimesCalled).To(BeZero())
+		})
+	})
+
+	Context("when the application is locked", func() {
+		It("returns http.StatusConflict without deploying", func() {
+			appLocker.LockCall.Returns.Error = locker.LockedError{Holder: I.LockHolder{UUID: "another uuid"}}
+			deployment := &I.Deployment{
+				Body: &[]byte{},
+				CFContext: I.CFContext{
+					Environment: environment,
+					Application: appName,
+				},
+			}
+
+			deployResponse := controller.StopDeployment(deployment, nil, response)
+
+			Expect(deployResponse.StatusCode).To(Equal(http.StatusConflict))
+			Expect(deployResponse.Error).To(MatchError(appLocker.LockCall.Returns.Error))
+			Expect(deployer.DeployCall.Called).To(Equal(0))
+		})
+	})
+
 	Context("When StopStartEvent succeeds", func() {
 		It("should emit a StopStarteEvent", func() {
 			deployment := &I.Deployment{
@@ -197,6 +265,23 @@ var _ = Describe("StopDeployment", func() {
 			Expect(deploymentResponse.DeploymentInfo.SkipSSL).Should(Equal(true))
 			Expect(deploymentResponse.DeploymentInfo.CustomParams["customName"]).Should(Equal("customParams"))
 		})
+
+		It("Should use the service account only when no credentials were given", func() {
+			controller.Config.Environments[environment] = structs.Environment{}
+
+			deployment := &I.Deployment{
+				CFContext: I.CFContext{
+					Environment: environment,
+				}}
+
+			deploymentResponse := controller.StopDeployment(deployment, nil, bytes.NewBuffer([]byte{}))
+			Expect(deploymentResponse.DeploymentInfo.ServiceAccount).Should(BeTrue())
+
+			deployment.Authorization = I.Authorization{Username: "bob", Password: "password"}
+
+			deploymentResponse = controller.StopDeployment(deployment, nil, bytes.NewBuffer([]byte{}))
+			Expect(deploymentResponse.DeploymentInfo.ServiceAccount).Should(BeFalse())
+		})
 	})
 	Context("When auth does not exist", func() {
 		Context("When environment authenticate is true", func() {
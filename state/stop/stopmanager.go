@@ -1,14 +1,15 @@
 package stop
 
 import (
+	"context"
 	"fmt"
+	"github.com/compozed/deployadactyl/controller/deployer"
 	"github.com/compozed/deployadactyl/controller/deployer/bluegreen"
 	I "github.com/compozed/deployadactyl/interfaces"
 	"github.com/compozed/deployadactyl/state"
 	S "github.com/compozed/deployadactyl/structs"
 	"io"
 	"net/http"
-	"regexp"
 )
 
 const successfulStop = `Your stop was successful! (^_^)b
@@ -16,7 +17,7 @@ const successfulStop = `Your stop was successful! (^_^)b
 `
 
 type courierCreator interface {
-	CreateCourier() (I.Courier, error)
+	CreateCourier(ctx context.Context) (I.Courier, error)
 }
 
 type StopManager struct {
@@ -41,15 +42,8 @@ func (a StopManager) OnStart() error {
 func (a StopManager) OnFinish(env S.Environment, response io.ReadWriter, err error) I.DeployResponse {
 	if err != nil {
 		fmt.Fprintf(response, "\nYour application was not successfully stopped on all foundations: %s\n\n", err.Error())
-		if matched, _ := regexp.MatchString("login failed", err.Error()); matched {
-			return I.DeployResponse{
-				StatusCode: http.StatusBadRequest,
-				Error:      err,
-			}
-		}
-
 		return I.DeployResponse{
-			StatusCode: http.StatusInternalServerError,
+			StatusCode: deployer.StatusCode(err),
 			Error:      err,
 		}
 	}
@@ -62,30 +56,36 @@ func (a StopManager) OnFinish(env S.Environment, response io.ReadWriter, err err
 
 func (a StopManager) CleanUp() {}
 
-func (a StopManager) Create(environment S.Environment, response io.ReadWriter, foundationURL string) (I.Action, error) {
-	courier, err := a.CourierCreator.CreateCourier()
+func (a StopManager) Create(ctx context.Context, environment S.Environment, response io.ReadWriter, foundationURL string) (I.Action, error) {
+	courier, err := a.CourierCreator.CreateCourier(ctx)
 	if err != nil {
 		a.Log.Error(err)
 		return &Stopper{}, state.CourierCreationError{Err: err}
 	}
+
+	username, password, clientCredentials := state.Login(*a.DeployEventData.DeploymentInfo, environment, foundationURL)
+
 	p := &Stopper{
 		Courier: courier,
 		CFContext: I.CFContext{
-			Environment:  environment.Name,
-			Organization: a.DeployEventData.DeploymentInfo.Org,
-			Space:        a.DeployEventData.DeploymentInfo.Space,
-			Application:  a.DeployEventData.DeploymentInfo.AppName,
-			SkipSSL:      a.DeployEventData.DeploymentInfo.SkipSSL,
+			Environment:       environment.Name,
+			Organization:      a.DeployEventData.DeploymentInfo.Org,
+			Space:             a.DeployEventData.DeploymentInfo.Space,
+			Application:       a.DeployEventData.DeploymentInfo.AppName,
+			SkipSSL:           a.DeployEventData.DeploymentInfo.SkipSSL,
+			ClientCredentials: clientCredentials,
 		},
 		Authorization: I.Authorization{
-			Username: a.DeployEventData.DeploymentInfo.Username,
-			Password: a.DeployEventData.DeploymentInfo.Password,
+			Username: username,
+			Password: password,
 		},
 		EventManager:  a.EventManager,
 		Response:      response,
 		Log:           a.Log,
 		FoundationURL: foundationURL,
 		AppName:       a.DeployEventData.DeploymentInfo.AppName,
+		Context:       ctx,
+		Verification:  state.DefaultVerification,
 	}
 
 	return p, nil
@@ -1,6 +1,7 @@
 package stop_test
 
 import (
+	"context"
 	"github.com/compozed/deployadactyl/state/stop"
 	"github.com/compozed/deployadactyl/structs"
 	. "github.com/onsi/ginkgo"
@@ -22,7 +23,7 @@ import (
 type courierCreator struct {
 	CourierCreatorFn func() (interfaces.Courier, error)
 }
-func (c courierCreator) CreateCourier() (interfaces.Courier, error) {
+func (c courierCreator) CreateCourier(ctx context.Context) (interfaces.Courier, error) {
 	if c.CourierCreatorFn != nil {
 		return c.CourierCreatorFn()
 	}
@@ -65,7 +66,7 @@ var _ = Describe("Stopmanager", func() {
 			It("should return a Stopper object", func() {
 				env := structs.Environment{}
 				foundationURL := "foundation url"
-				stopper, _ := stopManager.Create(env, response, foundationURL)
+				stopper, _ := stopManager.Create(context.Background(), env, response, foundationURL)
 
 				Expect(reflect.TypeOf(stopper)).Should(Equal(reflect.TypeOf(&stop.Stopper{})))
 
@@ -81,7 +82,7 @@ var _ = Describe("Stopmanager", func() {
 					Password: "password",
 				}
 				*stopManager.(stop.StopManager).DeployEventData.DeploymentInfo = deploymentInfo
-				stopper, _ := stopManager.Create(env, response, foundationURL)
+				stopper, _ := stopManager.Create(context.Background(), env, response, foundationURL)
 
 				stopperData := stopper.(*stop.Stopper)
 				Expect(stopperData.CFContext.Application).Should(Equal("myApp"))
@@ -91,6 +92,34 @@ var _ = Describe("Stopmanager", func() {
 				Expect(stopperData.FoundationURL).Should(Equal(foundationURL))
 
 			})
+			It("should log in with the credentials configured for the foundation when none were given", func() {
+				env := structs.Environment{
+					Credentials: structs.Credentials{Username: "environment-user", Password: "environment-password"},
+					FoundationCredentials: map[string]structs.Credentials{
+						"foundation url": {ClientID: "client", ClientSecret: "secret"},
+					},
+				}
+				*stopManager.(stop.StopManager).DeployEventData.DeploymentInfo = structs.DeploymentInfo{ServiceAccount: true}
+
+				stopper, _ := stopManager.Create(context.Background(), env, response, "foundation url")
+				stopperData := stopper.(*stop.Stopper)
+				Expect(stopperData.Authorization).Should(Equal(interfaces.Authorization{Username: "client", Password: "secret"}))
+				Expect(stopperData.CFContext.ClientCredentials).Should(BeTrue())
+
+				stopper, _ = stopManager.Create(context.Background(), env, response, "another foundation url")
+				stopperData = stopper.(*stop.Stopper)
+				Expect(stopperData.Authorization).Should(Equal(interfaces.Authorization{Username: "environment-user", Password: "environment-password"}))
+				Expect(stopperData.CFContext.ClientCredentials).Should(BeFalse())
+			})
+			It("should log in with the credentials of the request when they were given", func() {
+				env := structs.Environment{
+					Credentials: structs.Credentials{Username: "environment-user", Password: "environment-password"},
+				}
+				*stopManager.(stop.StopManager).DeployEventData.DeploymentInfo = structs.DeploymentInfo{Username: "bob", Password: "password"}
+
+				stopper, _ := stopManager.Create(context.Background(), env, response, "foundation url")
+				Expect(stopper.(*stop.Stopper).Authorization).Should(Equal(interfaces.Authorization{Username: "bob", Password: "password"}))
+			})
 		})
 
 		Context("when courier build failed", func() {
@@ -101,7 +130,7 @@ var _ = Describe("Stopmanager", func() {
 
 				env := structs.Environment{}
 				foundationURL := "foundation url"
-				_, err := stopManager.Create(env, response, foundationURL)
+				_, err := stopManager.Create(context.Background(), env, response, foundationURL)
 				Expect(err).ShouldNot(BeNil())
 				Expect(err.Error()).Should(ContainSubstring("a test error"))
 
@@ -132,7 +161,7 @@ var _ = Describe("Stopmanager", func() {
 		Context("when an error occurs", func() {
 			Context("and it is a log in error", func() {
 				It("returns a http status bad request", func() {
-					deployResponse := stopManager.OnFinish(structs.Environment{}, response, errors.New("login failed"))
+					deployResponse := stopManager.OnFinish(structs.Environment{}, response, bluegreen.LoginError{LoginErrors: []error{errors.New("bad credentials")}})
 
 					Expect(deployResponse.StatusCode).To(Equal(http.StatusBadRequest))
 				})
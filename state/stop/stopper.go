@@ -1,6 +1,7 @@
 package stop
 
 import (
+	"context"
 	I "github.com/compozed/deployadactyl/interfaces"
 	"github.com/compozed/deployadactyl/state"
 	"io"
@@ -15,9 +16,22 @@ type Stopper struct {
 	Log           I.DeploymentLogger
 	FoundationURL string
 	AppName       string
+	Context       context.Context
+	Verification  state.Verification
 }
 
+// Verify checks that the application is stopped.
 func (s Stopper) Verify() error {
+	s.Log.Debugf("verifying that %s is stopped", s.AppName)
+
+	_, err := s.Verification.WaitForAppState(s.Context, s.Courier, s.AppName, "stopped")
+	if err != nil {
+		s.Log.Errorf("failed to verify app on foundation %s: %s", s.FoundationURL, err)
+		return err
+	}
+
+	s.Log.Infof("verified that %s is stopped", s.AppName)
+
 	return nil
 }
 
@@ -47,6 +61,7 @@ func (s Stopper) Initially() error {
 		s.CFContext.Organization,
 		s.CFContext.Space,
 		s.CFContext.SkipSSL,
+		s.CFContext.ClientCredentials,
 	)
 	s.Response.Write(output)
 	if err != nil {
@@ -80,6 +95,18 @@ func (s Stopper) Execute() error {
 	return nil
 }
 
+// Plan returns the steps Execute and Verify would take on the foundation. The application has to exist.
+func (s Stopper) Plan() (I.FoundationPlan, error) {
+	if !s.Courier.Exists(s.AppName) {
+		return I.FoundationPlan{}, state.ExistsError{ApplicationName: s.AppName}
+	}
+
+	return I.FoundationPlan{
+		AppExists: true,
+		Steps:     []string{"stop " + s.AppName, "verify " + s.AppName + " is stopped"},
+	}, nil
+}
+
 func (s Stopper) Undo() error {
 
 	if s.Courier.Exists(s.AppName) != true {
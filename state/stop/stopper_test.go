@@ -199,6 +199,31 @@ var _ = Describe("Stopper", func() {
 		})
 	})
 
+	Describe("Plan", func() {
+		It("plans to stop the application", func() {
+			courier.ExistsCall.Returns.Bool = true
+
+			plan, err := stopper.Plan()
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan).To(Equal(interfaces.FoundationPlan{
+				AppExists: true,
+				Steps:     []string{"stop " + randomAppName, "verify " + randomAppName + " is stopped"},
+			}))
+			Expect(courier.StopCall.Received.AppName).To(BeEmpty())
+		})
+
+		Context("when the app does not exist", func() {
+			It("returns an error", func() {
+				courier.ExistsCall.Returns.Bool = false
+
+				_, err := stopper.Plan()
+
+				Expect(err).To(MatchError(state.ExistsError{ApplicationName: randomAppName}))
+			})
+		})
+	})
+
 	Describe("Undo", func() {
 		Context("when the app does not exist", func() {
 			It("return without error", func() {
@@ -237,8 +262,25 @@ var _ = Describe("Stopper", func() {
 	})
 
 	Describe("Verify", func() {
-		It("returns nil", func() {
-			Expect(stopper.Verify()).To(BeNil())
+		BeforeEach(func() {
+			stopper.Verification = state.Verification{Attempts: 10}
+		})
+
+		It("succeeds when the app is stopped", func() {
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "stopped", Instances: 2}
+
+			Expect(stopper.Verify()).To(Succeed())
+			Expect(courier.AppStatusCall.Received.AppName).To(Equal(randomAppName))
+
+			Eventually(logBuffer).Should(Say(fmt.Sprintf("verified that %s is stopped", randomAppName)))
+		})
+
+		It("returns an error when the app is still started", func() {
+			courier.AppStatusCall.Returns.Status = S.AppStatus{State: "started", Instances: 2, Running: 2}
+
+			err := stopper.Verify()
+
+			Expect(err).To(MatchError(state.AppStateError{ApplicationName: randomAppName, RequestedState: "stopped", State: "started"}))
 		})
 	})
 
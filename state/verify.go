@@ -0,0 +1,83 @@
+package state
+
+import (
+	"context"
+	"time"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	S "github.com/compozed/deployadactyl/structs"
+)
+
+// Verification is how many times and how far apart the status of an application is looked up
+// while it is still starting, before its verification fails. It is looked up at least once.
+type Verification struct {
+	Attempts int
+	Interval time.Duration
+}
+
+// DefaultVerification is the verification the managers give to their actions.
+var DefaultVerification = Verification{Attempts: 10, Interval: 3 * time.Second}
+
+// Sleep waits for the duration, or until the context of the deployment is done. It returns the error of
+// the context when the wait was cut short. A context that is already done when the wait starts is ignored,
+// the same way the commands that undo a cancelled deployment still run.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if ctx == nil || ctx.Err() != nil {
+		time.Sleep(d)
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// WaitForAppState looks up the status of an application until it has the requested state.
+// A started application also needs all of its instances running. It fails straight away
+// when an instance has crashed, and stops waiting when the context of the deployment is done.
+func (v Verification) WaitForAppState(ctx context.Context, courier I.Courier, appName, requestedState string) (S.AppStatus, error) {
+	var (
+		status S.AppStatus
+		err    error
+	)
+
+	for attempt := 0; attempt == 0 || attempt < v.Attempts; attempt++ {
+		if attempt > 0 {
+			if err = Sleep(ctx, v.Interval); err != nil {
+				return status, err
+			}
+		}
+
+		status, err = courier.AppStatus(appName)
+		if err != nil {
+			return status, err
+		}
+
+		if status.State != requestedState {
+			continue
+		}
+
+		if requestedState != "started" {
+			return status, nil
+		}
+
+		for _, instanceState := range status.InstanceStates {
+			if instanceState == "crashed" || instanceState == "down" {
+				return status, InstancesNotRunningError{ApplicationName: appName, Running: status.Running, Instances: status.Instances}
+			}
+		}
+
+		if status.Instances > 0 && status.Running == status.Instances {
+			return status, nil
+		}
+	}
+
+	if status.State != requestedState {
+		return status, AppStateError{ApplicationName: appName, RequestedState: requestedState, State: status.State}
+	}
+
+	return status, InstancesNotRunningError{ApplicationName: appName, Running: status.Running, Instances: status.Instances}
+}
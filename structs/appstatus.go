@@ -0,0 +1,16 @@
+package structs
+
+// AppStatus is the status of an application as reported by Cloud Foundry.
+type AppStatus struct {
+	// State is the requested state of the application, started or stopped.
+	State     string
+	Instances uint16
+	Running   uint16
+	// Memory is the memory limit of each instance, such as 1G or 512M.
+	Memory string
+	// InstanceStates holds the state of every instance, such as running, starting or crashed.
+	InstanceStates []string
+	Routes         []string
+	Buildpack      string
+	Stack          string
+}
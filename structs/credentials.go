@@ -0,0 +1,11 @@
+package structs
+
+// Credentials are a Cloud Foundry service account, either a user with its password or a UAA client
+// with its secret. Each value can reference a secret as secret://<provider>/<path>#<key>, or an environment
+// variable as ${NAME}.
+type Credentials struct {
+	Username     string
+	Password     string
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+}
@@ -17,6 +17,7 @@ type DeploymentInfo struct {
 	AppName              string
 	UUID                 string
 	SkipSSL              bool
+	ClientCredentials    bool `json:"-"`
 	Instances            uint16
 	Domain               string
 	AppPath              string
@@ -25,6 +26,9 @@ type DeploymentInfo struct {
 	EnvironmentVariables map[string]string `json:"environment_variables"`
 	HealthCheckEndpoint  string            `json:"health_check_endpoint"`
 	CustomParams         map[string]interface{}
+	DryRun               bool `json:"-"`
+	// ServiceAccount is true when the deployment was not given credentials and logs in with the configured ones.
+	ServiceAccount bool `json:"-"`
 
 	// Generic map used for users to provide their own deployment properties in JSON format.
 	Data map[string]interface{} `json:"data"`
@@ -2,12 +2,57 @@ package structs
 
 // Environment is representation of a single environment configuration.
 type Environment struct {
-	Name           string
-	Domain         string
-	Foundations    []string `yaml:",flow"`
-	Authenticate   bool
-	SkipSSL        bool `yaml:"skip_ssl"`
-	Instances      uint16
-	EnableRollback bool                   `yaml:"rollback_enabled"`
-	CustomParams   map[string]interface{} `yaml:"custom_params"`
+	Name                  string
+	Domain                string
+	Foundations           []string `yaml:",flow"`
+	Authenticate          bool
+	SkipSSL               bool                   `yaml:"skip_ssl"`
+	ClientCredentials     bool                   `yaml:"client_credentials"`
+	Credentials           Credentials            `yaml:"credentials"`
+	FoundationCredentials map[string]Credentials `yaml:"foundation_credentials"`
+	Instances             uint16
+	EnableRollback        bool                   `yaml:"rollback_enabled"`
+	KeepVenerable         uint16                 `yaml:"keep_venerable"`
+	Canary                Canary                 `yaml:"canary"`
+	Strategy              string                 `yaml:"strategy"`
+	BatchSize             uint16                 `yaml:"batch_size"`
+	MaxConcurrent         int                    `yaml:"max_concurrent_deployments"`
+	MaxPriority           int                    `yaml:"max_priority"`
+	Timeouts              Timeouts               `yaml:"timeouts"`
+	RouteCheck            RouteCheck             `yaml:"route_check"`
+	CustomParams          map[string]interface{} `yaml:"custom_params"`
+}
+
+// Canary is the configuration of pushes that shift traffic onto the new application in steps.
+type Canary struct {
+	Enabled bool
+	// Steps are the percentages of instances that run the new application, in order.
+	Steps []uint16 `yaml:",flow"`
+	// Checks is how many times the health check endpoint is called on each step.
+	Checks int
+	// Interval is the number of seconds to wait before each health check.
+	Interval int
+}
+
+// Timeouts are the number of seconds each phase of a deployment may take. Zero means no timeout.
+type Timeouts struct {
+	// Login is how long each cf login may take.
+	Login int
+	// Push is how long each cf push may take.
+	Push int
+	// HealthCheck is how long each call to the health check endpoint may take.
+	HealthCheck int `yaml:"health_check"`
+	// Deployment is how long the whole deployment may take, across all foundations.
+	Deployment int
+}
+
+// RouteCheck is how the own route of a new build is called before a push is verified.
+type RouteCheck struct {
+	// Skip turns the route check off.
+	Skip bool
+	// Path is called on the route when the deployment gives no health check endpoint.
+	Path string
+	// StatusCode is the status the route has to return. Without it the route has to return 200
+	// when a path is called, or anything but a server error when it is not.
+	StatusCode int `yaml:"status_code"`
 }
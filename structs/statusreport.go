@@ -0,0 +1,50 @@
+package structs
+
+// StatusReport is the status of an application on every foundation of an environment.
+type StatusReport struct {
+	Environment        string `json:"environment"`
+	Organization       string `json:"organization"`
+	Space              string `json:"space"`
+	Application        string `json:"application"`
+	LastDeploymentUUID string `json:"last_deployment_uuid,omitempty"`
+	// Drift is true when at least one foundation is flagged as drifted.
+	Drift       bool               `json:"drift"`
+	Foundations []FoundationReport `json:"foundations"`
+}
+
+// FoundationReport is the status of an application on a single foundation.
+type FoundationReport struct {
+	FoundationURL string   `json:"foundation_url"`
+	Exists        bool     `json:"exists"`
+	State         string   `json:"state,omitempty"`
+	Instances     uint16   `json:"instances"`
+	Running       uint16   `json:"running"`
+	Routes        []string `json:"routes,omitempty"`
+	Buildpack     string   `json:"buildpack,omitempty"`
+	Stack         string   `json:"stack,omitempty"`
+	// EnvironmentVariables are only read for a drift report and are never returned since they may hold secrets.
+	EnvironmentVariables map[string]string `json:"-"`
+	// Drifted is true when the application is not in the same state, or does not have the same
+	// number of instances, as on most of the other foundations.
+	Drifted bool   `json:"drifted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// DriftReport lists the properties of an application that are not the same on every foundation of an environment.
+type DriftReport struct {
+	Environment  string `json:"environment"`
+	Organization string `json:"organization"`
+	Space        string `json:"space"`
+	Application  string `json:"application"`
+	Drift        bool   `json:"drift"`
+	// Unread holds the error of every foundation that could not be compared, by foundation URL.
+	Unread      map[string]string `json:"unread,omitempty"`
+	Differences []Difference      `json:"differences"`
+}
+
+// Difference is a property of an application and its value on every foundation, by foundation URL.
+// The value of an environment variable is a fingerprint of it rather than the value itself.
+type Difference struct {
+	Property string            `json:"property"`
+	Values   map[string]string `json:"values"`
+}
@@ -0,0 +1,346 @@
+// Package tracker keeps the status of deployments while they run and for a while after they finish.
+package tracker
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+	"sync"
+	"time"
+
+	C "github.com/compozed/deployadactyl/constants"
+	I "github.com/compozed/deployadactyl/interfaces"
+)
+
+// DefaultRetention is how long a finished deployment is kept before it is forgotten.
+const DefaultRetention = 24 * time.Hour
+
+// DefaultMaxLines is how many lines of output are kept for a deployment.
+const DefaultMaxLines = 10000
+
+// Tracker holds the status and the output of every deployment by UUID.
+type Tracker struct {
+	Retention time.Duration
+	// MaxLines is how many lines of output are kept for a deployment, both of the lines it publishes and of
+	// the output of its result. Once there are more, the oldest are dropped and subscribers that have not
+	// received them yet skip them. Zero keeps every line.
+	MaxLines    int
+	deployments map[string]*trackedDeployment
+	lock        sync.RWMutex
+	published   *sync.Cond
+}
+
+type trackedDeployment struct {
+	I.DeploymentStatus
+	lines []I.OutputLine
+	// dropped is how many lines were published before the first line in lines.
+	dropped     int
+	subscribers int
+	// credentials is the digest of the basic auth the deployment was started with.
+	credentials [sha256.Size]byte
+}
+
+// NewTracker returns a Tracker that keeps finished deployments for the DefaultRetention
+// and the last DefaultMaxLines lines of output of each deployment.
+func NewTracker() *Tracker {
+	t := &Tracker{
+		Retention:   DefaultRetention,
+		MaxLines:    DefaultMaxLines,
+		deployments: make(map[string]*trackedDeployment),
+	}
+	t.published = sync.NewCond(&t.lock)
+
+	return t
+}
+
+// Start registers a new running deployment started with the authorization and forgets deployments
+// that finished longer ago than the Retention.
+func (t *Tracker) Start(uuid string, cfContext I.CFContext, authorization I.Authorization) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := time.Now()
+	for id, deployment := range t.deployments {
+		if deployment.FinishedAt != nil && now.Sub(*deployment.FinishedAt) > t.Retention {
+			delete(t.deployments, id)
+		}
+	}
+
+	t.deployments[uuid] = &trackedDeployment{
+		DeploymentStatus: I.DeploymentStatus{
+			UUID:         uuid,
+			Environment:  cfContext.Environment,
+			Organization: cfContext.Organization,
+			Space:        cfContext.Space,
+			Application:  cfContext.Application,
+			Status:       C.DeploymentStatusRunning,
+			Phase:        C.DeploymentPhaseStarted,
+			Foundations:  make(map[string]I.FoundationStatus),
+			StartedAt:    now,
+		},
+		credentials: digest(authorization),
+	}
+}
+
+// Authorized tells whether the authorization is the one the deployment was started with.
+func (t *Tracker) Authorized(uuid string, authorization I.Authorization) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	deployment, ok := t.deployments[uuid]
+	if !ok {
+		return false
+	}
+
+	credentials := digest(authorization)
+	return subtle.ConstantTimeCompare(deployment.credentials[:], credentials[:]) == 1
+}
+
+func digest(authorization I.Authorization) [sha256.Size]byte {
+	return sha256.Sum256([]byte(authorization.Username + "\x00" + authorization.Password))
+}
+
+// SetPhase records the phase the deployment is currently in.
+func (t *Tracker) SetPhase(uuid, phase string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if deployment, ok := t.deployments[uuid]; ok {
+		deployment.Phase = phase
+	}
+}
+
+// SetFoundationStatus records the phase a foundation just went through and the error it returned.
+func (t *Tracker) SetFoundationStatus(uuid, foundationURL, phase string, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	deployment, ok := t.deployments[uuid]
+	if !ok {
+		return
+	}
+
+	status := I.FoundationStatus{Phase: phase}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	deployment.Foundations[foundationURL] = status
+}
+
+// Finish records the final DeployResponse and output of the deployment. Only the last MaxLines lines of the output are kept.
+func (t *Tracker) Finish(uuid string, deployResponse I.DeployResponse, output string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	deployment, ok := t.deployments[uuid]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	deployment.Phase = C.DeploymentPhaseFinished
+	deployment.FinishedAt = &now
+	deployment.Result = &I.DeploymentResult{
+		StatusCode: deployResponse.StatusCode,
+		Output:     lastLines(output, t.MaxLines),
+	}
+
+	if deployResponse.Error != nil {
+		deployment.Status = C.DeploymentStatusFailed
+		deployment.Result.Error = deployResponse.Error.Error()
+	} else {
+		deployment.Status = C.DeploymentStatusSucceeded
+	}
+
+	if deployment.subscribers == 0 {
+		deployment.dropLines()
+	}
+
+	t.published.Broadcast()
+}
+
+// Publish records a line of output from a foundation and hands it to every subscriber of the deployment.
+func (t *Tracker) Publish(uuid string, line I.OutputLine) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	deployment, ok := t.deployments[uuid]
+	if !ok {
+		return
+	}
+
+	deployment.lines = append(deployment.lines, line)
+	if t.MaxLines > 0 && len(deployment.lines) > t.MaxLines {
+		excess := len(deployment.lines) - t.MaxLines
+		deployment.lines = append([]I.OutputLine(nil), deployment.lines[excess:]...)
+		deployment.dropped += excess
+	}
+
+	t.published.Broadcast()
+}
+
+// lastLines returns the last max lines of the output, or all of it when max is zero.
+func lastLines(output string, max int) string {
+	if max <= 0 {
+		return output
+	}
+
+	end := len(output)
+	if strings.HasSuffix(output, "\n") {
+		end--
+	}
+
+	for i := end - 1; i >= 0; i-- {
+		if output[i] == '\n' {
+			max--
+			if max == 0 {
+				return output[i+1:]
+			}
+		}
+	}
+
+	return output
+}
+
+// dropLines forgets the lines of a finished deployment that nobody is receiving any more.
+// Its output is still part of its result.
+func (d *trackedDeployment) dropLines() {
+	d.dropped += len(d.lines)
+	d.lines = nil
+}
+
+// Hold keeps the lines of the deployment until release is called, even once it has finished,
+// so that they can still be received after the deployment is done.
+func (t *Tracker) Hold(uuid string) func() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	deployment, ok := t.deployments[uuid]
+	if !ok {
+		return func() {}
+	}
+	deployment.subscribers++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { t.unsubscribe(uuid) })
+	}
+}
+
+// Subscribe returns every line of output the deployment has published so far followed by the lines it publishes later on.
+// The channel is closed once the deployment has finished and all of its lines have been received, or when unsubscribe is called.
+// The lines of a finished deployment are dropped once its last subscriber is gone and nothing holds them.
+func (t *Tracker) Subscribe(uuid string) (<-chan I.OutputLine, func()) {
+	lines := make(chan I.OutputLine)
+	done := make(chan struct{})
+
+	t.lock.Lock()
+	if deployment, ok := t.deployments[uuid]; ok {
+		deployment.subscribers++
+	}
+	t.lock.Unlock()
+
+	go func() {
+		defer close(lines)
+		defer t.unsubscribe(uuid)
+
+		for next := 0; ; next++ {
+			line, index, ok := t.waitForLine(uuid, next, done)
+			if !ok {
+				return
+			}
+			next = index
+
+			select {
+			case lines <- line:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			t.lock.Lock()
+			close(done)
+			t.published.Broadcast()
+			t.lock.Unlock()
+		})
+	}
+
+	return lines, unsubscribe
+}
+
+func (t *Tracker) unsubscribe(uuid string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	deployment, ok := t.deployments[uuid]
+	if !ok {
+		return
+	}
+
+	deployment.subscribers--
+	if deployment.subscribers == 0 && deployment.FinishedAt != nil {
+		deployment.dropLines()
+	}
+}
+
+// waitForLine returns the line with the index next, or the oldest line that is still kept
+// when it has been dropped, together with its index.
+func (t *Tracker) waitForLine(uuid string, next int, done <-chan struct{}) (I.OutputLine, int, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for {
+		select {
+		case <-done:
+			return I.OutputLine{}, 0, false
+		default:
+		}
+
+		deployment, ok := t.deployments[uuid]
+		if !ok {
+			return I.OutputLine{}, 0, false
+		}
+
+		if next < deployment.dropped {
+			next = deployment.dropped
+		}
+
+		if next-deployment.dropped < len(deployment.lines) {
+			return deployment.lines[next-deployment.dropped], next, true
+		}
+
+		if deployment.FinishedAt != nil {
+			return I.OutputLine{}, 0, false
+		}
+
+		t.published.Wait()
+	}
+}
+
+// Get returns a copy of the status of the deployment.
+func (t *Tracker) Get(uuid string) (I.DeploymentStatus, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	deployment, ok := t.deployments[uuid]
+	if !ok {
+		return I.DeploymentStatus{}, false
+	}
+
+	status := deployment.DeploymentStatus
+	status.Foundations = make(map[string]I.FoundationStatus, len(deployment.Foundations))
+	for foundationURL, foundationStatus := range deployment.Foundations {
+		status.Foundations[foundationURL] = foundationStatus
+	}
+
+	if deployment.Result != nil {
+		result := *deployment.Result
+		status.Result = &result
+	}
+
+	return status, true
+}
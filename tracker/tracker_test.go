@@ -0,0 +1,292 @@
+package tracker_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	C "github.com/compozed/deployadactyl/constants"
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/compozed/deployadactyl/randomizer"
+	. "github.com/compozed/deployadactyl/tracker"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracker", func() {
+	var (
+		tracker       *Tracker
+		uuid          string
+		foundationURL string
+		cfContext     I.CFContext
+	)
+
+	BeforeEach(func() {
+		uuid = "uuid-" + randomizer.StringRunes(10)
+		foundationURL = "foundationURL-" + randomizer.StringRunes(10)
+		cfContext = I.CFContext{
+			Environment:  "environment-" + randomizer.StringRunes(10),
+			Organization: "org-" + randomizer.StringRunes(10),
+			Space:        "space-" + randomizer.StringRunes(10),
+			Application:  "appName-" + randomizer.StringRunes(10),
+		}
+
+		tracker = NewTracker()
+	})
+
+	Describe("Start", func() {
+		It("registers a running deployment", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+
+			status, ok := tracker.Get(uuid)
+
+			Expect(ok).To(BeTrue())
+			Expect(status.UUID).To(Equal(uuid))
+			Expect(status.Environment).To(Equal(cfContext.Environment))
+			Expect(status.Organization).To(Equal(cfContext.Organization))
+			Expect(status.Space).To(Equal(cfContext.Space))
+			Expect(status.Application).To(Equal(cfContext.Application))
+			Expect(status.Status).To(Equal(C.DeploymentStatusRunning))
+			Expect(status.Phase).To(Equal(C.DeploymentPhaseStarted))
+			Expect(status.Result).To(BeNil())
+		})
+
+		It("forgets deployments that finished longer ago than the retention", func() {
+			tracker.Retention = time.Nanosecond
+			tracker.Start(uuid, cfContext, I.Authorization{})
+			tracker.Finish(uuid, I.DeployResponse{StatusCode: http.StatusOK}, "")
+
+			time.Sleep(time.Millisecond)
+			tracker.Start("another-uuid", cfContext, I.Authorization{})
+
+			_, ok := tracker.Get(uuid)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Authorized", func() {
+		It("accepts only the credentials the deployment was started with", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{Username: "username", Password: "password"})
+
+			Expect(tracker.Authorized(uuid, I.Authorization{Username: "username", Password: "password"})).To(BeTrue())
+			Expect(tracker.Authorized(uuid, I.Authorization{Username: "username", Password: "wrong password"})).To(BeFalse())
+			Expect(tracker.Authorized(uuid, I.Authorization{Username: "someone else", Password: "password"})).To(BeFalse())
+		})
+
+		It("rejects unknown deployments", func() {
+			Expect(tracker.Authorized(uuid, I.Authorization{})).To(BeFalse())
+		})
+	})
+
+	Describe("SetPhase", func() {
+		It("records the phase", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+
+			tracker.SetPhase(uuid, C.DeploymentPhaseExecute)
+
+			status, _ := tracker.Get(uuid)
+			Expect(status.Phase).To(Equal(C.DeploymentPhaseExecute))
+		})
+
+		It("ignores unknown deployments", func() {
+			tracker.SetPhase(uuid, C.DeploymentPhaseExecute)
+
+			_, ok := tracker.Get(uuid)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("SetFoundationStatus", func() {
+		It("records the phase of the foundation", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+
+			tracker.SetFoundationStatus(uuid, foundationURL, C.DeploymentPhaseLogin, nil)
+
+			status, _ := tracker.Get(uuid)
+			Expect(status.Foundations[foundationURL]).To(Equal(I.FoundationStatus{Phase: C.DeploymentPhaseLogin}))
+		})
+
+		It("records the error of the foundation", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+
+			tracker.SetFoundationStatus(uuid, foundationURL, C.DeploymentPhaseExecute, errors.New("push failed"))
+
+			status, _ := tracker.Get(uuid)
+			Expect(status.Foundations[foundationURL].Error).To(Equal("push failed"))
+		})
+	})
+
+	Describe("Finish", func() {
+		It("records a successful result", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+
+			tracker.Finish(uuid, I.DeployResponse{StatusCode: http.StatusOK}, "deploy output")
+
+			status, _ := tracker.Get(uuid)
+			Expect(status.Status).To(Equal(C.DeploymentStatusSucceeded))
+			Expect(status.Phase).To(Equal(C.DeploymentPhaseFinished))
+			Expect(status.FinishedAt).ToNot(BeNil())
+			Expect(*status.Result).To(Equal(I.DeploymentResult{StatusCode: http.StatusOK, Output: "deploy output"}))
+		})
+
+		It("records a failed result", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+
+			tracker.Finish(uuid, I.DeployResponse{StatusCode: http.StatusInternalServerError, Error: errors.New("deploy failed")}, "deploy output")
+
+			status, _ := tracker.Get(uuid)
+			Expect(status.Status).To(Equal(C.DeploymentStatusFailed))
+			Expect(status.Result.StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(status.Result.Error).To(Equal("deploy failed"))
+		})
+
+		It("keeps only the last MaxLines lines of the output", func() {
+			tracker.MaxLines = 2
+			tracker.Start(uuid, cfContext, I.Authorization{})
+
+			tracker.Finish(uuid, I.DeployResponse{StatusCode: http.StatusOK}, "line-0\nline-1\nline-2\n")
+
+			status, _ := tracker.Get(uuid)
+			Expect(status.Result.Output).To(Equal("line-1\nline-2\n"))
+		})
+	})
+
+	Describe("Get", func() {
+		It("returns a copy of the status", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+			status, _ := tracker.Get(uuid)
+
+			status.Foundations[foundationURL] = I.FoundationStatus{Phase: C.DeploymentPhaseLogin}
+
+			status, _ = tracker.Get(uuid)
+			Expect(status.Foundations).To(BeEmpty())
+		})
+	})
+
+	Describe("Subscribe", func() {
+		var line I.OutputLine
+
+		BeforeEach(func() {
+			line = I.OutputLine{FoundationURL: foundationURL, Line: "line-" + randomizer.StringRunes(10)}
+		})
+
+		It("receives the lines published before it subscribed", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+			tracker.Publish(uuid, line)
+
+			lines, unsubscribe := tracker.Subscribe(uuid)
+			defer unsubscribe()
+
+			Eventually(lines).Should(Receive(Equal(line)))
+		})
+
+		It("receives the lines published after it subscribed", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+
+			lines, unsubscribe := tracker.Subscribe(uuid)
+			defer unsubscribe()
+
+			tracker.Publish(uuid, line)
+
+			Eventually(lines).Should(Receive(Equal(line)))
+		})
+
+		It("is closed once the deployment has finished", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+
+			lines, unsubscribe := tracker.Subscribe(uuid)
+			defer unsubscribe()
+
+			tracker.Publish(uuid, line)
+			tracker.Finish(uuid, I.DeployResponse{StatusCode: http.StatusOK}, "")
+
+			Eventually(lines).Should(Receive(Equal(line)))
+			Eventually(lines).Should(BeClosed())
+		})
+
+		It("is closed when unsubscribing", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+
+			lines, unsubscribe := tracker.Subscribe(uuid)
+			unsubscribe()
+
+			Eventually(lines).Should(BeClosed())
+		})
+
+		It("skips the oldest lines once there are more than MaxLines", func() {
+			tracker.MaxLines = 2
+			tracker.Start(uuid, cfContext, I.Authorization{})
+			for i := 0; i < 3; i++ {
+				tracker.Publish(uuid, I.OutputLine{FoundationURL: foundationURL, Line: fmt.Sprintf("line-%d", i)})
+			}
+
+			lines, unsubscribe := tracker.Subscribe(uuid)
+			defer unsubscribe()
+
+			Eventually(lines).Should(Receive(Equal(I.OutputLine{FoundationURL: foundationURL, Line: "line-1"})))
+			Eventually(lines).Should(Receive(Equal(I.OutputLine{FoundationURL: foundationURL, Line: "line-2"})))
+		})
+
+		It("drops the lines once the deployment has finished without subscribers", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+			tracker.Publish(uuid, line)
+			tracker.Finish(uuid, I.DeployResponse{StatusCode: http.StatusOK}, "")
+
+			lines, unsubscribe := tracker.Subscribe(uuid)
+			defer unsubscribe()
+
+			Eventually(lines).Should(BeClosed())
+			Expect(lines).ToNot(Receive())
+		})
+
+		It("keeps the lines of a finished deployment until the last subscriber is gone", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+			tracker.Publish(uuid, line)
+
+			first, unsubscribeFirst := tracker.Subscribe(uuid)
+			tracker.Finish(uuid, I.DeployResponse{StatusCode: http.StatusOK}, "")
+
+			second, unsubscribeSecond := tracker.Subscribe(uuid)
+			Eventually(second).Should(Receive(Equal(line)))
+			Eventually(second).Should(BeClosed())
+			unsubscribeSecond()
+
+			unsubscribeFirst()
+			Eventually(first).Should(BeClosed())
+
+			third, unsubscribeThird := tracker.Subscribe(uuid)
+			defer unsubscribeThird()
+
+			Eventually(third).Should(BeClosed())
+			Expect(third).ToNot(Receive())
+		})
+
+		It("keeps the lines of a finished deployment while they are held", func() {
+			tracker.Start(uuid, cfContext, I.Authorization{})
+			release := tracker.Hold(uuid)
+			tracker.Publish(uuid, line)
+			tracker.Finish(uuid, I.DeployResponse{StatusCode: http.StatusOK}, "")
+
+			lines, unsubscribe := tracker.Subscribe(uuid)
+			Eventually(lines).Should(Receive(Equal(line)))
+			Eventually(lines).Should(BeClosed())
+			unsubscribe()
+
+			release()
+
+			lines, unsubscribe = tracker.Subscribe(uuid)
+			defer unsubscribe()
+			Eventually(lines).Should(BeClosed())
+			Expect(lines).ToNot(Receive())
+		})
+
+		It("is closed for unknown deployments", func() {
+			lines, unsubscribe := tracker.Subscribe(uuid)
+			defer unsubscribe()
+
+			Eventually(lines).Should(BeClosed())
+		})
+	})
+})